@@ -10,43 +10,62 @@ import (
 
 	"github.com/gorilla/mux"
 	"github.com/meterio/meter-pov/api/utils"
+	"github.com/meterio/meter-pov/chain"
 	"github.com/meterio/meter-pov/meter"
 	"github.com/meterio/meter-pov/script/accountlock"
+	"github.com/meterio/meter-pov/state"
 	"github.com/pkg/errors"
 )
 
 type AccountLock struct {
+	chain        *chain.Chain
+	stateCreator *state.Creator
 }
 
-func New() *AccountLock {
-	return &AccountLock{}
+func New(chain *chain.Chain,
+	stateCreator *state.Creator) *AccountLock {
+	return &AccountLock{chain: chain, stateCreator: stateCreator}
 }
 
 func (a *AccountLock) handleGetAccountLockProfile(w http.ResponseWriter, req *http.Request) error {
-	list, err := accountlock.GetLatestProfileList()
+	st, err := a.handleState(req.URL.Query().Get("revision"))
 	if err != nil {
 		return err
 	}
+	list := accountlock.GetProfileListByState(st)
 	profileList := convertProfileList(list)
 	return utils.WriteJSON(w, profileList)
 }
 
 func (a *AccountLock) handleGetProfileByID(w http.ResponseWriter, req *http.Request) error {
-	list, err := accountlock.GetLatestProfileList()
+	st, err := a.handleState(req.URL.Query().Get("revision"))
 	if err != nil {
 		return err
 	}
+	list := accountlock.GetProfileListByState(st)
 	id := mux.Vars(req)["address"]
 	bytes, err := meter.ParseAddress(id)
 	if err != nil {
 		return utils.BadRequest(errors.WithMessage(err, "address"))
 	}
 	s := list.Get(bytes)
+	if s == nil {
+		return utils.WriteJSON(w, nil)
+	}
 	profile := convertProfile(s)
 	return utils.WriteJSON(w, profile)
 }
 
+func (a *AccountLock) handleState(revision string) (*state.State, error) {
+	h, err := utils.HandleRevision(a.chain, revision)
+	if err != nil {
+		return nil, err
+	}
+	return a.stateCreator.NewState(h.StateRoot())
+}
+
 func (a *AccountLock) Mount(root *mux.Router, pathPrefix string) {
 	sub := root.PathPrefix(pathPrefix).Subrouter()
 	sub.Path("/profiles").Methods("Get").HandlerFunc(utils.WrapHandlerFunc(a.handleGetAccountLockProfile))
+	sub.Path("/profiles/{address}").Methods("Get").HandlerFunc(utils.WrapHandlerFunc(a.handleGetProfileByID))
 }
@@ -10,7 +10,7 @@ import (
 	"fmt"
 	"math/big"
 	"net/http"
-	"strconv"
+	"strings"
 
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/common/math"
@@ -59,7 +59,7 @@ func (a *Accounts) handleGetCode(w http.ResponseWriter, req *http.Request) error
 	if err != nil {
 		return utils.BadRequest(errors.WithMessage(err, "address"))
 	}
-	h, err := a.handleRevision(req.URL.Query().Get("revision"))
+	h, err := utils.HandleRevision(a.chain, req.URL.Query().Get("revision"))
 	if err != nil {
 		return err
 	}
@@ -109,7 +109,7 @@ func (a *Accounts) handleGetAccount(w http.ResponseWriter, req *http.Request) er
 	if err != nil {
 		return utils.BadRequest(errors.WithMessage(err, "address"))
 	}
-	h, err := a.handleRevision(req.URL.Query().Get("revision"))
+	h, err := utils.HandleRevision(a.chain, req.URL.Query().Get("revision"))
 	if err != nil {
 		return err
 	}
@@ -129,7 +129,7 @@ func (a *Accounts) handleGetStorage(w http.ResponseWriter, req *http.Request) er
 	if err != nil {
 		return utils.BadRequest(errors.WithMessage(err, "key"))
 	}
-	h, err := a.handleRevision(req.URL.Query().Get("revision"))
+	h, err := utils.HandleRevision(a.chain, req.URL.Query().Get("revision"))
 	if err != nil {
 		return err
 	}
@@ -140,6 +140,72 @@ func (a *Accounts) handleGetStorage(w http.ResponseWriter, req *http.Request) er
 	return utils.WriteJSON(w, map[string]string{"value": storage.String()})
 }
 
+func (a *Accounts) getProof(addr meter.Address, keys []meter.Bytes32, stateRoot meter.Bytes32) (*AccountProof, error) {
+	state, err := a.stateCreator.NewState(stateRoot)
+	if err != nil {
+		return nil, err
+	}
+	acc, accountProof, err := state.ProveAccount(addr)
+	if err != nil {
+		return nil, err
+	}
+	storageProof := make([]*StorageProof, 0, len(keys))
+	for _, key := range keys {
+		proof, err := state.ProveStorage(addr, key)
+		if err != nil {
+			return nil, err
+		}
+		storageProof = append(storageProof, &StorageProof{
+			Key:   key,
+			Value: state.GetStorage(addr, key),
+			Proof: encodeProof(proof),
+		})
+	}
+	if err := state.Err(); err != nil {
+		return nil, err
+	}
+	return &AccountProof{
+		Address:      addr,
+		Balance:      math.HexOrDecimal256(*acc.Balance),
+		Energy:       math.HexOrDecimal256(*acc.Energy),
+		BoundBalance: math.HexOrDecimal256(*acc.BoundBalance),
+		BoundEnergy:  math.HexOrDecimal256(*acc.BoundEnergy),
+		Master:       hexutil.Encode(acc.Master),
+		CodeHash:     meter.BytesToBytes32(acc.CodeHash),
+		StorageRoot:  meter.BytesToBytes32(acc.StorageRoot),
+		StateRoot:    stateRoot,
+		AccountProof: encodeProof(accountProof),
+		StorageProof: storageProof,
+	}, nil
+}
+
+func (a *Accounts) handleGetProof(w http.ResponseWriter, req *http.Request) error {
+	addr, err := meter.ParseAddress(mux.Vars(req)["address"])
+	if err != nil {
+		return utils.BadRequest(errors.WithMessage(err, "address"))
+	}
+	var keys []meter.Bytes32
+	for _, k := range strings.Split(req.URL.Query().Get("keys"), ",") {
+		if k = strings.TrimSpace(k); k == "" {
+			continue
+		}
+		key, err := meter.ParseBytes32(k)
+		if err != nil {
+			return utils.BadRequest(errors.WithMessage(err, "keys"))
+		}
+		keys = append(keys, key)
+	}
+	h, err := utils.HandleRevision(a.chain, req.URL.Query().Get("revision"))
+	if err != nil {
+		return err
+	}
+	proof, err := a.getProof(addr, keys, h.StateRoot())
+	if err != nil {
+		return err
+	}
+	return utils.WriteJSON(w, proof)
+}
+
 func (a *Accounts) handleCallPow(w http.ResponseWriter, req *http.Request) error {
 	callData := &CallData{}
 	callPow := &CallPow{}
@@ -148,7 +214,7 @@ func (a *Accounts) handleCallPow(w http.ResponseWriter, req *http.Request) error
 	if err := utils.ParseJSON(req.Body, &callPow); err != nil {
 		return utils.BadRequest(errors.WithMessage(err, "body"))
 	}
-	h, err := a.handleRevision(req.URL.Query().Get("revision"))
+	h, err := utils.HandleRevision(a.chain, req.URL.Query().Get("revision"))
 	if err != nil {
 		return err
 	}
@@ -192,7 +258,7 @@ func (a *Accounts) handleCallContract(w http.ResponseWriter, req *http.Request)
 	fmt.Println("Gas:", callData.Gas)
 	fmt.Println("GasPrice:", callData.GasPrice)
 	fmt.Println("mux.Vars(req)['address']: ", mux.Vars(req)["address"])
-	h, err := a.handleRevision(req.URL.Query().Get("revision"))
+	h, err := utils.HandleRevision(a.chain, req.URL.Query().Get("revision"))
 	if err != nil {
 		fmt.Println("Error in handleRevision:", err)
 		return err
@@ -236,7 +302,7 @@ func (a *Accounts) handleCallBatchCode(w http.ResponseWriter, req *http.Request)
 	if err := utils.ParseJSON(req.Body, &batchCallData); err != nil {
 		return utils.BadRequest(errors.WithMessage(err, "body"))
 	}
-	h, err := a.handleRevision(req.URL.Query().Get("revision"))
+	h, err := utils.HandleRevision(a.chain, req.URL.Query().Get("revision"))
 	if err != nil {
 		return err
 	}
@@ -258,6 +324,12 @@ func (a *Accounts) batchCall(ctx context.Context, batchCallData *BatchCallData,
 		return nil, err
 	}
 	signer, _ := header.Signer()
+	var epoch uint64
+	if header.Number() > 0 {
+		if epoch, err = a.chain.GetBlockEpoch(header.ParentID()); err != nil {
+			return nil, err
+		}
+	}
 	rt := runtime.New(a.chain.NewSeeker(header.ParentID()), state,
 		&xenv.BlockContext{
 			Beneficiary: header.Beneficiary(),
@@ -265,7 +337,8 @@ func (a *Accounts) batchCall(ctx context.Context, batchCallData *BatchCallData,
 			Number:      header.Number(),
 			Time:        header.Timestamp(),
 			GasLimit:    header.GasLimit(),
-			TotalScore:  header.TotalScore()})
+			TotalScore:  header.TotalScore(),
+			Epoch:       epoch})
 	results = make(BatchCallResults, 0)
 	vmout := make(chan *runtime.Output, 1)
 	best := a.chain.BestBlock()
@@ -344,41 +417,6 @@ func (a *Accounts) handleBatchCallData(batchCallData *BatchCallData) (gas uint64
 	return
 }
 
-func (a *Accounts) handleRevision(revision string) (*block.Header, error) {
-	if revision == "" || revision == "best" {
-		return a.chain.BestBlock().Header(), nil
-	}
-	if len(revision) == 66 || len(revision) == 64 {
-		blockID, err := meter.ParseBytes32(revision)
-		if err != nil {
-			return nil, utils.BadRequest(errors.WithMessage(err, "revision"))
-		}
-		h, err := a.chain.GetBlockHeader(blockID)
-		if err != nil {
-			if a.chain.IsNotFound(err) {
-				return nil, utils.BadRequest(errors.WithMessage(err, "revision"))
-			}
-			return nil, err
-		}
-		return h, nil
-	}
-	n, err := strconv.ParseUint(revision, 0, 0)
-	if err != nil {
-		return nil, utils.BadRequest(errors.WithMessage(err, "revision"))
-	}
-	if n > math.MaxUint32 {
-		return nil, utils.BadRequest(errors.WithMessage(errors.New("block number out of max uint32"), "revision"))
-	}
-	h, err := a.chain.GetTrunkBlockHeader(uint32(n))
-	if err != nil {
-		if a.chain.IsNotFound(err) {
-			return nil, utils.BadRequest(errors.WithMessage(err, "revision"))
-		}
-		return nil, err
-	}
-	return h, nil
-}
-
 func (a *Accounts) Mount(root *mux.Router, pathPrefix string) {
 	sub := root.PathPrefix(pathPrefix).Subrouter()
 
@@ -386,6 +424,7 @@ func (a *Accounts) Mount(root *mux.Router, pathPrefix string) {
 	sub.Path("/{address}").Methods(http.MethodGet).HandlerFunc(utils.WrapHandlerFunc(a.handleGetAccount))
 	sub.Path("/{address}/code").Methods(http.MethodGet).HandlerFunc(utils.WrapHandlerFunc(a.handleGetCode))
 	sub.Path("/{address}/storage/{key}").Methods("GET").HandlerFunc(utils.WrapHandlerFunc(a.handleGetStorage))
+	sub.Path("/{address}/proof").Methods(http.MethodGet).HandlerFunc(utils.WrapHandlerFunc(a.handleGetProof))
 	sub.Path("").Methods("POST").HandlerFunc(utils.WrapHandlerFunc(a.handleCallPow))
 	sub.Path("/{address}").Methods("POST").HandlerFunc(utils.WrapHandlerFunc(a.handleCallContract))
 
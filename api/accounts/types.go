@@ -24,6 +24,37 @@ type Account struct {
 	HasCode      bool                 `json:"hasCode"`
 }
 
+// AccountProof is the account along with the merkle proofs of the account and the storage
+// slots, against the state root of the block.
+type AccountProof struct {
+	Address      meter.Address        `json:"address"`
+	Balance      math.HexOrDecimal256 `json:"balance"`
+	Energy       math.HexOrDecimal256 `json:"energy"`
+	BoundBalance math.HexOrDecimal256 `json:"boundbalance"`
+	BoundEnergy  math.HexOrDecimal256 `json:"boundenergy"`
+	Master       string               `json:"master"`
+	CodeHash     meter.Bytes32        `json:"codeHash"`
+	StorageRoot  meter.Bytes32        `json:"storageRoot"`
+	StateRoot    meter.Bytes32        `json:"stateRoot"`
+	AccountProof []string             `json:"accountProof"`
+	StorageProof []*StorageProof      `json:"storageProof"`
+}
+
+// StorageProof is the storage value along with its merkle proof against the storage root.
+type StorageProof struct {
+	Key   meter.Bytes32 `json:"key"`
+	Value meter.Bytes32 `json:"value"`
+	Proof []string      `json:"proof"`
+}
+
+func encodeProof(proof [][]byte) []string {
+	nodes := make([]string, 0, len(proof))
+	for _, node := range proof {
+		nodes = append(nodes, hexutil.Encode(node))
+	}
+	return nodes
+}
+
 //CallData represents contract-call body
 type CallData struct {
 	Value    *math.HexOrDecimal256 `json:"value"`
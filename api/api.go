@@ -14,13 +14,17 @@ import (
 	"github.com/gorilla/mux"
 	"github.com/meterio/meter-pov/api/accountlock"
 	"github.com/meterio/meter-pov/api/accounts"
+	"github.com/meterio/meter-pov/api/auction"
 	"github.com/meterio/meter-pov/api/blocks"
 	"github.com/meterio/meter-pov/api/debug"
 	"github.com/meterio/meter-pov/api/doc"
+	"github.com/meterio/meter-pov/api/ethrpc"
 	"github.com/meterio/meter-pov/api/events"
 	"github.com/meterio/meter-pov/api/eventslegacy"
 	"github.com/meterio/meter-pov/api/node"
 	"github.com/meterio/meter-pov/api/peers"
+	"github.com/meterio/meter-pov/api/pool"
+	"github.com/meterio/meter-pov/api/script"
 	"github.com/meterio/meter-pov/api/slashing"
 	"github.com/meterio/meter-pov/api/staking"
 	"github.com/meterio/meter-pov/api/subscriptions"
@@ -31,17 +35,23 @@ import (
 	"github.com/meterio/meter-pov/logdb"
 	"github.com/meterio/meter-pov/p2psrv"
 	"github.com/meterio/meter-pov/state"
+	"github.com/meterio/meter-pov/tracedb"
 	"github.com/meterio/meter-pov/txpool"
+	"github.com/prometheus/client_golang/prometheus"
 )
 
 //New return api router
-func New(chain *chain.Chain, stateCreator *state.Creator, txPool *txpool.TxPool, logDB *logdb.LogDB, nw node.Network, allowedOrigins string, backtraceLimit uint32, callGasLimit uint64, p2pServer *p2psrv.Server, pubKey string) (http.HandlerFunc, func()) {
+func New(chain *chain.Chain, stateCreator *state.Creator, txPool *txpool.TxPool, logDB *logdb.LogDB, traceDB *tracedb.TraceDB, nw node.Network, allowedOrigins string, backtraceLimit uint32, callGasLimit uint64, p2pServer *p2psrv.Server, pubKey string) (http.HandlerFunc, func()) {
 	origins := strings.Split(strings.TrimSpace(allowedOrigins), ",")
 	for i, o := range origins {
 		origins[i] = strings.ToLower(strings.TrimSpace(o))
 	}
 
+	prometheus.Register(apiRequestDuration)
+	prometheus.Register(apiRequestCounter)
+
 	router := mux.NewRouter()
+	router.Use(metricsMiddleware)
 
 	// to serve api doc and swagger-ui
 	router.PathPrefix("/doc").Handler(
@@ -71,25 +81,31 @@ func New(chain *chain.Chain, stateCreator *state.Creator, txPool *txpool.TxPool,
 		Mount(router, "/logs/transfers")
 	transfers.New(logDB).
 		Mount(router, "/logs/transfer")
-	blocks.New(chain).
+	blocks.New(chain, stateCreator).
 		Mount(router, "/blocks")
 	transactions.New(chain, txPool).
 		Mount(router, "/transactions")
-	debug.New(chain, stateCreator).
+	pool.New(txPool).
+		Mount(router, "/txpool")
+	debug.New(chain, stateCreator, traceDB).
 		Mount(router, "/debug")
+	ethrpc.New(chain, stateCreator, txPool, logDB, nw, callGasLimit).
+		Mount(router, "/rpc")
 	node.New(nw, pubKey).
 		Mount(router, "/node")
 	peers.New(p2pServer).Mount(router, "/peers")
-	subs := subscriptions.New(chain, origins, backtraceLimit)
+	subs := subscriptions.New(chain, txPool, origins, backtraceLimit)
 	subs.Mount(router, "/subscriptions")
 	staking.New(chain, stateCreator).
 		Mount(router, "/staking")
-	slashing.New().
+	slashing.New(chain, stateCreator).
 		Mount(router, "/slashing")
-	//auction.New(chain, stateCreator).
-	//	Mount(router, "/auction")
-	accountlock.New().
+	auction.New(chain, stateCreator).
+		Mount(router, "/auction")
+	accountlock.New(chain, stateCreator).
 		Mount(router, "/accountlock")
+	script.New().
+		Mount(router, "/script")
 
 	return handlers.CORS(
 			handlers.AllowedOrigins(origins),
@@ -6,13 +6,11 @@
 package auction
 
 import (
-	"math"
 	"net/http"
 	"strconv"
 
 	"github.com/gorilla/mux"
 	"github.com/meterio/meter-pov/api/utils"
-	"github.com/meterio/meter-pov/block"
 	"github.com/meterio/meter-pov/chain"
 	"github.com/meterio/meter-pov/meter"
 	"github.com/meterio/meter-pov/script/auction"
@@ -31,28 +29,24 @@ func New(chain *chain.Chain,
 }
 
 func (at *Auction) handleGetAuctionSummary(w http.ResponseWriter, req *http.Request) error {
-	h, err := at.handleRevision(req.URL.Query().Get("revision"))
+	st, err := at.handleState(req.URL.Query().Get("revision"))
 	if err != nil {
 		return err
 	}
-	list, err := auction.GetAuctionSummaryListByHeader(h)
+	summaries, err := paginate(auction.GetSummaryListByState(st).Summaries, req)
 	if err != nil {
 		return err
 	}
-	summaryList := convertSummaryList(list)
+	summaryList := convertSummaryList(auction.NewAuctionSummaryList(summaries))
 	return utils.WriteJSON(w, summaryList)
 }
 
 func (at *Auction) handleGetLastAuctionSummary(w http.ResponseWriter, req *http.Request) error {
-	h, err := at.handleRevision(req.URL.Query().Get("revision"))
+	st, err := at.handleState(req.URL.Query().Get("revision"))
 	if err != nil {
 		return err
 	}
-	list, err := auction.GetAuctionSummaryListByHeader(h)
-	if err != nil {
-		return err
-	}
-	last := list.Last()
+	last := auction.GetSummaryListByState(st).Last()
 	if last == nil {
 		last = &auction.AuctionSummary{}
 	}
@@ -61,94 +55,149 @@ func (at *Auction) handleGetLastAuctionSummary(w http.ResponseWriter, req *http.
 }
 
 func (at *Auction) handleGetAuctionDigest(w http.ResponseWriter, req *http.Request) error {
-	h, err := at.handleRevision(req.URL.Query().Get("revision"))
+	st, err := at.handleState(req.URL.Query().Get("revision"))
 	if err != nil {
 		return err
 	}
-	list, err := auction.GetAuctionSummaryListByHeader(h)
+	summaries, err := paginate(auction.GetSummaryListByState(st).Summaries, req)
 	if err != nil {
 		return err
 	}
-	digestList := convertDigestList(list)
+	digestList := convertDigestList(auction.NewAuctionSummaryList(summaries))
 	return utils.WriteJSON(w, digestList)
 }
 
 func (at *Auction) handleGetSummaryByID(w http.ResponseWriter, req *http.Request) error {
-	list, err := auction.GetAuctionSummaryList()
+	st, err := at.handleState(req.URL.Query().Get("revision"))
 	if err != nil {
 		return err
 	}
-	id := mux.Vars(req)["auctionID"]
-	bytes, err := meter.ParseBytes32(id)
+	id, err := meter.ParseBytes32(mux.Vars(req)["auctionID"])
 	if err != nil {
-		return err
+		return utils.BadRequest(errors.WithMessage(err, "auctionID"))
+	}
+	s := auction.GetSummaryListByState(st).Get(id)
+	if s == nil {
+		return utils.WriteJSON(w, nil)
 	}
-	s := list.Get(bytes)
 	summary := convertSummary(s)
 	return utils.WriteJSON(w, summary)
 }
 
-func (at *Auction) handleGetAuctionCB(w http.ResponseWriter, req *http.Request) error {
-	header, err := at.handleRevision(req.URL.Query().Get("revision"))
+func (at *Auction) handleGetSummarySettlement(w http.ResponseWriter, req *http.Request) error {
+	st, err := at.handleState(req.URL.Query().Get("revision"))
 	if err != nil {
 		return err
 	}
-
-	state, err := at.stateCreator.NewState(header.StateRoot())
+	id, err := meter.ParseBytes32(mux.Vars(req)["auctionID"])
 	if err != nil {
-		return err
+		return utils.BadRequest(errors.WithMessage(err, "auctionID"))
+	}
+	s := auction.GetSummaryListByState(st).Get(id)
+	if s == nil {
+		return utils.WriteJSON(w, nil)
 	}
-	auctionInst := auction.GetAuctionGlobInst()
-	if auctionInst == nil {
-		err := errors.New("auction is not initialized...")
+	return utils.WriteJSON(w, convertSettlement(s.AuctionID, true, s.ActualPrice, s.AuctionTxs, s.DistMTRG))
+}
+
+func (at *Auction) handleGetAuctionCB(w http.ResponseWriter, req *http.Request) error {
+	st, err := at.handleState(req.URL.Query().Get("revision"))
+	if err != nil {
 		return err
 	}
-	cb := auctionInst.GetAuctionCB(state)
+	cb := auction.GetAuctionCBByState(st)
 	acb := convertAuctionCB(cb)
-
 	return utils.WriteJSON(w, acb)
 }
 
-func (at *Auction) handleRevision(revision string) (*block.Header, error) {
-	if revision == "" || revision == "best" {
-		return at.chain.BestBlock().Header(), nil
+// handleGetAuctionCBSettlement returns the settlement of the present auction if it's cleared
+// at the revision.
+func (at *Auction) handleGetAuctionCBSettlement(w http.ResponseWriter, req *http.Request) error {
+	st, err := at.handleState(req.URL.Query().Get("revision"))
+	if err != nil {
+		return err
+	}
+	cb := auction.GetAuctionCBByState(st)
+	if !cb.IsActive() {
+		return utils.WriteJSON(w, nil)
 	}
-	if len(revision) == 66 || len(revision) == 64 {
-		blockID, err := meter.ParseBytes32(revision)
+	actualPrice, dists := auction.CalcSettlement(cb)
+	return utils.WriteJSON(w, convertSettlement(cb.AuctionID, false, actualPrice, cb.AuctionTxs, dists))
+}
+
+// handleGetBidsByAddress returns the bids of the address in the present auction, and in the
+// summaries too if history is true.
+func (at *Auction) handleGetBidsByAddress(history bool) utils.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) error {
+		st, err := at.handleState(req.URL.Query().Get("revision"))
 		if err != nil {
-			return nil, utils.BadRequest(errors.WithMessage(err, "revision"))
+			return err
 		}
-		h, err := at.chain.GetBlockHeader(blockID)
+		addr, err := meter.ParseAddress(mux.Vars(req)["address"])
 		if err != nil {
-			if at.chain.IsNotFound(err) {
-				return nil, utils.BadRequest(errors.WithMessage(err, "revision"))
+			return utils.BadRequest(errors.WithMessage(err, "address"))
+		}
+
+		bids := &AddressBids{Address: addr.String(), Auctions: make([]*AuctionBids, 0)}
+		if history {
+			for _, s := range auction.GetSummaryListByState(st).Summaries {
+				if b := convertAuctionBids(addr, s.AuctionID, s.Sequence, true, s.ActualPrice, s.AuctionTxs, s.DistMTRG); b != nil {
+					bids.Auctions = append(bids.Auctions, b)
+				}
 			}
-			return nil, err
 		}
-		return h, nil
+		if cb := auction.GetAuctionCBByState(st); cb.IsActive() {
+			actualPrice, dists := auction.CalcSettlement(cb)
+			if b := convertAuctionBids(addr, cb.AuctionID, cb.Sequence, false, actualPrice, cb.AuctionTxs, dists); b != nil {
+				bids.Auctions = append(bids.Auctions, b)
+			}
+		}
+		return utils.WriteJSON(w, bids)
 	}
-	n, err := strconv.ParseUint(revision, 0, 0)
+}
+
+func (at *Auction) handleState(revision string) (*state.State, error) {
+	h, err := utils.HandleRevision(at.chain, revision)
 	if err != nil {
-		return nil, utils.BadRequest(errors.WithMessage(err, "revision"))
+		return nil, err
 	}
-	if n > math.MaxUint32 {
-		return nil, utils.BadRequest(errors.WithMessage(errors.New("block number out of max uint32"), "revision"))
+	return at.stateCreator.NewState(h.StateRoot())
+}
+
+// paginate returns the summaries in the page given by the offset and limit in query, all
+// summaries from the offset if limit is omitted.
+func paginate(summaries []*auction.AuctionSummary, req *http.Request) ([]*auction.AuctionSummary, error) {
+	offset, limit := uint64(0), uint64(len(summaries))
+	var err error
+	if s := req.URL.Query().Get("offset"); s != "" {
+		if offset, err = strconv.ParseUint(s, 10, 32); err != nil {
+			return nil, utils.BadRequest(errors.WithMessage(err, "offset"))
+		}
 	}
-	h, err := at.chain.GetTrunkBlockHeader(uint32(n))
-	if err != nil {
-		if at.chain.IsNotFound(err) {
-			return nil, utils.BadRequest(errors.WithMessage(err, "revision"))
+	if s := req.URL.Query().Get("limit"); s != "" {
+		if limit, err = strconv.ParseUint(s, 10, 32); err != nil {
+			return nil, utils.BadRequest(errors.WithMessage(err, "limit"))
 		}
-		return nil, err
 	}
-	return h, nil
+	if offset >= uint64(len(summaries)) {
+		return nil, nil
+	}
+	end := offset + limit
+	if end > uint64(len(summaries)) {
+		end = uint64(len(summaries))
+	}
+	return summaries[offset:end], nil
 }
 
 func (at *Auction) Mount(root *mux.Router, pathPrefix string) {
 	sub := root.PathPrefix(pathPrefix).Subrouter()
 	sub.Path("/summaries").Methods("Get").HandlerFunc(utils.WrapHandlerFunc(at.handleGetAuctionSummary))
-	sub.Path("/summaries/{id}").Methods("Get").HandlerFunc(utils.WrapHandlerFunc(at.handleGetSummaryByID))
+	sub.Path("/summaries/{auctionID}").Methods("Get").HandlerFunc(utils.WrapHandlerFunc(at.handleGetSummaryByID))
+	sub.Path("/summaries/{auctionID}/settlement").Methods("Get").HandlerFunc(utils.WrapHandlerFunc(at.handleGetSummarySettlement))
+	sub.Path("/digests").Methods("Get").HandlerFunc(utils.WrapHandlerFunc(at.handleGetAuctionDigest))
 	sub.Path("/last/summary").Methods("Get").HandlerFunc(utils.WrapHandlerFunc(at.handleGetLastAuctionSummary))
 	sub.Path("/present").Methods("Get").HandlerFunc(utils.WrapHandlerFunc(at.handleGetAuctionCB))
-	//sub.Path("/auctioncb/{address}").Methods("Get").HandlerFunc(utils.WrapHandlerFunc(st.handleGetAuctionTxByAddress))
+	sub.Path("/present/settlement").Methods("Get").HandlerFunc(utils.WrapHandlerFunc(at.handleGetAuctionCBSettlement))
+	sub.Path("/auctioncb/{address}").Methods("Get").HandlerFunc(utils.WrapHandlerFunc(at.handleGetBidsByAddress(false)))
+	sub.Path("/bids/{address}").Methods("Get").HandlerFunc(utils.WrapHandlerFunc(at.handleGetBidsByAddress(true)))
 }
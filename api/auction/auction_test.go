@@ -0,0 +1,157 @@
+// Copyright (c) 2020 The Meter.io developers
+
+// Distributed under the GNU Lesser General Public License v3.0 software license, see the accompanying
+// file LICENSE or <https://www.gnu.org/licenses/lgpl-3.0.html>
+
+package auction_test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	apiauction "github.com/meterio/meter-pov/api/auction"
+	"github.com/meterio/meter-pov/block"
+	"github.com/meterio/meter-pov/chain"
+	"github.com/meterio/meter-pov/genesis"
+	"github.com/meterio/meter-pov/lvldb"
+	"github.com/meterio/meter-pov/meter"
+	"github.com/meterio/meter-pov/script/auction"
+	"github.com/meterio/meter-pov/state"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	ts     *httptest.Server
+	alice  = meter.BytesToAddress([]byte("alice"))
+	bob    = meter.BytesToAddress([]byte("bob"))
+	mtr    = func(n int64) *big.Int { return new(big.Int).Mul(big.NewInt(n), big.NewInt(1e18)) }
+	cbID   = meter.BytesToBytes32([]byte("present"))
+	sumIDs = []meter.Bytes32{meter.BytesToBytes32([]byte("first")), meter.BytesToBytes32([]byte("second"))}
+)
+
+func TestAuction(t *testing.T) {
+	initAuctionServer(t)
+	defer ts.Close()
+
+	var summaries []*apiauction.AuctionSummary
+	httpGet(t, ts.URL+"/auction/summaries?offset=1&limit=5", &summaries)
+	assert.Equal(t, 1, len(summaries))
+	assert.Equal(t, sumIDs[1].String(), summaries[0].AuctionID)
+	httpGet(t, ts.URL+"/auction/summaries?revision=0", &summaries)
+	assert.Equal(t, 0, len(summaries))
+
+	var summary *apiauction.AuctionSummary
+	httpGet(t, ts.URL+"/auction/summaries/"+sumIDs[0].String(), &summary)
+	assert.Equal(t, uint64(1), summary.Sequence)
+
+	// settled with the stored distributions
+	var settlement *apiauction.Settlement
+	httpGet(t, ts.URL+"/auction/summaries/"+sumIDs[0].String()+"/settlement", &settlement)
+	assert.True(t, settlement.Settled)
+	assert.Equal(t, []*apiauction.BidderSettlement{
+		{Address: alice.String(), UserbidTotal: mtr(10).String(), AutobidTotal: mtr(20).String(), ReceivedMTRG: mtr(30).String()},
+		{Address: bob.String(), UserbidTotal: mtr(10).String(), AutobidTotal: "0", ReceivedMTRG: mtr(10).String()},
+	}, settlement.Bidders)
+
+	// present auction at the price of 2 MTR, which is more than the reserved price
+	httpGet(t, ts.URL+"/auction/present/settlement", &settlement)
+	assert.False(t, settlement.Settled)
+	assert.Equal(t, mtr(2).String(), settlement.ActualPrice)
+	assert.Equal(t, []*apiauction.BidderSettlement{
+		{Address: bob.String(), UserbidTotal: mtr(40).String(), AutobidTotal: "0", ReceivedMTRG: mtr(20).String()},
+	}, settlement.Bidders)
+
+	var bids apiauction.AddressBids
+	httpGet(t, ts.URL+"/auction/bids/"+bob.String(), &bids)
+	assert.Equal(t, 3, len(bids.Auctions))
+	assert.Equal(t, sumIDs[0].String(), bids.Auctions[0].AuctionID)
+	assert.Equal(t, mtr(10).String(), bids.Auctions[0].ReceivedMTRG)
+	assert.Equal(t, cbID.String(), bids.Auctions[2].AuctionID)
+	assert.False(t, bids.Auctions[2].Settled)
+	assert.Equal(t, mtr(20).String(), bids.Auctions[2].ReceivedMTRG)
+
+	httpGet(t, ts.URL+"/auction/bids/"+alice.String(), &bids)
+	assert.Equal(t, 1, len(bids.Auctions))
+	assert.Equal(t, 1, len(bids.Auctions[0].Userbids))
+	assert.Equal(t, 1, len(bids.Auctions[0].Autobids))
+	assert.Equal(t, mtr(20).String(), bids.Auctions[0].AutobidTotal)
+
+	httpGet(t, ts.URL+"/auction/auctioncb/"+bob.String(), &bids)
+	assert.Equal(t, 1, len(bids.Auctions))
+	assert.Equal(t, cbID.String(), bids.Auctions[0].AuctionID)
+
+	res, err := http.Get(ts.URL + "/auction/summaries?limit=x")
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+}
+
+func initAuctionServer(t *testing.T) {
+	kv, _ := lvldb.NewMem()
+	stateCreator := state.NewCreator(kv)
+	b0, _, err := genesis.NewDevnet().Build(stateCreator)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ch, _ := chain.New(kv, b0, true)
+
+	st, _ := stateCreator.NewState(b0.Header().StateRoot())
+	a := &auction.Auction{}
+	a.SetSummaryList(auction.NewAuctionSummaryList([]*auction.AuctionSummary{
+		{
+			AuctionID: sumIDs[0], Sequence: 1,
+			RlsdMTRG: mtr(40), RsvdMTRG: mtr(0), RsvdPrice: mtr(1), RcvdMTR: mtr(40), ActualPrice: mtr(1), LeftoverMTRG: mtr(0),
+			AuctionTxs: []*auction.AuctionTx{
+				auction.NewAuctionTx(alice, mtr(10), auction.USER_BID, 1, 1),
+				auction.NewAuctionTx(bob, mtr(10), auction.USER_BID, 1, 2),
+				auction.NewAuctionTx(alice, mtr(20), auction.AUTO_BID, 1, 3),
+			},
+			// one distribution per bid
+			DistMTRG: []*auction.DistMtrg{{Addr: alice, Amount: mtr(10)}, {Addr: bob, Amount: mtr(10)}, {Addr: alice, Amount: mtr(20)}},
+		},
+		{
+			AuctionID: sumIDs[1], Sequence: 2,
+			RlsdMTRG: mtr(10), RsvdMTRG: mtr(0), RsvdPrice: mtr(1), RcvdMTR: mtr(10), ActualPrice: mtr(1), LeftoverMTRG: mtr(0),
+			AuctionTxs: []*auction.AuctionTx{auction.NewAuctionTx(bob, mtr(10), auction.USER_BID, 2, 1)},
+			DistMTRG:   []*auction.DistMtrg{{Addr: bob, Amount: mtr(10)}},
+		},
+	}), st)
+	a.SetAuctionCB(&auction.AuctionCB{
+		AuctionID: cbID, Sequence: 3,
+		RlsdMTRG: mtr(20), RsvdMTRG: mtr(0), RsvdPrice: mtr(1), RcvdMTR: mtr(40),
+		AuctionTxs: []*auction.AuctionTx{auction.NewAuctionTx(bob, mtr(40), auction.USER_BID, 3, 1)},
+	}, st)
+	root, err := st.Stage().Commit()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b1 := new(block.Builder).ParentID(b0.Header().ID()).Timestamp(b0.Header().Timestamp() + 10).GasLimit(10000000).TotalScore(1).StateRoot(root).Build()
+	b1.SetQC(&block.QuorumCert{QCHeight: 1, QCRound: 1, EpochID: 0})
+	if _, err := ch.AddBlock(b1, nil, true); err != nil {
+		t.Fatal(err)
+	}
+
+	router := mux.NewRouter()
+	apiauction.New(ch, stateCreator).Mount(router, "/auction")
+	ts = httptest.NewServer(router)
+}
+
+func httpGet(t *testing.T, url string, v interface{}) {
+	res, err := http.Get(url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(r, v); err != nil {
+		t.Fatal(string(r), err)
+	}
+}
@@ -11,6 +11,7 @@ import (
 	"math/big"
 	"time"
 
+	"github.com/meterio/meter-pov/meter"
 	"github.com/meterio/meter-pov/script/auction"
 )
 
@@ -87,6 +88,41 @@ type AuctionCB struct {
 	AuctionTxs  []*AuctionTx `json:"auctionTxs"`
 }
 
+// AuctionBids bids of an address in an auction, and the MTRG it receives when the auction is
+// cleared, which is estimated with the present price if the auction is not settled.
+type AuctionBids struct {
+	AuctionID    string       `json:"auctionID"`
+	Sequence     uint64       `json:"sequence"`
+	Settled      bool         `json:"settled"`
+	ActualPrice  string       `json:"actualPrice"`
+	UserbidTotal string       `json:"userbidTotal"`
+	AutobidTotal string       `json:"autobidTotal"`
+	ReceivedMTRG string       `json:"receivedMTRG"`
+	Userbids     []*AuctionTx `json:"userbids"`
+	Autobids     []*AuctionTx `json:"autobids"`
+}
+
+type AddressBids struct {
+	Address  string         `json:"address"`
+	Auctions []*AuctionBids `json:"auctions"`
+}
+
+type BidderSettlement struct {
+	Address      string `json:"address"`
+	UserbidTotal string `json:"userbidTotal"`
+	AutobidTotal string `json:"autobidTotal"`
+	ReceivedMTRG string `json:"receivedMTRG"`
+}
+
+// Settlement MTRG received by each bidder of an auction, estimated with the present price if
+// the auction is not settled.
+type Settlement struct {
+	AuctionID   string              `json:"auctionID"`
+	Settled     bool                `json:"settled"`
+	ActualPrice string              `json:"actualPrice"`
+	Bidders     []*BidderSettlement `json:"bidders"`
+}
+
 func convertDigestList(list *auction.AuctionSummaryList) []*AuctionDigest {
 	digestList := make([]*AuctionDigest, 0)
 	for _, s := range list.ToList() {
@@ -229,3 +265,83 @@ func convertAuctionCB(cb *auction.AuctionCB) *AuctionCB {
 		AuctionTxs:  txs,
 	}
 }
+
+// convertAuctionBids returns nil if the address has no bid in the auction.
+func convertAuctionBids(addr meter.Address, auctionID meter.Bytes32, sequence uint64, settled bool, actualPrice *big.Int, txs []*auction.AuctionTx, dists []*auction.DistMtrg) *AuctionBids {
+	bids := &AuctionBids{
+		AuctionID: auctionID.String(),
+		Sequence:  sequence,
+		Settled:   settled,
+		Userbids:  make([]*AuctionTx, 0),
+		Autobids:  make([]*AuctionTx, 0),
+	}
+	userbidTotal := big.NewInt(0)
+	autobidTotal := big.NewInt(0)
+	for _, t := range txs {
+		if t.Address != addr {
+			continue
+		}
+		if t.Type == auction.USER_BID {
+			bids.Userbids = append(bids.Userbids, convertAuctionTx(t))
+			userbidTotal.Add(userbidTotal, t.Amount)
+		} else {
+			bids.Autobids = append(bids.Autobids, convertAuctionTx(t))
+			autobidTotal.Add(autobidTotal, t.Amount)
+		}
+	}
+	if len(bids.Userbids) == 0 && len(bids.Autobids) == 0 {
+		return nil
+	}
+
+	bids.ActualPrice = actualPrice.String()
+	bids.UserbidTotal = userbidTotal.String()
+	bids.AutobidTotal = autobidTotal.String()
+	bids.ReceivedMTRG = receivedMTRG(addr, dists).String()
+	return bids
+}
+
+// convertSettlement lists bidders in the order of their first bids.
+func convertSettlement(auctionID meter.Bytes32, settled bool, actualPrice *big.Int, txs []*auction.AuctionTx, dists []*auction.DistMtrg) *Settlement {
+	settlement := &Settlement{
+		AuctionID:   auctionID.String(),
+		Settled:     settled,
+		ActualPrice: actualPrice.String(),
+		Bidders:     make([]*BidderSettlement, 0),
+	}
+	userbidTotals := make(map[meter.Address]*big.Int)
+	autobidTotals := make(map[meter.Address]*big.Int)
+	addrs := make([]meter.Address, 0)
+	for _, t := range txs {
+		if _, ok := userbidTotals[t.Address]; !ok {
+			userbidTotals[t.Address] = big.NewInt(0)
+			autobidTotals[t.Address] = big.NewInt(0)
+			addrs = append(addrs, t.Address)
+		}
+		if t.Type == auction.USER_BID {
+			userbidTotals[t.Address].Add(userbidTotals[t.Address], t.Amount)
+		} else {
+			autobidTotals[t.Address].Add(autobidTotals[t.Address], t.Amount)
+		}
+	}
+	for _, addr := range addrs {
+		settlement.Bidders = append(settlement.Bidders, &BidderSettlement{
+			Address:      addr.String(),
+			UserbidTotal: userbidTotals[addr].String(),
+			AutobidTotal: autobidTotals[addr].String(),
+			ReceivedMTRG: receivedMTRG(addr, dists).String(),
+		})
+	}
+	return settlement
+}
+
+// receivedMTRG sums up the distributions to the address, older summaries have one distribution
+// per bid.
+func receivedMTRG(addr meter.Address, dists []*auction.DistMtrg) *big.Int {
+	total := big.NewInt(0)
+	for _, d := range dists {
+		if d.Addr == addr {
+			total.Add(total, d.Amount)
+		}
+	}
+	return total
+}
@@ -17,17 +17,21 @@ import (
 	"github.com/meterio/meter-pov/block"
 	"github.com/meterio/meter-pov/chain"
 	"github.com/meterio/meter-pov/meter"
+	"github.com/meterio/meter-pov/script/staking"
+	"github.com/meterio/meter-pov/state"
 	"github.com/meterio/meter-pov/tx"
 	"github.com/pkg/errors"
 )
 
 type Blocks struct {
-	chain *chain.Chain
+	chain        *chain.Chain
+	stateCreator *state.Creator
 }
 
-func New(chain *chain.Chain) *Blocks {
+func New(chain *chain.Chain, stateCreator *state.Creator) *Blocks {
 	return &Blocks{
 		chain,
+		stateCreator,
 	}
 }
 
@@ -272,6 +276,82 @@ func (b *Blocks) handleGetQC(w http.ResponseWriter, req *http.Request) error {
 	return utils.WriteJSON(w, qc)
 }
 
+// handleGetCommitteeProof returns the QC which certifies the trunk block, and the committee
+// which signs the QC, for light clients to verify the finality of the block.
+func (b *Blocks) handleGetCommitteeProof(w http.ResponseWriter, req *http.Request) error {
+	revision, err := b.parseRevision(mux.Vars(req)["revision"])
+	if err != nil {
+		return utils.BadRequest(errors.WithMessage(err, "revision"))
+	}
+	blk, err := b.getBlock(revision)
+	if err != nil {
+		if b.chain.IsNotFound(err) {
+			return utils.WriteJSON(w, nil)
+		}
+		return err
+	}
+	header := blk.Header()
+	isTrunk, err := b.isTrunk(header.ID(), header.Number())
+	if err != nil {
+		return err
+	}
+	if !isTrunk || header.Number() == 0 {
+		return utils.WriteJSON(w, nil)
+	}
+
+	// the QC of the block is carried by its child, or is the best QC if the block is the best
+	qc := b.chain.BestQC()
+	if header.Number() < b.chain.BestBlock().Header().Number() {
+		child, err := b.chain.GetTrunkBlock(header.Number() + 1)
+		if err != nil {
+			return err
+		}
+		qc = child.QC
+	}
+	if qc == nil || qc.QCHeight != header.Number() {
+		return utils.WriteJSON(w, nil)
+	}
+
+	// the committee is carried by the first block after the last K-block
+	committeeBlk := blk
+	if header.LastKBlockHeight()+1 != header.Number() {
+		if committeeBlk, err = b.chain.GetTrunkBlock(header.LastKBlockHeight() + 1); err != nil {
+			return err
+		}
+	}
+	kblock, err := b.chain.GetTrunkBlock(header.LastKBlockHeight())
+	if err != nil {
+		return err
+	}
+	accountProof, delegatesProof, err := b.proveDelegates(kblock.Header().StateRoot())
+	if err != nil {
+		return err
+	}
+	proof, err := buildCommitteeProof(blk, qc, committeeBlk, kblock, accountProof, delegatesProof)
+	if err != nil {
+		return err
+	}
+	return utils.WriteJSON(w, proof)
+}
+
+// proveDelegates returns the merkle proofs of the staking module account and of the delegate
+// list, which the committee is chosen from.
+func (b *Blocks) proveDelegates(stateRoot meter.Bytes32) ([][]byte, [][]byte, error) {
+	st, err := b.stateCreator.NewState(stateRoot)
+	if err != nil {
+		return nil, nil, err
+	}
+	_, accountProof, err := st.ProveAccount(staking.StakingModuleAddr)
+	if err != nil {
+		return nil, nil, err
+	}
+	delegatesProof, err := st.ProveStorage(staking.StakingModuleAddr, staking.DelegateListKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	return accountProof, delegatesProof, nil
+}
+
 func (b *Blocks) handleGetEpochPowInfo(w http.ResponseWriter, req *http.Request) error {
 	epoch, err := b.parseEpoch(mux.Vars(req)["epoch"])
 	if err != nil {
@@ -298,5 +378,6 @@ func (b *Blocks) Mount(root *mux.Router, pathPrefix string) {
 	sub := root.PathPrefix(pathPrefix).Subrouter()
 	sub.Path("/qc/{revision}").Methods("Get").HandlerFunc(utils.WrapHandlerFunc(b.handleGetQC))
 	sub.Path("/{revision}").Methods("GET").HandlerFunc(utils.WrapHandlerFunc(b.handleGetBlock))
+	sub.Path("/{revision}/committee-proof").Methods("GET").HandlerFunc(utils.WrapHandlerFunc(b.handleGetCommitteeProof))
 	sub.Path("/epoch/{epoch}").Methods("Get").HandlerFunc(utils.WrapHandlerFunc(b.handleGetEpochPowInfo))
 }
@@ -15,6 +15,7 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/rlp"
 	"github.com/meterio/meter-pov/block"
 	"github.com/meterio/meter-pov/meter"
 	"github.com/meterio/meter-pov/powpool"
@@ -354,6 +355,59 @@ func convertQCWithRaw(qc *block.QuorumCert) (*QCWithRaw, error) {
 	}, nil
 }
 
+// CommitteeProof is the proof of finality of a block, which is verified with the
+// lightclient package. Header, QC, CommitteeHeader and Committee are rlp encoded.
+// NonceProof is the VRF proof of the last K-block, and AccountProof and DelegatesProof
+// prove the delegate list against the state root of the last K-block, which bind the
+// committee to the K-block.
+type CommitteeProof struct {
+	Number          uint32        `json:"number"`
+	ID              meter.Bytes32 `json:"id"`
+	Epoch           uint64        `json:"epoch"`
+	Header          string        `json:"header"`
+	QC              string        `json:"qc"`
+	CommitteeHeader string        `json:"committeeHeader"`
+	Committee       string        `json:"committee"`
+	NonceProof      string        `json:"nonceProof"`
+	AccountProof    []string      `json:"accountProof"`
+	DelegatesProof  []string      `json:"delegatesProof"`
+}
+
+func buildCommitteeProof(blk *block.Block, qc *block.QuorumCert, committeeBlk *block.Block, kblock *block.Block, accountProof, delegatesProof [][]byte) (*CommitteeProof, error) {
+	header, err := rlp.EncodeToBytes(blk.Header())
+	if err != nil {
+		return nil, err
+	}
+	committeeHeader, err := rlp.EncodeToBytes(committeeBlk.Header())
+	if err != nil {
+		return nil, err
+	}
+	committee, err := rlp.EncodeToBytes(&committeeBlk.CommitteeInfos)
+	if err != nil {
+		return nil, err
+	}
+	return &CommitteeProof{
+		Number:          blk.Header().Number(),
+		ID:              blk.Header().ID(),
+		Epoch:           qc.EpochID,
+		Header:          hexutil.Encode(header),
+		QC:              hexutil.Encode(qc.ToBytes()),
+		CommitteeHeader: hexutil.Encode(committeeHeader),
+		Committee:       hexutil.Encode(committee),
+		NonceProof:      hexutil.Encode(kblock.KBlockData.Proof),
+		AccountProof:    encodeProof(accountProof),
+		DelegatesProof:  encodeProof(delegatesProof),
+	}, nil
+}
+
+func encodeProof(proof [][]byte) []string {
+	nodes := make([]string, 0, len(proof))
+	for _, node := range proof {
+		nodes = append(nodes, hexutil.Encode(node))
+	}
+	return nodes
+}
+
 func convertKBlockData(kdata *block.KBlockData) {
 	for _, raw := range kdata.Data {
 		blk := wire.MsgBlock{}
@@ -7,7 +7,6 @@ package debug
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"net/http"
 	"strconv"
@@ -17,32 +16,42 @@ import (
 	"github.com/ethereum/go-ethereum/common/math"
 	"github.com/ethereum/go-ethereum/rlp"
 	"github.com/gorilla/mux"
+	"github.com/inconshreveable/log15"
 	"github.com/meterio/meter-pov/api/utils"
 	"github.com/meterio/meter-pov/block"
 	"github.com/meterio/meter-pov/chain"
 	"github.com/meterio/meter-pov/meter"
 	"github.com/meterio/meter-pov/runtime"
 	"github.com/meterio/meter-pov/state"
+	"github.com/meterio/meter-pov/tracedb"
 	"github.com/meterio/meter-pov/tracers"
 	"github.com/meterio/meter-pov/trie"
+	"github.com/meterio/meter-pov/tx"
 	"github.com/meterio/meter-pov/vm"
 	"github.com/meterio/meter-pov/xenv"
 	"github.com/pkg/errors"
 )
 
+var log = log15.New("pkg", "debug")
+
 type Debug struct {
-	chain  *chain.Chain
-	stateC *state.Creator
+	chain   *chain.Chain
+	stateC  *state.Creator
+	tracer  *tracedb.BlockTracer
+	traceDB *tracedb.TraceDB
 }
 
 var (
 	Magic = [4]byte{0x00, 0x00, 0x00, 0x00}
 )
 
-func New(chain *chain.Chain, stateC *state.Creator) *Debug {
+// New creates the debug api. traceDB is optional, traces are looked up in it when the blocks are indexed.
+func New(chain *chain.Chain, stateC *state.Creator, traceDB *tracedb.TraceDB) *Debug {
 	return &Debug{
 		chain,
 		stateC,
+		tracedb.NewBlockTracer(chain, stateC),
+		traceDB,
 	}
 }
 
@@ -62,8 +71,12 @@ func (d *Debug) newRuntimeOnBlock(header *block.Header) (*runtime.Runtime, error
 	if err != nil {
 		return nil, err
 	}
+	epoch, err := d.chain.GetBlockEpoch(header.ParentID())
+	if err != nil {
+		return nil, err
+	}
 
-	return runtime.New(
+	rt := runtime.New(
 		d.chain.NewSeeker(header.ParentID()),
 		state,
 		&xenv.BlockContext{
@@ -73,96 +86,31 @@ func (d *Debug) newRuntimeOnBlock(header *block.Header) (*runtime.Runtime, error
 			Time:        header.Timestamp(),
 			GasLimit:    header.GasLimit(),
 			TotalScore:  header.TotalScore(),
-		}), nil
-}
-func (d *Debug) isScriptEngineClause(rt *runtime.Runtime, blockID meter.Bytes32, txIndex uint64, clauseIndex uint64) bool {
-	block, err := d.chain.GetBlock(blockID)
-	if err != nil {
-		if d.chain.IsNotFound(err) {
-			return false
-		}
-		return false
-	}
-	txs := block.Transactions()
-	if txIndex >= uint64(len(txs)) {
-		return false
-	}
-	if clauseIndex >= uint64(len(txs[txIndex].Clauses())) {
-		return false
-	}
-
-	tx := txs[txIndex]
-	clause := tx.Clauses()[clauseIndex]
-	if (clause.Value().Sign() == 0) && (len(clause.Data()) > 16) && rt.ScriptEngineCheck(clause.Data()) {
-		return true
-	}
-	return false
-}
-
-func (d *Debug) handleTxEnvNew(ctx context.Context, blockID meter.Bytes32, txIndex uint64) (*runtime.Runtime, *runtime.TransactionExecutor, error) {
-	block, err := d.chain.GetBlock(blockID)
-	if err != nil {
-		if d.chain.IsNotFound(err) {
-			return nil, nil, utils.Forbidden(errors.New("block not found"))
-		}
-		return nil, nil, err
-	}
-	txs := block.Transactions()
-	if txIndex >= uint64(len(txs)) {
-		return nil, nil, utils.Forbidden(errors.New("tx index out of range"))
-	}
-
-	rt, err := d.newRuntimeOnBlock(block.Header())
-	if err != nil {
-		return nil, nil, err
-	}
-	for i, tx := range txs {
-		if uint64(i) > txIndex {
-			break
-		}
-		txExec, err := rt.PrepareTransaction(tx)
-		if err != nil {
-			return nil, nil, err
-		}
-		if txIndex == uint64(i) {
-			return rt, txExec, nil
-		}
-		for txExec.HasNextClause() {
-			if _, _, err := txExec.NextClause(); err != nil {
-				return nil, nil, err
-			}
-		}
-		if _, err := txExec.Finalize(); err != nil {
-			return nil, nil, err
-		}
-		select {
-		case <-ctx.Done():
-			return nil, nil, ctx.Err()
-		default:
-		}
-	}
-	return nil, nil, utils.Forbidden(errors.New("early reverted"))
+			Epoch:       epoch,
+		})
+	rt.ApplyForks()
+	return rt, nil
 }
 
-func (d *Debug) handleClauseEnv(ctx context.Context, blockID meter.Bytes32, txIndex uint64, clauseIndex uint64) (*runtime.Runtime, *runtime.TransactionExecutor, error) {
+func (d *Debug) handleClauseEnv(ctx context.Context, blockID meter.Bytes32, txIndex uint64, clauseIndex uint64) (*runtime.Runtime, *runtime.TransactionExecutor, *tx.Clause, error) {
 	block, err := d.chain.GetBlock(blockID)
 	if err != nil {
 		if d.chain.IsNotFound(err) {
-			return nil, nil, utils.Forbidden(errors.New("block not found"))
+			return nil, nil, nil, utils.Forbidden(errors.New("block not found"))
 		}
-		return nil, nil, err
+		return nil, nil, nil, err
 	}
 	txs := block.Transactions()
 	if txIndex >= uint64(len(txs)) {
-		return nil, nil, utils.Forbidden(errors.New("tx index out of range"))
+		return nil, nil, nil, utils.Forbidden(errors.New("tx index out of range"))
 	}
 	if clauseIndex >= uint64(len(txs[txIndex].Clauses())) {
-		return nil, nil, utils.Forbidden(errors.New("clause index out of range"))
+		return nil, nil, nil, utils.Forbidden(errors.New("clause index out of range"))
 	}
 
 	rt, err := d.newRuntimeOnBlock(block.Header())
 	if err != nil {
-		return nil, nil, err
+		return nil, nil, nil, err
 	}
 	for i, tx := range txs {
 		if uint64(i) > txIndex {
@@ -170,76 +118,39 @@ func (d *Debug) handleClauseEnv(ctx context.Context, blockID meter.Bytes32, txIn
 		}
 		txExec, err := rt.PrepareTransaction(tx)
 		if err != nil {
-			return nil, nil, err
+			return nil, nil, nil, err
 		}
 		clauseCounter := uint64(0)
 		for txExec.HasNextClause() {
 			if txIndex == uint64(i) && clauseIndex == clauseCounter {
-				return rt, txExec, nil
+				return rt, txExec, txs[txIndex].Clauses()[clauseIndex], nil
 			}
 			if _, _, err := txExec.NextClause(); err != nil {
-				return nil, nil, err
+				return nil, nil, nil, err
 			}
 			clauseCounter++
 		}
 		if _, err := txExec.Finalize(); err != nil {
-			return nil, nil, err
+			return nil, nil, nil, err
 		}
 		select {
 		case <-ctx.Done():
-			return nil, nil, ctx.Err()
+			return nil, nil, nil, ctx.Err()
 		default:
 		}
 	}
-	return nil, nil, utils.Forbidden(errors.New("early reverted"))
-}
-
-//trace an existed transaction
-func (d *Debug) traceTransactionWithAllClauses(ctx context.Context, blockID meter.Bytes32, txIndex uint64) (interface{}, error) {
-	rt, txExec, err := d.handleTxEnvNew(ctx, blockID, txIndex)
-	defer func() {
-		rt = nil
-		txExec = nil
-	}()
-	if err != nil {
-		return nil, err
-	}
-	code, ok := tracers.CodeByName("callTracer")
-	if !ok {
-		return nil, errors.New("name: unsupported tracer")
-	}
-	tracer, err := tracers.New(code)
-	if err != nil {
-		return nil, errors.New("could not get tracer")
-	}
-	rt.SetVMConfig(vm.Config{Debug: true, Tracer: tracer})
-	clauseIndex := 0
-	if d.isScriptEngineClause(rt, blockID, txIndex, uint64(0)) {
-		return nil, nil
-	}
-
-	for txExec.HasNextClause() {
-		fmt.Println("Execute ", blockID, txIndex, clauseIndex)
-		_, _, err := txExec.NextClause()
-		if err != nil {
-			return nil, err
-		}
-		clauseIndex++
-	}
-
-	if _, err := txExec.Finalize(); err != nil {
-		return nil, err
-	}
-
-	return tracer.GetResult()
+	return nil, nil, nil, utils.Forbidden(errors.New("early reverted"))
 }
 
 //trace an existed transaction
 func (d *Debug) traceTransaction(ctx context.Context, tracer vm.Tracer, blockID meter.Bytes32, txIndex uint64, clauseIndex uint64) (interface{}, error) {
-	rt, txExec, err := d.handleClauseEnv(ctx, blockID, txIndex, clauseIndex)
+	rt, txExec, clause, err := d.handleClauseEnv(ctx, blockID, txIndex, clauseIndex)
 	if err != nil {
 		return nil, err
 	}
+	if tr, ok := tracer.(tracers.TokenTracer); ok {
+		tr.SetToken(clause.Token())
+	}
 	rt.SetVMConfig(vm.Config{Debug: true, Tracer: tracer})
 	gasUsed, output, err := txExec.NextClause()
 	if err != nil {
@@ -253,110 +164,13 @@ func (d *Debug) traceTransaction(ctx context.Context, tracer vm.Tracer, blockID
 			ReturnValue: hexutil.Encode(output.Data),
 			StructLogs:  formatLogs(tr.StructLogs()),
 		}, nil
-	case *tracers.Tracer:
+	case tracers.ResultTracer:
 		return tr.GetResult()
 	default:
 		return nil, fmt.Errorf("bad tracer type %T", tracer)
 	}
 }
 
-type CallTraceResultWithPath struct {
-	callTraceResult CallTraceResult
-	path            []int
-}
-
-func (d *Debug) parseMeterTrace(traceResult []byte, blockHash meter.Bytes32, blockNumber uint64, txHash meter.Bytes32, txIndex uint64) ([]*TraceData, error) {
-	var callTraceResult CallTraceResult
-	err := json.Unmarshal(traceResult, &callTraceResult)
-	if err != nil {
-		return make([]*TraceData, 0), err
-	}
-	return d.convertTraceData(callTraceResult, []uint64{}, blockHash, blockNumber, txHash, txIndex)
-}
-
-func (d *Debug) convertTraceData(callTraceResult CallTraceResult, path []uint64, blockHash meter.Bytes32, blockNumber uint64, txHash meter.Bytes32, txIndex uint64) ([]*TraceData, error) {
-	value := callTraceResult.Value
-	if value == "" {
-		value = "0x0"
-	}
-	gasUsed := callTraceResult.GasUsed
-	if gasUsed == "" {
-		gasUsed = "0x0"
-	}
-	gas := callTraceResult.Gas
-	if gas == "" {
-		gas = "0x0"
-	}
-	from, err := meter.ParseAddress(callTraceResult.From)
-	if err != nil {
-		fmt.Println("Error parsing from: ", err, ", set it as default")
-		from = meter.Address{}
-	}
-	to, err := meter.ParseAddress(callTraceResult.To)
-	if err != nil {
-		fmt.Println("Error parsing to: ", err, ", set it as default")
-		to = meter.Address{}
-	}
-	resultType := strings.ToLower(callTraceResult.Type)
-	callType := ""
-	txType := ""
-	switch resultType {
-	case "call":
-		fallthrough
-	case "callcode":
-		fallthrough
-	case "delegatecall":
-		fallthrough
-	case "staticcall":
-		callType = resultType
-		txType = "call"
-	case "create":
-		fallthrough
-	case "create2":
-		callType = "create"
-		txType = "create"
-	case "selfdestruct":
-		callType = "selfdestruct"
-		txType = "suicide"
-	default:
-		callType = resultType
-		txType = "unknown"
-	}
-
-	datas := []*TraceData{
-		&TraceData{
-			Action: TraceAction{
-				CallType: callType,
-				From:     from,
-				Input:    callTraceResult.Input,
-				Gas:      gas,
-				To:       to,
-				Value:    value,
-			},
-			BlockHash:   blockHash,
-			BlockNumber: blockNumber,
-			Result: TraceDataResult{
-				GasUsed: gasUsed,
-				Output:  callTraceResult.Output,
-			},
-			Subtraces:           uint64(len(callTraceResult.Calls)),
-			TraceAddress:        path,
-			TransactionHash:     txHash,
-			TransactionPosition: txIndex,
-			Type:                txType,
-		},
-	}
-	for index, call := range callTraceResult.Calls {
-		fmt.Println("convert subcall data: ", call, path, blockHash, blockNumber, txIndex)
-		subdatas, err := d.convertTraceData(call, append(path, uint64(index)), blockHash, blockNumber, txHash, txIndex)
-		if err != nil {
-			fmt.Println("Error happened during subdata query")
-		}
-		datas = append(datas, subdatas...)
-	}
-	return datas, nil
-}
-
 func (d *Debug) handleTraceTransaction(w http.ResponseWriter, req *http.Request) error {
 	var opt *TracerOption
 	if err := utils.ParseJSON(req.Body, &opt); err != nil {
@@ -373,13 +187,19 @@ func (d *Debug) handleTraceTransaction(w http.ResponseWriter, req *http.Request)
 		if !strings.HasSuffix(name, "Tracer") {
 			name += "Tracer"
 		}
-		code, ok := tracers.CodeByName(name)
-		if !ok {
-			return utils.BadRequest(errors.New("name: unsupported tracer"))
-		}
-		tr, err := tracers.New(code)
+		tr, ok, err := tracers.NewNative(name, opt.Config)
 		if err != nil {
-			return err
+			return utils.BadRequest(errors.WithMessage(err, "config"))
+		}
+		if !ok {
+			// the name is taken as custom tracer code if there's no builtin tracer of the name
+			code, ok := tracers.CodeByName(name)
+			if !ok {
+				code = opt.Name
+			}
+			if tr, err = tracers.New(code); err != nil {
+				return utils.BadRequest(errors.WithMessage(err, "name"))
+			}
 		}
 		tracer = tr
 	}
@@ -395,7 +215,7 @@ func (d *Debug) handleTraceTransaction(w http.ResponseWriter, req *http.Request)
 }
 
 func (d *Debug) debugStorage(ctx context.Context, contractAddress meter.Address, blockID meter.Bytes32, txIndex uint64, clauseIndex uint64, keyStart []byte, maxResult int) (*StorageRangeResult, error) {
-	rt, _, err := d.handleClauseEnv(ctx, blockID, txIndex, clauseIndex)
+	rt, _, _, err := d.handleClauseEnv(ctx, blockID, txIndex, clauseIndex)
 	if err != nil {
 		return nil, err
 	}
@@ -493,7 +313,7 @@ func (d *Debug) parseTarget(target string) (blockID meter.Bytes32, txIndex uint6
 }
 
 func (d *Debug) parseRevision(revision string) (interface{}, error) {
-	if revision == "" || revision == "best" {
+	if revision == "" || revision == "best" || revision == "latest" {
 		return nil, nil
 	}
 	if len(revision) == 66 || len(revision) == 64 {
@@ -524,295 +344,13 @@ func (d *Debug) getBlock(revision interface{}) (*block.Block, error) {
 	}
 }
 
-func (d *Debug) openEthTraceTransaction(ctx context.Context, txHash meter.Bytes32) ([]*TraceData, error) {
-	tx, meta, err := d.chain.GetTrunkTransaction(txHash)
-	datas := make([]*TraceData, 0)
-	if err != nil {
-		fmt.Println("Error happened in GetTrunkTransaction: ", err)
-		return datas, err
-	}
-	_, err = tx.Signer()
-	if err != nil {
-		fmt.Println("Could not get signer:", err)
-		return datas, err
-	}
-	fmt.Println("Trace Tx for: ", tx.ID(), ", BlockID:", meta.BlockID)
-	blk, err := d.getBlock(meta.BlockID)
-	if err != nil {
-		fmt.Println("Could not get block: ", err)
-		return datas, err
-	}
-
-	res, err := d.traceTransactionWithAllClauses(ctx, blk.Header().ID(), meta.Index)
-	if err != nil {
-		return datas, err
-	}
-	if res == nil {
-		return datas, nil
-	}
-	resBytes, ok := res.(json.RawMessage)
-	if !ok {
-		return datas, errors.New("not expected res")
-	}
-	fmt.Println("Parse Meter Tracer Result: ", string(resBytes))
-	clauseDatas, err := d.parseMeterTrace(resBytes, meta.BlockID, uint64(blk.Header().Number()), tx.ID(), meta.Index)
-	if err != nil {
-		return datas, err
-	}
-	datas = append(datas, clauseDatas...)
-	return datas, nil
-
-}
-
-func (d *Debug) handleOpenEthTraceTransaction(w http.ResponseWriter, req *http.Request) error {
-	params := make([]meter.Bytes32, 0)
-	if err := utils.ParseJSON(req.Body, &params); err != nil {
-		return utils.BadRequest(errors.WithMessage(err, "body"))
-	}
-
-	results := make([]*TraceData, 0)
-	for _, txHash := range params {
-		txDatas, err := d.openEthTraceTransaction(req.Context(), txHash)
-		if err != nil {
-			return err
-		}
-		results = append(results, txDatas...)
-	}
-	return utils.WriteJSON(w, results)
-}
-
-func (d *Debug) handleOpenEthTraceBlock(w http.ResponseWriter, req *http.Request) error {
-	params := make([]string, 0)
-	if err := utils.ParseJSON(req.Body, &params); err != nil {
-		return utils.BadRequest(errors.WithMessage(err, "body"))
-	}
-	if len(params) <= 0 {
-		return utils.BadRequest(errors.New("not enough params"))
-	}
-
-	revision, err := d.parseRevision(params[0])
-	fmt.Println("Trace Block with REVISION: ", revision)
-	if err != nil {
-		fmt.Println("could not parse reivision")
-	}
-	blk, err := d.getBlock(revision)
-	if err != nil {
-		return utils.BadRequest(errors.WithMessage(err, "could not get block"))
-	}
-	fmt.Println("BLOCK: ", blk.Header().ID())
-
-	results := make([]*TraceData, 0)
-	for _, tx := range blk.Transactions() {
-		txDatas, err := d.openEthTraceTransaction(req.Context(), tx.ID())
-		if err != nil {
-			return utils.BadRequest(err)
-		}
-		results = append(results, txDatas...)
-	}
-	fmt.Println("RESULT: ", results)
-	return utils.WriteJSON(w, results)
-}
-
-/*
-func (d *Debug) handleTraceFilter(w http.ResponseWriter, req *http.Request) error {
-	var opt TraceFilterOptions
-	if err := utils.ParseJSON(req.Body, &opt); err != nil {
-		return utils.BadRequest(errors.WithMessage(err, "body"))
-	}
-	fromBlockNum := uint32(0)
-	toBlockNum := uint32(0)
-
-	if opt.FromBlock != "" {
-		revision, err := d.parseRevision(opt.FromBlock)
-		if err != nil {
-			return utils.BadRequest(errors.WithMessage(err, "invalid fromBlock"))
-		}
-		blk, err := d.getBlock(revision)
-		if err != nil {
-			return utils.BadRequest(errors.WithMessage(err, "could not get block"))
-		}
-		fromBlockNum = blk.Header().Number()
-	}
-
-	if opt.ToBlock != "" {
-		revision, err := d.parseRevision(opt.ToBlock)
-		if err != nil {
-			return utils.BadRequest(errors.WithMessage(err, "invalid fromBlock"))
-		}
-		blk, err := d.getBlock(revision)
-		if err != nil {
-			return utils.BadRequest(errors.WithMessage(err, "could not get block"))
-		}
-		toBlockNum = blk.Header().Number()
-	}
-
-	if fromBlockNum > toBlockNum {
-		return utils.BadRequest(errors.New("fromBlock > toBlock"))
-	}
-
-	fromAddrMap := make(map[string]bool)
-	toAddrMap := make(map[string]bool)
-	for _, addr := range opt.FromAddress {
-		fromAddrMap[strings.ToLower(addr.String())] = true
-	}
-	for _, addr := range opt.ToAddress {
-		toAddrMap[strings.ToLower(addr.String())] = true
-	}
-
-	results := make([]TraceData, 0)
-	// start to filter tx in block range
-	num := fromBlockNum
-	for num < toBlockNum {
-		blk, err := d.getBlock(num)
-		if err != nil {
-			return utils.BadRequest(errors.WithMessage(err, "could not get block"))
-		}
-		for txIndex, tx := range blk.Txs {
-			signer, err := tx.Signer()
-			if err != nil {
-				return utils.BadRequest(errors.WithMessage(err, "could not get signer"))
-			}
-
-			matched := false
-			clauseIndex := 0
-
-			_, fromExist := fromAddrMap[strings.ToLower(signer.String())]
-			fromMatch := (len(fromAddrMap) > 0 && fromExist) || len(fromAddrMap) == 0
-			for index, clause := range tx.Clauses() {
-				var to *meter.Address
-				if clause.To() == nil {
-					to = &meter.Address{}
-				} else {
-					to = clause.To()
-				}
-
-				_, toExist := toAddrMap[strings.ToLower(to.String())]
-				toMatch := len(toAddrMap) > 0 && toExist || len(toAddrMap) == 0
-				if fromMatch && toMatch {
-					matched = true
-					clauseIndex = index
-					break
-				}
-			}
-
-			if matched {
-				var to *meter.Address
-				if tx.Clauses()[clauseIndex].To() == nil {
-					to = &meter.Address{}
-				} else {
-					to = tx.Clauses()[clauseIndex].To()
-				}
-
-				results = append(results, TraceData{
-					Action: TraceAction{
-						CallType: "call",
-						From:     signer,
-						Input:    "0x" + hex.EncodeToString(tx.Clauses()[clauseIndex].Data()),
-						To:       *to,
-						Value:    math.HexOrDecimal256(*(tx.Clauses()[clauseIndex].Value())),
-					},
-					BlockHash:           blk.Header().ID(),
-					BlockNumber:         uint64(num),
-					Result:              TraceDataResult{GasUsed: math.HexOrDecimal256(*big.NewInt(0)), Output: "0x"}, // FIXME: fake data
-					Subtraces:           0,                                                                            // FIXME: fake data
-					TraceAddress:        make([]meter.Address, 0),                                                     // FIXME: fake data
-					TransactionHash:     tx.ID(),
-					TransactionPosition: uint64(txIndex),
-					Type:                "call",
-				})
-			}
-		}
-		num++
-	}
-
-	return utils.WriteJSON(w, results)
-}
-*/
-
-func (d *Debug) handleOpenEthTraceFilter(w http.ResponseWriter, req *http.Request) error {
-	var opt TraceFilterOptions
-	if err := utils.ParseJSON(req.Body, &opt); err != nil {
-		return utils.BadRequest(errors.WithMessage(err, "body"))
-	}
-	fromBlockNum := uint32(0)
-	toBlockNum := uint32(0)
-
-	if opt.FromBlock != "" {
-		revision, err := d.parseRevision(opt.FromBlock)
-		if err != nil {
-			return utils.BadRequest(errors.WithMessage(err, "invalid fromBlock"))
-		}
-		blk, err := d.getBlock(revision)
-		if err != nil {
-			return utils.BadRequest(errors.WithMessage(err, "could not get block"))
-		}
-		fromBlockNum = blk.Header().Number()
-	}
-
-	if opt.ToBlock != "" {
-		revision, err := d.parseRevision(opt.ToBlock)
-		if err != nil {
-			return utils.BadRequest(errors.WithMessage(err, "invalid fromBlock"))
-		}
-		blk, err := d.getBlock(revision)
-		if err != nil {
-			return utils.BadRequest(errors.WithMessage(err, "could not get block"))
-		}
-		toBlockNum = blk.Header().Number()
-	}
-
-	if fromBlockNum > toBlockNum {
-		return utils.BadRequest(errors.New("fromBlock > toBlock"))
-	}
-
-	fromAddrMap := make(map[string]bool)
-	toAddrMap := make(map[string]bool)
-	for _, addr := range opt.FromAddress {
-		fromAddrMap[strings.ToLower(addr.String())] = true
-	}
-	for _, addr := range opt.ToAddress {
-		toAddrMap[strings.ToLower(addr.String())] = true
-	}
-
-	results := make([]*TraceData, 0)
-
-	// start to filter tx in block range
-	num := fromBlockNum
-	for num < toBlockNum {
-		blk, err := d.getBlock(num)
-		if err != nil {
-			return utils.BadRequest(errors.WithMessage(err, "could not get block"))
-		}
-		for _, tx := range blk.Txs {
-			txDatas, err := d.openEthTraceTransaction(req.Context(), tx.ID())
-			if err != nil {
-				return err
-			}
-			for _, d := range txDatas {
-				_, fromExist := fromAddrMap[strings.ToLower(d.Action.From.String())]
-				_, toExist := fromAddrMap[strings.ToLower(d.Action.To.String())]
-
-				fromMatch := (len(fromAddrMap) > 0 && fromExist) || len(fromAddrMap) == 0
-				toMatch := len(toAddrMap) > 0 && toExist || len(toAddrMap) == 0
-				if fromMatch && toMatch {
-					results = append(results, d)
-				}
-			}
-
-		}
-		num++
-	}
-
-	return utils.WriteJSON(w, results)
-}
-
 func (d *Debug) Mount(root *mux.Router, pathPrefix string) {
 	sub := root.PathPrefix(pathPrefix).Subrouter()
 	sub.Path("/tracers").Methods(http.MethodPost).HandlerFunc(utils.WrapHandlerFunc(d.handleTraceTransaction))
 	sub.Path("/storage-range").Methods(http.MethodPost).HandlerFunc(utils.WrapHandlerFunc(d.handleDebugStorage))
-	// sub.Path("/trace_filter").Methods(http.MethodPost).HandlerFunc((utils.WrapHandlerFunc(d.handleTraceFilter)))
+	sub.Path("/trace_filter").Methods(http.MethodPost).HandlerFunc(utils.WrapHandlerFunc(d.handleTraceFilter))
 	// sub.Path("/trace_transaction").Methods(http.MethodPost).HandlerFunc((utils.WrapHandlerFunc(d.handleOpenEthTraceTransaction)))
 	sub.Path("/openeth_trace_transaction").Methods(http.MethodPost).HandlerFunc((utils.WrapHandlerFunc(d.handleOpenEthTraceTransaction)))
 	sub.Path("/openeth_trace_block").Methods(http.MethodPost).HandlerFunc((utils.WrapHandlerFunc(d.handleOpenEthTraceBlock)))
-	sub.Path("/openeth_trace_filter").Methods(http.MethodPost).HandlerFunc((utils.WrapHandlerFunc(d.handleOpenEthTraceFilter)))
+	sub.Path("/openeth_trace_filter").Methods(http.MethodPost).HandlerFunc(utils.WrapHandlerFunc(d.handleTraceFilter))
 }
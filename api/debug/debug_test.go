@@ -0,0 +1,175 @@
+// Copyright (c) 2020 The Meter.io developers
+
+// Distributed under the GNU Lesser General Public License v3.0 software license, see the accompanying
+// file LICENSE or <https://www.gnu.org/licenses/lgpl-3.0.html>
+
+package debug_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/gorilla/mux"
+	"github.com/meterio/meter-pov/api/debug"
+	"github.com/meterio/meter-pov/chain"
+	"github.com/meterio/meter-pov/chain/testchain"
+	"github.com/meterio/meter-pov/genesis"
+	"github.com/meterio/meter-pov/lvldb"
+	"github.com/meterio/meter-pov/meter"
+	"github.com/meterio/meter-pov/state"
+	"github.com/meterio/meter-pov/tracedb"
+	"github.com/meterio/meter-pov/tx"
+	"github.com/stretchr/testify/assert"
+)
+
+var recipient = meter.BytesToAddress([]byte("recipient"))
+
+// forwarder forwards the received value to recipient with an internal call
+var forwarderCode = common.Hex2Bytes("60218060" + "0b" + "6000396000f3" +
+	"6000600060006000" + "34" + "73" + common.Bytes2Hex(recipient.Bytes()) + "5a" + "f1" + "00")
+
+var (
+	ts         *httptest.Server
+	forwarder  meter.Address
+	deployTx   *tx.Transaction
+	forwardTx  *tx.Transaction
+	nonceCount uint64
+)
+
+func TestTraceFilter(t *testing.T) {
+	initDebugServer(t, false)
+	defer ts.Close()
+	testTraceFilter(t)
+}
+
+func TestTraceFilterIndexed(t *testing.T) {
+	initDebugServer(t, true)
+	defer ts.Close()
+	testTraceFilter(t)
+}
+
+func testTraceFilter(t *testing.T) {
+	traces := traceFilter(t, &debug.TraceFilterOptions{FromBlock: "1", ToBlock: "2"})
+	// create, reward in block 1; call, internal call, reward in block 2
+	assert.Equal(t, 5, len(traces))
+
+	create := traces[0]
+	assert.Equal(t, "create", create.Type)
+	assert.Equal(t, deployTx.ID(), *create.TransactionHash)
+	assert.Equal(t, forwarder, *create.Result.Address)
+	assert.Equal(t, "reward", traces[1].Type)
+	assert.Nil(t, traces[1].TransactionHash)
+
+	call := traces[2]
+	assert.Equal(t, "call", call.Type)
+	assert.Equal(t, forwarder, *call.Action.To)
+	assert.Equal(t, uint64(1), call.Subtraces)
+	assert.Equal(t, []uint64{}, call.TraceAddress)
+
+	internal := traces[3]
+	assert.Equal(t, "call", internal.Type)
+	assert.Equal(t, forwardTx.ID(), *internal.TransactionHash)
+	assert.Equal(t, forwarder, *internal.Action.From)
+	assert.Equal(t, recipient, *internal.Action.To)
+	assert.Equal(t, "0x1", internal.Action.Value)
+	assert.Equal(t, []uint64{0}, internal.TraceAddress)
+	assert.NotNil(t, internal.Result)
+
+	// filter by receiver
+	traces = traceFilter(t, &debug.TraceFilterOptions{FromBlock: "0", ToBlock: "best", ToAddress: []meter.Address{recipient}})
+	assert.Equal(t, 1, len(traces))
+	assert.Equal(t, []uint64{0}, traces[0].TraceAddress)
+
+	// filter by sender
+	traces = traceFilter(t, &debug.TraceFilterOptions{FromBlock: "0", ToBlock: "best", FromAddress: []meter.Address{forwarder}})
+	assert.Equal(t, 1, len(traces))
+
+	// pagination
+	traces = traceFilter(t, &debug.TraceFilterOptions{FromBlock: "1", ToBlock: "2", After: 1, Count: 2})
+	assert.Equal(t, 2, len(traces))
+	assert.Equal(t, "reward", traces[0].Type)
+	assert.Equal(t, "call", traces[1].Type)
+
+	res, statusCode := httpPost(t, ts.URL+"/debug/trace_filter", &debug.TraceFilterOptions{FromBlock: "2", ToBlock: "1"})
+	assert.Equal(t, http.StatusBadRequest, statusCode, string(res))
+}
+
+func traceFilter(t *testing.T, opt *debug.TraceFilterOptions) []*debug.TraceData {
+	res, statusCode := httpPost(t, ts.URL+"/debug/trace_filter", opt)
+	assert.Equal(t, http.StatusOK, statusCode, string(res))
+	var traces []*debug.TraceData
+	if err := json.Unmarshal(res, &traces); err != nil {
+		t.Fatal(err)
+	}
+	return traces
+}
+
+func initDebugServer(t *testing.T, indexed bool) {
+	db, _ := lvldb.NewMem()
+	c, stateC, err := testchain.New(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	deployTx = buildTx(t, c.Tag(), tx.NewClause(nil).WithData(forwarderCode))
+	forwarder = meter.Address(meter.EthCreateContractAddress(common.Address(genesis.DevAccounts()[0].Address), uint32(deployTx.Nonce())))
+	packTx(t, c, stateC, deployTx)
+
+	forwardTx = buildTx(t, c.Tag(), tx.NewClause(&forwarder).WithToken(meter.STPT).WithValue(big.NewInt(1)))
+	packTx(t, c, stateC, forwardTx)
+
+	var traceDB *tracedb.TraceDB
+	if indexed {
+		traceDB, err = tracedb.NewMem()
+		if err != nil {
+			t.Fatal(err)
+		}
+		indexer := tracedb.NewIndexer(c, tracedb.NewBlockTracer(c, stateC), traceDB, 0)
+		if err := indexer.CatchUp(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	router := mux.NewRouter()
+	debug.New(c, stateC, traceDB).Mount(router, "/debug")
+	ts = httptest.NewServer(router)
+}
+
+func buildTx(t *testing.T, chainTag byte, clause *tx.Clause) *tx.Transaction {
+	nonceCount++
+	return testchain.NewTx(chainTag, clause, 1000000, nonceCount)
+}
+
+func packTx(t *testing.T, c *chain.Chain, stateC *state.Creator, trx *tx.Transaction) {
+	_, receipts, err := testchain.Mint(c, stateC, trx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if receipts[0].Reverted {
+		t.Fatal("transaction reverted")
+	}
+}
+
+func httpPost(t *testing.T, url string, body interface{}) ([]byte, int) {
+	data, err := json.Marshal(body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := http.Post(url, "application/x-www-form-urlencoded", bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return r, res.StatusCode
+}
@@ -0,0 +1,299 @@
+// Copyright (c) 2020 The Meter.io developers
+
+// Distributed under the GNU Lesser General Public License v3.0 software license, see the accompanying
+// file LICENSE or <https://www.gnu.org/licenses/lgpl-3.0.html>
+
+package debug
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/meterio/meter-pov/api/utils"
+	"github.com/meterio/meter-pov/block"
+	"github.com/meterio/meter-pov/meter"
+	"github.com/meterio/meter-pov/tracedb"
+	"github.com/pkg/errors"
+)
+
+// maxTraceFilterRange is the max number of blocks re-executed by one trace filter query,
+// ranges in the trace db are not limited.
+const maxTraceFilterRange = 100
+
+// convertTrace converts the trace to OpenEthereum format.
+func convertTrace(trace *tracedb.Trace) *TraceData {
+	data := &TraceData{
+		BlockHash:    trace.BlockID,
+		BlockNumber:  uint64(trace.BlockNumber),
+		Error:        traceError(trace.Error),
+		Subtraces:    uint64(trace.Subtraces),
+		TraceAddress: make([]uint64, 0, len(trace.TraceAddress)),
+		Type:         trace.Type,
+	}
+	for _, i := range trace.TraceAddress {
+		data.TraceAddress = append(data.TraceAddress, uint64(i))
+	}
+	if trace.TxID != nil {
+		txIndex, clauseIndex := uint64(trace.TxIndex), uint64(trace.ClauseIndex)
+		data.TransactionHash = trace.TxID
+		data.TransactionPosition = &txIndex
+		data.ClauseIndex = &clauseIndex
+	}
+
+	value := "0x0"
+	if trace.Value != nil {
+		value = hexutil.EncodeBig(trace.Value)
+	}
+	token := uint32(trace.Token)
+	switch trace.Type {
+	case tracedb.TypeCreate:
+		data.Action = TraceAction{
+			From:  trace.From,
+			Gas:   hexutil.EncodeUint64(trace.Gas),
+			Init:  hexutil.Encode(trace.Input),
+			Value: value,
+			Token: &token,
+		}
+		if trace.Error == "" {
+			data.Result = &TraceDataResult{
+				Address: trace.To,
+				Code:    hexutil.Encode(trace.Output),
+				GasUsed: hexutil.EncodeUint64(trace.GasUsed),
+			}
+		}
+	case tracedb.TypeSuicide:
+		data.Action = TraceAction{
+			Address:       trace.From,
+			RefundAddress: trace.To,
+			Balance:       value,
+		}
+	case tracedb.TypeReward:
+		data.Action = TraceAction{
+			Author:     trace.To,
+			RewardType: "block",
+			Value:      value,
+		}
+	default:
+		data.Action = TraceAction{
+			CallType: trace.CallType,
+			From:     trace.From,
+			To:       trace.To,
+			Gas:      hexutil.EncodeUint64(trace.Gas),
+			Input:    hexutil.Encode(trace.Input),
+			Value:    value,
+			Token:    &token,
+		}
+		if trace.Error == "" {
+			data.Result = &TraceDataResult{
+				GasUsed: hexutil.EncodeUint64(trace.GasUsed),
+				Output:  hexutil.Encode(trace.Output),
+			}
+		}
+	}
+	return data
+}
+
+func convertTraces(traces []*tracedb.Trace) []*TraceData {
+	results := make([]*TraceData, 0, len(traces))
+	for _, trace := range traces {
+		results = append(results, convertTrace(trace))
+	}
+	return results
+}
+
+// traceError converts vm errors to OpenEthereum trace errors.
+func traceError(err string) string {
+	switch err {
+	case "execution reverted":
+		return "Reverted"
+	case "out of gas":
+		return "Out of gas"
+	default:
+		return err
+	}
+}
+
+// indexed returns whether traces of trunk blocks in range [from, to] are in the trace db.
+func (d *Debug) indexed(from, to uint32) bool {
+	if d.traceDB == nil {
+		return false
+	}
+	covered, err := d.traceDB.Covers(from, to)
+	if err != nil {
+		log.Warn("failed to read trace db", "err", err)
+		return false
+	}
+	return covered
+}
+
+// traceBlock returns traces of the block, from the trace db if indexed, or by re-executing the block.
+func (d *Debug) traceBlock(ctx context.Context, blk *block.Block) ([]*tracedb.Trace, error) {
+	num := blk.Header().Number()
+	if d.indexed(num, num) {
+		if trunkID, err := d.chain.GetTrunkBlockID(num); err == nil && trunkID == blk.Header().ID() {
+			return d.traceDB.FilterTraces(ctx, &tracedb.TraceFilter{From: num, To: num})
+		}
+	}
+	return d.tracer.TraceBlock(ctx, blk)
+}
+
+// filterTraces returns traces of trunk blocks matched by the filter.
+func (d *Debug) filterTraces(ctx context.Context, filter *tracedb.TraceFilter) ([]*tracedb.Trace, error) {
+	if d.indexed(filter.From, filter.To) {
+		return d.traceDB.FilterTraces(ctx, filter)
+	}
+	if filter.To-filter.From >= maxTraceFilterRange {
+		return nil, utils.BadRequest(fmt.Errorf("range of blocks not indexed exceeds %v", maxTraceFilterRange))
+	}
+
+	results := make([]*tracedb.Trace, 0)
+	skipped := uint64(0)
+	for num := filter.From; num <= filter.To; num++ {
+		blk, err := d.chain.GetTrunkBlock(num)
+		if err != nil {
+			return nil, err
+		}
+		traces, err := d.tracer.TraceBlock(ctx, blk)
+		if err != nil {
+			return nil, errors.WithMessage(err, fmt.Sprintf("trace block %v", num))
+		}
+		for _, trace := range traces {
+			if !filter.Match(trace) {
+				continue
+			}
+			if skipped < filter.Offset {
+				skipped++
+				continue
+			}
+			results = append(results, trace)
+			if filter.Limit > 0 && uint64(len(results)) >= filter.Limit {
+				return results, nil
+			}
+		}
+		// avoid overflow when to is math.MaxUint32
+		if num == filter.To {
+			break
+		}
+	}
+	return results, nil
+}
+
+func (d *Debug) parseBlockNumber(revision string) (uint32, error) {
+	rev, err := d.parseRevision(revision)
+	if err != nil {
+		return 0, err
+	}
+	blk, err := d.getBlock(rev)
+	if err != nil {
+		return 0, err
+	}
+	return blk.Header().Number(), nil
+}
+
+func (d *Debug) handleTraceFilter(w http.ResponseWriter, req *http.Request) error {
+	var opt TraceFilterOptions
+	if err := utils.ParseJSON(req.Body, &opt); err != nil {
+		return utils.BadRequest(errors.WithMessage(err, "body"))
+	}
+	fromBlockNum, err := d.parseBlockNumber(opt.FromBlock)
+	if err != nil {
+		if d.chain.IsNotFound(err) {
+			return utils.BadRequest(errors.New("fromBlock: block not found"))
+		}
+		return utils.BadRequest(errors.WithMessage(err, "fromBlock"))
+	}
+	toBlockNum, err := d.parseBlockNumber(opt.ToBlock)
+	if err != nil {
+		if d.chain.IsNotFound(err) {
+			return utils.BadRequest(errors.New("toBlock: block not found"))
+		}
+		return utils.BadRequest(errors.WithMessage(err, "toBlock"))
+	}
+	if fromBlockNum > toBlockNum {
+		return utils.BadRequest(errors.New("fromBlock > toBlock"))
+	}
+
+	traces, err := d.filterTraces(req.Context(), &tracedb.TraceFilter{
+		From:        fromBlockNum,
+		To:          toBlockNum,
+		FromAddress: opt.FromAddress,
+		ToAddress:   opt.ToAddress,
+		Offset:      opt.After,
+		Limit:       opt.Count,
+	})
+	if err != nil {
+		return err
+	}
+	return utils.WriteJSON(w, convertTraces(traces))
+}
+
+func (d *Debug) openEthTraceTransaction(ctx context.Context, txID meter.Bytes32) ([]*TraceData, error) {
+	_, meta, err := d.chain.GetTrunkTransaction(txID)
+	if err != nil {
+		if d.chain.IsNotFound(err) {
+			return nil, utils.Forbidden(errors.New("transaction not found"))
+		}
+		return nil, err
+	}
+	blk, err := d.chain.GetBlock(meta.BlockID)
+	if err != nil {
+		return nil, err
+	}
+	traces, err := d.traceBlock(ctx, blk)
+	if err != nil {
+		return nil, err
+	}
+	results := make([]*TraceData, 0)
+	for _, trace := range traces {
+		if trace.TxID != nil && *trace.TxID == txID {
+			results = append(results, convertTrace(trace))
+		}
+	}
+	return results, nil
+}
+
+func (d *Debug) handleOpenEthTraceTransaction(w http.ResponseWriter, req *http.Request) error {
+	params := make([]meter.Bytes32, 0)
+	if err := utils.ParseJSON(req.Body, &params); err != nil {
+		return utils.BadRequest(errors.WithMessage(err, "body"))
+	}
+
+	results := make([]*TraceData, 0)
+	for _, txID := range params {
+		traces, err := d.openEthTraceTransaction(req.Context(), txID)
+		if err != nil {
+			return err
+		}
+		results = append(results, traces...)
+	}
+	return utils.WriteJSON(w, results)
+}
+
+func (d *Debug) handleOpenEthTraceBlock(w http.ResponseWriter, req *http.Request) error {
+	params := make([]string, 0)
+	if err := utils.ParseJSON(req.Body, &params); err != nil {
+		return utils.BadRequest(errors.WithMessage(err, "body"))
+	}
+	if len(params) <= 0 {
+		return utils.BadRequest(errors.New("not enough params"))
+	}
+
+	revision, err := d.parseRevision(params[0])
+	if err != nil {
+		return utils.BadRequest(errors.WithMessage(err, "revision"))
+	}
+	blk, err := d.getBlock(revision)
+	if err != nil {
+		if d.chain.IsNotFound(err) {
+			return utils.BadRequest(errors.New("revision: block not found"))
+		}
+		return err
+	}
+	traces, err := d.traceBlock(req.Context(), blk)
+	if err != nil {
+		return err
+	}
+	return utils.WriteJSON(w, convertTraces(traces))
+}
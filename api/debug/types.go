@@ -1,6 +1,7 @@
 package debug
 
 import (
+	"encoding/json"
 	"fmt"
 
 	"github.com/meterio/meter-pov/meter"
@@ -10,8 +11,9 @@ import (
 )
 
 type TracerOption struct {
-	Name   string `json:"name"`
-	Target string `json:"target"`
+	Name   string          `json:"name"`
+	Target string          `json:"target"`
+	Config json.RawMessage `json:"config"`
 }
 
 type ExecutionResult struct {
@@ -42,40 +44,45 @@ type TraceFilterOptions struct {
 	Count       uint64          `json:"count"`
 }
 
+// TraceAction action of a trace in OpenEthereum format. Fields are set according to the trace type,
+// call and create actions carry the token of the transferred value.
 type TraceAction struct {
-	CallType string        `json:"callType"`
-	From     meter.Address `json:"from"`
-	Gas      string        `json:"gas"`
-	Input    string        `json:"input"`
-	To       meter.Address `json:"to"`
-	Value    string        `json:"value"`
+	CallType      string         `json:"callType,omitempty"`
+	From          *meter.Address `json:"from,omitempty"`
+	To            *meter.Address `json:"to,omitempty"`
+	Gas           string         `json:"gas,omitempty"`
+	Input         string         `json:"input,omitempty"`
+	Init          string         `json:"init,omitempty"`
+	Value         string         `json:"value,omitempty"`
+	Token         *uint32        `json:"token,omitempty"`
+	Address       *meter.Address `json:"address,omitempty"`
+	RefundAddress *meter.Address `json:"refundAddress,omitempty"`
+	Balance       string         `json:"balance,omitempty"`
+	Author        *meter.Address `json:"author,omitempty"`
+	RewardType    string         `json:"rewardType,omitempty"`
 }
+
 type TraceDataResult struct {
-	GasUsed string `json:"gasUsed"`
-	Output  string `json:"output"`
-}
-type TraceData struct {
-	Action              TraceAction     `json:"action"`
-	BlockHash           meter.Bytes32   `json:"blockHash"`
-	BlockNumber         uint64          `json:"blockNumber"`
-	Result              TraceDataResult `json:"result"`
-	Subtraces           uint64          `json:"subtraces"`
-	TraceAddress        []uint64        `json:"traceAddress"`
-	TransactionHash     meter.Bytes32   `json:"transactionHash"`
-	TransactionPosition uint64          `json:"transactionPosition"`
-	Type                string          `json:"type"`
+	Address *meter.Address `json:"address,omitempty"`
+	Code    string         `json:"code,omitempty"`
+	GasUsed string         `json:"gasUsed"`
+	Output  string         `json:"output,omitempty"`
 }
 
-type CallTraceResult struct {
-	Type    string            `json:"type"`
-	From    string            `json:"from"`
-	To      string            `json:"to"`
-	Value   string            `json:"value"`
-	Gas     string            `json:"gas"`
-	GasUsed string            `json:"gasUsed"`
-	Input   string            `json:"input"`
-	Output  string            `json:"output"`
-	Calls   []CallTraceResult `json:"calls"`
+// TraceData a flattened call trace, compatible with OpenEthereum trace_filter.
+// Result is nil for failed calls, suicides and rewards; transaction fields are nil for rewards.
+type TraceData struct {
+	Action              TraceAction      `json:"action"`
+	BlockHash           meter.Bytes32    `json:"blockHash"`
+	BlockNumber         uint64           `json:"blockNumber"`
+	Result              *TraceDataResult `json:"result"`
+	Error               string           `json:"error,omitempty"`
+	Subtraces           uint64           `json:"subtraces"`
+	TraceAddress        []uint64         `json:"traceAddress"`
+	TransactionHash     *meter.Bytes32   `json:"transactionHash"`
+	TransactionPosition *uint64          `json:"transactionPosition"`
+	ClauseIndex         *uint64          `json:"clauseIndex,omitempty"`
+	Type                string           `json:"type"`
 }
 
 // formatLogs formats EVM returned structured logs for json output
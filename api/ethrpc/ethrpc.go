@@ -0,0 +1,975 @@
+// Copyright (c) 2020 The Meter.io developers
+
+// Distributed under the GNU Lesser General Public License v3.0 software license, see the accompanying
+// file LICENSE or <https://www.gnu.org/licenses/lgpl-3.0.html>
+
+package ethrpc
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/gorilla/mux"
+	"github.com/inconshreveable/log15"
+	"github.com/meterio/meter-pov/api/doc"
+	"github.com/meterio/meter-pov/api/utils"
+	"github.com/meterio/meter-pov/block"
+	"github.com/meterio/meter-pov/builtin"
+	"github.com/meterio/meter-pov/chain"
+	"github.com/meterio/meter-pov/comm"
+	"github.com/meterio/meter-pov/logdb"
+	"github.com/meterio/meter-pov/meter"
+	"github.com/meterio/meter-pov/runtime"
+	"github.com/meterio/meter-pov/state"
+	"github.com/meterio/meter-pov/tx"
+	"github.com/meterio/meter-pov/txpool"
+	"github.com/meterio/meter-pov/vm"
+	"github.com/meterio/meter-pov/xenv"
+)
+
+const (
+	// maxBatchSize limits the number of calls in one batch request
+	maxBatchSize = 100
+	// maxLogs limits the number of logs returned by eth_getLogs
+	maxLogs = 10000
+	// maxFeeHistory limits the number of blocks queried by eth_feeHistory
+	maxFeeHistory = 1024
+)
+
+var log = log15.New("pkg", "ethrpc")
+
+// Network is the p2p view needed by net_peerCount.
+type Network interface {
+	PeersStats() []*comm.PeerStats
+}
+
+type methodFunc func(ctx context.Context, params json.RawMessage) (interface{}, error)
+
+// EthRPC serves the Ethereum JSON-RPC protocol (eth_*, net_*, web3_*) on top of the meter chain.
+type EthRPC struct {
+	chain        *chain.Chain
+	stateCreator *state.Creator
+	txPool       *txpool.TxPool
+	logDB        *logdb.LogDB
+	nw           Network
+	callGasLimit uint64
+	methods      map[string]methodFunc
+}
+
+func New(chain *chain.Chain, stateCreator *state.Creator, txPool *txpool.TxPool, logDB *logdb.LogDB, nw Network, callGasLimit uint64) *EthRPC {
+	e := &EthRPC{
+		chain:        chain,
+		stateCreator: stateCreator,
+		txPool:       txPool,
+		logDB:        logDB,
+		nw:           nw,
+		callGasLimit: callGasLimit,
+	}
+	e.methods = map[string]methodFunc{
+		"web3_clientVersion":                    e.clientVersion,
+		"web3_sha3":                             e.sha3,
+		"net_version":                           e.netVersion,
+		"net_listening":                         e.netListening,
+		"net_peerCount":                         e.netPeerCount,
+		"eth_chainId":                           e.chainID,
+		"eth_protocolVersion":                   e.protocolVersion,
+		"eth_syncing":                           e.syncing,
+		"eth_mining":                            e.mining,
+		"eth_hashrate":                          e.hashrate,
+		"eth_accounts":                          e.accounts,
+		"eth_gasPrice":                          e.gasPrice,
+		"eth_maxPriorityFeePerGas":              e.maxPriorityFeePerGas,
+		"eth_feeHistory":                        e.feeHistory,
+		"eth_blockNumber":                       e.blockNumber,
+		"eth_getBalance":                        e.getBalance,
+		"eth_getCode":                           e.getCode,
+		"eth_getStorageAt":                      e.getStorageAt,
+		"eth_getTransactionCount":               e.getTransactionCount,
+		"eth_call":                              e.call,
+		"eth_estimateGas":                       e.estimateGas,
+		"eth_sendRawTransaction":                e.sendRawTransaction,
+		"eth_getTransactionByHash":              e.getTransactionByHash,
+		"eth_getTransactionByBlockHashAndIndex": e.getTransactionByBlockAndIndex,
+		"eth_getTransactionByBlockNumberAndIndex": e.getTransactionByBlockAndIndex,
+		"eth_getTransactionReceipt":               e.getTransactionReceipt,
+		"eth_getBlockByNumber":                    e.getBlock,
+		"eth_getBlockByHash":                      e.getBlock,
+		"eth_getBlockTransactionCountByNumber":    e.getBlockTransactionCount,
+		"eth_getBlockTransactionCountByHash":      e.getBlockTransactionCount,
+		"eth_getUncleCountByBlockNumber":          e.getUncleCount,
+		"eth_getUncleCountByBlockHash":            e.getUncleCount,
+		"eth_getLogs":                             e.getLogs,
+	}
+	return e
+}
+
+func (e *EthRPC) handleRequest(w http.ResponseWriter, req *http.Request) error {
+	data, err := ioutil.ReadAll(req.Body)
+	if err != nil {
+		return err
+	}
+	data = bytes.TrimSpace(data)
+	if len(data) > 0 && data[0] == '[' {
+		var batch []json.RawMessage
+		if err := json.Unmarshal(data, &batch); err != nil {
+			return utils.WriteJSON(w, errorResponse(nil, &rpcError{Code: errCodeParse, Message: err.Error()}))
+		}
+		if len(batch) == 0 || len(batch) > maxBatchSize {
+			return utils.WriteJSON(w, errorResponse(nil, &rpcError{Code: errCodeInvalidRequest, Message: fmt.Sprintf("batch size should be in [1, %d]", maxBatchSize)}))
+		}
+		responses := make([]*rpcResponse, 0, len(batch))
+		for _, msg := range batch {
+			responses = append(responses, e.dispatch(req.Context(), msg))
+		}
+		return utils.WriteJSON(w, responses)
+	}
+	return utils.WriteJSON(w, e.dispatch(req.Context(), data))
+}
+
+func (e *EthRPC) dispatch(ctx context.Context, msg json.RawMessage) *rpcResponse {
+	var r rpcRequest
+	if err := json.Unmarshal(msg, &r); err != nil {
+		return errorResponse(nil, &rpcError{Code: errCodeParse, Message: err.Error()})
+	}
+	if r.JSONRPC != jsonrpcVersion || r.Method == "" {
+		return errorResponse(r.ID, &rpcError{Code: errCodeInvalidRequest, Message: "invalid request"})
+	}
+	method, ok := e.methods[r.Method]
+	if !ok {
+		return errorResponse(r.ID, &rpcError{Code: errCodeMethodNotFound, Message: fmt.Sprintf("the method %s does not exist/is not available", r.Method)})
+	}
+	result, err := method(ctx, r.Params)
+	if err != nil {
+		if re, ok := err.(*rpcError); ok {
+			return errorResponse(r.ID, re)
+		}
+		log.Debug("rpc call failed", "method", r.Method, "err", err)
+		return errorResponse(r.ID, &rpcError{Code: errCodeInternal, Message: err.Error()})
+	}
+	raw, err := json.Marshal(result)
+	if err != nil {
+		return errorResponse(r.ID, &rpcError{Code: errCodeInternal, Message: err.Error()})
+	}
+	return &rpcResponse{JSONRPC: jsonrpcVersion, ID: r.ID, Result: raw}
+}
+
+func errorResponse(id json.RawMessage, err *rpcError) *rpcResponse {
+	if len(id) == 0 {
+		id = json.RawMessage("null")
+	}
+	return &rpcResponse{JSONRPC: jsonrpcVersion, ID: id, Error: err}
+}
+
+// chainIDValue is the EIP-155 chain id, consistent with the one used by the runtime.
+func chainIDValue() *big.Int {
+	if meter.IsMainNet() {
+		return new(big.Int).SetUint64(meter.MainnetChainID)
+	}
+	return new(big.Int).SetUint64(meter.TestnetChainID)
+}
+
+func (e *EthRPC) resolveHeader(b BlockNumberOrHash) (*block.Header, error) {
+	var (
+		header *block.Header
+		err    error
+	)
+	switch {
+	case b.Hash != nil:
+		header, err = e.chain.GetBlockHeader(*b.Hash)
+	case b.Number != nil:
+		header, err = e.chain.GetTrunkBlockHeader(*b.Number)
+	default:
+		return e.chain.BestBlock().Header(), nil
+	}
+	if err != nil {
+		if e.chain.IsNotFound(err) {
+			return nil, errHeaderNotFound
+		}
+		return nil, err
+	}
+	return header, nil
+}
+
+func (e *EthRPC) stateAt(b BlockNumberOrHash) (*state.State, *block.Header, error) {
+	header, err := e.resolveHeader(b)
+	if err != nil {
+		return nil, nil, err
+	}
+	st, err := e.stateCreator.NewState(header.StateRoot())
+	if err != nil {
+		return nil, nil, err
+	}
+	return st, header, nil
+}
+
+func (e *EthRPC) baseGasPrice(header *block.Header) (*big.Int, error) {
+	st, err := e.stateCreator.NewState(header.StateRoot())
+	if err != nil {
+		return nil, err
+	}
+	price := builtin.Params.Native(st).Get(meter.KeyBaseGasPrice)
+	if err := st.Err(); err != nil {
+		return nil, err
+	}
+	return price, nil
+}
+
+func (e *EthRPC) clientVersion(ctx context.Context, params json.RawMessage) (interface{}, error) {
+	return "Meter/" + doc.Version(), nil
+}
+
+func (e *EthRPC) sha3(ctx context.Context, params json.RawMessage) (interface{}, error) {
+	var data hexutil.Bytes
+	if err := parseParams(params, 1, &data); err != nil {
+		return nil, err
+	}
+	return hexutil.Bytes(crypto.Keccak256(data)), nil
+}
+
+func (e *EthRPC) netVersion(ctx context.Context, params json.RawMessage) (interface{}, error) {
+	return chainIDValue().String(), nil
+}
+
+func (e *EthRPC) netListening(ctx context.Context, params json.RawMessage) (interface{}, error) {
+	return true, nil
+}
+
+func (e *EthRPC) netPeerCount(ctx context.Context, params json.RawMessage) (interface{}, error) {
+	if e.nw == nil {
+		return hexutil.Uint(0), nil
+	}
+	return hexutil.Uint(len(e.nw.PeersStats())), nil
+}
+
+func (e *EthRPC) chainID(ctx context.Context, params json.RawMessage) (interface{}, error) {
+	return (*hexutil.Big)(chainIDValue()), nil
+}
+
+func (e *EthRPC) protocolVersion(ctx context.Context, params json.RawMessage) (interface{}, error) {
+	return hexutil.Uint(65), nil
+}
+
+func (e *EthRPC) syncing(ctx context.Context, params json.RawMessage) (interface{}, error) {
+	return false, nil
+}
+
+func (e *EthRPC) mining(ctx context.Context, params json.RawMessage) (interface{}, error) {
+	return false, nil
+}
+
+func (e *EthRPC) hashrate(ctx context.Context, params json.RawMessage) (interface{}, error) {
+	return hexutil.Uint64(0), nil
+}
+
+func (e *EthRPC) accounts(ctx context.Context, params json.RawMessage) (interface{}, error) {
+	return []meter.Address{}, nil
+}
+
+func (e *EthRPC) gasPrice(ctx context.Context, params json.RawMessage) (interface{}, error) {
+	price, err := e.baseGasPrice(e.chain.BestBlock().Header())
+	if err != nil {
+		return nil, err
+	}
+	return (*hexutil.Big)(price), nil
+}
+
+// maxPriorityFeePerGas suggests no premium, as txs pay the base gas price by default, see tx.EthTx.GasPriceCoef.
+func (e *EthRPC) maxPriorityFeePerGas(ctx context.Context, params json.RawMessage) (interface{}, error) {
+	return (*hexutil.Big)(new(big.Int)), nil
+}
+
+// feeHistory reports the base gas price as the base fee, and the premium over it paid by txs as the reward.
+func (e *EthRPC) feeHistory(ctx context.Context, params json.RawMessage) (interface{}, error) {
+	var (
+		blockCount  hexutil.Uint64
+		newest      BlockNumberOrHash
+		percentiles []float64
+	)
+	if err := parseParams(params, 2, &blockCount, &newest, &percentiles); err != nil {
+		return nil, err
+	}
+	for i, p := range percentiles {
+		if p < 0 || p > 100 || (i > 0 && p < percentiles[i-1]) {
+			return nil, invalidParams(errors.New("invalid reward percentiles"))
+		}
+	}
+	if blockCount == 0 {
+		return &FeeHistory{BaseFee: []*hexutil.Big{}, GasUsedRatio: []float64{}}, nil
+	}
+	if blockCount > maxFeeHistory {
+		blockCount = maxFeeHistory
+	}
+	header, err := e.resolveHeader(newest)
+	if err != nil {
+		return nil, err
+	}
+	// there are only number+1 blocks up to the newest one
+	if uint64(blockCount) > uint64(header.Number())+1 {
+		blockCount = hexutil.Uint64(header.Number()) + 1
+	}
+
+	oldest := header.Number() + 1 - uint32(blockCount)
+	result := &FeeHistory{
+		OldestBlock:  hexutil.Uint64(oldest),
+		BaseFee:      make([]*hexutil.Big, 0, blockCount+1),
+		GasUsedRatio: make([]float64, 0, blockCount),
+	}
+	for num := oldest; num <= header.Number(); num++ {
+		blk, err := e.chain.GetTrunkBlock(num)
+		if err != nil {
+			return nil, err
+		}
+		price, err := e.baseGasPrice(blk.Header())
+		if err != nil {
+			return nil, err
+		}
+		result.BaseFee = append(result.BaseFee, (*hexutil.Big)(price))
+		result.GasUsedRatio = append(result.GasUsedRatio, float64(blk.Header().GasUsed())/float64(blk.Header().GasLimit()))
+		if len(percentiles) > 0 {
+			receipts, err := e.chain.GetBlockReceipts(blk.Header().ID())
+			if err != nil {
+				return nil, err
+			}
+			result.Reward = append(result.Reward, feeRewards(blk.Transactions(), receipts, price, percentiles))
+		}
+	}
+	// the base fee of the next block, which is not known yet, assume unchanged
+	result.BaseFee = append(result.BaseFee, result.BaseFee[len(result.BaseFee)-1])
+	return result, nil
+}
+
+// feeRewards returns the premiums over the base gas price at the percentiles, weighted by gas used.
+func feeRewards(txs tx.Transactions, receipts tx.Receipts, baseGasPrice *big.Int, percentiles []float64) []*hexutil.Big {
+	type premium struct {
+		gasUsed uint64
+		reward  *big.Int
+	}
+	rewards := make([]*hexutil.Big, len(percentiles))
+	if len(txs) == 0 || len(txs) != len(receipts) {
+		for i := range rewards {
+			rewards[i] = (*hexutil.Big)(new(big.Int))
+		}
+		return rewards
+	}
+
+	var total uint64
+	premiums := make([]premium, len(txs))
+	for i, t := range txs {
+		premiums[i] = premium{receipts[i].GasUsed, new(big.Int).Sub(t.GasPrice(baseGasPrice), baseGasPrice)}
+		total += receipts[i].GasUsed
+	}
+	sort.Slice(premiums, func(i, j int) bool { return premiums[i].reward.Cmp(premiums[j].reward) < 0 })
+
+	var idx int
+	sum := premiums[0].gasUsed
+	for i, p := range percentiles {
+		threshold := uint64(float64(total) * p / 100)
+		for sum < threshold && idx < len(premiums)-1 {
+			idx++
+			sum += premiums[idx].gasUsed
+		}
+		rewards[i] = (*hexutil.Big)(premiums[idx].reward)
+	}
+	return rewards
+}
+
+func (e *EthRPC) blockNumber(ctx context.Context, params json.RawMessage) (interface{}, error) {
+	return hexutil.Uint64(e.chain.BestBlock().Header().Number()), nil
+}
+
+// getBalance returns the MTR (energy) balance, which is the token carried by eth transactions.
+func (e *EthRPC) getBalance(ctx context.Context, params json.RawMessage) (interface{}, error) {
+	var (
+		addr meter.Address
+		rev  BlockNumberOrHash
+	)
+	if err := parseParams(params, 1, &addr, &rev); err != nil {
+		return nil, err
+	}
+	st, _, err := e.stateAt(rev)
+	if err != nil {
+		return nil, err
+	}
+	balance := st.GetEnergy(addr)
+	if err := st.Err(); err != nil {
+		return nil, err
+	}
+	return (*hexutil.Big)(balance), nil
+}
+
+func (e *EthRPC) getCode(ctx context.Context, params json.RawMessage) (interface{}, error) {
+	var (
+		addr meter.Address
+		rev  BlockNumberOrHash
+	)
+	if err := parseParams(params, 1, &addr, &rev); err != nil {
+		return nil, err
+	}
+	st, _, err := e.stateAt(rev)
+	if err != nil {
+		return nil, err
+	}
+	code := st.GetCode(addr)
+	if err := st.Err(); err != nil {
+		return nil, err
+	}
+	return hexutil.Bytes(code), nil
+}
+
+func (e *EthRPC) getStorageAt(ctx context.Context, params json.RawMessage) (interface{}, error) {
+	var (
+		addr meter.Address
+		key  StorageKey
+		rev  BlockNumberOrHash
+	)
+	if err := parseParams(params, 2, &addr, &key, &rev); err != nil {
+		return nil, err
+	}
+	st, _, err := e.stateAt(rev)
+	if err != nil {
+		return nil, err
+	}
+	value := st.GetStorage(addr, meter.Bytes32(key))
+	if err := st.Err(); err != nil {
+		return nil, err
+	}
+	return &value, nil
+}
+
+// getTransactionCount returns a nonce suggestion for the account, which is not an account nonce.
+// Meter does not keep account nonces, replay protection relies on unique tx IDs and block ref
+// expiration. The nonce of eth txs is only used to derive contract addresses, so the value is the
+// number of the block plus the account's txs in the pool, which keeps it unique per sender.
+func (e *EthRPC) getTransactionCount(ctx context.Context, params json.RawMessage) (interface{}, error) {
+	var (
+		addr meter.Address
+		rev  BlockNumberOrHash
+	)
+	if err := parseParams(params, 1, &addr, &rev); err != nil {
+		return nil, err
+	}
+	header, err := e.resolveHeader(rev)
+	if err != nil {
+		return nil, err
+	}
+	nonce := uint64(header.Number())
+	if e.txPool != nil {
+		nonce += uint64(e.txPool.AccountStats(addr).Total)
+	}
+	return hexutil.Uint64(nonce), nil
+}
+
+func (e *EthRPC) doCall(ctx context.Context, args *CallArgs, header *block.Header, gas uint64) (*runtime.Output, error) {
+	st, err := e.stateCreator.NewState(header.StateRoot())
+	if err != nil {
+		return nil, err
+	}
+	signer, _ := header.Signer()
+	var epoch uint64
+	if header.Number() > 0 {
+		if epoch, err = e.chain.GetBlockEpoch(header.ParentID()); err != nil {
+			return nil, err
+		}
+	}
+	rt := runtime.New(e.chain.NewSeeker(header.ParentID()), st,
+		&xenv.BlockContext{
+			Beneficiary: header.Beneficiary(),
+			Signer:      signer,
+			Number:      header.Number(),
+			Time:        header.Timestamp(),
+			GasLimit:    header.GasLimit(),
+			TotalScore:  header.TotalScore(),
+			Epoch:       epoch})
+
+	var (
+		origin   meter.Address
+		value    = new(big.Int)
+		gasPrice = new(big.Int)
+	)
+	if args.From != nil {
+		origin = *args.From
+	}
+	if args.Value != nil {
+		value = args.Value.ToInt()
+	}
+	if args.GasPrice != nil {
+		gasPrice = args.GasPrice.ToInt()
+	}
+	clause := tx.NewClause(args.To).WithValue(value).WithToken(meter.STPT).WithData(args.data())
+	exec, interrupt := rt.PrepareClause(clause, 0, gas, &xenv.TransactionContext{
+		Origin:     origin,
+		GasPrice:   gasPrice,
+		BlockRef:   tx.NewBlockRefFromID(header.ID()),
+		ProvedWork: &big.Int{}})
+
+	vmout := make(chan *runtime.Output, 1)
+	go func() {
+		out, _ := exec()
+		vmout <- out
+	}()
+	select {
+	case <-ctx.Done():
+		interrupt()
+		return nil, ctx.Err()
+	case out := <-vmout:
+		if err := rt.Seeker().Err(); err != nil {
+			return nil, err
+		}
+		if err := st.Err(); err != nil {
+			return nil, err
+		}
+		return out, nil
+	}
+}
+
+func (e *EthRPC) callGas(args *CallArgs) (uint64, error) {
+	if args.Gas == nil || uint64(*args.Gas) == 0 {
+		return e.callGasLimit, nil
+	}
+	if uint64(*args.Gas) > e.callGasLimit {
+		return 0, invalidParams(errors.New("gas: exceeds limit"))
+	}
+	return uint64(*args.Gas), nil
+}
+
+// vmError returns the error of a failed call, only reverts carry the return data.
+func vmError(out *runtime.Output) error {
+	if out.VMErr == vm.ErrExecutionReverted {
+		return &rpcError{
+			Code:    errCodeReverted,
+			Message: "execution reverted",
+			Data:    hexutil.Bytes(out.Data).String(),
+		}
+	}
+	return &rpcError{Code: errCodeServer, Message: out.VMErr.Error()}
+}
+
+func (e *EthRPC) call(ctx context.Context, params json.RawMessage) (interface{}, error) {
+	var (
+		args CallArgs
+		rev  BlockNumberOrHash
+	)
+	if err := parseParams(params, 1, &args, &rev); err != nil {
+		return nil, err
+	}
+	gas, err := e.callGas(&args)
+	if err != nil {
+		return nil, err
+	}
+	header, err := e.resolveHeader(rev)
+	if err != nil {
+		return nil, err
+	}
+	out, err := e.doCall(ctx, &args, header, gas)
+	if err != nil {
+		return nil, err
+	}
+	if out.VMErr != nil {
+		return nil, vmError(out)
+	}
+	return hexutil.Bytes(out.Data), nil
+}
+
+// estimateGas returns intrinsic gas plus the gas consumed executing the clause at the given block.
+// Refunds are not deducted, so the estimation is an upper bound.
+func (e *EthRPC) estimateGas(ctx context.Context, params json.RawMessage) (interface{}, error) {
+	var (
+		args CallArgs
+		rev  BlockNumberOrHash
+	)
+	if err := parseParams(params, 1, &args, &rev); err != nil {
+		return nil, err
+	}
+	gas, err := e.callGas(&args)
+	if err != nil {
+		return nil, err
+	}
+	header, err := e.resolveHeader(rev)
+	if err != nil {
+		return nil, err
+	}
+	out, err := e.doCall(ctx, &args, header, gas)
+	if err != nil {
+		return nil, err
+	}
+	if out.VMErr != nil {
+		return nil, vmError(out)
+	}
+	intrinsic, err := tx.IntrinsicGas(tx.NewClause(args.To).WithData(args.data()))
+	if err != nil {
+		return nil, err
+	}
+	return hexutil.Uint64(intrinsic + gas - out.LeftOverGas), nil
+}
+
+func (e *EthRPC) sendRawTransaction(ctx context.Context, params json.RawMessage) (interface{}, error) {
+	var raw hexutil.Bytes
+	if err := parseParams(params, 1, &raw); err != nil {
+		return nil, err
+	}
+	ethTx, err := tx.DecodeEthTx(raw)
+	if err != nil {
+		return nil, invalidParams(err)
+	}
+	best := e.chain.BestBlock()
+	nativeTx, err := tx.NewTransactionFromEthTx(ethTx, e.chain.Tag(), tx.NewBlockRefFromID(best.Header().ID()))
+	if err != nil {
+		return nil, invalidParams(err)
+	}
+	if err := e.txPool.Add(nativeTx); err != nil {
+		return nil, err
+	}
+	id := nativeTx.ID()
+	return &id, nil
+}
+
+func (e *EthRPC) getTransactionByHash(ctx context.Context, params json.RawMessage) (interface{}, error) {
+	var txID meter.Bytes32
+	if err := parseParams(params, 1, &txID); err != nil {
+		return nil, err
+	}
+	best := e.chain.BestBlock().Header()
+	meta, err := e.chain.GetTransactionMeta(txID, best.ID())
+	if err != nil {
+		if !e.chain.IsNotFound(err) {
+			return nil, err
+		}
+		if e.txPool != nil {
+			if pending := e.txPool.Get(txID); pending != nil {
+				price, err := e.baseGasPrice(best)
+				if err != nil {
+					return nil, err
+				}
+				return convertTransaction(pending, nil, 0, price)
+			}
+		}
+		return nil, nil
+	}
+	t, err := e.chain.GetTransaction(meta.BlockID, meta.Index)
+	if err != nil {
+		return nil, err
+	}
+	header, err := e.chain.GetBlockHeader(meta.BlockID)
+	if err != nil {
+		return nil, err
+	}
+	price, err := e.baseGasPrice(header)
+	if err != nil {
+		return nil, err
+	}
+	return convertTransaction(t, header, meta.Index, price)
+}
+
+func (e *EthRPC) getTransactionByBlockAndIndex(ctx context.Context, params json.RawMessage) (interface{}, error) {
+	var (
+		rev   BlockNumberOrHash
+		index hexutil.Uint64
+	)
+	if err := parseParams(params, 2, &rev, &index); err != nil {
+		return nil, err
+	}
+	header, err := e.resolveHeader(rev)
+	if err != nil {
+		if err == errHeaderNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	body, err := e.chain.GetBlockBody(header.ID())
+	if err != nil {
+		return nil, err
+	}
+	if uint64(index) >= uint64(len(body.Txs)) {
+		return nil, nil
+	}
+	price, err := e.baseGasPrice(header)
+	if err != nil {
+		return nil, err
+	}
+	return convertTransaction(body.Txs[index], header, uint64(index), price)
+}
+
+func (e *EthRPC) getTransactionReceipt(ctx context.Context, params json.RawMessage) (interface{}, error) {
+	var txID meter.Bytes32
+	if err := parseParams(params, 1, &txID); err != nil {
+		return nil, err
+	}
+	meta, err := e.chain.GetTransactionMeta(txID, e.chain.BestBlock().Header().ID())
+	if err != nil {
+		if e.chain.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	blk, err := e.chain.GetBlock(meta.BlockID)
+	if err != nil {
+		return nil, err
+	}
+	receipts, err := e.chain.GetBlockReceipts(meta.BlockID)
+	if err != nil {
+		return nil, err
+	}
+	price, err := e.baseGasPrice(blk.Header())
+	if err != nil {
+		return nil, err
+	}
+	return convertReceipt(blk, receipts, meta.Index, price)
+}
+
+func convertReceipt(blk *block.Block, receipts tx.Receipts, index uint64, baseGasPrice *big.Int) (*RPCReceipt, error) {
+	header := blk.Header()
+	txs := blk.Transactions()
+	if index >= uint64(len(txs)) || index >= uint64(len(receipts)) {
+		return nil, errors.New("tx index out of range")
+	}
+	t, receipt := txs[index], receipts[index]
+	origin, err := t.Signer()
+	if err != nil {
+		return nil, err
+	}
+	var cumulativeGas, logIndex uint64
+	for i := uint64(0); i < index; i++ {
+		cumulativeGas += receipts[i].GasUsed
+		for _, o := range receipts[i].Outputs {
+			logIndex += uint64(len(o.Events))
+		}
+	}
+	r := &RPCReceipt{
+		TransactionHash:   t.ID(),
+		TransactionIndex:  hexutil.Uint64(index),
+		BlockHash:         header.ID(),
+		BlockNumber:       hexutil.Uint64(header.Number()),
+		From:              origin,
+		CumulativeGasUsed: hexutil.Uint64(cumulativeGas + receipt.GasUsed),
+		GasUsed:           hexutil.Uint64(receipt.GasUsed),
+		EffectiveGasPrice: (*hexutil.Big)(t.GasPrice(baseGasPrice)),
+		Logs:              make([]*RPCLog, 0),
+		LogsBloom:         tx.CreateEthBloom(tx.Receipts{receipt}),
+	}
+	if !receipt.Reverted {
+		r.Status = 1
+	}
+	if t.IsEthTx() {
+		ethTx, err := t.GetEthTx()
+		if err != nil {
+			return nil, err
+		}
+		r.Type = hexutil.Uint64(ethTx.Type)
+	}
+	if clauses := t.Clauses(); len(clauses) > 0 {
+		r.To = clauses[0].To()
+		if r.To == nil {
+			addr := meter.Address(meter.EthCreateContractAddress(common.Address(origin), uint32(t.Nonce())))
+			r.ContractAddress = &addr
+		}
+	}
+	for _, o := range receipt.Outputs {
+		for _, ev := range o.Events {
+			r.Logs = append(r.Logs, convertEvent(ev, header, t.ID(), index, logIndex))
+			logIndex++
+		}
+	}
+	return r, nil
+}
+
+func (e *EthRPC) getBlock(ctx context.Context, params json.RawMessage) (interface{}, error) {
+	var (
+		rev     BlockNumberOrHash
+		fullTxs bool
+	)
+	if err := parseParams(params, 1, &rev, &fullTxs); err != nil {
+		return nil, err
+	}
+	header, err := e.resolveHeader(rev)
+	if err != nil {
+		if err == errHeaderNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	blk, err := e.chain.GetBlock(header.ID())
+	if err != nil {
+		return nil, err
+	}
+	receipts, err := e.chain.GetBlockReceipts(header.ID())
+	if err != nil {
+		return nil, err
+	}
+	result := convertBlockHeader(header, uint64(blk.Size()), tx.CreateEthBloom(receipts))
+	price, err := e.baseGasPrice(header)
+	if err != nil {
+		return nil, err
+	}
+	result.BaseFeePerGas = (*hexutil.Big)(price)
+	for i, t := range blk.Transactions() {
+		if fullTxs {
+			converted, err := convertTransaction(t, header, uint64(i), price)
+			if err != nil {
+				return nil, err
+			}
+			result.Transactions = append(result.Transactions, converted)
+		} else {
+			id := t.ID()
+			result.Transactions = append(result.Transactions, &id)
+		}
+	}
+	return result, nil
+}
+
+func (e *EthRPC) getBlockTransactionCount(ctx context.Context, params json.RawMessage) (interface{}, error) {
+	var rev BlockNumberOrHash
+	if err := parseParams(params, 1, &rev); err != nil {
+		return nil, err
+	}
+	header, err := e.resolveHeader(rev)
+	if err != nil {
+		if err == errHeaderNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	body, err := e.chain.GetBlockBody(header.ID())
+	if err != nil {
+		return nil, err
+	}
+	return hexutil.Uint(len(body.Txs)), nil
+}
+
+func (e *EthRPC) getUncleCount(ctx context.Context, params json.RawMessage) (interface{}, error) {
+	return hexutil.Uint(0), nil
+}
+
+func (e *EthRPC) getLogs(ctx context.Context, params json.RawMessage) (interface{}, error) {
+	var query FilterQuery
+	if err := parseParams(params, 1, &query); err != nil {
+		return nil, err
+	}
+	if len(query.Topics) > 4 {
+		return nil, invalidParams(errors.New("too many topics"))
+	}
+
+	var from, to uint32
+	if query.BlockHash != nil {
+		if query.FromBlock != nil || query.ToBlock != nil {
+			return nil, invalidParams(errors.New("blockHash is exclusive with fromBlock/toBlock"))
+		}
+		header, err := e.resolveHeader(BlockNumberOrHash{Hash: query.BlockHash})
+		if err != nil {
+			return nil, err
+		}
+		from, to = header.Number(), header.Number()
+	} else {
+		best := e.chain.BestBlock().Header().Number()
+		from, to = best, best
+		if query.FromBlock != nil {
+			header, err := e.resolveHeader(*query.FromBlock)
+			if err != nil {
+				return nil, err
+			}
+			from = header.Number()
+		}
+		if query.ToBlock != nil {
+			header, err := e.resolveHeader(*query.ToBlock)
+			if err != nil {
+				return nil, err
+			}
+			to = header.Number()
+		}
+		if from > to {
+			return []*RPCLog{}, nil
+		}
+	}
+
+	filter := &logdb.EventFilter{
+		CriteriaSet: buildCriteriaSet(query.Addresses, query.Topics),
+		Range:       &logdb.Range{Unit: logdb.Block, From: uint64(from), To: uint64(to)},
+		Options:     &logdb.Options{Offset: 0, Limit: maxLogs + 1},
+		Order:       logdb.ASC,
+	}
+	events, err := e.logDB.FilterEvents(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	if len(events) > maxLogs {
+		return nil, &rpcError{Code: errCodeInvalidParams, Message: fmt.Sprintf("query returned more than %d results", maxLogs)}
+	}
+
+	logs := make([]*RPCLog, 0, len(events))
+	txIndexes := make(map[meter.Bytes32]uint64)
+	for _, ev := range events {
+		if query.BlockHash != nil && ev.BlockID != *query.BlockHash {
+			continue
+		}
+		txIndex, ok := txIndexes[ev.TxID]
+		if !ok {
+			meta, err := e.chain.GetTransactionMeta(ev.TxID, ev.BlockID)
+			if err != nil {
+				return nil, err
+			}
+			txIndex = meta.Index
+			txIndexes[ev.TxID] = txIndex
+		}
+		topics := make([]meter.Bytes32, 0, len(ev.Topics))
+		for _, topic := range ev.Topics {
+			if topic == nil {
+				break
+			}
+			topics = append(topics, *topic)
+		}
+		logs = append(logs, &RPCLog{
+			Address:          ev.Address,
+			Topics:           topics,
+			Data:             ev.Data,
+			BlockNumber:      hexutil.Uint64(ev.BlockNumber),
+			BlockHash:        ev.BlockID,
+			TransactionHash:  ev.TxID,
+			TransactionIndex: hexutil.Uint64(txIndex),
+			LogIndex:         hexutil.Uint64(ev.Index),
+		})
+	}
+	return logs, nil
+}
+
+// buildCriteriaSet expands eth address/topic alternatives into logdb criteria,
+// which are OR-ed together by the log db.
+func buildCriteriaSet(addresses addressList, topics []topicList) []*logdb.EventCriteria {
+	criteriaSet := []*logdb.EventCriteria{{}}
+	if len(addresses) > 0 {
+		expanded := make([]*logdb.EventCriteria, 0, len(addresses))
+		for i := range addresses {
+			expanded = append(expanded, &logdb.EventCriteria{Address: &addresses[i]})
+		}
+		criteriaSet = expanded
+	}
+	for pos, alternatives := range topics {
+		if len(alternatives) == 0 {
+			continue
+		}
+		expanded := make([]*logdb.EventCriteria, 0, len(criteriaSet)*len(alternatives))
+		for _, c := range criteriaSet {
+			for i := range alternatives {
+				criteria := *c
+				criteria.Topics[pos] = &alternatives[i]
+				expanded = append(expanded, &criteria)
+			}
+		}
+		criteriaSet = expanded
+	}
+	return criteriaSet
+}
+
+func (e *EthRPC) Mount(root *mux.Router, pathPrefix string) {
+	sub := root.PathPrefix(pathPrefix).Subrouter()
+
+	sub.Path("").Methods(http.MethodPost).HandlerFunc(utils.WrapHandlerFunc(e.handleRequest))
+}
@@ -0,0 +1,180 @@
+// Copyright (c) 2020 The Meter.io developers
+
+// Distributed under the GNU Lesser General Public License v3.0 software license, see the accompanying
+// file LICENSE or <https://www.gnu.org/licenses/lgpl-3.0.html>
+
+package ethrpc_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"math"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/gorilla/mux"
+	"github.com/meterio/meter-pov/api/ethrpc"
+	"github.com/meterio/meter-pov/builtin"
+	"github.com/meterio/meter-pov/chain/testchain"
+	"github.com/meterio/meter-pov/genesis"
+	"github.com/meterio/meter-pov/logdb"
+	"github.com/meterio/meter-pov/lvldb"
+	"github.com/meterio/meter-pov/meter"
+	"github.com/meterio/meter-pov/tx"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	ts        *httptest.Server
+	recipient = meter.BytesToAddress([]byte("recipient"))
+	value     = big.NewInt(10000)
+	topic0    = meter.BytesToBytes32([]byte("topic0"))
+	packedTx  *tx.Transaction
+)
+
+type rpcResult struct {
+	Result json.RawMessage `json:"result"`
+	Error  *struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+func TestEthRPC(t *testing.T) {
+	initRPCServer(t)
+	defer ts.Close()
+
+	var num hexutil.Uint64
+	callRPC(t, "eth_blockNumber", []interface{}{}, &num)
+	assert.Equal(t, hexutil.Uint64(1), num)
+
+	var balance hexutil.Big
+	callRPC(t, "eth_getBalance", []interface{}{recipient.String(), "latest"}, &balance)
+	assert.Equal(t, value, balance.ToInt())
+	callRPC(t, "eth_getBalance", []interface{}{recipient.String(), "earliest"}, &balance)
+	assert.Equal(t, 0, balance.ToInt().Sign())
+	callRPC(t, "eth_getBalance", []interface{}{recipient.String(), " 0x1 "}, &balance)
+	assert.Equal(t, value, balance.ToInt())
+
+	var nonce hexutil.Uint64
+	callRPC(t, "eth_getTransactionCount", []interface{}{recipient.String(), "latest"}, &nonce)
+	assert.Equal(t, hexutil.Uint64(1), nonce)
+
+	// storage keys are accepted with leading zeros as sent by web3 and ethers, and as quantities
+	var slot, slotByQuantity meter.Bytes32
+	callRPC(t, "eth_getStorageAt", []interface{}{builtin.Params.Address.String(), meter.KeyExecutorAddress.String(), "latest"}, &slot)
+	assert.False(t, slot.IsZero())
+	quantity := hexutil.EncodeBig(new(big.Int).SetBytes(meter.KeyExecutorAddress[:]))
+	callRPC(t, "eth_getStorageAt", []interface{}{builtin.Params.Address.String(), quantity, "latest"}, &slotByQuantity)
+	assert.Equal(t, slot, slotByQuantity)
+
+	var history ethrpc.FeeHistory
+	callRPC(t, "eth_feeHistory", []interface{}{"0x0", "latest"}, &history)
+	assert.Equal(t, 0, len(history.BaseFee))
+	callRPC(t, "eth_feeHistory", []interface{}{"0x10", "latest"}, &history)
+	assert.Equal(t, hexutil.Uint64(0), history.OldestBlock)
+	assert.Equal(t, 3, len(history.BaseFee))
+	assert.Equal(t, 2, len(history.GasUsedRatio))
+
+	var blk ethrpc.RPCBlock
+	callRPC(t, "eth_getBlockByNumber", []interface{}{"0x1", false}, &blk)
+	assert.Equal(t, hexutil.Uint64(1), blk.Number)
+	assert.Equal(t, 1, len(blk.Transactions))
+	assert.Equal(t, packedTx.ID().String(), blk.Transactions[0])
+
+	var receipt ethrpc.RPCReceipt
+	callRPC(t, "eth_getTransactionReceipt", []interface{}{packedTx.ID().String()}, &receipt)
+	assert.Equal(t, hexutil.Uint64(1), receipt.Status)
+	assert.Equal(t, blk.Hash, receipt.BlockHash)
+	assert.Equal(t, genesis.DevAccounts()[0].Address, receipt.From)
+
+	var logs []*ethrpc.RPCLog
+	callRPC(t, "eth_getLogs", []interface{}{map[string]interface{}{
+		"fromBlock": "earliest",
+		"topics":    []interface{}{[]string{topic0.String(), meter.Bytes32{}.String()}},
+	}}, &logs)
+	assert.Equal(t, 1, len(logs))
+	assert.Equal(t, packedTx.ID(), logs[0].TransactionHash)
+
+	res := postRPC(t, map[string]interface{}{"jsonrpc": "2.0", "id": 1, "method": "eth_unknown"})
+	var r rpcResult
+	if err := json.Unmarshal(res, &r); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, -32601, r.Error.Code)
+
+	res = postRPC(t, []map[string]interface{}{
+		{"jsonrpc": "2.0", "id": 1, "method": "eth_chainId"},
+		{"jsonrpc": "2.0", "id": 2, "method": "net_version"},
+	})
+	var batch []rpcResult
+	if err := json.Unmarshal(res, &batch); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, 2, len(batch))
+}
+
+func initRPCServer(t *testing.T) {
+	db, _ := lvldb.NewMem()
+	c, stateC, err := testchain.New(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	logDB, err := logdb.NewMem()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	acc := genesis.DevAccounts()[0]
+	packedTx = testchain.NewTx(c.Tag(), tx.NewClause(&recipient).WithToken(meter.STPT).WithValue(value), 300000, uint64(time.Now().UnixNano()))
+	blk, _, err := testchain.Mint(c, stateC, packedTx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ev := &tx.Event{Address: recipient, Topics: []meter.Bytes32{topic0}, Data: []byte("data")}
+	if err := logDB.Prepare(blk.Header()).ForTransaction(packedTx.ID(), acc.Address).
+		Insert(tx.Events{ev}, nil).Commit(); err != nil {
+		t.Fatal(err)
+	}
+
+	router := mux.NewRouter()
+	ethrpc.New(c, stateC, nil, logDB, nil, math.MaxUint64).Mount(router, "/rpc")
+	ts = httptest.NewServer(router)
+}
+
+func callRPC(t *testing.T, method string, params interface{}, result interface{}) {
+	res := postRPC(t, map[string]interface{}{"jsonrpc": "2.0", "id": 1, "method": method, "params": params})
+	var r rpcResult
+	if err := json.Unmarshal(res, &r); err != nil {
+		t.Fatal(err)
+	}
+	if r.Error != nil {
+		t.Fatalf("%v: %v", method, r.Error.Message)
+	}
+	if err := json.Unmarshal(r.Result, result); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func postRPC(t *testing.T, obj interface{}) []byte {
+	data, err := json.Marshal(obj)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := http.Post(ts.URL+"/rpc", "application/json", bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return r
+}
@@ -0,0 +1,423 @@
+// Copyright (c) 2020 The Meter.io developers
+
+// Distributed under the GNU Lesser General Public License v3.0 software license, see the accompanying
+// file LICENSE or <https://www.gnu.org/licenses/lgpl-3.0.html>
+
+package ethrpc
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/meterio/meter-pov/block"
+	"github.com/meterio/meter-pov/meter"
+	"github.com/meterio/meter-pov/tx"
+)
+
+const jsonrpcVersion = "2.0"
+
+// standard JSON-RPC 2.0 error codes, plus the geth conventions for failed and reverted calls
+const (
+	errCodeParse          = -32700
+	errCodeInvalidRequest = -32600
+	errCodeMethodNotFound = -32601
+	errCodeInvalidParams  = -32602
+	errCodeInternal       = -32603
+	errCodeServer         = -32000
+	errCodeReverted       = 3
+)
+
+var (
+	errHeaderNotFound = errors.New("header not found")
+	// keccak256(rlp([])), the uncle hash of a block without uncles
+	emptyUncleHash = meter.MustParseBytes32("0x1dcc4de8dec75d7aab85b567b6ccd41ad312451b948a7413f0a142fd40d49347")
+)
+
+type rpcRequest struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params"`
+}
+
+type rpcResponse struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *rpcError       `json:"error,omitempty"`
+}
+
+type rpcError struct {
+	Code    int         `json:"code"`
+	Message string      `json:"message"`
+	Data    interface{} `json:"data,omitempty"`
+}
+
+func (e *rpcError) Error() string {
+	return e.Message
+}
+
+func invalidParams(err error) error {
+	return &rpcError{Code: errCodeInvalidParams, Message: err.Error()}
+}
+
+// parseParams decodes positional params into args. The first `required` args must be present,
+// the rest are optional and keep their zero values when omitted.
+func parseParams(raw json.RawMessage, required int, args ...interface{}) error {
+	var params []json.RawMessage
+	if len(raw) > 0 && string(raw) != "null" {
+		if err := json.Unmarshal(raw, &params); err != nil {
+			return invalidParams(errors.New("params: expected array"))
+		}
+	}
+	if len(params) < required {
+		return invalidParams(fmt.Errorf("missing value for required argument %d", len(params)))
+	}
+	if len(params) > len(args) {
+		return invalidParams(fmt.Errorf("too many arguments, want at most %d", len(args)))
+	}
+	for i, p := range params {
+		if err := json.Unmarshal(p, args[i]); err != nil {
+			return invalidParams(fmt.Errorf("invalid argument %d: %v", i, err))
+		}
+	}
+	return nil
+}
+
+// StorageKey is the key of a storage slot, which clients send either as 32 bytes in hex with
+// leading zeros or as a quantity.
+type StorageKey meter.Bytes32
+
+func (k *StorageKey) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	if !strings.HasPrefix(s, "0x") && !strings.HasPrefix(s, "0X") {
+		return errors.New("storage key: hex string without 0x prefix")
+	}
+	s = s[2:]
+	if len(s)%2 == 1 {
+		s = "0" + s
+	}
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		return fmt.Errorf("storage key: %v", err)
+	}
+	if len(b) > len(meter.Bytes32{}) {
+		return errors.New("storage key: longer than 32 bytes")
+	}
+	*k = StorageKey(meter.BytesToBytes32(b))
+	return nil
+}
+
+// BlockNumberOrHash identifies a block by tag ("latest", "earliest", "pending"), by number
+// or by hash. The zero value refers to the best block.
+type BlockNumberOrHash struct {
+	Number *uint32
+	Hash   *meter.Bytes32
+}
+
+func (b *BlockNumberOrHash) UnmarshalJSON(data []byte) error {
+	var obj struct {
+		BlockNumber *string        `json:"blockNumber"`
+		BlockHash   *meter.Bytes32 `json:"blockHash"`
+	}
+	if len(data) > 0 && data[0] == '{' {
+		if err := json.Unmarshal(data, &obj); err != nil {
+			return err
+		}
+		if obj.BlockHash != nil {
+			b.Hash = obj.BlockHash
+			return nil
+		}
+		if obj.BlockNumber != nil {
+			return b.parse(*obj.BlockNumber)
+		}
+		return errors.New("either blockNumber or blockHash required")
+	}
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	return b.parse(s)
+}
+
+func (b *BlockNumberOrHash) parse(s string) error {
+	s = strings.TrimSpace(s)
+	switch s {
+	case "", "latest", "pending", "safe", "finalized":
+		return nil
+	case "earliest":
+		zero := uint32(0)
+		b.Number = &zero
+		return nil
+	}
+	if len(s) == 66 {
+		hash, err := meter.ParseBytes32(s)
+		if err != nil {
+			return err
+		}
+		b.Hash = &hash
+		return nil
+	}
+	n, err := hexutil.DecodeUint64(s)
+	if err != nil {
+		return err
+	}
+	if n > uint64(^uint32(0)) {
+		return errors.New("block number out of max uint32")
+	}
+	num := uint32(n)
+	b.Number = &num
+	return nil
+}
+
+// CallArgs represents the arguments of eth_call and eth_estimateGas.
+type CallArgs struct {
+	From     *meter.Address  `json:"from"`
+	To       *meter.Address  `json:"to"`
+	Gas      *hexutil.Uint64 `json:"gas"`
+	GasPrice *hexutil.Big    `json:"gasPrice"`
+	Value    *hexutil.Big    `json:"value"`
+	Data     *hexutil.Bytes  `json:"data"`
+	Input    *hexutil.Bytes  `json:"input"`
+}
+
+func (args *CallArgs) data() []byte {
+	if args.Input != nil {
+		return *args.Input
+	}
+	if args.Data != nil {
+		return *args.Data
+	}
+	return nil
+}
+
+// FilterQuery represents the argument of eth_getLogs.
+type FilterQuery struct {
+	BlockHash *meter.Bytes32     `json:"blockHash"`
+	FromBlock *BlockNumberOrHash `json:"fromBlock"`
+	ToBlock   *BlockNumberOrHash `json:"toBlock"`
+	Addresses addressList        `json:"address"`
+	Topics    []topicList        `json:"topics"`
+}
+
+// addressList accepts either a single address or an array of addresses.
+type addressList []meter.Address
+
+func (l *addressList) UnmarshalJSON(data []byte) error {
+	if len(data) > 0 && data[0] == '[' {
+		return json.Unmarshal(data, (*[]meter.Address)(l))
+	}
+	var addr meter.Address
+	if err := json.Unmarshal(data, &addr); err != nil {
+		return err
+	}
+	*l = addressList{addr}
+	return nil
+}
+
+// topicList accepts null (wildcard), a single topic or an array of alternative topics.
+type topicList []meter.Bytes32
+
+func (l *topicList) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		*l = nil
+		return nil
+	}
+	if len(data) > 0 && data[0] == '[' {
+		return json.Unmarshal(data, (*[]meter.Bytes32)(l))
+	}
+	var topic meter.Bytes32
+	if err := json.Unmarshal(data, &topic); err != nil {
+		return err
+	}
+	*l = topicList{topic}
+	return nil
+}
+
+// RPCBlock is the eth style representation of a block.
+type RPCBlock struct {
+	Number           hexutil.Uint64  `json:"number"`
+	Hash             meter.Bytes32   `json:"hash"`
+	ParentHash       meter.Bytes32   `json:"parentHash"`
+	Nonce            hexutil.Bytes   `json:"nonce"`
+	Sha3Uncles       meter.Bytes32   `json:"sha3Uncles"`
+	LogsBloom        tx.EthBloom     `json:"logsBloom"`
+	TransactionsRoot meter.Bytes32   `json:"transactionsRoot"`
+	StateRoot        meter.Bytes32   `json:"stateRoot"`
+	ReceiptsRoot     meter.Bytes32   `json:"receiptsRoot"`
+	Miner            meter.Address   `json:"miner"`
+	Difficulty       hexutil.Uint64  `json:"difficulty"`
+	TotalDifficulty  hexutil.Uint64  `json:"totalDifficulty"`
+	ExtraData        hexutil.Bytes   `json:"extraData"`
+	Size             hexutil.Uint64  `json:"size"`
+	GasLimit         hexutil.Uint64  `json:"gasLimit"`
+	GasUsed          hexutil.Uint64  `json:"gasUsed"`
+	Timestamp        hexutil.Uint64  `json:"timestamp"`
+	BaseFeePerGas    *hexutil.Big    `json:"baseFeePerGas"`
+	Transactions     []interface{}   `json:"transactions"`
+	Uncles           []meter.Bytes32 `json:"uncles"`
+}
+
+// FeeHistory is the result of eth_feeHistory.
+type FeeHistory struct {
+	OldestBlock  hexutil.Uint64   `json:"oldestBlock"`
+	BaseFee      []*hexutil.Big   `json:"baseFeePerGas"`
+	GasUsedRatio []float64        `json:"gasUsedRatio"`
+	Reward       [][]*hexutil.Big `json:"reward,omitempty"`
+}
+
+// RPCTransaction is the eth style representation of a transaction. Multi-clause
+// Meter transactions are represented by their first clause.
+type RPCTransaction struct {
+	BlockHash        *meter.Bytes32  `json:"blockHash"`
+	BlockNumber      *hexutil.Uint64 `json:"blockNumber"`
+	From             meter.Address   `json:"from"`
+	Gas              hexutil.Uint64  `json:"gas"`
+	GasPrice         *hexutil.Big    `json:"gasPrice"`
+	Hash             meter.Bytes32   `json:"hash"`
+	Input            hexutil.Bytes   `json:"input"`
+	Nonce            hexutil.Uint64  `json:"nonce"`
+	To               *meter.Address  `json:"to"`
+	TransactionIndex *hexutil.Uint64 `json:"transactionIndex"`
+	Value            *hexutil.Big    `json:"value"`
+	Type             hexutil.Uint64  `json:"type"`
+	ChainID          *hexutil.Big    `json:"chainId,omitempty"`
+	// fee caps of dynamic fee txs
+	MaxFeePerGas         *hexutil.Big   `json:"maxFeePerGas,omitempty"`
+	MaxPriorityFeePerGas *hexutil.Big   `json:"maxPriorityFeePerGas,omitempty"`
+	AccessList           *tx.AccessList `json:"accessList,omitempty"`
+	V                    *hexutil.Big   `json:"v"`
+	R                    *hexutil.Big   `json:"r"`
+	S                    *hexutil.Big   `json:"s"`
+}
+
+// RPCLog is the eth style representation of an event log.
+type RPCLog struct {
+	Address          meter.Address   `json:"address"`
+	Topics           []meter.Bytes32 `json:"topics"`
+	Data             hexutil.Bytes   `json:"data"`
+	BlockNumber      hexutil.Uint64  `json:"blockNumber"`
+	BlockHash        meter.Bytes32   `json:"blockHash"`
+	TransactionHash  meter.Bytes32   `json:"transactionHash"`
+	TransactionIndex hexutil.Uint64  `json:"transactionIndex"`
+	LogIndex         hexutil.Uint64  `json:"logIndex"`
+	Removed          bool            `json:"removed"`
+}
+
+// RPCReceipt is the eth style representation of a transaction receipt.
+type RPCReceipt struct {
+	TransactionHash   meter.Bytes32  `json:"transactionHash"`
+	TransactionIndex  hexutil.Uint64 `json:"transactionIndex"`
+	BlockHash         meter.Bytes32  `json:"blockHash"`
+	BlockNumber       hexutil.Uint64 `json:"blockNumber"`
+	From              meter.Address  `json:"from"`
+	To                *meter.Address `json:"to"`
+	CumulativeGasUsed hexutil.Uint64 `json:"cumulativeGasUsed"`
+	GasUsed           hexutil.Uint64 `json:"gasUsed"`
+	EffectiveGasPrice *hexutil.Big   `json:"effectiveGasPrice"`
+	ContractAddress   *meter.Address `json:"contractAddress"`
+	Logs              []*RPCLog      `json:"logs"`
+	LogsBloom         tx.EthBloom    `json:"logsBloom"`
+	Status            hexutil.Uint64 `json:"status"`
+	Type              hexutil.Uint64 `json:"type"`
+}
+
+func convertBlockHeader(header *block.Header, size uint64, bloom tx.EthBloom) *RPCBlock {
+	return &RPCBlock{
+		Number:           hexutil.Uint64(header.Number()),
+		Hash:             header.ID(),
+		ParentHash:       header.ParentID(),
+		Nonce:            make(hexutil.Bytes, 8),
+		Sha3Uncles:       emptyUncleHash,
+		LogsBloom:        bloom,
+		TransactionsRoot: header.TxsRoot(),
+		StateRoot:        header.StateRoot(),
+		ReceiptsRoot:     header.ReceiptsRoot(),
+		Miner:            header.Beneficiary(),
+		Difficulty:       0,
+		TotalDifficulty:  hexutil.Uint64(header.TotalScore()),
+		ExtraData:        hexutil.Bytes{},
+		Size:             hexutil.Uint64(size),
+		GasLimit:         hexutil.Uint64(header.GasLimit()),
+		GasUsed:          hexutil.Uint64(header.GasUsed()),
+		Timestamp:        hexutil.Uint64(header.Timestamp()),
+		Transactions:     make([]interface{}, 0),
+		Uncles:           make([]meter.Bytes32, 0),
+	}
+}
+
+// convertTransaction converts a tx into eth style. header may be nil for pending txs.
+func convertTransaction(t *tx.Transaction, header *block.Header, index uint64, baseGasPrice *big.Int) (*RPCTransaction, error) {
+	origin, err := t.Signer()
+	if err != nil {
+		return nil, err
+	}
+	rt := &RPCTransaction{
+		From:     origin,
+		Gas:      hexutil.Uint64(t.Gas()),
+		GasPrice: (*hexutil.Big)(t.GasPrice(baseGasPrice)),
+		Hash:     t.ID(),
+		Input:    hexutil.Bytes{},
+		Nonce:    hexutil.Uint64(t.Nonce()),
+		Value:    (*hexutil.Big)(new(big.Int)),
+		V:        (*hexutil.Big)(new(big.Int)),
+		R:        (*hexutil.Big)(new(big.Int)),
+		S:        (*hexutil.Big)(new(big.Int)),
+	}
+	if header != nil {
+		id := header.ID()
+		num := hexutil.Uint64(header.Number())
+		idx := hexutil.Uint64(index)
+		rt.BlockHash = &id
+		rt.BlockNumber = &num
+		rt.TransactionIndex = &idx
+	}
+	if clauses := t.Clauses(); len(clauses) > 0 {
+		rt.To = clauses[0].To()
+		rt.Value = (*hexutil.Big)(clauses[0].Value())
+		rt.Input = clauses[0].Data()
+	}
+	if t.IsEthTx() {
+		ethTx, err := t.GetEthTx()
+		if err != nil {
+			return nil, err
+		}
+		rt.V, rt.R, rt.S = (*hexutil.Big)(ethTx.V), (*hexutil.Big)(ethTx.R), (*hexutil.Big)(ethTx.S)
+		rt.Type = hexutil.Uint64(ethTx.Type)
+		rt.ChainID = (*hexutil.Big)(ethTx.ChainID)
+		if ethTx.Type != tx.LegacyTxType {
+			rt.AccessList = &ethTx.AccessList
+		}
+		if ethTx.Type == tx.DynamicFeeTxType {
+			// gas price stays the price paid, see tx.EthTx.GasPriceCoef
+			rt.MaxFeePerGas = (*hexutil.Big)(ethTx.GasFeeCap)
+			rt.MaxPriorityFeePerGas = (*hexutil.Big)(ethTx.GasTipCap)
+		} else {
+			rt.GasPrice = (*hexutil.Big)(ethTx.GasPrice)
+		}
+	}
+	return rt, nil
+}
+
+func convertEvent(ev *tx.Event, header *block.Header, txID meter.Bytes32, txIndex uint64, logIndex uint64) *RPCLog {
+	topics := make([]meter.Bytes32, len(ev.Topics))
+	copy(topics, ev.Topics)
+	return &RPCLog{
+		Address:          ev.Address,
+		Topics:           topics,
+		Data:             ev.Data,
+		BlockNumber:      hexutil.Uint64(header.Number()),
+		BlockHash:        header.ID(),
+		TransactionHash:  txID,
+		TransactionIndex: hexutil.Uint64(txIndex),
+		LogIndex:         hexutil.Uint64(logIndex),
+	}
+}
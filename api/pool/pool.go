@@ -0,0 +1,103 @@
+// Copyright (c) 2020 The Meter.io developers
+
+// Distributed under the GNU Lesser General Public License v3.0 software license, see the accompanying
+// file LICENSE or <https://www.gnu.org/licenses/lgpl-3.0.html>
+
+package pool
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/meterio/meter-pov/api/utils"
+	"github.com/meterio/meter-pov/meter"
+	"github.com/meterio/meter-pov/txpool"
+	"github.com/pkg/errors"
+)
+
+type Pool struct {
+	pool *txpool.TxPool
+}
+
+func New(pool *txpool.TxPool) *Pool {
+	return &Pool{
+		pool,
+	}
+}
+
+func (p *Pool) handleGetTxs(w http.ResponseWriter, req *http.Request) error {
+	var origin *meter.Address
+	if s := req.URL.Query().Get("origin"); s != "" {
+		addr, err := meter.ParseAddress(s)
+		if err != nil {
+			return utils.BadRequest(errors.WithMessage(err, "origin"))
+		}
+		origin = &addr
+	}
+	// pending txs are executable, queued are not
+	status := req.URL.Query().Get("status")
+	if status != "" && status != "pending" && status != "queued" {
+		return utils.BadRequest(errors.WithMessage(errors.New("should be pending or queued"), "status"))
+	}
+
+	statuses, err := p.pool.Inspect(origin)
+	if err != nil {
+		return err
+	}
+	txs := make([]*Tx, 0, len(statuses))
+	for _, s := range statuses {
+		if (status == "pending" && !s.Executable) || (status == "queued" && s.Executable) {
+			continue
+		}
+		txs = append(txs, convertTx(s))
+	}
+	return utils.WriteJSON(w, txs)
+}
+
+func (p *Pool) handleGetTxByID(w http.ResponseWriter, req *http.Request) error {
+	id, err := meter.ParseBytes32(mux.Vars(req)["id"])
+	if err != nil {
+		return utils.BadRequest(errors.WithMessage(err, "id"))
+	}
+	status, err := p.pool.InspectTx(id)
+	if err != nil {
+		return err
+	}
+	if status == nil {
+		return utils.WriteJSON(w, nil)
+	}
+	return utils.WriteJSON(w, convertTx(status))
+}
+
+func (p *Pool) handleGetStatus(w http.ResponseWriter, req *http.Request) error {
+	stats := p.pool.Stats()
+	return utils.WriteJSON(w, &Status{
+		Total:           stats.Total,
+		Executable:      stats.Executables,
+		Limit:           stats.Limit,
+		LimitPerAccount: stats.LimitPerAccount,
+	})
+}
+
+func (p *Pool) handleGetAccount(w http.ResponseWriter, req *http.Request) error {
+	addr, err := meter.ParseAddress(mux.Vars(req)["address"])
+	if err != nil {
+		return utils.BadRequest(errors.WithMessage(err, "address"))
+	}
+	stats := p.pool.AccountStats(addr)
+	return utils.WriteJSON(w, &Account{
+		Address:   stats.Origin,
+		Total:     stats.Total,
+		Limit:     stats.Limit,
+		Remaining: stats.Remaining,
+	})
+}
+
+func (p *Pool) Mount(root *mux.Router, pathPrefix string) {
+	sub := root.PathPrefix(pathPrefix).Subrouter()
+
+	sub.Path("/txs").Methods("GET").HandlerFunc(utils.WrapHandlerFunc(p.handleGetTxs))
+	sub.Path("/txs/{id}").Methods("GET").HandlerFunc(utils.WrapHandlerFunc(p.handleGetTxByID))
+	sub.Path("/status").Methods("GET").HandlerFunc(utils.WrapHandlerFunc(p.handleGetStatus))
+	sub.Path("/accounts/{address}").Methods("GET").HandlerFunc(utils.WrapHandlerFunc(p.handleGetAccount))
+}
@@ -0,0 +1,168 @@
+// Copyright (c) 2020 The Meter.io developers
+
+// Distributed under the GNU Lesser General Public License v3.0 software license, see the accompanying
+// file LICENSE or <https://www.gnu.org/licenses/lgpl-3.0.html>
+
+package pool_test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/gorilla/mux"
+	"github.com/meterio/meter-pov/api/pool"
+	"github.com/meterio/meter-pov/chain/testchain"
+	"github.com/meterio/meter-pov/genesis"
+	"github.com/meterio/meter-pov/lvldb"
+	"github.com/meterio/meter-pov/meter"
+	"github.com/meterio/meter-pov/tx"
+	"github.com/meterio/meter-pov/txpool"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	ts       *httptest.Server
+	pending  *tx.Transaction
+	queued   *tx.Transaction
+	acc0     = genesis.DevAccounts()[0]
+	acc1     = genesis.DevAccounts()[1]
+	toAddr   = meter.BytesToAddress([]byte("to"))
+	unknown  = meter.BytesToBytes32([]byte("unknown"))
+	limitAcc = 16
+)
+
+func TestPool(t *testing.T) {
+	txPool := initPoolServer(t)
+	defer ts.Close()
+	defer txPool.Close()
+
+	getTxs(t)
+	getTxByID(t)
+	getStatus(t)
+	getAccount(t)
+}
+
+func getTxs(t *testing.T) {
+	var txs []*pool.Tx
+	httpGetJSON(t, "/txpool/txs", http.StatusOK, &txs)
+	assert.Equal(t, 2, len(txs))
+
+	httpGetJSON(t, "/txpool/txs?status=pending", http.StatusOK, &txs)
+	assert.Equal(t, 1, len(txs))
+	assert.Equal(t, pending.ID(), txs[0].ID)
+	assert.True(t, txs[0].Executable)
+	assert.Empty(t, txs[0].Reason)
+
+	httpGetJSON(t, "/txpool/txs?status=queued", http.StatusOK, &txs)
+	assert.Equal(t, 1, len(txs))
+	assert.Equal(t, queued.ID(), txs[0].ID)
+	assert.False(t, txs[0].Executable)
+	assert.NotEmpty(t, txs[0].Reason)
+
+	httpGetJSON(t, "/txpool/txs?origin="+acc1.Address.String(), http.StatusOK, &txs)
+	assert.Equal(t, 1, len(txs))
+	assert.Equal(t, queued.ID(), txs[0].ID)
+	assert.Equal(t, acc1.Address, txs[0].Origin)
+
+	httpGetJSON(t, "/txpool/txs?origin="+acc1.Address.String()+"&status=pending", http.StatusOK, &txs)
+	assert.Empty(t, txs)
+
+	httpGet(t, "/txpool/txs?status=executable", http.StatusBadRequest)
+	httpGet(t, "/txpool/txs?origin=0x01", http.StatusBadRequest)
+}
+
+func getTxByID(t *testing.T) {
+	var got *pool.Tx
+	httpGetJSON(t, "/txpool/txs/"+pending.ID().String(), http.StatusOK, &got)
+	assert.Equal(t, pending.ID(), got.ID)
+	assert.Equal(t, acc0.Address, got.Origin)
+	assert.Equal(t, 1, len(got.Clauses))
+	assert.Equal(t, &toAddr, got.Clauses[0].To)
+
+	// null if not in the pool
+	got = nil
+	httpGetJSON(t, "/txpool/txs/"+unknown.String(), http.StatusOK, &got)
+	assert.Nil(t, got)
+
+	httpGet(t, "/txpool/txs/0x01", http.StatusBadRequest)
+}
+
+func getStatus(t *testing.T) {
+	// executables are only counted once the pool is washed
+	var status pool.Status
+	httpGetJSON(t, "/txpool/status", http.StatusOK, &status)
+	assert.Equal(t, pool.Status{Total: 2, Executable: 0, Limit: 100, LimitPerAccount: limitAcc}, status)
+}
+
+func getAccount(t *testing.T) {
+	var account pool.Account
+	httpGetJSON(t, "/txpool/accounts/"+acc0.Address.String(), http.StatusOK, &account)
+	assert.Equal(t, pool.Account{Address: acc0.Address, Total: 1, Limit: limitAcc, Remaining: limitAcc - 1}, account)
+
+	other := meter.BytesToAddress([]byte("other"))
+	httpGetJSON(t, "/txpool/accounts/"+other.String(), http.StatusOK, &account)
+	assert.Equal(t, pool.Account{Address: other, Total: 0, Limit: limitAcc, Remaining: limitAcc}, account)
+
+	httpGet(t, "/txpool/accounts/0x01", http.StatusBadRequest)
+}
+
+func newTx(chainTag byte, dependsOn *meter.Bytes32, from genesis.DevAccount) *tx.Transaction {
+	trx := new(tx.Builder).
+		ChainTag(chainTag).
+		Clause(tx.NewClause(&toAddr)).
+		Expiration(100).
+		Gas(21000).
+		DependsOn(dependsOn).
+		Nonce(1).
+		Build()
+	sig, _ := crypto.Sign(trx.SigningHash().Bytes(), from.PrivateKey)
+	return trx.WithSignature(sig)
+}
+
+func initPoolServer(t *testing.T) *txpool.TxPool {
+	kv, _ := lvldb.NewMem()
+	c, stateC, err := testchain.New(kv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	txPool := txpool.New(c, stateC, txpool.Options{Limit: 100, LimitPerAccount: limitAcc, MaxLifetime: time.Hour})
+
+	// queued as the tx it depends on is not found
+	pending = newTx(c.Tag(), nil, acc0)
+	queued = newTx(c.Tag(), &unknown, acc1)
+	for _, trx := range []*tx.Transaction{pending, queued} {
+		if err := txPool.Add(trx); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	router := mux.NewRouter()
+	pool.New(txPool).Mount(router, "/txpool")
+	ts = httptest.NewServer(router)
+	return txPool
+}
+
+func httpGet(t *testing.T, path string, status int) []byte {
+	res, err := http.Get(ts.URL + path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, status, res.StatusCode, path)
+	return r
+}
+
+func httpGetJSON(t *testing.T, path string, status int, v interface{}) {
+	if err := json.Unmarshal(httpGet(t, path, status), v); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,78 @@
+// Copyright (c) 2020 The Meter.io developers
+
+// Distributed under the GNU Lesser General Public License v3.0 software license, see the accompanying
+// file LICENSE or <https://www.gnu.org/licenses/lgpl-3.0.html>
+
+package pool
+
+import (
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/meterio/meter-pov/api/transactions"
+	"github.com/meterio/meter-pov/meter"
+	"github.com/meterio/meter-pov/txpool"
+)
+
+//Tx tx in the pool with its executable status
+type Tx struct {
+	ID           meter.Bytes32        `json:"id"`
+	ChainTag     byte                 `json:"chainTag"`
+	BlockRef     string               `json:"blockRef"`
+	Expiration   uint32               `json:"expiration"`
+	Clauses      transactions.Clauses `json:"clauses"`
+	GasPriceCoef uint8                `json:"gasPriceCoef"`
+	Gas          uint64               `json:"gas"`
+	Origin       meter.Address        `json:"origin"`
+	Nonce        math.HexOrDecimal64  `json:"nonce"`
+	DependsOn    *meter.Bytes32       `json:"dependsOn"`
+	Size         uint32               `json:"size"`
+	TimeAdded    int64                `json:"timeAdded"`
+	Executable   bool                 `json:"executable"`
+	Reason       string               `json:"reason,omitempty"`
+}
+
+func convertTx(status *txpool.TxStatus) *Tx {
+	tx := status.Tx
+	clauses := make(transactions.Clauses, 0, len(tx.Clauses()))
+	for _, c := range tx.Clauses() {
+		clauses = append(clauses, transactions.Clause{
+			To:    c.To(),
+			Value: math.HexOrDecimal256(*c.Value()),
+			Token: c.Token(),
+			Data:  hexutil.Encode(c.Data()),
+		})
+	}
+	br := tx.BlockRef()
+	return &Tx{
+		ID:           tx.ID(),
+		ChainTag:     tx.ChainTag(),
+		BlockRef:     hexutil.Encode(br[:]),
+		Expiration:   tx.Expiration(),
+		Clauses:      clauses,
+		GasPriceCoef: tx.GasPriceCoef(),
+		Gas:          tx.Gas(),
+		Origin:       status.Origin,
+		Nonce:        math.HexOrDecimal64(tx.Nonce()),
+		DependsOn:    tx.DependsOn(),
+		Size:         uint32(tx.Size()),
+		TimeAdded:    status.TimeAdded.Unix(),
+		Executable:   status.Executable,
+		Reason:       status.Reason,
+	}
+}
+
+//Status statistics of the pool
+type Status struct {
+	Total           int `json:"total"`
+	Executable      int `json:"executable"`
+	Limit           int `json:"limit"`
+	LimitPerAccount int `json:"limitPerAccount"`
+}
+
+//Account statistics of txs from an account
+type Account struct {
+	Address   meter.Address `json:"address"`
+	Total     int           `json:"total"`
+	Limit     int           `json:"limit"`
+	Remaining int           `json:"remaining"`
+}
@@ -0,0 +1,53 @@
+// Copyright (c) 2020 The Meter.io developers
+
+// Distributed under the GNU Lesser General Public License v3.0 software license, see the accompanying
+// file LICENSE or <https://www.gnu.org/licenses/lgpl-3.0.html>
+
+package script
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/meterio/meter-pov/api/utils"
+	"github.com/meterio/meter-pov/script"
+	"github.com/pkg/errors"
+)
+
+// Script encodes and decodes the clause data of script engine ops, it doesn't read the chain.
+type Script struct {
+}
+
+func New() *Script {
+	return &Script{}
+}
+
+func (s *Script) handleDecode(w http.ResponseWriter, req *http.Request) error {
+	var data ScriptData
+	if err := utils.ParseJSON(req.Body, &data); err != nil {
+		return utils.BadRequest(errors.WithMessage(err, "body"))
+	}
+	sd, err := script.DecodeScriptData(data.Data)
+	if err != nil {
+		return utils.BadRequest(errors.WithMessage(err, "data"))
+	}
+	return utils.WriteJSON(w, sd)
+}
+
+func (s *Script) handleEncode(w http.ResponseWriter, req *http.Request) error {
+	var sd script.ScriptData
+	if err := utils.ParseJSON(req.Body, &sd); err != nil {
+		return utils.BadRequest(errors.WithMessage(err, "body"))
+	}
+	data, err := script.EncodeScriptData(&sd)
+	if err != nil {
+		return utils.BadRequest(errors.WithMessage(err, "body"))
+	}
+	return utils.WriteJSON(w, &ScriptData{Data: data})
+}
+
+func (s *Script) Mount(root *mux.Router, pathPrefix string) {
+	sub := root.PathPrefix(pathPrefix).Subrouter()
+	sub.Path("/decode").Methods("POST").HandlerFunc(utils.WrapHandlerFunc(s.handleDecode))
+	sub.Path("/encode").Methods("POST").HandlerFunc(utils.WrapHandlerFunc(s.handleEncode))
+}
@@ -0,0 +1,136 @@
+// Copyright (c) 2020 The Meter.io developers
+
+// Distributed under the GNU Lesser General Public License v3.0 software license, see the accompanying
+// file LICENSE or <https://www.gnu.org/licenses/lgpl-3.0.html>
+
+package script_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/gorilla/mux"
+	apiscript "github.com/meterio/meter-pov/api/script"
+	"github.com/meterio/meter-pov/reward"
+	"github.com/meterio/meter-pov/script"
+	"github.com/meterio/meter-pov/script/accountlock"
+	"github.com/meterio/meter-pov/script/staking"
+	"github.com/stretchr/testify/assert"
+)
+
+var ts *httptest.Server
+
+func TestScript(t *testing.T) {
+	initScriptServer(t)
+	defer ts.Close()
+
+	decode(t)
+	encode(t)
+	badRequest(t)
+}
+
+func decode(t *testing.T) {
+	data := reward.BuildAccountLockGoverningTx(0, 0, 7).Clauses()[0].Data()
+	res, statusCode := httpPost(t, ts.URL+"/script/decode", &apiscript.ScriptData{Data: data})
+	assert.Equal(t, http.StatusOK, statusCode, string(res))
+
+	var sd script.ScriptData
+	if err := json.Unmarshal(res, &sd); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, script.ACCOUNTLOCK_MODULE_NAME, sd.Module)
+	assert.Equal(t, script.ACCOUNTLOCK_MODULE_ID, sd.ModuleID)
+	assert.Equal(t, accountlock.OP_GOVERNING, sd.Opcode)
+	assert.Equal(t, "governing", sd.Op)
+	assert.Equal(t, json.RawMessage("7"), sd.Body["version"])
+
+	// the data without the clause prefix decodes the same
+	res, statusCode = httpPost(t, ts.URL+"/script/decode", &apiscript.ScriptData{Data: data[4:]})
+	assert.Equal(t, http.StatusOK, statusCode, string(res))
+	var sd2 script.ScriptData
+	if err := json.Unmarshal(res, &sd2); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, sd, sd2)
+
+	// and encodes back to the clause data
+	res, statusCode = httpPost(t, ts.URL+"/script/encode", &sd)
+	assert.Equal(t, http.StatusOK, statusCode, string(res))
+	var encoded apiscript.ScriptData
+	if err := json.Unmarshal(res, &encoded); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, hexutil.Bytes(data), encoded.Data)
+}
+
+func encode(t *testing.T) {
+	res, statusCode := httpPost(t, ts.URL+"/script/encode", map[string]interface{}{
+		"module": "staking",
+		"op":     "bound",
+		"body": map[string]interface{}{
+			"amount": "1000",
+			"nonce":  3,
+		},
+	})
+	assert.Equal(t, http.StatusOK, statusCode, string(res))
+	var encoded apiscript.ScriptData
+	if err := json.Unmarshal(res, &encoded); err != nil {
+		t.Fatal(err)
+	}
+
+	sd, err := script.DecodeScriptData(encoded.Data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, script.STAKING_MODULE_ID, sd.ModuleID)
+	assert.Equal(t, staking.OP_BOUND, sd.Opcode)
+	assert.Equal(t, json.RawMessage(`"1000"`), sd.Body["amount"])
+	assert.Equal(t, json.RawMessage("3"), sd.Body["nonce"])
+}
+
+func badRequest(t *testing.T) {
+	cases := []struct {
+		path string
+		body interface{}
+	}{
+		{"/script/decode", "not an object"},
+		{"/script/decode", &apiscript.ScriptData{Data: []byte{0xff, 0xff, 0xff, 0xff, 0x01}}},
+		{"/script/encode", "not an object"},
+		{"/script/encode", &script.ScriptData{Module: "nope", Op: "bound"}},
+		{"/script/encode", &script.ScriptData{Module: "staking", Op: "nope"}},
+		{"/script/encode", &script.ScriptData{Module: "staking", Op: "bound", Body: map[string]json.RawMessage{"nope": json.RawMessage("1")}}},
+		{"/script/encode", &script.ScriptData{Module: "staking", Op: "bound", Body: map[string]json.RawMessage{"amount": json.RawMessage(`"-1"`)}}},
+	}
+	for _, c := range cases {
+		res, statusCode := httpPost(t, ts.URL+c.path, c.body)
+		assert.Equal(t, http.StatusBadRequest, statusCode, "%v %v: %s", c.path, c.body, res)
+	}
+}
+
+func initScriptServer(t *testing.T) {
+	router := mux.NewRouter()
+	apiscript.New().Mount(router, "/script")
+	ts = httptest.NewServer(router)
+}
+
+func httpPost(t *testing.T, url string, body interface{}) ([]byte, int) {
+	data, err := json.Marshal(body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := http.Post(url, "application/x-www-form-urlencoded", bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return r, res.StatusCode
+}
@@ -0,0 +1,15 @@
+// Copyright (c) 2020 The Meter.io developers
+
+// Distributed under the GNU Lesser General Public License v3.0 software license, see the accompanying
+// file LICENSE or <https://www.gnu.org/licenses/lgpl-3.0.html>
+
+package script
+
+import (
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// ScriptData clause data of a script, with or without the clause prefix 0xffffffff
+type ScriptData struct {
+	Data hexutil.Bytes `json:"data"`
+}
@@ -10,34 +10,49 @@ import (
 
 	"github.com/gorilla/mux"
 	"github.com/meterio/meter-pov/api/utils"
+	"github.com/meterio/meter-pov/chain"
 	"github.com/meterio/meter-pov/script/staking"
+	"github.com/meterio/meter-pov/state"
 )
 
 type Slashing struct {
+	chain        *chain.Chain
+	stateCreator *state.Creator
 }
 
-func New() *Slashing {
-	return &Slashing{}
+func New(chain *chain.Chain,
+	stateCreator *state.Creator) *Slashing {
+	return &Slashing{chain: chain, stateCreator: stateCreator}
 }
 
 func (sl *Slashing) handleGetDelegateJailedList(w http.ResponseWriter, req *http.Request) error {
-	list, err := staking.GetLatestInJailList()
+	st, err := sl.handleState(req.URL.Query().Get("revision"))
 	if err != nil {
 		return err
 	}
+	list := staking.GetInJailListByState(st)
 	jailedList := convertJailedList(list)
 	return utils.WriteJSON(w, jailedList)
 }
 
 func (sl *Slashing) handleGetDelegateStatsList(w http.ResponseWriter, req *http.Request) error {
-	list, err := staking.GetLatestStatisticsList()
+	st, err := sl.handleState(req.URL.Query().Get("revision"))
 	if err != nil {
 		return err
 	}
+	list := staking.GetStatisticsListByState(st)
 	statsList := convertStatisticsList(list)
 	return utils.WriteJSON(w, statsList)
 }
 
+func (sl *Slashing) handleState(revision string) (*state.State, error) {
+	h, err := utils.HandleRevision(sl.chain, revision)
+	if err != nil {
+		return nil, err
+	}
+	return sl.stateCreator.NewState(h.StateRoot())
+}
+
 func (sl *Slashing) Mount(root *mux.Router, pathPrefix string) {
 	sub := root.PathPrefix(pathPrefix).Subrouter()
 	sub.Path("/injail").Methods("Get").HandlerFunc(utils.WrapHandlerFunc(sl.handleGetDelegateJailedList))
@@ -7,18 +7,14 @@ package staking
 
 import (
 	"encoding/hex"
-	"math"
 	"net/http"
-	"strconv"
 
 	"github.com/gorilla/mux"
 	"github.com/meterio/meter-pov/api/utils"
-	"github.com/meterio/meter-pov/block"
 	"github.com/meterio/meter-pov/chain"
 	"github.com/meterio/meter-pov/meter"
 	"github.com/meterio/meter-pov/script/staking"
 	"github.com/meterio/meter-pov/state"
-	"github.com/pkg/errors"
 )
 
 type Staking struct {
@@ -32,7 +28,7 @@ func New(chain *chain.Chain,
 }
 
 func (st *Staking) handleGetCandidateList(w http.ResponseWriter, req *http.Request) error {
-	h, err := st.handleRevision(req.URL.Query().Get("revision"))
+	h, err := utils.HandleRevision(st.chain, req.URL.Query().Get("revision"))
 	if err != nil {
 		return err
 	}
@@ -45,7 +41,11 @@ func (st *Staking) handleGetCandidateList(w http.ResponseWriter, req *http.Reque
 }
 
 func (st *Staking) handleGetCandidateByAddress(w http.ResponseWriter, req *http.Request) error {
-	list, err := staking.GetLatestCandidateList()
+	h, err := utils.HandleRevision(st.chain, req.URL.Query().Get("revision"))
+	if err != nil {
+		return err
+	}
+	list, err := staking.GetCandidateListByHeader(h)
 	if err != nil {
 		return err
 	}
@@ -56,12 +56,15 @@ func (st *Staking) handleGetCandidateByAddress(w http.ResponseWriter, req *http.
 	}
 	meterAddr := meter.BytesToAddress(bytes)
 	c := list.Get(meterAddr)
+	if c == nil {
+		return utils.WriteJSON(w, nil)
+	}
 	candidate := convertCandidate(*c)
 	return utils.WriteJSON(w, candidate)
 }
 
 func (st *Staking) handleGetBucketList(w http.ResponseWriter, req *http.Request) error {
-	h, err := st.handleRevision(req.URL.Query().Get("revision"))
+	h, err := utils.HandleRevision(st.chain, req.URL.Query().Get("revision"))
 	if err != nil {
 		return err
 	}
@@ -75,19 +78,29 @@ func (st *Staking) handleGetBucketList(w http.ResponseWriter, req *http.Request)
 }
 
 func (st *Staking) handleGetBucketByID(w http.ResponseWriter, req *http.Request) error {
-	list, err := staking.GetLatestBucketList()
+	h, err := utils.HandleRevision(st.chain, req.URL.Query().Get("revision"))
+	if err != nil {
+		return err
+	}
+	list, err := staking.GetBucketListByHeader(h)
+	if err != nil {
+		return err
+	}
 	id := mux.Vars(req)["id"]
 	bucketID, err := meter.ParseBytes32(id)
 	if err != nil {
 		return err
 	}
 	bucket := list.Get(bucketID)
+	if bucket == nil {
+		return utils.WriteJSON(w, nil)
+	}
 	converted := convertBucket(bucket)
 	return utils.WriteJSON(w, converted)
 }
 
 func (st *Staking) handleGetStakeholderList(w http.ResponseWriter, req *http.Request) error {
-	h, err := st.handleRevision(req.URL.Query().Get("revision"))
+	h, err := utils.HandleRevision(st.chain, req.URL.Query().Get("revision"))
 	if err != nil {
 		return err
 	}
@@ -101,7 +114,14 @@ func (st *Staking) handleGetStakeholderList(w http.ResponseWriter, req *http.Req
 }
 
 func (st *Staking) handleGetStakeholderByAddress(w http.ResponseWriter, req *http.Request) error {
-	list, err := staking.GetLatestStakeholderList()
+	h, err := utils.HandleRevision(st.chain, req.URL.Query().Get("revision"))
+	if err != nil {
+		return err
+	}
+	list, err := staking.GetStakeholderListByHeader(h)
+	if err != nil {
+		return err
+	}
 	addr := mux.Vars(req)["address"]
 	bytes, err := hex.DecodeString(addr)
 	if err != nil {
@@ -110,12 +130,19 @@ func (st *Staking) handleGetStakeholderByAddress(w http.ResponseWriter, req *htt
 	meterAddr := meter.BytesToAddress(bytes)
 
 	s := list.Get(meterAddr)
+	if s == nil {
+		return utils.WriteJSON(w, nil)
+	}
 	stakeholder := convertStakeholder(*s)
 	return utils.WriteJSON(w, stakeholder)
 }
 
 func (st *Staking) handleGetDelegateList(w http.ResponseWriter, req *http.Request) error {
-	list, err := staking.GetLatestDelegateList()
+	h, err := utils.HandleRevision(st.chain, req.URL.Query().Get("revision"))
+	if err != nil {
+		return err
+	}
+	list, err := staking.GetDelegateListByHeader(h)
 	if err != nil {
 		return err
 	}
@@ -124,7 +151,7 @@ func (st *Staking) handleGetDelegateList(w http.ResponseWriter, req *http.Reques
 }
 
 func (st *Staking) handleGetLastValidatorReward(w http.ResponseWriter, req *http.Request) error {
-	h, err := st.handleRevision(req.URL.Query().Get("revision"))
+	h, err := utils.HandleRevision(st.chain, req.URL.Query().Get("revision"))
 	if err != nil {
 		return err
 	}
@@ -141,7 +168,7 @@ func (st *Staking) handleGetLastValidatorReward(w http.ResponseWriter, req *http
 }
 
 func (st *Staking) handleGetValidatorRewardList(w http.ResponseWriter, req *http.Request) error {
-	h, err := st.handleRevision(req.URL.Query().Get("revision"))
+	h, err := utils.HandleRevision(st.chain, req.URL.Query().Get("revision"))
 	if err != nil {
 		return err
 	}
@@ -153,41 +180,6 @@ func (st *Staking) handleGetValidatorRewardList(w http.ResponseWriter, req *http
 	return utils.WriteJSON(w, validatorRewardList)
 }
 
-func (st *Staking) handleRevision(revision string) (*block.Header, error) {
-	if revision == "" || revision == "best" {
-		return st.chain.BestBlock().Header(), nil
-	}
-	if len(revision) == 66 || len(revision) == 64 {
-		blockID, err := meter.ParseBytes32(revision)
-		if err != nil {
-			return nil, utils.BadRequest(errors.WithMessage(err, "revision"))
-		}
-		h, err := st.chain.GetBlockHeader(blockID)
-		if err != nil {
-			if st.chain.IsNotFound(err) {
-				return nil, utils.BadRequest(errors.WithMessage(err, "revision"))
-			}
-			return nil, err
-		}
-		return h, nil
-	}
-	n, err := strconv.ParseUint(revision, 0, 0)
-	if err != nil {
-		return nil, utils.BadRequest(errors.WithMessage(err, "revision"))
-	}
-	if n > math.MaxUint32 {
-		return nil, utils.BadRequest(errors.WithMessage(errors.New("block number out of max uint32"), "revision"))
-	}
-	h, err := st.chain.GetTrunkBlockHeader(uint32(n))
-	if err != nil {
-		if st.chain.IsNotFound(err) {
-			return nil, utils.BadRequest(errors.WithMessage(err, "revision"))
-		}
-		return nil, err
-	}
-	return h, nil
-}
-
 func (st *Staking) Mount(root *mux.Router, pathPrefix string) {
 	sub := root.PathPrefix(pathPrefix).Subrouter()
 	sub.Path("/candidates").Methods("Get").HandlerFunc(utils.WrapHandlerFunc(st.handleGetCandidateList))
@@ -17,22 +17,21 @@ import (
 
 type beatReader struct {
 	chain       *chain.Chain
-	blockReader chain.BlockReader
+	chainReader *chainReader
 }
 
-func newBeatReader(chain *chain.Chain, position meter.Bytes32) *beatReader {
+func newBeatReader(chain *chain.Chain, chainReader *chainReader) *beatReader {
 	return &beatReader{
 		chain:       chain,
-		blockReader: chain.NewBlockReader(position),
+		chainReader: chainReader,
 	}
 }
 
 func (br *beatReader) Read() ([]interface{}, bool, error) {
-	blocks, err := br.blockReader.Read()
+	blocks, msgs, err := br.chainReader.Read()
 	if err != nil {
 		return nil, false, err
 	}
-	var msgs []interface{}
 	for _, block := range blocks {
 		header := block.Header()
 		receipts, err := br.chain.GetBlockReceipts(header.ID())
@@ -7,27 +7,25 @@ package subscriptions
 
 import (
 	"github.com/meterio/meter-pov/chain"
-	"github.com/meterio/meter-pov/meter"
 )
 
 type blockReader struct {
 	chain       *chain.Chain
-	blockReader chain.BlockReader
+	chainReader *chainReader
 }
 
-func newBlockReader(chain *chain.Chain, position meter.Bytes32) *blockReader {
+func newBlockReader(chain *chain.Chain, chainReader *chainReader) *blockReader {
 	return &blockReader{
 		chain:       chain,
-		blockReader: chain.NewBlockReader(position),
+		chainReader: chainReader,
 	}
 }
 
 func (br *blockReader) Read() ([]interface{}, bool, error) {
-	blocks, err := br.blockReader.Read()
+	blocks, msgs, err := br.chainReader.Read()
 	if err != nil {
 		return nil, false, err
 	}
-	var msgs []interface{}
 	for _, block := range blocks {
 		msg, err := convertBlock(block)
 		if err != nil {
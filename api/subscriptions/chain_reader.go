@@ -0,0 +1,41 @@
+// Copyright (c) 2020 The Meter.io developers
+
+// Distributed under the GNU Lesser General Public License v3.0 software license, see the accompanying
+// file LICENSE or <https://www.gnu.org/licenses/lgpl-3.0.html>
+
+package subscriptions
+
+import (
+	"github.com/meterio/meter-pov/chain"
+	"github.com/meterio/meter-pov/meter"
+)
+
+// chainReader reads blocks of the chain for the readers of block related subjects.
+type chainReader struct {
+	blockReader chain.BlockReader
+	revert      bool // whether to pipe revert messages
+}
+
+// newChainReader creates a chain reader from the position. Trunk blocks are only read up to
+// the best block, which is the last block committed by a QC.
+func newChainReader(chain *chain.Chain, position meter.Bytes32, revert bool) *chainReader {
+	return &chainReader{
+		blockReader: chain.NewBlockReader(position),
+		revert:      revert,
+	}
+}
+
+// Read reads the next blocks, along with the revert message of the obsolete blocks.
+func (cr *chainReader) Read() ([]*chain.Block, []interface{}, error) {
+	blocks, err := cr.blockReader.Read()
+	if err != nil {
+		return nil, nil, err
+	}
+	var msgs []interface{}
+	if cr.revert {
+		if msg := convertRevert(blocks); msg != nil {
+			msgs = append(msgs, msg)
+		}
+	}
+	return blocks, msgs, nil
+}
@@ -0,0 +1,111 @@
+// Copyright (c) 2020 The Meter.io developers
+
+// Distributed under the GNU Lesser General Public License v3.0 software license, see the accompanying
+// file LICENSE or <https://www.gnu.org/licenses/lgpl-3.0.html>
+
+package subscriptions
+
+import (
+	"errors"
+
+	"github.com/meterio/meter-pov/consensus"
+)
+
+// consensusEventBufferSize events buffered for a slow subscriber before dropping.
+const consensusEventBufferSize = 1000
+
+var errConsensusEventsDropped = errors.New("consensus events dropped, subscriber is too slow")
+
+// consensusReader receives events from the consensus reactor.
+// Like the tx reader, it's driven by events pushed by the reactor, so that consensus is never
+// blocked by a slow subscriber. Once an event exceeds the buffer, the reader fails so that the
+// subscriber is closed instead of silently missing events.
+type consensusReader struct {
+	types   map[string]bool // event types to read, all if empty
+	events  chan *consensus.Event
+	notify  chan bool
+	dropped chan struct{} // closed once an event is dropped
+	done    chan struct{}
+	unsub   func()
+}
+
+func newConsensusReader(conR *consensus.ConsensusReactor, types map[string]bool) *consensusReader {
+	ch := make(chan *consensus.Event)
+	sub := conR.SubscribeEvent(ch)
+
+	cr := &consensusReader{
+		types:   types,
+		events:  make(chan *consensus.Event, consensusEventBufferSize),
+		notify:  make(chan bool, 1),
+		dropped: make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+	cr.unsub = func() {
+		sub.Unsubscribe()
+		close(cr.done)
+	}
+	go func() {
+		for {
+			select {
+			case <-cr.done:
+				return
+			case <-sub.Err():
+				return
+			case ev := <-ch:
+				cr.receive(ev)
+			}
+		}
+	}()
+	return cr
+}
+
+// receive buffers the event if it's of the types.
+func (cr *consensusReader) receive(ev *consensus.Event) {
+	select {
+	case <-cr.dropped:
+		// keep draining the reactor until closed
+		return
+	default:
+	}
+	if len(cr.types) > 0 && !cr.types[ev.Type] {
+		return
+	}
+	select {
+	case cr.events <- ev:
+	default:
+		log.Debug("consensus event dropped", "type", ev.Type, "height", ev.Height, "round", ev.Round)
+		close(cr.dropped)
+	}
+	select {
+	case cr.notify <- true:
+	default:
+	}
+}
+
+// Read returns buffered events without blocking, or errConsensusEventsDropped if events were dropped.
+func (cr *consensusReader) Read() ([]interface{}, bool, error) {
+	select {
+	case <-cr.dropped:
+		return nil, false, errConsensusEventsDropped
+	default:
+	}
+	var msgs []interface{}
+	for {
+		select {
+		case ev := <-cr.events:
+			msgs = append(msgs, convertConsensusEvent(ev))
+		default:
+			return msgs, false, nil
+		}
+	}
+}
+
+// C implements co.Waiter, signaled when new events arrive.
+func (cr *consensusReader) C() <-chan bool {
+	return cr.notify
+}
+
+// Close stops receiving events from the reactor.
+func (cr *consensusReader) Close() {
+	cr.unsub()
+}
@@ -0,0 +1,141 @@
+// Copyright (c) 2020 The Meter.io developers
+
+// Distributed under the GNU Lesser General Public License v3.0 software license, see the accompanying
+// file LICENSE or <https://www.gnu.org/licenses/lgpl-3.0.html>
+
+package subscriptions
+
+import (
+	b64 "encoding/base64"
+	"math/big"
+	"net"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/meterio/meter-pov/block"
+	"github.com/meterio/meter-pov/consensus"
+	"github.com/meterio/meter-pov/meter"
+	"github.com/meterio/meter-pov/script/staking"
+	"github.com/meterio/meter-pov/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConvertConsensusEvent(t *testing.T) {
+	msg := convertConsensusEvent(&consensus.Event{Type: consensus.EventTimeout, Height: 10, Round: 3, Epoch: 2, Counter: 5})
+	assert.Equal(t, &ConsensusMessage{Type: "timeout", Height: 10, Round: 3, Epoch: 2, Counter: 5}, msg)
+
+	msg = convertConsensusEvent(&consensus.Event{
+		Type:   consensus.EventQC,
+		Height: 10,
+		Round:  3,
+		QC:     &block.QuorumCert{QCHeight: 9, QCRound: 2, EpochID: 2, VoterBitArrayStr: "x_x"},
+	})
+	assert.Equal(t, &QC{QCHeight: 9, QCRound: 2, EpochID: 2, VoterBitArrayStr: "x_x"}, msg.QC)
+	assert.Nil(t, msg.Members)
+
+	key, _ := crypto.GenerateKey()
+	addr := meter.BytesToAddress([]byte("delegate"))
+	msg = convertConsensusEvent(&consensus.Event{
+		Type:  consensus.EventEpoch,
+		Epoch: 3,
+		Nonce: 1234,
+		Committee: []*types.Validator{{
+			Name:        "delegate",
+			Address:     addr,
+			PubKey:      key.PublicKey,
+			VotingPower: 100,
+			NetAddr:     types.NetAddress{IP: net.ParseIP("10.0.0.1"), Port: 8670},
+		}},
+	})
+	assert.Equal(t, uint64(1234), msg.Nonce)
+	assert.Equal(t, []*CommitteeMember{{
+		Name:        "delegate",
+		Address:     addr,
+		PubKey:      b64.StdEncoding.EncodeToString(crypto.FromECDSAPub(&key.PublicKey)),
+		VotingPower: 100,
+		NetAddr:     "10.0.0.1:8670",
+	}}, msg.Members)
+
+	msg = convertConsensusEvent(&consensus.Event{
+		Type:       consensus.EventStatistics,
+		Statistics: []staking.DelegateStatistics{{Addr: addr, Name: []byte("delegate"), TotalPts: 20}},
+		InJail:     []staking.DelegateJailed{{Addr: addr, Name: []byte("delegate"), TotalPts: 40, BailAmount: big.NewInt(100), JailedTime: 1000}},
+	})
+	assert.Equal(t, []*DelegateStatistics{{Address: addr, Name: "delegate", TotalPoints: 20}}, msg.Statistics)
+	assert.Equal(t, []*DelegateJailed{{Address: addr, Name: "delegate", TotalPoints: 40, BailAmount: (*math.HexOrDecimal256)(big.NewInt(100)), JailedTime: 1000}}, msg.InJail)
+}
+
+func TestParseConsensusTypes(t *testing.T) {
+	types, err := parseConsensusTypes("")
+	assert.Nil(t, err)
+	assert.Empty(t, types)
+
+	types, err = parseConsensusTypes("qc,epoch")
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]bool{"qc": true, "epoch": true}, types)
+
+	_, err = parseConsensusTypes("qc,block")
+	assert.NotNil(t, err)
+}
+
+func newTestConsensusReader(types map[string]bool, size int) *consensusReader {
+	return &consensusReader{
+		types:   types,
+		events:  make(chan *consensus.Event, size),
+		notify:  make(chan bool, 1),
+		dropped: make(chan struct{}),
+	}
+}
+
+func TestConsensusReaderTypes(t *testing.T) {
+	events := []*consensus.Event{
+		{Type: consensus.EventQC, Height: 1},
+		{Type: consensus.EventRound, Height: 1, Round: 1},
+		{Type: consensus.EventEpoch, Epoch: 1},
+		{Type: consensus.EventQC, Height: 2},
+	}
+
+	// all types if empty
+	cr := newTestConsensusReader(map[string]bool{}, 10)
+	for _, ev := range events {
+		cr.receive(ev)
+	}
+	msgs, _, err := cr.Read()
+	assert.Nil(t, err)
+	assert.Equal(t, 4, len(msgs))
+
+	cr = newTestConsensusReader(map[string]bool{"qc": true, "epoch": true}, 10)
+	for _, ev := range events {
+		cr.receive(ev)
+	}
+	<-cr.C()
+	msgs, _, err = cr.Read()
+	assert.Nil(t, err)
+	assert.Equal(t, 3, len(msgs))
+	for _, msg := range msgs {
+		assert.NotEqual(t, "round", msg.(*ConsensusMessage).Type)
+	}
+
+	msgs, _, err = cr.Read()
+	assert.Nil(t, err)
+	assert.Empty(t, msgs)
+}
+
+func TestConsensusReaderDropped(t *testing.T) {
+	cr := newTestConsensusReader(map[string]bool{"qc": true}, 1)
+
+	// filtered events don't take the buffer
+	cr.receive(&consensus.Event{Type: consensus.EventRound})
+	cr.receive(&consensus.Event{Type: consensus.EventQC})
+	msgs, _, err := cr.Read()
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(msgs))
+
+	// the subscriber is closed once an event is dropped
+	cr.receive(&consensus.Event{Type: consensus.EventQC})
+	cr.receive(&consensus.Event{Type: consensus.EventQC})
+	cr.receive(&consensus.Event{Type: consensus.EventQC})
+	_, _, err = cr.Read()
+	assert.Equal(t, errConsensusEventsDropped, err)
+}
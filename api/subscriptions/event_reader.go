@@ -7,29 +7,27 @@ package subscriptions
 
 import (
 	"github.com/meterio/meter-pov/chain"
-	"github.com/meterio/meter-pov/meter"
 )
 
 type eventReader struct {
 	chain       *chain.Chain
 	filter      *EventFilter
-	blockReader chain.BlockReader
+	chainReader *chainReader
 }
 
-func newEventReader(chain *chain.Chain, position meter.Bytes32, filter *EventFilter) *eventReader {
+func newEventReader(chain *chain.Chain, chainReader *chainReader, filter *EventFilter) *eventReader {
 	return &eventReader{
 		chain:       chain,
 		filter:      filter,
-		blockReader: chain.NewBlockReader(position),
+		chainReader: chainReader,
 	}
 }
 
 func (er *eventReader) Read() ([]interface{}, bool, error) {
-	blocks, err := er.blockReader.Read()
+	blocks, msgs, err := er.chainReader.Read()
 	if err != nil {
 		return nil, false, err
 	}
-	var msgs []interface{}
 	for _, block := range blocks {
 		receipts, err := er.chain.GetBlockReceipts(block.Header().ID())
 		if err != nil {
@@ -7,12 +7,16 @@ package subscriptions
 
 import (
 	"net/http"
+	"strings"
 	"sync"
 
 	"github.com/meterio/meter-pov/api/utils"
 	"github.com/meterio/meter-pov/block"
 	"github.com/meterio/meter-pov/chain"
+	"github.com/meterio/meter-pov/co"
+	"github.com/meterio/meter-pov/consensus"
 	"github.com/meterio/meter-pov/meter"
+	"github.com/meterio/meter-pov/txpool"
 	"github.com/gorilla/mux"
 	"github.com/gorilla/websocket"
 	"github.com/inconshreveable/log15"
@@ -22,6 +26,7 @@ import (
 type Subscriptions struct {
 	backtraceLimit uint32
 	chain          *chain.Chain
+	txPool         *txpool.TxPool
 	upgrader       *websocket.Upgrader
 	done           chan struct{}
 	wg             sync.WaitGroup
@@ -35,10 +40,11 @@ var (
 	log = log15.New("pkg", "subscriptions")
 )
 
-func New(chain *chain.Chain, allowedOrigins []string, backtraceLimit uint32) *Subscriptions {
+func New(chain *chain.Chain, txPool *txpool.TxPool, allowedOrigins []string, backtraceLimit uint32) *Subscriptions {
 	return &Subscriptions{
 		backtraceLimit: backtraceLimit,
 		chain:          chain,
+		txPool:         txPool,
 		upgrader: &websocket.Upgrader{
 			CheckOrigin: func(r *http.Request) bool {
 				origin := r.Header.Get("Origin")
@@ -58,15 +64,15 @@ func New(chain *chain.Chain, allowedOrigins []string, backtraceLimit uint32) *Su
 }
 
 func (s *Subscriptions) handleBlockReader(w http.ResponseWriter, req *http.Request) (*blockReader, error) {
-	position, err := s.parsePosition(req.URL.Query().Get("pos"))
+	chainReader, err := s.parseChainReader(req)
 	if err != nil {
 		return nil, err
 	}
-	return newBlockReader(s.chain, position), nil
+	return newBlockReader(s.chain, chainReader), nil
 }
 
 func (s *Subscriptions) handleEventReader(w http.ResponseWriter, req *http.Request) (*eventReader, error) {
-	position, err := s.parsePosition(req.URL.Query().Get("pos"))
+	chainReader, err := s.parseChainReader(req)
 	if err != nil {
 		return nil, err
 	}
@@ -102,11 +108,11 @@ func (s *Subscriptions) handleEventReader(w http.ResponseWriter, req *http.Reque
 		Topic3:  t3,
 		Topic4:  t4,
 	}
-	return newEventReader(s.chain, position, eventFilter), nil
+	return newEventReader(s.chain, chainReader, eventFilter), nil
 }
 
 func (s *Subscriptions) handleTransferReader(w http.ResponseWriter, req *http.Request) (*transferReader, error) {
-	position, err := s.parsePosition(req.URL.Query().Get("pos"))
+	chainReader, err := s.parseChainReader(req)
 	if err != nil {
 		return nil, err
 	}
@@ -127,15 +133,52 @@ func (s *Subscriptions) handleTransferReader(w http.ResponseWriter, req *http.Re
 		Sender:    sender,
 		Recipient: recipient,
 	}
-	return newTransferReader(s.chain, position, transferFilter), nil
+	return newTransferReader(s.chain, chainReader, transferFilter), nil
 }
 
 func (s *Subscriptions) handleBeatReader(w http.ResponseWriter, req *http.Request) (*beatReader, error) {
-	position, err := s.parsePosition(req.URL.Query().Get("pos"))
+	chainReader, err := s.parseChainReader(req)
 	if err != nil {
 		return nil, err
 	}
-	return newBeatReader(s.chain, position), nil
+	return newBeatReader(s.chain, chainReader), nil
+}
+
+func (s *Subscriptions) handleTxReader(w http.ResponseWriter, req *http.Request) (*txReader, error) {
+	origin, err := parseAddress(req.URL.Query().Get("origin"))
+	if err != nil {
+		return nil, utils.BadRequest(errors.WithMessage(err, "origin"))
+	}
+	return newTxReader(s.txPool, origin), nil
+}
+
+func (s *Subscriptions) handleConsensusReader(w http.ResponseWriter, req *http.Request) (*consensusReader, error) {
+	conR := consensus.GetConsensusGlobInst()
+	if conR == nil {
+		return nil, utils.HTTPError(errors.New("consensus is not initialized"), http.StatusServiceUnavailable)
+	}
+	types, err := parseConsensusTypes(req.URL.Query().Get("types"))
+	if err != nil {
+		return nil, utils.BadRequest(errors.WithMessage(err, "types"))
+	}
+	return newConsensusReader(conR, types), nil
+}
+
+// parseConsensusTypes parses the comma separated event types, empty for all types.
+func parseConsensusTypes(typesStr string) (map[string]bool, error) {
+	types := make(map[string]bool)
+	if typesStr == "" {
+		return types, nil
+	}
+	for _, t := range strings.Split(typesStr, ",") {
+		switch t {
+		case consensus.EventQC, consensus.EventRound, consensus.EventTimeout, consensus.EventEpoch, consensus.EventStatistics:
+			types[t] = true
+		default:
+			return nil, errors.New("unknown type " + t)
+		}
+	}
+	return types, nil
 }
 
 func (s *Subscriptions) handleSubject(w http.ResponseWriter, req *http.Request) error {
@@ -144,6 +187,7 @@ func (s *Subscriptions) handleSubject(w http.ResponseWriter, req *http.Request)
 
 	var (
 		reader msgReader
+		waiter co.Waiter
 		err    error
 	)
 	switch mux.Vars(req)["subject"] {
@@ -163,11 +207,29 @@ func (s *Subscriptions) handleSubject(w http.ResponseWriter, req *http.Request)
 		if reader, err = s.handleBeatReader(w, req); err != nil {
 			return err
 		}
+	case "txpool":
+		tr, err := s.handleTxReader(w, req)
+		if err != nil {
+			return err
+		}
+		defer tr.Close()
+		reader, waiter = tr, tr
+	case "consensus":
+		cr, err := s.handleConsensusReader(w, req)
+		if err != nil {
+			return err
+		}
+		defer cr.Close()
+		reader, waiter = cr, cr
 
 	default:
 		return utils.HTTPError(errors.New("not found"), http.StatusNotFound)
 	}
 
+	if waiter == nil {
+		waiter = s.chain.NewTicker()
+	}
+
 	conn, err := s.upgrader.Upgrade(w, req, nil)
 	// since the conn is hijacked here, no error should be returned in lines below
 	if err != nil {
@@ -182,7 +244,7 @@ func (s *Subscriptions) handleSubject(w http.ResponseWriter, req *http.Request)
 	}()
 
 	var closeMsg []byte
-	if err := s.pipe(conn, reader); err != nil {
+	if err := s.pipe(conn, reader, waiter); err != nil {
 		closeMsg = websocket.FormatCloseMessage(websocket.CloseInternalServerErr, err.Error())
 	} else {
 		closeMsg = websocket.FormatCloseMessage(websocket.CloseGoingAway, "")
@@ -194,7 +256,8 @@ func (s *Subscriptions) handleSubject(w http.ResponseWriter, req *http.Request)
 	return nil
 }
 
-func (s *Subscriptions) pipe(conn *websocket.Conn, reader msgReader) error {
+// pipe writes msgs from the reader to the conn, and waits for the waiter when no more msgs.
+func (s *Subscriptions) pipe(conn *websocket.Conn, reader msgReader, waiter co.Waiter) error {
 	closed := make(chan struct{})
 	// start read loop to handle close event
 	s.wg.Add(1)
@@ -208,7 +271,6 @@ func (s *Subscriptions) pipe(conn *websocket.Conn, reader msgReader) error {
 			}
 		}
 	}()
-	ticker := s.chain.NewTicker()
 	for {
 		msgs, hasMore, err := reader.Read()
 		if err != nil {
@@ -225,7 +287,7 @@ func (s *Subscriptions) pipe(conn *websocket.Conn, reader msgReader) error {
 				return nil
 			case <-closed:
 				return nil
-			case <-ticker.C():
+			case <-waiter.C():
 			}
 		} else {
 			select {
@@ -239,6 +301,23 @@ func (s *Subscriptions) pipe(conn *websocket.Conn, reader msgReader) error {
 	}
 }
 
+// parseChainReader creates the chain reader from the pos and revert query params. The finalized
+// param is still accepted for compatibility, but has no effect, as blocks are only read once committed.
+func (s *Subscriptions) parseChainReader(req *http.Request) (*chainReader, error) {
+	position, err := s.parsePosition(req.URL.Query().Get("pos"))
+	if err != nil {
+		return nil, err
+	}
+	if _, err := parseBool(req.URL.Query().Get("finalized")); err != nil {
+		return nil, utils.BadRequest(errors.WithMessage(err, "finalized"))
+	}
+	revert, err := parseBool(req.URL.Query().Get("revert"))
+	if err != nil {
+		return nil, utils.BadRequest(errors.WithMessage(err, "revert"))
+	}
+	return newChainReader(s.chain, position, revert), nil
+}
+
 func (s *Subscriptions) parsePosition(posStr string) (meter.Bytes32, error) {
 	bestID := s.chain.BestBlock().Header().ID()
 	if posStr == "" {
@@ -265,6 +344,17 @@ func parseTopic(t string) (*meter.Bytes32, error) {
 	return &topic, nil
 }
 
+func parseBool(b string) (bool, error) {
+	switch b {
+	case "", "false":
+		return false, nil
+	case "true":
+		return true, nil
+	default:
+		return false, errors.New("should be boolean")
+	}
+}
+
 func parseAddress(addr string) (*meter.Address, error) {
 	if addr == "" {
 		return nil, nil
@@ -0,0 +1,60 @@
+// Copyright (c) 2020 The Meter.io developers
+
+// Distributed under the GNU Lesser General Public License v3.0 software license, see the accompanying
+// file LICENSE or <https://www.gnu.org/licenses/lgpl-3.0.html>
+
+package subscriptions
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/gorilla/websocket"
+	"github.com/meterio/meter-pov/chain"
+	"github.com/meterio/meter-pov/chain/testchain"
+	"github.com/meterio/meter-pov/lvldb"
+	"github.com/meterio/meter-pov/state"
+	"github.com/meterio/meter-pov/txpool"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestChain(t *testing.T) (*chain.Chain, *state.Creator) {
+	kv, _ := lvldb.NewMem()
+	ch, stateC, err := testchain.New(kv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ch, stateC
+}
+
+func newTestServer(ch *chain.Chain, pool *txpool.TxPool) (*httptest.Server, *Subscriptions) {
+	router := mux.NewRouter()
+	s := New(ch, pool, []string{}, 10)
+	s.Mount(router, "/subscriptions")
+	return httptest.NewServer(router), s
+}
+
+func dial(ts *httptest.Server, path string) (*websocket.Conn, *http.Response, error) {
+	return websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(ts.URL, "http")+path, nil)
+}
+
+func TestFinalizedParam(t *testing.T) {
+	ch, _ := newTestChain(t)
+	ts, s := newTestServer(ch, nil)
+	defer ts.Close()
+	defer s.Close()
+
+	for _, subject := range []string{"block", "event", "transfer", "beat"} {
+		// accepted but has no effect
+		conn, _, err := dial(ts, "/subscriptions/"+subject+"?finalized=true")
+		assert.Nil(t, err, subject)
+		conn.Close()
+
+		_, res, err := dial(ts, "/subscriptions/"+subject+"?finalized=yes")
+		assert.NotNil(t, err, subject)
+		assert.Equal(t, http.StatusBadRequest, res.StatusCode, subject)
+	}
+}
@@ -7,29 +7,27 @@ package subscriptions
 
 import (
 	"github.com/meterio/meter-pov/chain"
-	"github.com/meterio/meter-pov/meter"
 )
 
 type transferReader struct {
 	chain       *chain.Chain
 	filter      *TransferFilter
-	blockReader chain.BlockReader
+	chainReader *chainReader
 }
 
-func newTransferReader(chain *chain.Chain, position meter.Bytes32, filter *TransferFilter) *transferReader {
+func newTransferReader(chain *chain.Chain, chainReader *chainReader, filter *TransferFilter) *transferReader {
 	return &transferReader{
 		chain:       chain,
 		filter:      filter,
-		blockReader: chain.NewBlockReader(position),
+		chainReader: chainReader,
 	}
 }
 
 func (tr *transferReader) Read() ([]interface{}, bool, error) {
-	blocks, err := tr.blockReader.Read()
+	blocks, msgs, err := tr.chainReader.Read()
 	if err != nil {
 		return nil, false, err
 	}
-	var msgs []interface{}
 	for _, block := range blocks {
 		receipts, err := tr.chain.GetBlockReceipts(block.Header().ID())
 		if err != nil {
@@ -0,0 +1,118 @@
+// Copyright (c) 2020 The Meter.io developers
+
+// Distributed under the GNU Lesser General Public License v3.0 software license, see the accompanying
+// file LICENSE or <https://www.gnu.org/licenses/lgpl-3.0.html>
+
+package subscriptions
+
+import (
+	"errors"
+
+	"github.com/meterio/meter-pov/meter"
+	"github.com/meterio/meter-pov/txpool"
+)
+
+// txEventBufferSize events buffered for a slow subscriber before dropping.
+const txEventBufferSize = 1000
+
+var errTxEventsDropped = errors.New("tx events dropped, subscriber is too slow")
+
+// txReader receives tx events from the pool.
+// Unlike readers of the chain, it is driven by events pushed by the pool, so that the pool
+// is never blocked by a slow subscriber. Events are filtered by origin before buffered, and once
+// an event exceeds the buffer, the reader fails so that the subscriber is closed instead of
+// silently missing events.
+type txReader struct {
+	origin  *meter.Address
+	events  chan *TxMessage
+	notify  chan bool
+	dropped chan struct{} // closed once an event is dropped
+	done    chan struct{}
+	unsub   func()
+}
+
+func newTxReader(pool *txpool.TxPool, origin *meter.Address) *txReader {
+	ch := make(chan *txpool.TxEvent)
+	sub := pool.SubscribeTxEvent(ch)
+
+	tr := &txReader{
+		origin:  origin,
+		events:  make(chan *TxMessage, txEventBufferSize),
+		notify:  make(chan bool, 1),
+		dropped: make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+	tr.unsub = func() {
+		sub.Unsubscribe()
+		close(tr.done)
+	}
+	go func() {
+		for {
+			select {
+			case <-tr.done:
+				return
+			case <-sub.Err():
+				return
+			case ev := <-ch:
+				tr.receive(ev)
+			}
+		}
+	}()
+	return tr
+}
+
+// receive buffers the event if it's from the origin.
+func (tr *txReader) receive(ev *txpool.TxEvent) {
+	select {
+	case <-tr.dropped:
+		// keep draining the pool until closed
+		return
+	default:
+	}
+	msg, err := convertTxEvent(ev)
+	if err != nil {
+		log.Debug("tx event ignored", "id", ev.Tx.ID(), "err", err)
+		return
+	}
+	if tr.origin != nil && *tr.origin != msg.Origin {
+		return
+	}
+	select {
+	case tr.events <- msg:
+	default:
+		log.Debug("tx event dropped", "id", msg.ID)
+		close(tr.dropped)
+	}
+	select {
+	case tr.notify <- true:
+	default:
+	}
+}
+
+// Read returns buffered events without blocking, or errTxEventsDropped if events were dropped.
+func (tr *txReader) Read() ([]interface{}, bool, error) {
+	select {
+	case <-tr.dropped:
+		return nil, false, errTxEventsDropped
+	default:
+	}
+	var msgs []interface{}
+	for {
+		select {
+		case msg := <-tr.events:
+			msgs = append(msgs, msg)
+		default:
+			return msgs, false, nil
+		}
+	}
+}
+
+// C implements co.Waiter, signaled when new events arrive.
+func (tr *txReader) C() <-chan bool {
+	return tr.notify
+}
+
+// Close stops receiving events from the pool.
+func (tr *txReader) Close() {
+	tr.unsub()
+}
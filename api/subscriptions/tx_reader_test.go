@@ -0,0 +1,86 @@
+// Copyright (c) 2020 The Meter.io developers
+
+// Distributed under the GNU Lesser General Public License v3.0 software license, see the accompanying
+// file LICENSE or <https://www.gnu.org/licenses/lgpl-3.0.html>
+
+package subscriptions
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/meterio/meter-pov/genesis"
+	"github.com/meterio/meter-pov/meter"
+	"github.com/meterio/meter-pov/tx"
+	"github.com/meterio/meter-pov/txpool"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestTx(chainTag byte, nonce uint64, from genesis.DevAccount) *tx.Transaction {
+	to := meter.BytesToAddress([]byte("to"))
+	trx := new(tx.Builder).
+		ChainTag(chainTag).
+		Clause(tx.NewClause(&to)).
+		Expiration(100).
+		Gas(21000).
+		Nonce(nonce).
+		Build()
+	sig, _ := crypto.Sign(trx.SigningHash().Bytes(), from.PrivateKey)
+	return trx.WithSignature(sig)
+}
+
+func TestTxReader(t *testing.T) {
+	ch, stateC := newTestChain(t)
+	pool := txpool.New(ch, stateC, txpool.Options{Limit: 100, LimitPerAccount: 16, MaxLifetime: time.Hour})
+	defer pool.Close()
+
+	acc0, acc1 := genesis.DevAccounts()[0], genesis.DevAccounts()[1]
+	tr := newTxReader(pool, &acc0.Address)
+	defer tr.Close()
+
+	// events of other origins are filtered before buffered
+	tx0 := newTestTx(ch.Tag(), 1, acc0)
+	assert.Nil(t, pool.Add(newTestTx(ch.Tag(), 1, acc1)))
+	assert.Nil(t, pool.Add(tx0))
+
+	var msgs []interface{}
+	for len(msgs) == 0 {
+		select {
+		case <-tr.C():
+		case <-time.After(5 * time.Second):
+			t.Fatal("timeout waiting for tx event")
+		}
+		read, _, err := tr.Read()
+		assert.Nil(t, err)
+		msgs = append(msgs, read...)
+	}
+	assert.Equal(t, 1, len(msgs))
+	assert.Equal(t, tx0.ID(), msgs[0].(*TxMessage).ID)
+	assert.Equal(t, acc0.Address, msgs[0].(*TxMessage).Origin)
+	assert.Equal(t, 0, len(tr.events))
+}
+
+func TestTxReaderDropped(t *testing.T) {
+	acc0, acc1 := genesis.DevAccounts()[0], genesis.DevAccounts()[1]
+	tr := &txReader{
+		origin:  &acc0.Address,
+		events:  make(chan *TxMessage, 1),
+		notify:  make(chan bool, 1),
+		dropped: make(chan struct{}),
+	}
+
+	// filtered events don't take the buffer
+	tr.receive(&txpool.TxEvent{Tx: newTestTx(0, 1, acc1)})
+	tr.receive(&txpool.TxEvent{Tx: newTestTx(0, 1, acc0)})
+	msgs, _, err := tr.Read()
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(msgs))
+
+	// the subscriber is closed once an event is dropped
+	tr.receive(&txpool.TxEvent{Tx: newTestTx(0, 2, acc0)})
+	tr.receive(&txpool.TxEvent{Tx: newTestTx(0, 3, acc0)})
+	tr.receive(&txpool.TxEvent{Tx: newTestTx(0, 4, acc0)})
+	_, _, err = tr.Read()
+	assert.Equal(t, errTxEventsDropped, err)
+}
@@ -6,15 +6,20 @@
 package subscriptions
 
 import (
+	b64 "encoding/base64"
+
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/meterio/meter-pov/block"
 	"github.com/meterio/meter-pov/chain"
+	"github.com/meterio/meter-pov/consensus"
 	"github.com/meterio/meter-pov/meter"
 	"github.com/meterio/meter-pov/tx"
+	"github.com/meterio/meter-pov/txpool"
 )
 
-//BlockMessage block piped by websocket
+// BlockMessage block piped by websocket
 type BlockMessage struct {
 	Number       uint32          `json:"number"`
 	ID           meter.Bytes32   `json:"id"`
@@ -64,6 +69,35 @@ func convertBlock(b *chain.Block) (*BlockMessage, error) {
 	}, nil
 }
 
+// RevertMessage reverted blocks piped by websocket, which comes before the obsolete items of the blocks
+type RevertMessage struct {
+	Type       string        `json:"type"` // always "revert"
+	FromNumber uint32        `json:"fromNumber"`
+	FromID     meter.Bytes32 `json:"fromID"`
+	ToNumber   uint32        `json:"toNumber"`
+	ToID       meter.Bytes32 `json:"toID"`
+	AncestorID meter.Bytes32 `json:"ancestorID"` // the block which the chain is reverted to
+}
+
+// convertRevert returns the revert message of the leading obsolete blocks, which are read
+// from the highest one, or nil if there's no obsolete block.
+func convertRevert(blocks []*chain.Block) *RevertMessage {
+	var msg *RevertMessage
+	for _, b := range blocks {
+		if !b.Obsolete {
+			break
+		}
+		header := b.Header()
+		if msg == nil {
+			msg = &RevertMessage{Type: "revert", ToNumber: header.Number(), ToID: header.ID()}
+		}
+		msg.FromNumber = header.Number()
+		msg.FromID = header.ID()
+		msg.AncestorID = header.ParentID()
+	}
+	return msg
+}
+
 type LogMeta struct {
 	BlockID        meter.Bytes32 `json:"blockID"`
 	BlockNumber    uint32        `json:"blockNumber"`
@@ -72,7 +106,7 @@ type LogMeta struct {
 	TxOrigin       meter.Address `json:"txOrigin"`
 }
 
-//TransferMessage transfer piped by websocket
+// TransferMessage transfer piped by websocket
 type TransferMessage struct {
 	Sender    meter.Address         `json:"sender"`
 	Recipient meter.Address         `json:"recipient"`
@@ -104,7 +138,7 @@ func convertTransfer(header *block.Header, tx *tx.Transaction, transfer *tx.Tran
 	}, nil
 }
 
-//EventMessage event piped by websocket
+// EventMessage event piped by websocket
 type EventMessage struct {
 	Address  meter.Address   `json:"address"`
 	Topics   []meter.Bytes32 `json:"topics"`
@@ -212,3 +246,118 @@ type BeatMessage struct {
 	Nonce        uint64        `json:"nonce"`
 	Epoch        uint64        `json:"epoch"`
 }
+
+// TxMessage tx event of the pool piped by websocket
+type TxMessage struct {
+	ID         meter.Bytes32       `json:"id"`
+	Origin     meter.Address       `json:"origin"`
+	Nonce      math.HexOrDecimal64 `json:"nonce"`
+	DependsOn  *meter.Bytes32      `json:"dependsOn"`
+	Executable *bool               `json:"executable"`
+}
+
+func convertTxEvent(ev *txpool.TxEvent) (*TxMessage, error) {
+	origin, err := ev.Tx.Signer()
+	if err != nil {
+		return nil, err
+	}
+	return &TxMessage{
+		ID:         ev.Tx.ID(),
+		Origin:     origin,
+		Nonce:      math.HexOrDecimal64(ev.Tx.Nonce()),
+		DependsOn:  ev.Tx.DependsOn(),
+		Executable: ev.Executable,
+	}, nil
+}
+
+// ConsensusMessage consensus event piped by websocket, fields are set by type
+type ConsensusMessage struct {
+	Type   string `json:"type"`
+	Height uint32 `json:"height"`
+	Round  uint32 `json:"round"`
+	Epoch  uint64 `json:"epoch"`
+
+	Reason  string             `json:"reason,omitempty"`
+	Counter uint64             `json:"counter,omitempty"`
+	QC      *QC                `json:"qc,omitempty"`
+	Nonce   uint64             `json:"nonce,omitempty"`
+	Members []*CommitteeMember `json:"committee,omitempty"`
+
+	Statistics []*DelegateStatistics `json:"statistics,omitempty"`
+	InJail     []*DelegateJailed     `json:"inJail,omitempty"`
+}
+
+type QC struct {
+	QCHeight         uint32 `json:"qcHeight"`
+	QCRound          uint32 `json:"qcRound"`
+	VoterBitArrayStr string `json:"voterBitArrayStr"`
+	EpochID          uint64 `json:"epochID"`
+}
+
+type CommitteeMember struct {
+	Name        string        `json:"name"`
+	Address     meter.Address `json:"addr"`
+	PubKey      string        `json:"pubKey"`
+	VotingPower int64         `json:"votingPower"`
+	NetAddr     string        `json:"netAddr"`
+}
+
+type DelegateStatistics struct {
+	Address     meter.Address `json:"address"`
+	Name        string        `json:"name"`
+	TotalPoints uint64        `json:"totalPoints"`
+}
+
+type DelegateJailed struct {
+	Address     meter.Address         `json:"address"`
+	Name        string                `json:"name"`
+	TotalPoints uint64                `json:"totalPoints"`
+	BailAmount  *math.HexOrDecimal256 `json:"bailAmount"`
+	JailedTime  uint64                `json:"jailedTime"`
+}
+
+func convertConsensusEvent(ev *consensus.Event) *ConsensusMessage {
+	msg := &ConsensusMessage{
+		Type:    ev.Type,
+		Height:  ev.Height,
+		Round:   ev.Round,
+		Epoch:   ev.Epoch,
+		Reason:  ev.Reason,
+		Counter: ev.Counter,
+		Nonce:   ev.Nonce,
+	}
+	if ev.QC != nil {
+		msg.QC = &QC{
+			QCHeight:         ev.QC.QCHeight,
+			QCRound:          ev.QC.QCRound,
+			VoterBitArrayStr: ev.QC.VoterBitArrayStr,
+			EpochID:          ev.QC.EpochID,
+		}
+	}
+	for _, v := range ev.Committee {
+		msg.Members = append(msg.Members, &CommitteeMember{
+			Name:        v.Name,
+			Address:     v.Address,
+			PubKey:      b64.StdEncoding.EncodeToString(crypto.FromECDSAPub(&v.PubKey)),
+			VotingPower: v.VotingPower,
+			NetAddr:     v.NetAddr.String(),
+		})
+	}
+	for _, s := range ev.Statistics {
+		msg.Statistics = append(msg.Statistics, &DelegateStatistics{
+			Address:     s.Addr,
+			Name:        string(s.Name),
+			TotalPoints: s.TotalPts,
+		})
+	}
+	for _, j := range ev.InJail {
+		msg.InJail = append(msg.InJail, &DelegateJailed{
+			Address:     j.Addr,
+			Name:        string(j.Name),
+			TotalPoints: j.TotalPts,
+			BailAmount:  (*math.HexOrDecimal256)(j.BailAmount),
+			JailedTime:  j.JailedTime,
+		})
+	}
+	return msg
+}
@@ -0,0 +1,84 @@
+// Copyright (c) 2020 The Meter.io developers
+
+// Distributed under the GNU Lesser General Public License v3.0 software license, see the accompanying
+// file LICENSE or <https://www.gnu.org/licenses/lgpl-3.0.html>
+
+package subscriptions
+
+import (
+	"testing"
+
+	"github.com/meterio/meter-pov/block"
+	"github.com/meterio/meter-pov/chain"
+	"github.com/stretchr/testify/assert"
+)
+
+func obsolete(b *block.Block) *chain.Block {
+	return &chain.Block{Block: b, Obsolete: true}
+}
+
+func trunk(b *block.Block) *chain.Block {
+	return &chain.Block{Block: b}
+}
+
+func TestConvertRevert(t *testing.T) {
+	b0 := new(block.Builder).Build()
+	b1 := new(block.Builder).ParentID(b0.Header().ID()).Build()
+	b2 := new(block.Builder).ParentID(b1.Header().ID()).Build()
+	b3 := new(block.Builder).ParentID(b2.Header().ID()).Build()
+	b1x := new(block.Builder).ParentID(b0.Header().ID()).Timestamp(1).Build()
+
+	// no obsolete blocks
+	assert.Nil(t, convertRevert(nil))
+	assert.Nil(t, convertRevert([]*chain.Block{trunk(b1x)}))
+
+	// obsolete blocks are read from the highest one, followed by the new trunk blocks
+	msg := convertRevert([]*chain.Block{obsolete(b3), obsolete(b2), obsolete(b1), trunk(b1x)})
+	assert.Equal(t, &RevertMessage{
+		Type:       "revert",
+		FromNumber: b1.Header().Number(),
+		FromID:     b1.Header().ID(),
+		ToNumber:   b3.Header().Number(),
+		ToID:       b3.Header().ID(),
+		AncestorID: b0.Header().ID(),
+	}, msg)
+
+	// a single obsolete block
+	msg = convertRevert([]*chain.Block{obsolete(b3)})
+	assert.Equal(t, &RevertMessage{
+		Type:       "revert",
+		FromNumber: b3.Header().Number(),
+		FromID:     b3.Header().ID(),
+		ToNumber:   b3.Header().Number(),
+		ToID:       b3.Header().ID(),
+		AncestorID: b2.Header().ID(),
+	}, msg)
+
+	// obsolete blocks after trunk blocks are not reverted
+	assert.Nil(t, convertRevert([]*chain.Block{trunk(b1x), obsolete(b1)}))
+}
+
+func TestChainReaderRevert(t *testing.T) {
+	b0 := new(block.Builder).Build()
+	b1 := new(block.Builder).ParentID(b0.Header().ID()).Build()
+	b1x := new(block.Builder).ParentID(b0.Header().ID()).Timestamp(1).Build()
+	blocks := []*chain.Block{obsolete(b1), trunk(b1x)}
+
+	cr := &chainReader{blockReader: fixedBlockReader(blocks), revert: true}
+	read, msgs, err := cr.Read()
+	assert.Nil(t, err)
+	assert.Equal(t, blocks, read)
+	assert.Equal(t, []interface{}{convertRevert(blocks)}, msgs)
+
+	// no revert messages unless asked
+	cr = &chainReader{blockReader: fixedBlockReader(blocks)}
+	_, msgs, err = cr.Read()
+	assert.Nil(t, err)
+	assert.Empty(t, msgs)
+}
+
+type fixedBlockReader []*chain.Block
+
+func (r fixedBlockReader) Read() ([]*chain.Block, error) {
+	return r, nil
+}
@@ -15,7 +15,6 @@ import (
 	"strings"
 
 	"github.com/ethereum/go-ethereum/common/hexutil"
-	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/rlp"
 	"github.com/gorilla/mux"
 	"github.com/meterio/meter-pov/api/utils"
@@ -163,18 +162,16 @@ func (t *Transactions) handleSendEthRawTransaction(w http.ResponseWriter, req *h
 	if hasKey(m, "raw") {
 		raw := strings.Replace(m["raw"].(string), "0x", "", 1)
 		rawBytes, _ := hex.DecodeString(raw)
-		ethTx := types.Transaction{}
-		stream := rlp.NewStream(bytes.NewReader(rawBytes), 0)
-		err := ethTx.DecodeRLP(stream)
+		ethTx, err := tx.DecodeEthTx(rawBytes)
 		if err != nil {
-			fmt.Println("raw tx ERR: ", err)
+			return utils.BadRequest(errors.WithMessage(err, "raw"))
 		}
 		bestBlock := t.chain.BestBlock()
 		genID, _ := t.chain.GetAncestorBlockID(bestBlock.BlockHeader.ID(), 0)
 		chainTag := genID[len(genID)-1]
 		bestBlockID := bestBlock.BlockHeader.ID()
 		blockRef := tx.NewBlockRefFromID(bestBlockID)
-		nativeTx, err := tx.NewTransactionFromEthTx(&ethTx, chainTag, blockRef)
+		nativeTx, err := tx.NewTransactionFromEthTx(ethTx, chainTag, blockRef)
 		if err != nil {
 			return utils.BadRequest(err)
 		} else {
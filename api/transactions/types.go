@@ -6,7 +6,6 @@
 package transactions
 
 import (
-	"encoding/json"
 	"fmt"
 	"math/big"
 	"strings"
@@ -66,16 +65,47 @@ func hasKey(m map[string]interface{}, key string) bool {
 }
 
 type EthTx struct {
-	Nonce    string `json:"nonce"`
-	GasPrice string `json:"gasPrice"`
-	Gas      string `json:"gas"`
-	To       string `json:"to"`
-	Value    string `json:"value"`
-	Input    string `json:"input"`
-	V        string `json:"v"`
-	R        string `json:"r"`
-	S        string `json:"s"`
-	Hash     string `json:"hash"`
+	Type                 string        `json:"type"`
+	ChainID              string        `json:"chainId"`
+	Nonce                string        `json:"nonce"`
+	GasPrice             string        `json:"gasPrice,omitempty"`
+	MaxFeePerGas         string        `json:"maxFeePerGas,omitempty"`
+	MaxPriorityFeePerGas string        `json:"maxPriorityFeePerGas,omitempty"`
+	Gas                  string        `json:"gas"`
+	To                   string        `json:"to"`
+	Value                string        `json:"value"`
+	Input                string        `json:"input"`
+	AccessList           tx.AccessList `json:"accessList,omitempty"`
+	V                    string        `json:"v"`
+	R                    string        `json:"r"`
+	S                    string        `json:"s"`
+	Hash                 string        `json:"hash"`
+}
+
+func convertEthTx(ethTx *tx.EthTx) *EthTx {
+	etx := &EthTx{
+		Type:       hexutil.EncodeUint64(uint64(ethTx.Type)),
+		ChainID:    hexutil.EncodeBig(ethTx.ChainID),
+		Nonce:      hexutil.EncodeUint64(ethTx.Nonce),
+		Gas:        hexutil.EncodeUint64(ethTx.Gas),
+		Value:      hexutil.EncodeBig(ethTx.Value),
+		Input:      hexutil.Encode(ethTx.Data),
+		AccessList: ethTx.AccessList,
+		V:          hexutil.EncodeBig(ethTx.V),
+		R:          hexutil.EncodeBig(ethTx.R),
+		S:          hexutil.EncodeBig(ethTx.S),
+		Hash:       ethTx.Hash().Hex(),
+	}
+	if ethTx.To != nil {
+		etx.To = ethTx.To.Hex()
+	}
+	if ethTx.Type == tx.DynamicFeeTxType {
+		etx.MaxFeePerGas = hexutil.EncodeBig(ethTx.GasFeeCap)
+		etx.MaxPriorityFeePerGas = hexutil.EncodeBig(ethTx.GasTipCap)
+	} else {
+		etx.GasPrice = hexutil.EncodeBig(ethTx.GasPrice)
+	}
+	return etx
 }
 
 //Transaction transaction
@@ -181,22 +211,11 @@ func convertTransaction(tx *tx.Transaction, header *block.Header, txIndex uint64
 	for i, c := range tx.Clauses() {
 		cls[i] = convertClause(c)
 	}
-	ethTxJSON := make([]byte, 0)
 	var convertedEthTx *EthTx
 	br := tx.BlockRef()
 	if tx.IsEthTx() {
-		ethTx, err := tx.GetEthTx()
-		if err == nil {
-			ethTxJSON, err = ethTx.MarshalJSON()
-			if err != nil {
-				fmt.Println("could not marshal ethereum tx")
-			}
-			etx := EthTx{}
-			err = json.Unmarshal(ethTxJSON, &etx)
-			if err != nil {
-				fmt.Println("could not unmarshal ethTx")
-			}
-			convertedEthTx = &etx
+		if ethTx, err := tx.GetEthTx(); err == nil {
+			convertedEthTx = convertEthTx(ethTx)
 		}
 	}
 	t := &Transaction{
@@ -0,0 +1,53 @@
+// Copyright (c) 2020 The Meter.io developers
+
+// Distributed under the GNU Lesser General Public License v3.0 software license, see the accompanying
+// file LICENSE or <https://www.gnu.org/licenses/lgpl-3.0.html>
+
+package utils
+
+import (
+	"math"
+	"strconv"
+
+	"github.com/meterio/meter-pov/block"
+	"github.com/meterio/meter-pov/chain"
+	"github.com/meterio/meter-pov/meter"
+	"github.com/pkg/errors"
+)
+
+// HandleRevision returns the header of the block given by the revision, which is empty or
+// "best" for the best block, a block ID or a block number on the trunk.
+func HandleRevision(chain *chain.Chain, revision string) (*block.Header, error) {
+	if revision == "" || revision == "best" {
+		return chain.BestBlock().Header(), nil
+	}
+	if len(revision) == 66 || len(revision) == 64 {
+		blockID, err := meter.ParseBytes32(revision)
+		if err != nil {
+			return nil, BadRequest(errors.WithMessage(err, "revision"))
+		}
+		h, err := chain.GetBlockHeader(blockID)
+		if err != nil {
+			if chain.IsNotFound(err) {
+				return nil, BadRequest(errors.WithMessage(err, "revision"))
+			}
+			return nil, err
+		}
+		return h, nil
+	}
+	n, err := strconv.ParseUint(revision, 0, 0)
+	if err != nil {
+		return nil, BadRequest(errors.WithMessage(err, "revision"))
+	}
+	if n > math.MaxUint32 {
+		return nil, BadRequest(errors.WithMessage(errors.New("block number out of max uint32"), "revision"))
+	}
+	h, err := chain.GetTrunkBlockHeader(uint32(n))
+	if err != nil {
+		if chain.IsNotFound(err) {
+			return nil, BadRequest(errors.WithMessage(err, "revision"))
+		}
+		return nil, err
+	}
+	return h, nil
+}
@@ -28,6 +28,7 @@ var (
 	Prototype = &prototypeContract{mustLoadContract("Prototype")}
 	Extension = &extensionContract{mustLoadContract("Extension")}
 	Measure   = mustLoadContract("Measure")
+	Staking   = &stakingContract{mustLoadContract("Staking")}
 
 	OldMeter        = &erc20Contract{mustLoadContract("Meter")}
 	OldMeterGov     = &erc20Contract{mustLoadContract("MeterGov")}
@@ -41,6 +42,7 @@ type (
 	executorContract     struct{ *contract }
 	prototypeContract    struct{ *contract }
 	extensionContract    struct{ *contract }
+	stakingContract      struct{ *contract }
 )
 
 func (p *paramsContract) Native(state *state.State) *params.Params {
@@ -90,3 +92,16 @@ func FindNativeCall(to meter.Address, input []byte) (*abi.Method, func(*xenv.Env
 	}
 	return method.abi, method.run, true
 }
+
+// RegisterNative registers the native implementation of the method, which is implemented
+// by the staking module since it imports builtin.
+func (s *stakingContract) RegisterNative(name string, run func(env *xenv.Environment) []interface{}) {
+	method, found := s.ABI.MethodByName(name)
+	if !found {
+		panic("method not found: " + name)
+	}
+	nativeMethods[methodKey{s.Address, method.ID()}] = &nativeMethod{
+		abi: method,
+		run: run,
+	}
+}
@@ -48,6 +48,9 @@
 // compiled/PrototypeNative.abi
 // compiled/PrototypeNative.bin
 // compiled/PrototypeNative.bin-runtime
+// compiled/Staking.abi
+// compiled/Staking.bin
+// compiled/Staking.bin-runtime
 package gen
 
 import (
@@ -1073,6 +1076,66 @@ func compiledPrototypenativeBinRuntime() (*asset, error) {
 	return a, nil
 }
 
+var _compiledStakingAbi = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\xdc\x56\x3d\x6f\xdb\x40\x0c\xfd\x2f\x9c\x35\x39\x6d\x10\x78\x0c\xb2\x74\x28\xba\xd4\x5d\x0c\x0f\x94\x8e\x2e\x0e\x91\x48\x41\xc7\x73\x2a\x18\xfe\xef\x45\xdc\xb3\xbe\x22\x9d\x65\x34\x4d\xe2\xae\xd6\x23\xfd\xde\xe3\x23\xa5\xf5\x1e\x32\x61\xa7\xc8\x0a\xcb\x2d\xe6\x8e\x12\xb0\x5c\x7a\x75\xb0\x5c\xef\x81\xb1\x20\x58\x42\xea\xb3\x47\xd2\x2f\x0f\x90\x80\xd6\xe5\xf1\x97\x5a\xc9\xdd\x2c\xe0\xb0\x49\x4e\x20\xcf\xa9\x78\x36\x90\x80\x78\x0d\x1d\x36\x09\x94\x58\x63\x9a\x53\xd3\xdd\x29\x2a\x7d\xf5\x8a\xa9\xcd\xad\xd6\xb0\x04\x16\x3e\x81\x9a\xfe\x5b\xcf\x99\x5a\x61\x38\x24\x5d\x86\x5a\xf9\x51\x82\xf2\xc4\x54\xb5\xd5\x68\x4c\x45\xce\x75\xd9\xfd\x91\xe0\xbe\x6d\x7b\xfc\x9a\x06\x6d\x6d\x50\xb6\xde\xc0\x61\x16\xfb\x9d\xa5\xa7\xb3\xbc\xff\xd6\x59\x43\x39\xfd\x44\xa5\x77\x31\xf7\xd9\xcd\xa8\xb7\x19\xb2\xb1\x66\x48\xaf\xa9\xcf\xad\x53\x32\x6d\x87\x54\x24\x3f\xfa\x13\x9e\xab\x28\xe6\x3f\x44\xc9\xb5\x18\x6f\x59\x17\x9f\x6f\xdf\x7b\x06\xc9\x7e\x4c\xe3\xd0\x89\x16\x85\x5e\x25\xb5\x1d\xad\xcf\x3a\xee\xba\x5e\xbd\xc9\x24\x27\xa5\xce\x54\x51\x88\x67\x1d\x19\x46\x0b\x51\x79\x24\xee\x23\xee\xba\xcf\xa5\x3c\xd2\xea\x01\x6e\x16\x97\x59\xf5\xf2\x9c\xcc\x19\xd9\x5b\x6d\x45\x9c\x42\x0f\x34\xae\x61\xea\x66\x25\x17\x4e\x6b\x87\xb9\xa7\x33\xc3\x8a\x2c\xd8\x6b\xce\x34\xbc\x02\x62\xcb\x5e\xa0\xfa\x8a\xbe\xdb\x62\x40\xf9\xf6\x53\x17\x95\x55\x84\x3a\x81\xfa\x28\x17\x61\x46\xc4\xa7\xf6\x68\x18\x90\x55\x19\xa6\xdc\xc6\xe4\x75\x62\x8c\x2c\x5c\x17\xe2\xdd\x98\x58\xcb\x86\x7e\x91\x39\x45\xfc\x7c\x2c\xc7\x0b\xe2\x66\x35\x35\xe1\xff\xe7\x45\x7b\xa2\x2a\x76\x98\x26\x4a\xc6\x43\xdd\x0e\xe0\xfe\xc8\xfe\x3e\x9c\x9a\x80\xa2\x1d\xb1\x7e\x6c\x03\xff\x99\x15\xab\xe6\x33\xee\x7a\xcc\x88\xa5\x69\xa8\xef\x21\xbc\x80\xff\x5f\x85\x2b\x36\x57\xa9\x31\x72\x50\x2f\x5d\x82\x17\x96\x94\x66\xd4\x8e\xcd\xef\x01\x00\x0e\x87\xc7\x1f\x00\x0d\x00\x00")
+
+func compiledStakingAbiBytes() ([]byte, error) {
+	return bindataRead(
+		_compiledStakingAbi,
+		"compiled/Staking.abi",
+	)
+}
+
+func compiledStakingAbi() (*asset, error) {
+	bytes, err := compiledStakingAbiBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "compiled/Staking.abi", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _compiledStakingBin = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x01\x00\x00\xff\xff\x00\x00\x00\x00\x00\x00\x00\x00")
+
+func compiledStakingBinBytes() ([]byte, error) {
+	return bindataRead(
+		_compiledStakingBin,
+		"compiled/Staking.bin",
+	)
+}
+
+func compiledStakingBin() (*asset, error) {
+	bytes, err := compiledStakingBinBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "compiled/Staking.bin", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _compiledStakingBinRuntime = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x01\x00\x00\xff\xff\x00\x00\x00\x00\x00\x00\x00\x00")
+
+func compiledStakingBinRuntimeBytes() ([]byte, error) {
+	return bindataRead(
+		_compiledStakingBinRuntime,
+		"compiled/Staking.bin-runtime",
+	)
+}
+
+func compiledStakingBinRuntime() (*asset, error) {
+	bytes, err := compiledStakingBinRuntimeBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "compiled/Staking.bin-runtime", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
 // Asset loads and returns the asset for the given name.
 // It returns an error if the asset could not be found or
 // could not be loaded.
@@ -1173,6 +1236,9 @@ var _bindata = map[string]func() (*asset, error){
 	"compiled/PrototypeNative.abi": compiledPrototypenativeAbi,
 	"compiled/PrototypeNative.bin": compiledPrototypenativeBin,
 	"compiled/PrototypeNative.bin-runtime": compiledPrototypenativeBinRuntime,
+	"compiled/Staking.abi": compiledStakingAbi,
+	"compiled/Staking.bin": compiledStakingBin,
+	"compiled/Staking.bin-runtime": compiledStakingBinRuntime,
 }
 
 // AssetDir returns the file names below a certain
@@ -1264,6 +1330,9 @@ var _bintree = &bintree{nil, map[string]*bintree{
 		"PrototypeNative.abi": &bintree{compiledPrototypenativeAbi, map[string]*bintree{}},
 		"PrototypeNative.bin": &bintree{compiledPrototypenativeBin, map[string]*bintree{}},
 		"PrototypeNative.bin-runtime": &bintree{compiledPrototypenativeBinRuntime, map[string]*bintree{}},
+		"Staking.abi": &bintree{compiledStakingAbi, map[string]*bintree{}},
+		"Staking.bin": &bintree{compiledStakingBin, map[string]*bintree{}},
+		"Staking.bin-runtime": &bintree{compiledStakingBinRuntime, map[string]*bintree{}},
 	}},
 }}
 
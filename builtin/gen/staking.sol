@@ -0,0 +1,29 @@
+// Copyright (c) 2020 The Meter.io developers
+
+// Distributed under the GNU Lesser General Public License v3.0 software license, see the accompanying
+// file LICENSE or <https://www.gnu.org/licenses/lgpl-3.0.html>
+
+pragma solidity 0.4.24;
+
+/// @title Staking lets contracts stake, it's implemented natively with the same handlers as
+/// staking scripts, and the caller is the holder of buckets.
+interface Staking {
+    event BucketBound(address indexed owner, bytes32 indexed bucketID, address candidate, uint256 amount, uint8 token);
+    event BucketUnbound(address indexed owner, bytes32 indexed bucketID, uint256 amount, uint8 token);
+    event BucketDelegated(address indexed owner, bytes32 indexed bucketID, address candidate);
+    event BucketUndelegated(address indexed owner, bytes32 indexed bucketID, address candidate);
+    event BucketUpdated(address indexed owner, bytes32 indexed bucketID, uint32 option, uint256 amount);
+
+    // bound locks the amount of token (0 for MTR, 1 for MTRG) of the caller in a new bucket,
+    // and votes for the candidate if it's not zero
+    function bound(address candidate, uint256 amount, uint8 token, uint32 option, uint8 autobid) external returns(bytes32 bucketID);
+    function unbound(bytes32 bucketID) external;
+    function delegate(bytes32 bucketID, address candidate, uint8 autobid) external;
+    function undelegate(bytes32 bucketID) external;
+    // bucketUpdate adds the amount to the bucket, or subtracts it if option is 1
+    function bucketUpdate(bytes32 bucketID, uint32 option, uint256 amount) external;
+
+    function bucketsOf(address owner) external view returns(bytes32[]);
+    function bucket(bytes32 bucketID) external view returns(address owner, address candidate, uint256 value, uint256 totalVotes, uint8 token, uint32 option, bool unbounded, uint64 matureTime, uint64 createTime);
+    function candidate(address addr) external view returns(bool listed, uint256 totalVotes);
+}
@@ -166,7 +166,7 @@ func init() {
 			env.UseGas(meter.GetBalanceGas)
 			ok := false
 			//if meter.IsTestNet() || (meter.IsMainNet() && env.BlockContext().Number > meter.Tesla1_1MainnetStartNum) {
-				ok = MeterTracker.Native(env.State()).SubMeterGov(meter.Address(args.Addr), args.Amount)
+				ok = MeterTracker.Native(env.State()).SubMeterGov(meter.Address(args.Addr), args.Amount, uint32(env.BlockContext().Epoch))
 			//} else {
 			//	ok = MeterTracker.Native(env.State()).Tesla1_0_SubMeterGov(meter.Address(args.Addr), args.Amount)
 			//}
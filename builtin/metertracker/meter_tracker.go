@@ -235,15 +235,15 @@ func (e *MeterTracker) Tesla1_0_SubMeterGov(addr meter.Address, amount *big.Int)
 
 // Sub sub amount of energy from given address.
 // False is returned if no enough energy.
-// should consider account lock file here
-func (e *MeterTracker) SubMeterGov(addr meter.Address, amount *big.Int) bool {
+// The account lock profile of the address restricts the amount at the epoch.
+func (e *MeterTracker) SubMeterGov(addr meter.Address, amount *big.Int, epoch uint32) bool {
 	if amount.Sign() == 0 {
 		return true
 	}
 
 	// comment out for compile
 	//restrict, _, lockMtrg := accountlock.RestrictByAccountLock(addr, r.State())
-	restrict, _, lockMtrg := accountlock.RestrictByAccountLock(addr, e.state)
+	restrict, _, lockMtrg := accountlock.RestrictByAccountLock(addr, e.state, epoch)
 	// restrict, lockMtrg := false, big.NewInt(0)
 	if restrict == true {
 		balance := e.state.GetBalance(addr)
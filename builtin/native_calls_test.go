@@ -26,6 +26,7 @@ import (
 	"github.com/meterio/meter-pov/lvldb"
 	"github.com/meterio/meter-pov/meter"
 	"github.com/meterio/meter-pov/runtime"
+	"github.com/meterio/meter-pov/script/staking"
 	"github.com/meterio/meter-pov/state"
 	"github.com/meterio/meter-pov/tx"
 	"github.com/meterio/meter-pov/xenv"
@@ -791,3 +792,96 @@ func TestExtensionNative(t *testing.T) {
 		Assert(t)
 }
 */
+
+func TestStakingNative(t *testing.T) {
+	var (
+		holder = meter.BytesToAddress([]byte("holder"))
+		other  = meter.BytesToAddress([]byte("other"))
+		amount = new(big.Int).Mul(big.NewInt(100), big.NewInt(1e18))
+		now    = uint64(time.Now().Unix())
+	)
+
+	kv, _ := lvldb.NewMem()
+	b0 := buildGenesis(kv, func(state *state.State) error {
+		state.SetBalance(holder, new(big.Int).Mul(amount, big.NewInt(2)))
+		return nil
+	})
+	c, _ := chain.New(kv, b0, true)
+	st, _ := state.New(b0.Header().StateRoot(), kv)
+	seeker := c.NewSeeker(b0.Header().ID())
+	defer func() {
+		assert.Nil(t, st.Err())
+		assert.Nil(t, seeker.Err())
+	}()
+	staking.NewStaking(nil, nil)
+
+	rt := runtime.New(seeker, st, &xenv.BlockContext{Number: 1, Time: now}).
+		SetForkConfig(meter.ForkConfig{STAKING_CONTRACT: 1})
+	assert.Empty(t, st.GetCode(builtin.Staking.Address))
+	rt.ApplyForks()
+	assert.NotEmpty(t, st.GetCode(builtin.Staking.Address), "should deploy staking contract")
+
+	test := &ctest{
+		rt:     rt,
+		abi:    builtin.Staking.ABI,
+		to:     builtin.Staking.Address,
+		caller: holder,
+	}
+
+	bucketID := staking.NewBucket(holder, meter.Address{}, amount, meter.STPD, staking.ONE_WEEK_LOCK, 0, 0, now, 0).BucketID
+	boundEvent := func() *tx.Event {
+		ev, _ := builtin.Staking.ABI.EventByName("BucketBound")
+		data, _ := ev.Encode(meter.Address{}, amount, meter.STPD)
+		return &tx.Event{
+			Address: builtin.Staking.Address,
+			Topics:  []meter.Bytes32{ev.ID(), meter.BytesToBytes32(holder.Bytes()), bucketID},
+			Data:    data,
+		}
+	}
+
+	test.Case("bound", meter.Address{}, amount, meter.STPD, staking.ONE_WEEK_LOCK, uint8(0)).
+		ShouldOutput(bucketID).
+		ShouldLog(boundEvent()).
+		Assert(t)
+	assert.Equal(t, amount, st.GetBalance(holder))
+	assert.Equal(t, amount, st.GetBoundedBalance(holder))
+
+	test.Case("bound", meter.Address{}, big.NewInt(1), meter.STPD, staking.ONE_WEEK_LOCK, uint8(0)).
+		ShouldVMError(errReverted).
+		Assert(t)
+
+	test.Case("bucketsOf", holder).
+		ShouldOutput([][32]byte{bucketID}).
+		Assert(t)
+
+	test.Case("bucket", bucketID).
+		ShouldOutput(holder, meter.Address{}, amount, amount, meter.STPD, staking.ONE_WEEK_LOCK, false, uint64(0), now).
+		Assert(t)
+
+	test.Case("delegate", bucketID, other, uint8(101)).
+		ShouldVMError(errReverted).
+		Assert(t)
+
+	test.Case("unbound", bucketID).
+		Caller(other).
+		ShouldVMError(errReverted).
+		Assert(t)
+
+	test.Case("unbound", bucketID).
+		Assert(t)
+
+	test.Case("bucket", bucketID).
+		ShouldOutput(holder, meter.Address{}, amount, amount, meter.STPD, staking.ONE_WEEK_LOCK, true, now+staking.ONE_WEEK_LOCK_TIME, now).
+		Assert(t)
+
+	test.Case("bucket", meter.Bytes32{}).
+		ShouldVMError(errReverted).
+		Assert(t)
+
+	// malformed input fails the call
+	method, _ := builtin.Staking.ABI.MethodByName("bucket")
+	id := method.ID()
+	vmout := rt.ExecuteClause(tx.NewClause(&builtin.Staking.Address).WithData(append(id[:], 1)),
+		0, math.MaxUint64, &xenv.TransactionContext{Origin: holder, GasPrice: &big.Int{}})
+	assert.NotNil(t, vmout.VMErr)
+}
@@ -455,6 +455,15 @@ func (c *Chain) GetBlock(id meter.Bytes32) (*block.Block, error) {
 	return c.getBlock(id)
 }
 
+// GetBlockEpoch get the epoch of the block for given id.
+func (c *Chain) GetBlockEpoch(id meter.Bytes32) (uint64, error) {
+	blk, err := c.GetBlock(id)
+	if err != nil {
+		return 0, err
+	}
+	return blk.GetBlockEpoch(), nil
+}
+
 // GetBlockRaw get block rlp encoded bytes for given id.
 // Never modify the returned raw block.
 func (c *Chain) GetBlockRaw(id meter.Bytes32) (block.Raw, error) {
@@ -516,6 +525,15 @@ func (c *Chain) GetTrunkBlockID(num uint32) (meter.Bytes32, error) {
 	return c.ancestorTrie.GetAncestor(c.bestBlock.Header().ID(), num)
 }
 
+// GetBlockIndexRoot get the root of the trie that indexes ancestor ids of the block by number.
+func (c *Chain) GetBlockIndexRoot(id meter.Bytes32) (meter.Bytes32, error) {
+	root, err := c.ancestorTrie.rootsCache.GetOrLoad(id)
+	if err != nil {
+		return meter.Bytes32{}, err
+	}
+	return root.(meter.Bytes32), nil
+}
+
 // GetTrunkBlockHeader get block header on trunk by given block number.
 func (c *Chain) GetTrunkBlockHeader(num uint32) (*block.Header, error) {
 	c.rw.RLock()
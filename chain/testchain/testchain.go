@@ -0,0 +1,88 @@
+// Copyright (c) 2020 The Meter.io developers
+
+// Distributed under the GNU Lesser General Public License v3.0 software license, see the accompanying
+// file LICENSE or <https://www.gnu.org/licenses/lgpl-3.0.html>
+
+// Package testchain builds devnet chains and blocks for tests.
+package testchain
+
+import (
+	"math"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/meterio/meter-pov/block"
+	"github.com/meterio/meter-pov/chain"
+	"github.com/meterio/meter-pov/genesis"
+	"github.com/meterio/meter-pov/kv"
+	"github.com/meterio/meter-pov/meter"
+	"github.com/meterio/meter-pov/packer"
+	"github.com/meterio/meter-pov/state"
+	"github.com/meterio/meter-pov/tx"
+)
+
+// New builds the devnet genesis into kv and returns the chain on top of it.
+func New(kv kv.GetPutter) (*chain.Chain, *state.Creator, error) {
+	stateC := state.NewCreator(kv)
+	b0, _, err := genesis.NewDevnet().Build(stateC)
+	if err != nil {
+		return nil, nil, err
+	}
+	c, err := chain.New(kv, b0, true)
+	if err != nil {
+		return nil, nil, err
+	}
+	return c, stateC, nil
+}
+
+// NewTx builds a tx with the given clause and nonce, signed by the first dev account.
+func NewTx(chainTag byte, clause *tx.Clause, gas uint64, nonce uint64) *tx.Transaction {
+	trx := new(tx.Builder).
+		ChainTag(chainTag).
+		Clause(clause).
+		Gas(gas).
+		Nonce(nonce).
+		Expiration(math.MaxUint32).
+		Build()
+	sig, _ := crypto.Sign(trx.SigningHash().Bytes(), genesis.DevAccounts()[0].PrivateKey)
+	return trx.WithSignature(sig)
+}
+
+// Pack packs txs into a block on top of the best block, signed by the first dev
+// account, and commits its state. The block carries a QC of its own height but
+// is not added to the chain.
+func Pack(c *chain.Chain, stateC *state.Creator, txs ...*tx.Transaction) (*block.Block, tx.Receipts, error) {
+	acc := genesis.DevAccounts()[0]
+	best := c.BestBlock()
+	p := packer.New(c, stateC, acc.Address, &acc.Address)
+	flow, err := p.Mock(best.Header(), best.Header().Timestamp()+meter.BlockInterval, p.GasLimit(best.Header().GasLimit()), &meter.Address{})
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, trx := range txs {
+		if err := flow.Adopt(trx); err != nil {
+			return nil, nil, err
+		}
+	}
+	blk, stage, receipts, err := flow.Pack(acc.PrivateKey, block.BLOCK_TYPE_M_BLOCK, 0)
+	if err != nil {
+		return nil, nil, err
+	}
+	if _, err := stage.Commit(); err != nil {
+		return nil, nil, err
+	}
+	num := blk.Header().Number()
+	blk.SetQC(&block.QuorumCert{QCHeight: num, QCRound: num})
+	return blk, receipts, nil
+}
+
+// Mint packs txs into a block and adds it to the chain as the new best block.
+func Mint(c *chain.Chain, stateC *state.Creator, txs ...*tx.Transaction) (*block.Block, tx.Receipts, error) {
+	blk, receipts, err := Pack(c, stateC, txs...)
+	if err != nil {
+		return nil, nil, err
+	}
+	if _, err := c.AddBlock(blk, receipts, true); err != nil {
+		return nil, nil, err
+	}
+	return blk, receipts, nil
+}
@@ -0,0 +1,128 @@
+// Copyright (c) 2020 The Meter.io developers
+
+// Distributed under the GNU Lesser General Public License v3.0 software license, see the accompanying
+// file LICENSE or <https://www.gnu.org/licenses/lgpl-3.0.html>
+
+package main
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/meterio/meter-pov/cmd/meter/node"
+	"github.com/meterio/meter-pov/consensus"
+	"github.com/meterio/meter-pov/meter"
+	"github.com/meterio/meter-pov/script"
+	"github.com/meterio/meter-pov/state"
+	"github.com/pkg/errors"
+	cli "gopkg.in/urfave/cli.v1"
+)
+
+func exportAction(ctx *cli.Context) error {
+	initLogger(ctx)
+
+	out := ctx.String(exportOutFlag.Name)
+	if out == "" {
+		return errors.New("--" + exportOutFlag.Name + " is required")
+	}
+
+	gene := selectGenesis(ctx)
+	instanceDir := makeInstanceDir(ctx, gene)
+	mainDB := openMainDB(ctx, instanceDir)
+	defer mainDB.Close()
+	logDB := openLogDB(ctx, instanceDir)
+	defer logDB.Close()
+	chain := initChain(gene, mainDB, logDB)
+
+	from := uint32(ctx.Uint(exportFromFlag.Name))
+	to := chain.BestBlock().Header().Number()
+	if ctx.IsSet(exportToFlag.Name) {
+		to = uint32(ctx.Uint(exportToFlag.Name))
+	}
+	if from > to {
+		return errors.Errorf("invalid range [%v, %v], best block is %v", from, to, chain.BestBlock().Header().Number())
+	}
+
+	f, err := os.Create(out)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	var (
+		w  io.Writer = f
+		gw *gzip.Writer
+	)
+	if strings.HasSuffix(out, ".gz") {
+		gw = gzip.NewWriter(f)
+		w = gw
+	}
+
+	start := time.Now()
+	n, err := node.ExportBlocks(w, chain, from, to)
+	if err != nil {
+		return err
+	}
+	// the gzip footer is written on close, the file is truncated without it
+	if gw != nil {
+		if err := gw.Close(); err != nil {
+			return err
+		}
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	log.Info("exported blocks", "count", n, "from", from, "to", to, "file", out, "elapsed", time.Since(start))
+	return nil
+}
+
+func importAction(ctx *cli.Context) error {
+	initLogger(ctx)
+
+	in := ctx.String(importInFlag.Name)
+	if in == "" {
+		return errors.New("--" + importInFlag.Name + " is required")
+	}
+	f, err := os.Open(in)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	var r io.Reader = f
+	if strings.HasSuffix(in, ".gz") {
+		gr, err := gzip.NewReader(f)
+		if err != nil {
+			return err
+		}
+		defer gr.Close()
+		r = gr
+	}
+
+	gene := selectGenesis(ctx)
+	instanceDir := makeInstanceDir(ctx, gene)
+	mainDB := openMainDB(ctx, instanceDir)
+	defer mainDB.Close()
+	logDB := openLogDB(ctx, instanceDir)
+	defer logDB.Close()
+	chain := initChain(gene, mainDB, logDB)
+
+	master, blsCommon := loadNodeMaster(ctx)
+	loadPresetConfig(ctx)
+	meter.InitBlockChainConfig(gene.ID(), ctx.String(networkFlag.Name))
+	initMagic(ctx)
+	initDelegates := loadDelegates(ctx, blsCommon)
+
+	// the script engine and consensus reactor are needed to execute and validate blocks
+	stateCreator := state.NewCreator(mainDB)
+	script.NewScriptEngine(chain, stateCreator)
+	cons := consensus.NewConsensusReactor(ctx, chain, stateCreator, master.PrivateKey, master.PublicKey, consensusMagic, blsCommon, initDelegates)
+
+	start := time.Now()
+	n, err := node.ImportBlocks(r, chain, cons, logDB)
+	log.Info("imported blocks", "count", n, "best", chain.BestBlock().Header().Number(), "elapsed", time.Since(start))
+	return err
+}
@@ -0,0 +1,167 @@
+// Copyright (c) 2020 The Meter.io developers
+
+// Distributed under the GNU Lesser General Public License v3.0 software license, see the accompanying
+// file LICENSE or <https://www.gnu.org/licenses/lgpl-3.0.html>
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/pkg/errors"
+	cli "gopkg.in/urfave/cli.v1"
+)
+
+// withConfigFile returns the action which applies the config file to the flags of the command
+// before running the action.
+func withConfigFile(flags []cli.Flag, action func(*cli.Context) error) func(*cli.Context) error {
+	return func(ctx *cli.Context) error {
+		if err := loadConfigFile(ctx, flags); err != nil {
+			return err
+		}
+		return action(ctx)
+	}
+}
+
+// loadConfigFile applies the config file given by the config flag to the flags, which are
+// keyed by flag names. Flags set on the command line are left as they are, and keys of the
+// flags of other commands are skipped, so the same file serves the node and sub-commands.
+func loadConfigFile(ctx *cli.Context, flags []cli.Flag) error {
+	path := ctx.String(configFlag.Name)
+	if path == "" {
+		return nil
+	}
+	var values map[string]interface{}
+	if _, err := toml.DecodeFile(path, &values); err != nil {
+		return errors.Wrap(err, "load config file")
+	}
+
+	known := make(map[string]bool)
+	for _, flag := range allFlags() {
+		known[flagName(flag)] = true
+	}
+	byName := make(map[string]cli.Flag, len(flags))
+	for _, flag := range flags {
+		byName[flagName(flag)] = flag
+	}
+
+	// sorted to report errors deterministically
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		if !known[key] {
+			return fmt.Errorf("config file %v: unknown key %q", path, key)
+		}
+		flag, ok := byName[key]
+		if !ok || isFlagSet(ctx, flag) {
+			continue
+		}
+		if err := setFlag(ctx, flag, values[key]); err != nil {
+			return fmt.Errorf("config file %v: key %q: %v", path, key, err)
+		}
+	}
+	return nil
+}
+
+// flagName returns the long name of the flag, which is the key in the config file.
+func flagName(flag cli.Flag) string {
+	return strings.TrimSpace(strings.Split(flag.GetName(), ",")[0])
+}
+
+// isFlagSet returns whether the flag is set on the command line by any of its names.
+func isFlagSet(ctx *cli.Context, flag cli.Flag) bool {
+	for _, name := range strings.Split(flag.GetName(), ",") {
+		if ctx.IsSet(strings.TrimSpace(name)) {
+			return true
+		}
+	}
+	return false
+}
+
+func setFlag(ctx *cli.Context, flag cli.Flag, value interface{}) error {
+	if list, ok := value.([]interface{}); ok {
+		if _, ok := flag.(cli.StringSliceFlag); !ok {
+			return errors.New("list is not allowed")
+		}
+		for _, elem := range list {
+			str, err := scalarString(elem)
+			if err != nil {
+				return err
+			}
+			if err := ctx.Set(flagName(flag), str); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	str, err := scalarString(value)
+	if err != nil {
+		return err
+	}
+	return ctx.Set(flagName(flag), str)
+}
+
+func scalarString(value interface{}) (string, error) {
+	switch v := value.(type) {
+	case string:
+		return v, nil
+	case int64:
+		return strconv.FormatInt(v, 10), nil
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	default:
+		return "", fmt.Errorf("unsupported value %v", value)
+	}
+}
+
+// effectiveConfig returns the values of the flags keyed by flag names.
+func effectiveConfig(ctx *cli.Context, flags []cli.Flag) (map[string]interface{}, error) {
+	config := make(map[string]interface{}, len(flags))
+	for _, flag := range flags {
+		name := flagName(flag)
+		switch flag.(type) {
+		case cli.StringFlag:
+			config[name] = ctx.String(name)
+		case cli.IntFlag:
+			config[name] = ctx.Int(name)
+		case cli.Int64Flag:
+			config[name] = ctx.Int64(name)
+		case cli.UintFlag:
+			config[name] = ctx.Uint(name)
+		case cli.BoolFlag:
+			config[name] = ctx.Bool(name)
+		case cli.StringSliceFlag:
+			values := ctx.StringSlice(name)
+			if values == nil {
+				values = []string{}
+			}
+			config[name] = values
+		default:
+			return nil, fmt.Errorf("flag %v: unsupported flag type %T", name, flag)
+		}
+	}
+	return config, nil
+}
+
+func configDumpAction(ctx *cli.Context) error {
+	flags := allFlags()
+	if err := loadConfigFile(ctx, flags); err != nil {
+		return err
+	}
+	config, err := effectiveConfig(ctx, flags)
+	if err != nil {
+		return err
+	}
+	return toml.NewEncoder(os.Stdout).Encode(config)
+}
@@ -7,9 +7,13 @@ package main
 
 import (
 	"github.com/inconshreveable/log15"
+	"github.com/meterio/meter-pov/consensus"
 	cli "gopkg.in/urfave/cli.v1"
 )
 
+// masterKeyPasswordEnv is the environment variable holding the master key passphrase.
+const masterKeyPasswordEnv = "METER_MASTER_KEY_PASSWORD"
+
 var (
 	networkFlag = cli.StringFlag{
 		Name:  "network",
@@ -68,6 +72,11 @@ var (
 		Value: 11235,
 		Usage: "P2P network listening port",
 	}
+	consensusPortFlag = cli.IntFlag{
+		Name:  "consensus-port",
+		Value: consensus.DefaultConsensusPort,
+		Usage: "consensus and observe service listening port, should match the port registered with the candidate",
+	}
 	natFlag = cli.StringFlag{
 		Name:  "nat",
 		Value: "any",
@@ -94,6 +103,61 @@ var (
 		Name:  "export",
 		Usage: "export master key to keystore",
 	}
+	encryptMasterKeyFlag = cli.BoolFlag{
+		Name:  "encrypt",
+		Usage: "encrypt the plain text master key with a passphrase",
+	}
+	changePasswordFlag = cli.BoolFlag{
+		Name:  "change-password",
+		Usage: "change the passphrase of the encrypted master key",
+	}
+	passwordFileFlag = cli.StringFlag{
+		Name:  "password-file",
+		Usage: "path to the file holding the master key passphrase (env " + masterKeyPasswordEnv + " is used if not set)",
+	}
+	exportFromFlag = cli.UintFlag{
+		Name:  "from",
+		Usage: "first block number to export",
+	}
+	exportToFlag = cli.UintFlag{
+		Name:  "to",
+		Usage: "last block number to export (default: best block)",
+	}
+	exportOutFlag = cli.StringFlag{
+		Name:  "out",
+		Usage: "file to write the exported blocks, gzipped if ends with .gz",
+	}
+	importInFlag = cli.StringFlag{
+		Name:  "in",
+		Usage: "file to read the blocks to import, gzipped if ends with .gz",
+	}
+	pruneFlag = cli.BoolFlag{
+		Name:  "prune",
+		Usage: "prune unreachable state in background",
+	}
+	pruneKeepBlocksFlag = cli.UintFlag{
+		Name:  "prune-keep-blocks",
+		Value: 10000,
+		Usage: "number of recent blocks whose state is kept when pruning",
+	}
+	pruneKeepKBlocksFlag = cli.UintFlag{
+		Name:  "prune-keep-kblocks",
+		Value: 10,
+		Usage: "number of recent K-blocks whose state is kept as checkpoints when pruning",
+	}
+	traceIndexFlag = cli.BoolFlag{
+		Name:  "trace-index",
+		Usage: "record call traces of committed blocks, to serve trace queries from the index",
+	}
+	metricsAddrFlag = cli.StringFlag{
+		Name:  "metrics-addr",
+		Usage: "serve prometheus metrics on a dedicated listener, besides /metrics of the observe service",
+		Value: "",
+	}
+	configFlag = cli.StringFlag{
+		Name:  "config",
+		Usage: "path to the TOML config file keyed by flag names, flags on the command line take precedence",
+	}
 	generateKFrameFlag = cli.BoolFlag{
 		Name:  "gen-kframe",
 		Usage: "start a coroutine for kframe generation (FOR TEST ONLY)",
@@ -174,3 +238,112 @@ var (
 		Value: "meterio.key",
 	}
 )
+
+// nodeFlags are the flags of the node, which can also be set in the config file.
+var nodeFlags = []cli.Flag{
+	networkFlag,
+	dataDirFlag,
+	beneficiaryFlag,
+	apiAddrFlag,
+	apiCorsFlag,
+	apiTimeoutFlag,
+	apiCallGasLimitFlag,
+	apiBacktraceLimitFlag,
+	verbosityFlag,
+	maxPeersFlag,
+	p2pPortFlag,
+	consensusPortFlag,
+	natFlag,
+	peersFlag,
+	forceLastKFrameFlag,
+	generateKFrameFlag,
+	skipSignatureCheckFlag,
+	//powNodeFlag,
+	//powPortFlag,
+	//powUserFlag,
+	//powPassFlag,
+	noDiscoverFlag,
+	minCommitteeSizeFlag,
+	maxCommitteeSizeFlag,
+	maxDelegateSizeFlag,
+	discoServerFlag,
+	discoTopicFlag,
+	initCfgdDelegatesFlag,
+	epochBlockCountFlag,
+	httpsCertFlag,
+	httpsKeyFlag,
+	passwordFileFlag,
+	pruneFlag,
+	pruneKeepBlocksFlag,
+	pruneKeepKBlocksFlag,
+	traceIndexFlag,
+	metricsAddrFlag,
+}
+
+// flags of the sub-commands, which can also be set in the config file
+var (
+	masterKeyFlags = []cli.Flag{
+		dataDirFlag,
+		importMasterKeyFlag,
+		exportMasterKeyFlag,
+		encryptMasterKeyFlag,
+		changePasswordFlag,
+		passwordFileFlag,
+	}
+	enodeIDFlags = []cli.Flag{
+		dataDirFlag,
+		p2pPortFlag,
+	}
+	publicKeyFlags = []cli.Flag{
+		dataDirFlag,
+		passwordFileFlag,
+	}
+	exportFlags = []cli.Flag{
+		networkFlag,
+		dataDirFlag,
+		exportFromFlag,
+		exportToFlag,
+		exportOutFlag,
+		verbosityFlag,
+	}
+	importFlags = []cli.Flag{
+		networkFlag,
+		dataDirFlag,
+		importInFlag,
+		passwordFileFlag,
+		verbosityFlag,
+		minCommitteeSizeFlag,
+		maxCommitteeSizeFlag,
+		maxDelegateSizeFlag,
+		discoTopicFlag,
+		discoServerFlag,
+	}
+	pruneFlags = []cli.Flag{
+		networkFlag,
+		dataDirFlag,
+		pruneKeepBlocksFlag,
+		pruneKeepKBlocksFlag,
+		verbosityFlag,
+	}
+	peersFlags = []cli.Flag{
+		networkFlag,
+		dataDirFlag,
+	}
+)
+
+// allFlags returns the flags of the node and of all sub-commands, each flag once.
+func allFlags() []cli.Flag {
+	var (
+		flags []cli.Flag
+		seen  = make(map[string]bool)
+	)
+	for _, list := range [][]cli.Flag{nodeFlags, masterKeyFlags, enodeIDFlags, publicKeyFlags, exportFlags, importFlags, pruneFlags, peersFlags} {
+		for _, flag := range list {
+			if name := flagName(flag); !seen[name] {
+				seen[name] = true
+				flags = append(flags, flag)
+			}
+		}
+	}
+	return flags
+}
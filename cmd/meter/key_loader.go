@@ -17,7 +17,9 @@ import (
 
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/meterio/meter-pov/consensus"
+	"github.com/meterio/meter-pov/crypto/keystore"
 	bls "github.com/meterio/meter-pov/crypto/multi_sig"
+	"github.com/pkg/errors"
 	cli "gopkg.in/urfave/cli.v1"
 )
 
@@ -59,6 +61,10 @@ type KeyLoader struct {
 	blsPrivKey   *bls.PrivateKey
 	blsPubKey    *bls.PublicKey
 
+	// password protects the master key file, empty means plain text
+	password     string
+	readPassword func() (string, error)
+
 	updated bool
 }
 
@@ -79,11 +85,82 @@ func NewKeyLoader(ctx *cli.Context) *KeyLoader {
 		publicPath:  publicPath,
 		masterBytes: masterBytes,
 		publicBytes: publicBytes,
+		readPassword: func() (string, error) {
+			return masterKeyPassword(ctx, "Enter master key passphrase: ")
+		},
 
 		updated: false,
 	}
 }
 
+// masterKeyPassword reads the master key passphrase from the password file if given,
+// then from the environment variable, and finally prompts on the terminal.
+func masterKeyPassword(ctx *cli.Context, prompt string) (string, error) {
+	if path := ctx.String(passwordFileFlag.Name); path != "" {
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return "", err
+		}
+		return strings.TrimRight(string(data), "\r\n"), nil
+	}
+	if password, ok := os.LookupEnv(masterKeyPasswordEnv); ok {
+		return password, nil
+	}
+	return readPasswordFromNewTTY(prompt)
+}
+
+// IsEncrypted returns whether the master key file is password protected.
+func (k *KeyLoader) IsEncrypted() bool {
+	return keystore.IsEncrypted(k.masterBytes)
+}
+
+// SetPassword sets the passphrase used to encrypt the master key on next save.
+// An empty passphrase stores the master key in plain text.
+func (k *KeyLoader) SetPassword(password string) {
+	k.password = password
+}
+
+// Save writes the loaded keys back to the master and public key files.
+func (k *KeyLoader) Save() error {
+	system, err := getBlsSystem()
+	if err != nil {
+		return err
+	}
+	return k.saveKeys(*system)
+}
+
+// Export encrypts the loaded keys with the passphrase into the master keystore format.
+func (k *KeyLoader) Export(password string) ([]byte, error) {
+	system, err := getBlsSystem()
+	if err != nil {
+		return nil, err
+	}
+	return keystore.Encrypt(&keystore.MasterKey{
+		ECDSA: crypto.FromECDSA(k.ecdsaPrivKey),
+		BLS:   system.PrivKeyToBytes(*k.blsPrivKey),
+	}, password, keystore.StandardScryptN, keystore.StandardScryptP)
+}
+
+// unlock decrypts the master key file into the plain text layout, so keys could be validated as usual.
+func (k *KeyLoader) unlock() error {
+	if !k.IsEncrypted() {
+		return nil
+	}
+	password, err := k.readPassword()
+	if err != nil {
+		return err
+	}
+	key, err := keystore.Decrypt(k.masterBytes, password)
+	if err != nil {
+		return err
+	}
+	k.password = password
+	k.masterBytes = []byte(strings.Join([]string{
+		b64.StdEncoding.EncodeToString(key.ECDSA),
+		b64.StdEncoding.EncodeToString(key.BLS)}, ":::"))
+	return nil
+}
+
 func (k *KeyLoader) genECDSA() error {
 	k.updated = true
 	key, err := crypto.GenerateKey()
@@ -147,8 +224,7 @@ func (k *KeyLoader) genBls() error {
 
 func (k *KeyLoader) validateBls() error {
 	split := strings.Split(string(k.masterBytes), ":::")
-	psplit := strings.Split(string(k.publicBytes), ":::")
-	if len(split) < 2 || len(psplit) < 2 {
+	if len(split) < 2 {
 		return k.genBls()
 	}
 	system, err := getBlsSystem()
@@ -159,20 +235,25 @@ func (k *KeyLoader) validateBls() error {
 	if err != nil {
 		return k.genBls()
 	}
-	pubBytes, err := b64.StdEncoding.DecodeString(psplit[1])
-	if err != nil {
-		return k.genBls()
-	}
 	privKey, err := system.PrivKeyFromBytes(privBytes)
 	if err != nil {
 		return k.genBls()
 	}
-	pubKey, err := system.PubKeyFromBytes(pubBytes)
-	if err != nil || !verifyBls(privKey, pubKey) {
-		return k.genBls()
-	}
 	k.blsPrivKey = &privKey
+
+	// public key is derived from the private key if missing or mismatched, e.g. after an import
+	psplit := strings.Split(string(k.publicBytes), ":::")
+	if len(psplit) >= 2 {
+		if pubBytes, err := b64.StdEncoding.DecodeString(psplit[1]); err == nil {
+			if pubKey, err := system.PubKeyFromBytes(pubBytes); err == nil && verifyBls(privKey, pubKey) {
+				k.blsPubKey = &pubKey
+				return nil
+			}
+		}
+	}
+	pubKey := system.PubKeyFromPrivKey(privKey)
 	k.blsPubKey = &pubKey
+	k.updated = true
 	return nil
 }
 
@@ -190,7 +271,19 @@ func (k *KeyLoader) saveKeys(system bls.System) error {
 	pub := strings.Join([]string{ecdsaPubB64, blsPubB64}, ":::")
 	k.masterBytes = []byte(priv)
 	k.publicBytes = []byte(pub)
-	err := ioutil.WriteFile(k.masterPath, []byte(priv+"\n"), 0600)
+
+	content := []byte(priv + "\n")
+	if k.password != "" {
+		data, err := keystore.Encrypt(&keystore.MasterKey{
+			ECDSA: ecdsaPrivBytes,
+			BLS:   blsPrivBytes,
+		}, k.password, keystore.StandardScryptN, keystore.StandardScryptP)
+		if err != nil {
+			return err
+		}
+		content = append(data, '\n')
+	}
+	err := ioutil.WriteFile(k.masterPath, content, 0600)
 	if err != nil {
 		return err
 	}
@@ -199,6 +292,11 @@ func (k *KeyLoader) saveKeys(system bls.System) error {
 }
 
 func (k *KeyLoader) Load() (*ecdsa.PrivateKey, *ecdsa.PublicKey, *consensus.BlsCommon, error) {
+	// never fall through to key generation with an encrypted master key we could not open
+	if err := k.unlock(); err != nil {
+		return nil, nil, nil, errors.WithMessage(err, "unlock master key")
+	}
+
 	err := k.validateECDSA()
 	if err != nil {
 		fmt.Println("could not validate ecdsa keys, error:", err)
@@ -239,8 +337,8 @@ func (k *KeyLoader) Load() (*ecdsa.PrivateKey, *ecdsa.PublicKey, *consensus.BlsC
 		err := k.saveKeys(system)
 		if err != nil {
 			fmt.Println("save keys error:", err)
+			return nil, nil, nil, err
 		}
-
 	}
 
 	blsCommon := consensus.NewBlsCommonFromParams(*k.blsPubKey, *k.blsPrivKey, system, params, pairing)
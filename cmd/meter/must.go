@@ -7,6 +7,7 @@ package main
 
 import (
 	"crypto/ecdsa"
+	"crypto/sha256"
 	"crypto/tls"
 	b64 "encoding/base64"
 	"encoding/hex"
@@ -20,9 +21,11 @@ import (
 	"os"
 	"path"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"time"
 
+	"github.com/btcsuite/btcd/chaincfg"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/fdlimit"
 	"github.com/ethereum/go-ethereum/crypto"
@@ -30,6 +33,7 @@ import (
 	"github.com/ethereum/go-ethereum/p2p/nat"
 	"github.com/ethereum/go-ethereum/rlp"
 	"github.com/inconshreveable/log15"
+	"github.com/meterio/meter-pov/api/doc"
 	api_node "github.com/meterio/meter-pov/api/node"
 	api_utils "github.com/meterio/meter-pov/api/utils"
 	"github.com/meterio/meter-pov/chain"
@@ -40,6 +44,7 @@ import (
 	"github.com/meterio/meter-pov/consensus"
 	bls "github.com/meterio/meter-pov/crypto/multi_sig"
 	"github.com/meterio/meter-pov/genesis"
+	"github.com/meterio/meter-pov/kv"
 	"github.com/meterio/meter-pov/logdb"
 	"github.com/meterio/meter-pov/lvldb"
 	"github.com/meterio/meter-pov/meter"
@@ -47,6 +52,7 @@ import (
 	"github.com/meterio/meter-pov/powpool"
 	"github.com/meterio/meter-pov/preset"
 	"github.com/meterio/meter-pov/state"
+	"github.com/meterio/meter-pov/tracedb"
 	"github.com/meterio/meter-pov/txpool"
 	"github.com/meterio/meter-pov/types"
 	cli "gopkg.in/urfave/cli.v1"
@@ -69,6 +75,37 @@ func initLogger(ctx *cli.Context) {
 	ethlog.Root().SetHandler(ethLogHandler)
 }
 
+// loadPresetConfig overrides committee and discovery flags with the preset config of the network.
+func loadPresetConfig(ctx *cli.Context) {
+	if "test" == ctx.String(networkFlag.Name) {
+		config := preset.TestPresetConfig
+		ctx.Set("committee-min-size", strconv.Itoa(config.CommitteeMinSize))
+		ctx.Set("committee-max-size", strconv.Itoa(config.CommitteeMaxSize))
+		ctx.Set("delegate-max-size", strconv.Itoa(config.DelegateMaxSize))
+		ctx.Set("disco-topic", config.DiscoTopic)
+		ctx.Set("disco-server", config.DiscoServer)
+	} else if "main" == ctx.String(networkFlag.Name) {
+		config := preset.MainPresetConfig
+		ctx.Set("committee-min-size", strconv.Itoa(config.CommitteeMinSize))
+		ctx.Set("committee-max-size", strconv.Itoa(config.CommitteeMaxSize))
+		ctx.Set("delegate-max-size", strconv.Itoa(config.DelegateMaxSize))
+		ctx.Set("disco-topic", config.DiscoTopic)
+		ctx.Set("disco-server", config.DiscoServer)
+	}
+}
+
+// initMagic sets p2p and consensus magic from version and disco topic, returns the topic.
+func initMagic(ctx *cli.Context) string {
+	topic := ctx.String("disco-topic")
+	version := doc.Version()
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%v %v", version, topic)))
+
+	// Split magic to p2p_magic and consensus_magic
+	copy(p2pMagic[:], sum[:4])
+	copy(consensusMagic[:], sum[:4])
+	return topic
+}
+
 func selectGenesis(ctx *cli.Context) *genesis.Genesis {
 	network := ctx.String(networkFlag.Name)
 	switch network {
@@ -92,6 +129,19 @@ func selectGenesis(ctx *cli.Context) *genesis.Genesis {
 	}
 }
 
+// powChainParams returns params of the pow chain mined for the network, private networks
+// are expected to run a regression test pow chain.
+func powChainParams(ctx *cli.Context) *chaincfg.Params {
+	switch ctx.String(networkFlag.Name) {
+	case "main":
+		return &chaincfg.MainNetParams
+	case "test":
+		return &chaincfg.TestNet3Params
+	default:
+		return &chaincfg.RegressionNetParams
+	}
+}
+
 type Delegate1 struct {
 	Name        string           `json:"name"`
 	Address     string           `json:"address"`
@@ -255,7 +305,35 @@ func openLogDB(ctx *cli.Context, dataDir string) *logdb.LogDB {
 	return db
 }
 
-func initChain(gene *genesis.Genesis, mainDB *lvldb.LevelDB, logDB *logdb.LogDB) *chain.Chain {
+// openTraceDB opens the trace db if trace indexing is enabled, otherwise returns nil.
+func openTraceDB(ctx *cli.Context, dataDir string) *tracedb.TraceDB {
+	if !ctx.Bool(traceIndexFlag.Name) {
+		return nil
+	}
+	dir := filepath.Join(dataDir, "traces.db")
+	db, err := tracedb.New(dir)
+	if err != nil {
+		fatal(fmt.Sprintf("open trace database [%v]: %v", dir, err))
+	}
+	return db
+}
+
+// startTraceIndexer starts indexing traces if traceDB is not nil. Blocks are traced on the state of
+// their parents, so when pruning, an empty index starts from the best block instead of genesis.
+func startTraceIndexer(ctx *cli.Context, chain *chain.Chain, stateCreator *state.Creator, traceDB *tracedb.TraceDB) func() {
+	if traceDB == nil {
+		return func() {}
+	}
+	var from uint32
+	if ctx.Bool(pruneFlag.Name) {
+		from = chain.BestBlock().Header().Number()
+	}
+	indexer := tracedb.NewIndexer(chain, tracedb.NewBlockTracer(chain, stateCreator), traceDB, from)
+	indexer.Start()
+	return indexer.Stop
+}
+
+func initChain(gene *genesis.Genesis, mainDB kv.GetPutter, logDB *logdb.LogDB) *chain.Chain {
 	genesisBlock, genesisEvents, err := gene.Build(state.NewCreator(mainDB))
 	if err != nil {
 		fatal("build genesis block: ", err)
@@ -471,7 +549,7 @@ func (d *Dispatcher) handlePeers(w http.ResponseWriter, r *http.Request) {
 }
 
 func startObserveServer(ctx *cli.Context, cons *consensus.ConsensusReactor, complexPubkey string, nw probe.Network, chain *chain.Chain, allowedOrigins string) (string, func()) {
-	addr := ":8670"
+	addr := fmt.Sprintf(":%v", ctx.Int(consensusPortFlag.Name))
 	listener, err := net.Listen("tcp", addr)
 	if err != nil {
 		fatal(fmt.Sprintf("listen observe addr [%v]: %v", addr, err))
@@ -484,9 +562,11 @@ func startObserveServer(ctx *cli.Context, cons *consensus.ConsensusReactor, comp
 	mux.HandleFunc("/probe/pubkey", probe.HandlePubkey)
 	mux.HandleFunc("/probe/peers", probe.HandlePeers)
 
-	// dispatch the msg to reactor/pacemaker
+	// dispatch the msg to reactor/pacemaker, the http posts are only accepted before the
+	// consensus transport fork, authenticated connections afterwards
 	mux.HandleFunc("/committee", cons.ReceiveCommitteeMsg)
 	mux.HandleFunc("/pacemaker", cons.ReceivePacemakerMsg)
+	mux.HandleFunc(consensus.TransportPath, cons.ReceiveConsensusConn)
 
 	origins := strings.Split(strings.TrimSpace(allowedOrigins), ",")
 	for i, o := range origins {
@@ -522,6 +602,38 @@ func startObserveServer(ctx *cli.Context, cons *consensus.ConsensusReactor, comp
 	}
 }
 
+// startMetricsServer serves the prometheus metrics on the dedicated listener if configured.
+func startMetricsServer(ctx *cli.Context) (string, func()) {
+	addr := ctx.String(metricsAddrFlag.Name)
+	if addr == "" {
+		return "nil", func() {}
+	}
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		fatal(fmt.Sprintf("listen metrics addr [%v]: %v", addr, err))
+	}
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.Handler())
+	srv := &http.Server{
+		Handler:      mux,
+		ReadTimeout:  5 * time.Second,
+		WriteTimeout: 10 * time.Second,
+		IdleTimeout:  120 * time.Second,
+	}
+	var goes co.Goes
+	goes.Go(func() {
+		if err := srv.Serve(listener); err != nil && err != http.ErrServerClosed {
+			fmt.Println("metrics server stopped, error:", err)
+		}
+	})
+	return "http://" + listener.Addr().String() + "/metrics", func() {
+		if err := srv.Close(); err != nil {
+			fmt.Println("can't close metrics http service, error:", err)
+		}
+		goes.Wait()
+	}
+}
+
 func startAPIServer(ctx *cli.Context, handler http.Handler, genesisID meter.Bytes32) (string, func()) {
 	addr := ctx.String(apiAddrFlag.Name)
 	listener, err := net.Listen("tcp", addr)
@@ -650,6 +762,7 @@ func printStartupMessage(
 	apiURL string,
 	powApiURL string,
 	observeURL string,
+	metricsURL string,
 ) {
 	bestBlock := chain.BestBlock()
 
@@ -666,6 +779,7 @@ func printStartupMessage(
     API portal      [ %v ]
     POW API portal  [ %v ]
     Observe service [ %v ]
+    Metrics         [ %v ]
 `,
 		common.MakeName("Meter", fullVersion()),
 		topic,
@@ -682,7 +796,7 @@ func printStartupMessage(
 			return master.Beneficiary.String()
 		}(),
 		dataDir,
-		apiURL, powApiURL, observeURL)
+		apiURL, powApiURL, observeURL, metricsURL)
 }
 
 func openMemMainDB() *lvldb.LevelDB {
@@ -0,0 +1,117 @@
+// Copyright (c) 2020 The Meter.io developers
+
+// Distributed under the GNU Lesser General Public License v3.0 software license, see the accompanying
+// file LICENSE or <https://www.gnu.org/licenses/lgpl-3.0.html>
+
+package node
+
+import (
+	"io"
+	"time"
+
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/meterio/meter-pov/block"
+	"github.com/meterio/meter-pov/chain"
+	"github.com/meterio/meter-pov/consensus"
+	"github.com/meterio/meter-pov/logdb"
+	"github.com/meterio/meter-pov/meter"
+	"github.com/meterio/meter-pov/tx"
+	"github.com/pkg/errors"
+)
+
+// progress is logged every progressInterval blocks during export and import
+const progressInterval = 10000
+
+// ExportBlocks writes trunk blocks in range [from, to] to w as a stream of RLP encoded blocks,
+// which include the quorum cert and the k-block data. It returns the number of exported blocks.
+func ExportBlocks(w io.Writer, c *chain.Chain, from, to uint32) (int, error) {
+	n := 0
+	for num := from; num <= to; num++ {
+		blk, err := c.GetTrunkBlock(num)
+		if err != nil {
+			return n, errors.Wrapf(err, "get block %v", num)
+		}
+		if err := rlp.Encode(w, blk); err != nil {
+			return n, errors.Wrapf(err, "encode block %v", num)
+		}
+		n++
+		if n%progressInterval == 0 {
+			log.Info("exporting blocks", "exported", n, "number", num)
+		}
+		// avoid overflow when to is math.MaxUint32
+		if num == to {
+			break
+		}
+	}
+	return n, nil
+}
+
+// ImportBlocks reads the RLP encoded block stream written by ExportBlocks, validates each block with the
+// consensus reactor and adds it to the chain. Blocks already in the chain are skipped.
+// It returns the number of imported blocks.
+func ImportBlocks(r io.Reader, c *chain.Chain, cons *consensus.ConsensusReactor, logDB *logdb.LogDB) (int, error) {
+	stream := rlp.NewStream(r, 0)
+	n := 0
+	for {
+		var blk block.Block
+		if err := stream.Decode(&blk); err != nil {
+			if err == io.EOF {
+				return n, nil
+			}
+			return n, errors.Wrap(err, "decode block")
+		}
+
+		num := blk.Header().Number()
+		if num <= c.BestBlock().Header().Number() {
+			if _, err := c.GetTrunkBlockHeader(num); err == nil {
+				continue
+			}
+		}
+
+		stage, receipts, err := cons.Process(&blk, uint64(time.Now().Unix()))
+		if err != nil {
+			if consensus.IsKnownBlock(err) {
+				continue
+			}
+			return n, errors.Wrapf(err, "process block %v", num)
+		}
+		if _, err := stage.Commit(); err != nil {
+			return n, errors.Wrapf(err, "commit state of block %v", num)
+		}
+		if _, err := saveBlock(c, logDB, &blk, receipts); err != nil {
+			return n, errors.Wrapf(err, "save block %v", num)
+		}
+		cons.RefreshCurHeight()
+		n++
+		if n%progressInterval == 0 {
+			log.Info("importing blocks", "imported", n, "number", num)
+		}
+	}
+}
+
+// saveBlock adds the block to the chain, and writes its events and transfers to the log db.
+func saveBlock(c *chain.Chain, logDB *logdb.LogDB, newBlock *block.Block, receipts tx.Receipts) (*chain.Fork, error) {
+	fork, err := c.AddBlock(newBlock, receipts, true)
+	if err != nil {
+		return nil, err
+	}
+
+	forkIDs := make([]meter.Bytes32, 0, len(fork.Branch))
+	for _, header := range fork.Branch {
+		forkIDs = append(forkIDs, header.ID())
+	}
+
+	batch := logDB.Prepare(newBlock.Header())
+	for i, tx := range newBlock.Transactions() {
+		origin, _ := tx.Signer()
+		txBatch := batch.ForTransaction(tx.ID(), origin)
+		for _, output := range receipts[i].Outputs {
+			txBatch.Insert(output.Events, output.Transfers)
+		}
+	}
+
+	if err := batch.Commit(forkIDs...); err != nil {
+		return nil, errors.Wrap(err, "commit logs")
+	}
+	return fork, nil
+}
@@ -0,0 +1,79 @@
+// Copyright (c) 2020 The Meter.io developers
+
+// Distributed under the GNU Lesser General Public License v3.0 software license, see the accompanying
+// file LICENSE or <https://www.gnu.org/licenses/lgpl-3.0.html>
+
+package node
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/meterio/meter-pov/block"
+	"github.com/meterio/meter-pov/chain"
+	"github.com/meterio/meter-pov/chain/testchain"
+	"github.com/meterio/meter-pov/consensus"
+	"github.com/meterio/meter-pov/logdb"
+	"github.com/meterio/meter-pov/lvldb"
+	"github.com/meterio/meter-pov/meter"
+	"github.com/meterio/meter-pov/state"
+	"github.com/meterio/meter-pov/tx"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestChain(t *testing.T) (*chain.Chain, *state.Creator, *logdb.LogDB) {
+	db, _ := lvldb.NewMem()
+	c, stateC, err := testchain.New(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	logDB, err := logdb.NewMem()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c, stateC, logDB
+}
+
+func TestExportImportBlocks(t *testing.T) {
+	src, stateC, logDB := newTestChain(t)
+
+	recipient := meter.BytesToAddress([]byte("recipient"))
+	trx := testchain.NewTx(src.Tag(), tx.NewClause(&recipient).WithToken(meter.STPT).WithValue(big.NewInt(1)), 300000, 1)
+	blk, receipts, err := testchain.Pack(src, stateC, trx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	blk.SetMagic(block.BlockMagicVersion1)
+	blk.SetQC(&block.QuorumCert{QCHeight: 1, QCRound: 1, EpochID: 1})
+	if _, err := saveBlock(src, logDB, blk, receipts); err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	n, err := ExportBlocks(&buf, src, 0, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, 2, n)
+
+	dst, dstStateC, dstLogDB := newTestChain(t)
+	key, _ := crypto.GenerateKey()
+	cons := consensus.NewConsensusReactor(nil, dst, dstStateC, key, &key.PublicKey, [4]byte{}, consensus.NewBlsCommon(), nil)
+
+	n, err = ImportBlocks(bytes.NewReader(buf.Bytes()), dst, cons, dstLogDB)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, 1, n)
+	assert.Equal(t, blk.Header().ID(), dst.BestBlock().Header().ID())
+	assert.Equal(t, blk.QC.EpochID, dst.BestBlock().QC.EpochID)
+
+	// importing again skips known blocks
+	n, err = ImportBlocks(bytes.NewReader(buf.Bytes()), dst, cons, dstLogDB)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, 0, n)
+}
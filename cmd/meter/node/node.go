@@ -31,7 +31,7 @@ import (
 	"github.com/meterio/meter-pov/state"
 	"github.com/meterio/meter-pov/tx"
 	"github.com/meterio/meter-pov/txpool"
-	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
 )
 
 var log = log15.New("pkg", "node")
@@ -75,6 +75,9 @@ func New(
 	cons *consensus.ConsensusReactor,
 	script *script.ScriptEngine,
 ) *Node {
+	prometheus.Register(blockImportDuration)
+	prometheus.Register(blockImportCounter)
+
 	node := &Node{
 		packer:      packer.New(chain, stateCreator, master.Address(), master.Beneficiary),
 		cons:        cons,
@@ -276,15 +279,19 @@ func (n *Node) processBlock(blk *block.Block, stats *blockStats) (bool, error) {
 		switch {
 		case consensus.IsKnownBlock(err):
 			stats.UpdateIgnored(1)
+			blockImportCounter.WithLabelValues("ignored").Inc()
 			return false, nil
 		case consensus.IsFutureBlock(err) || consensus.IsParentMissing(err):
 			stats.UpdateQueued(1)
+			blockImportCounter.WithLabelValues("queued").Inc()
+			return false, err
 		case consensus.IsCritical(err):
 			msg := fmt.Sprintf(`failed to process block due to consensus failure \n%v\n`, blk.Header())
 			log.Error(msg, "err", err)
 		default:
 			log.Error("failed to process block", "err", err)
 		}
+		blockImportCounter.WithLabelValues("failed").Inc()
 		return false, err
 	}
 
@@ -304,6 +311,9 @@ func (n *Node) processBlock(blk *block.Block, stats *blockStats) (bool, error) {
 	}
 	commitElapsed := mclock.Now() - startTime - execElapsed
 	stats.UpdateProcessed(1, len(receipts), execElapsed, commitElapsed, blk.Header().GasUsed())
+	blockImportDuration.WithLabelValues("exec").Observe(time.Duration(execElapsed).Seconds())
+	blockImportDuration.WithLabelValues("commit").Observe(time.Duration(commitElapsed).Seconds())
+	blockImportCounter.WithLabelValues("processed").Inc()
 	n.processFork(fork)
 
 	// XXX: shortcut to refresh height
@@ -338,35 +348,10 @@ func (n *Node) commitBlock(newBlock *block.Block, receipts tx.Receipts) (*chain.
 	defer n.commitLock.Unlock()
 
 	// fmt.Println("Calling AddBlock from node.commitBlock, newBlock=", newBlock.Header().ID())
-	fork, err := n.chain.AddBlock(newBlock, receipts, true)
+	fork, err := saveBlock(n.chain, n.logDB, newBlock, receipts)
 	if err != nil {
 		return nil, err
 	}
-
-	// if meter.IsMainNet() {
-	//if newBlock.Header().Number() == meter.TeslaMainnetStartNum {
-	// if newBlock.Header().Number() == 0 {
-	// script.EnterTeslaForkInit()
-	// }
-	// }
-
-	forkIDs := make([]meter.Bytes32, 0, len(fork.Branch))
-	for _, header := range fork.Branch {
-		forkIDs = append(forkIDs, header.ID())
-	}
-
-	batch := n.logDB.Prepare(newBlock.Header())
-	for i, tx := range newBlock.Transactions() {
-		origin, _ := tx.Signer()
-		txBatch := batch.ForTransaction(tx.ID(), origin)
-		for _, output := range receipts[i].Outputs {
-			txBatch.Insert(output.Events, output.Transfers)
-		}
-	}
-
-	if err := batch.Commit(forkIDs...); err != nil {
-		return nil, errors.Wrap(err, "commit logs")
-	}
 	return fork, nil
 }
 
@@ -0,0 +1,20 @@
+// Copyright (c) 2020 The Meter.io developers
+
+// Distributed under the GNU Lesser General Public License v3.0 software license, see the accompanying
+// file LICENSE or <https://www.gnu.org/licenses/lgpl-3.0.html>
+
+package node
+
+import "github.com/prometheus/client_golang/prometheus"
+
+var (
+	blockImportDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "block_import_duration_seconds",
+		Help:    "Time taken to import a block by stage (exec, commit)",
+		Buckets: prometheus.ExponentialBuckets(0.001, 2, 14),
+	}, []string{"stage"})
+	blockImportCounter = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "blocks_imported_total",
+		Help: "Counter of blocks received by import result (processed, ignored, queued, failed)",
+	}, []string{"result"})
+)
@@ -0,0 +1,64 @@
+// Copyright (c) 2020 The Meter.io developers
+
+// Distributed under the GNU Lesser General Public License v3.0 software license, see the accompanying
+// file LICENSE or <https://www.gnu.org/licenses/lgpl-3.0.html>
+
+package main
+
+import (
+	"context"
+	"path/filepath"
+	"time"
+
+	"github.com/meterio/meter-pov/chain"
+	"github.com/meterio/meter-pov/pruner"
+	cli "gopkg.in/urfave/cli.v1"
+)
+
+func pruneOptions(ctx *cli.Context, instanceDir string) pruner.Options {
+	keepBlocks := uint32(ctx.Uint(pruneKeepBlocksFlag.Name))
+	return pruner.Options{
+		KeepBlocks:  keepBlocks,
+		KeepKBlocks: uint32(ctx.Uint(pruneKeepKBlocksFlag.Name)),
+		Interval:    keepBlocks,
+		MarkDir:     filepath.Join(instanceDir, "prune-marks.db"),
+	}
+}
+
+// startPruner starts the online pruner if guard is not nil.
+func startPruner(ctx *cli.Context, chain *chain.Chain, guard *pruner.Guard, instanceDir string) func() {
+	if guard == nil {
+		return func() {}
+	}
+	opts := pruneOptions(ctx, instanceDir)
+	opts.GracePeriod = time.Minute
+	p := pruner.New(chain, guard, opts)
+	p.Start()
+	return p.Stop
+}
+
+func pruneAction(ctx *cli.Context) error {
+	initLogger(ctx)
+
+	gene := selectGenesis(ctx)
+	instanceDir := makeInstanceDir(ctx, gene)
+	mainDB := openMainDB(ctx, instanceDir)
+	defer mainDB.Close()
+	logDB := openLogDB(ctx, instanceDir)
+	defer logDB.Close()
+	chain := initChain(gene, mainDB, logDB)
+
+	p := pruner.New(chain, pruner.NewGuard(mainDB), pruneOptions(ctx, instanceDir))
+	stats, err := p.Prune(context.Background())
+	if err != nil {
+		return err
+	}
+	log.Info("pruned", "best", chain.BestBlock().Header().Number(), "marked", stats.Marked, "swept", stats.Swept, "elapsed", stats.Elapsed)
+
+	start := time.Now()
+	if err := mainDB.Compact(); err != nil {
+		return err
+	}
+	log.Info("compacted database", "elapsed", time.Since(start))
+	return nil
+}
@@ -0,0 +1,80 @@
+// Copyright (c) 2020 The Meter.io developers
+
+// Distributed under the GNU Lesser General Public License v3.0 software license, see the accompanying
+// file LICENSE or <https://www.gnu.org/licenses/lgpl-3.0.html>
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/meterio/meter-pov/script"
+	"github.com/pkg/errors"
+	cli "gopkg.in/urfave/cli.v1"
+)
+
+// scriptDecodeAction prints the JSON description of the clause data given as the argument.
+func scriptDecodeAction(ctx *cli.Context) error {
+	arg, err := scriptArg(ctx)
+	if err != nil {
+		return err
+	}
+	if !strings.HasPrefix(arg, "0x") {
+		arg = "0x" + arg
+	}
+	data, err := hexutil.Decode(arg)
+	if err != nil {
+		return errors.Wrap(err, "data")
+	}
+	sd, err := script.DecodeScriptData(data)
+	if err != nil {
+		return err
+	}
+	out, err := json.MarshalIndent(sd, "", "  ")
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(out))
+	return nil
+}
+
+// scriptEncodeAction prints the clause data of the JSON description given as the argument.
+func scriptEncodeAction(ctx *cli.Context) error {
+	arg, err := scriptArg(ctx)
+	if err != nil {
+		return err
+	}
+	var sd script.ScriptData
+	decoder := json.NewDecoder(strings.NewReader(arg))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&sd); err != nil {
+		return errors.Wrap(err, "json")
+	}
+	data, err := script.EncodeScriptData(&sd)
+	if err != nil {
+		return err
+	}
+	fmt.Println(hexutil.Encode(data))
+	return nil
+}
+
+// scriptArg returns the only argument, which is read from stdin if it's "-" or omitted.
+func scriptArg(ctx *cli.Context) (string, error) {
+	if ctx.NArg() > 1 {
+		return "", errors.New("too many arguments")
+	}
+	arg := ctx.Args().First()
+	if arg == "" || arg == "-" {
+		input, err := ioutil.ReadAll(os.Stdin)
+		if err != nil {
+			return "", err
+		}
+		arg = string(input)
+	}
+	return strings.TrimSpace(arg), nil
+}
@@ -9,11 +9,11 @@ import (
 )
 
 func calcEpochRelease() {
-	lastSummary := &auction.AuctionSummary{
+	lastAuction := &auction.AuctionCB{
 		RlsdMTRG: new(big.Int).Mul(big.NewInt(54801906), big.NewInt(1e14)),
 		RsvdMTRG: big.NewInt(0),
 	}
-	release, err := reward.ComputeEpochReleaseWithInflation(1111, 1113, lastSummary)
+	release, err := reward.ComputeEpochReleaseWithInflation(1113, lastAuction)
 	if err != nil {
 		fmt.Println("Error: ", err)
 	}
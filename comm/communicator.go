@@ -27,6 +27,7 @@ import (
 	"github.com/meterio/meter-pov/tx"
 	"github.com/meterio/meter-pov/txpool"
 	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
 )
 
 var (
@@ -65,6 +66,12 @@ func GetGlobCommInst() *Communicator {
 
 // New create a new Communicator instance.
 func New(chain *chain.Chain, txPool *txpool.TxPool, powPool *powpool.PowPool, configTopic string, magic [4]byte) *Communicator {
+	prometheus.Register(peersGauge)
+	prometheus.Register(syncedGauge)
+	prometheus.Register(syncTargetHeightGauge)
+	prometheus.Register(syncBlocksCounter)
+	prometheus.Register(syncErrorsCounter)
+
 	ctx, cancel := context.WithCancel(context.Background())
 	c := &Communicator{
 		chain:          chain,
@@ -172,6 +179,7 @@ func (c *Communicator) Sync(handler HandleBlockStream, qcHandler HandleQC) {
 					delay = syncInterval
 					c.onceSynced.Do(func() {
 						close(c.syncedCh)
+						syncedGauge.Set(1)
 					})
 				}
 			}
@@ -205,6 +205,7 @@ func (ps *PeerSet) Add(peer *Peer, dir string) {
 	} else {
 		ps.counter.Outbound++
 	}
+	ps.updateGauge()
 }
 
 // Find find peer for given nodeID.
@@ -225,6 +226,7 @@ func (ps *PeerSet) Remove(nodeID discover.NodeID) *Peer {
 		} else {
 			ps.counter.Outbound--
 		}
+		ps.updateGauge()
 	}
 
 	if peer, ok := ps.m[nodeID]; ok {
@@ -259,6 +261,12 @@ func (ps *PeerSet) Len() int {
 	return len(ps.m)
 }
 
+// updateGauge reports the peer counts, the lock is required to be held.
+func (ps *PeerSet) updateGauge() {
+	peersGauge.WithLabelValues("inbound").Set(float64(ps.counter.Inbound))
+	peersGauge.WithLabelValues("outbound").Set(float64(ps.counter.Outbound))
+}
+
 func (ps *PeerSet) DirectionCount() DirectionCount {
 	ps.lock.Lock()
 	defer ps.lock.Unlock()
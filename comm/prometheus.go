@@ -0,0 +1,31 @@
+// Copyright (c) 2020 The Meter.io developers
+
+// Distributed under the GNU Lesser General Public License v3.0 software license, see the accompanying
+// file LICENSE or <https://www.gnu.org/licenses/lgpl-3.0.html>
+
+package comm
+
+import "github.com/prometheus/client_golang/prometheus"
+
+var (
+	peersGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "p2p_peers",
+		Help: "Number of connected peers by direction (inbound, outbound)",
+	}, []string{"direction"})
+	syncedGauge = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "sync_synced",
+		Help: "status of initial synchronization (0-syncing, 1-synced)",
+	})
+	syncTargetHeightGauge = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "sync_target_height",
+		Help: "Height of the head block of the peer to sync with",
+	})
+	syncBlocksCounter = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "sync_blocks_downloaded_total",
+		Help: "Counter of blocks downloaded from peers by synchronization",
+	})
+	syncErrorsCounter = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "sync_errors_total",
+		Help: "Counter of failed synchronizations",
+	})
+)
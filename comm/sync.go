@@ -17,11 +17,19 @@ import (
 )
 
 func (c *Communicator) sync(peer *Peer, headNum uint32, handler HandleBlockStream, qcHandler HandleQC) error {
+	headID, _ := peer.Head()
+	syncTargetHeightGauge.Set(float64(block.Number(headID)))
+
 	ancestor, err := c.findCommonAncestor(peer, headNum)
 	if err != nil {
+		syncErrorsCounter.Inc()
 		return errors.WithMessage(err, "find common ancestor")
 	}
-	return c.download(peer, ancestor+1, handler, qcHandler)
+	if err := c.download(peer, ancestor+1, handler, qcHandler); err != nil {
+		syncErrorsCounter.Inc()
+		return err
+	}
+	return nil
 }
 
 func (c *Communicator) download(peer *Peer, fromNum uint32, handler HandleBlockStream, qcHandler HandleQC) error {
@@ -82,6 +90,7 @@ func (c *Communicator) download(peer *Peer, fromNum uint32, handler HandleBlockS
 				}
 				fromNum++
 				blocks = append(blocks, &blk)
+				syncBlocksCounter.Inc()
 			}
 
 			<-co.Parallel(func(queue chan<- func()) {
@@ -112,7 +112,7 @@ func (cl *ConsensusLeader) CreateAnnounceMsgPeers() []*ConsensusPeer {
 			continue
 		}
 		// initialize PeerConn
-		p := newConsensusPeer(v.Name, v.NetAddr.IP, v.NetAddr.Port, cl.csReactor.magic)
+		p := newConsensusPeer(v.Name, v.NetAddr.IP, v.NetAddr.Port, v.PubKey, cl.csReactor.transport)
 		csPeers = append(csPeers, p)
 	}
 	return csPeers
@@ -126,7 +126,7 @@ func (cl *ConsensusLeader) CreateNotaryMsgPeers() []*ConsensusPeer {
 			continue
 		}
 		// initialize PeerConn
-		p := newConsensusPeer(cm.Name, cm.NetAddr.IP, cm.NetAddr.Port, cl.csReactor.magic)
+		p := newConsensusPeer(cm.Name, cm.NetAddr.IP, cm.NetAddr.Port, cm.PubKey, cl.csReactor.transport)
 		csPeers = append(csPeers, p)
 	}
 	return csPeers
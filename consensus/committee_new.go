@@ -105,7 +105,7 @@ func (conR *ConsensusReactor) NewCommitteeTimeout() {
 		size := len(conR.newCommittee.Committee.Validators)
 		nl := conR.newCommittee.Committee.Validators[conR.newCommittee.Round%uint32(size)]
 
-		leader := newConsensusPeer(nl.Name, nl.NetAddr.IP, nl.NetAddr.Port, conR.magic)
+		leader := newConsensusPeer(nl.Name, nl.NetAddr.IP, nl.NetAddr.Port, nl.PubKey, conR.transport)
 		leaderPubKey := nl.PubKey
 		conR.sendNewCommitteeMessage(leader, leaderPubKey, conR.newCommittee.KblockHeight,
 			conR.newCommittee.Nonce, conR.newCommittee.Round)
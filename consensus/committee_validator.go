@@ -17,6 +17,7 @@ package consensus
 
 import (
 	"bytes"
+	"crypto/ecdsa"
 	"encoding/base64"
 	"time"
 
@@ -43,9 +44,9 @@ type ConsensusValidator struct {
 	newCommittee NewCommittee
 }
 
-func (cv *ConsensusValidator) SendMsgToPeer(msg *ConsensusMessage, netAddr types.NetAddress) bool {
+func (cv *ConsensusValidator) SendMsgToPeer(msg *ConsensusMessage, netAddr types.NetAddress, pubKey ecdsa.PublicKey) bool {
 	name := cv.csReactor.GetDelegateNameByIP(netAddr.IP)
-	csPeer := newConsensusPeer(name, netAddr.IP, netAddr.Port, cv.csReactor.magic)
+	csPeer := newConsensusPeer(name, netAddr.IP, netAddr.Port, pubKey, cv.csReactor.transport)
 	return cv.csReactor.asyncSendCommitteeMsg(msg, false, csPeer)
 }
 
@@ -189,7 +190,7 @@ func (cv *ConsensusValidator) ProcessAnnounceCommittee(announceMsg *AnnounceComm
 	msg := cv.GenerateCommitMessage(sign, msgHash, cv.csReactor.newCommittee.Round)
 
 	var m ConsensusMessage = msg
-	cv.SendMsgToPeer(&m, lv.NetAddr)
+	cv.SendMsgToPeer(&m, lv.NetAddr, lv.PubKey)
 	cv.state = COMMITTEE_VALIDATOR_COMMITSENT
 
 	//update conR
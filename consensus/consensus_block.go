@@ -221,7 +221,11 @@ func (c *ConsensusReactor) NewRuntimeForReplay(header *block.Header) (*runtime.R
 		return nil, err
 	}
 	****/
-	return runtime.New(
+	epoch, err := c.chain.GetBlockEpoch(header.ParentID())
+	if err != nil {
+		return nil, err
+	}
+	rt := runtime.New(
 		c.chain.NewSeeker(header.ParentID()),
 		state,
 		&xenv.BlockContext{
@@ -231,7 +235,10 @@ func (c *ConsensusReactor) NewRuntimeForReplay(header *block.Header) (*runtime.R
 			Time:        header.Timestamp(),
 			GasLimit:    header.GasLimit(),
 			TotalScore:  header.TotalScore(),
-		}), nil
+			Epoch:       epoch,
+		})
+	rt.ApplyForks()
+	return rt, nil
 }
 
 func (c *ConsensusReactor) validate(
@@ -780,6 +787,10 @@ func (c *ConsensusReactor) verifyBlock(blk *block.Block, state *state.State) (*s
 	processedTxs := make(map[meter.Bytes32]bool)
 	header := blk.Header()
 	signer, _ := header.Signer()
+	epoch, err := c.chain.GetBlockEpoch(header.ParentID())
+	if err != nil {
+		return nil, nil, err
+	}
 	rt := runtime.New(
 		c.chain.NewSeeker(header.ParentID()),
 		state,
@@ -790,7 +801,9 @@ func (c *ConsensusReactor) verifyBlock(blk *block.Block, state *state.State) (*s
 			Time:        header.Timestamp(),
 			GasLimit:    header.GasLimit(),
 			TotalScore:  header.TotalScore(),
+			Epoch:       epoch,
 		})
+	rt.ApplyForks()
 
 	findTx := func(txID meter.Bytes32) (found bool, reverted bool, err error) {
 		if reverted, ok := processedTxs[txID]; ok {
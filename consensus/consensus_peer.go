@@ -6,12 +6,11 @@
 package consensus
 
 import (
-	"bytes"
+	"crypto/ecdsa"
 	"fmt"
 	"net"
-	"net/http"
+	"strconv"
 	"strings"
-	"time"
 
 	"github.com/inconshreveable/log15"
 	"github.com/meterio/meter-pov/types"
@@ -19,46 +18,37 @@ import (
 
 // Consensus Topology Peer
 type ConsensusPeer struct {
-	name    string
-	netAddr types.NetAddress
-	logger  log15.Logger
-	magic   [4]byte
+	name      string
+	netAddr   types.NetAddress
+	pubKey    ecdsa.PublicKey // the key the peer must authenticate with
+	logger    log15.Logger
+	transport *consensusTransport
 }
 
-func newConsensusPeer(name string, ip net.IP, port uint16, magic [4]byte) *ConsensusPeer {
+func newConsensusPeer(name string, ip net.IP, port uint16, pubKey ecdsa.PublicKey, transport *consensusTransport) *ConsensusPeer {
 	return &ConsensusPeer{
 		name: name,
 		netAddr: types.NetAddress{
 			IP:   ip,
 			Port: port,
 		},
-		logger: log15.New("pkg", "peer", "peer", name, "ip", ip.String()),
-		magic:  magic,
+		pubKey:    pubKey,
+		logger:    log15.New("pkg", "peer", "peer", name, "ip", ip.String()),
+		transport: transport,
 	}
 }
 
-func (peer *ConsensusPeer) sendPacemakerMsg(rawData []byte, msgSummary string, msgHashHex string, relay bool) error {
-	// full size message may taker longer time (> 2s) to complete the tranport.
-	var netClient = &http.Client{
-		Timeout: time.Second * 4, // 2
+// addr returns the host:port of the consensus endpoint of the peer.
+func (peer *ConsensusPeer) addr() string {
+	port := peer.netAddr.Port
+	if port == 0 {
+		port = DefaultConsensusPort
 	}
+	return net.JoinHostPort(peer.netAddr.IP.String(), strconv.Itoa(int(port)))
+}
 
-	// split := strings.Split(msgSummary, " ")
-	// name := ""
-	// tail := ""
-	// if len(split) > 0 {
-	// 	name = split[0]
-	// 	tail = strings.Join(split[1:], " ")
-	// }
-
-	// if relay {
-	// 	peer.logger.Info("Relay>> "+name+" "+msgHashHex+"]", "size", len(rawData))
-	// } else {
-	// 	peer.logger.Info("Send>> "+name+" "+msgHashHex+" "+tail, "size", len(rawData))
-	// }
-
-	url := "http://" + peer.netAddr.IP.String() + ":8670/pacemaker"
-	_, err := netClient.Post(url, "application/json", bytes.NewBuffer(rawData))
+func (peer *ConsensusPeer) sendPacemakerMsg(rawData []byte, msgSummary string, msgHashHex string, relay bool) error {
+	err := peer.transport.send(peer.addr(), &peer.pubKey, frameKindPacemaker, rawData)
 	if err != nil {
 		peer.logger.Error("Failed to send message to peer", "err", err)
 		return err
@@ -67,10 +57,6 @@ func (peer *ConsensusPeer) sendPacemakerMsg(rawData []byte, msgSummary string, m
 }
 
 func (peer *ConsensusPeer) sendCommitteeMsg(rawData []byte, msgSummary string, msgHashHex string, relay bool) error {
-	var netClient = &http.Client{
-		Timeout: time.Second * 4,
-	}
-
 	split := strings.Split(msgSummary, " ")
 	name := ""
 	tail := ""
@@ -83,8 +69,7 @@ func (peer *ConsensusPeer) sendCommitteeMsg(rawData []byte, msgSummary string, m
 	} else {
 		peer.logger.Info("Send>> "+name+" "+msgHashHex+" "+tail, "size", len(rawData))
 	}
-	url := "http://" + peer.netAddr.IP.String() + ":8670/committee"
-	_, err := netClient.Post(url, "application/json", bytes.NewBuffer(rawData))
+	err := peer.transport.send(peer.addr(), &peer.pubKey, frameKindCommittee, rawData)
 	if err != nil {
 		peer.logger.Error("Failed to send message to peer", "err", err)
 		return err
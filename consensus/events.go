@@ -0,0 +1,95 @@
+// Copyright (c) 2020 The Meter.io developers
+
+// Distributed under the GNU Lesser General Public License v3.0 software license, see the accompanying
+// file LICENSE or <https://www.gnu.org/licenses/lgpl-3.0.html>
+
+package consensus
+
+import (
+	"github.com/ethereum/go-ethereum/event"
+	"github.com/meterio/meter-pov/block"
+	"github.com/meterio/meter-pov/script/staking"
+	"github.com/meterio/meter-pov/types"
+)
+
+// Types of consensus events
+const (
+	EventQC         = "qc"         // QCHigh of pacemaker updated
+	EventRound      = "round"      // current round of pacemaker updated
+	EventTimeout    = "timeout"    // round of pacemaker timed out
+	EventEpoch      = "epoch"      // committee of the next epoch calculated from the K-block
+	EventStatistics = "statistics" // jail and slash statistics updated by the K-block
+)
+
+// Event is a consensus event, fields are set by type.
+type Event struct {
+	Type   string
+	Height uint32
+	Round  uint32
+	Epoch  uint64
+
+	Reason  string            // reason of round update
+	Counter uint64            // timeout counter
+	QC      *block.QuorumCert // new QCHigh
+
+	Nonce     uint64             // nonce of the K-block
+	Committee []*types.Validator // committee of the next epoch
+
+	Statistics []staking.DelegateStatistics
+	InJail     []staking.DelegateJailed
+}
+
+// SubscribeEvent receivers will receive consensus events.
+// Events are sent synchronously, receivers must not block.
+func (conR *ConsensusReactor) SubscribeEvent(ch chan *Event) event.Subscription {
+	return conR.eventScope.Track(conR.eventFeed.Subscribe(ch))
+}
+
+// hasEventSubscriber returns whether there are subscribers, to skip building costly events.
+func (conR *ConsensusReactor) hasEventSubscriber() bool {
+	return conR.eventScope.Count() > 0
+}
+
+func (conR *ConsensusReactor) sendEvent(ev *Event) {
+	if conR.hasEventSubscriber() {
+		conR.eventFeed.Send(ev)
+	}
+}
+
+// sendKBlockEvents sends the epoch event with the committee calculated from the nonce of the
+// K-block, and the statistics event with the jail and slash statistics at the K-block.
+func (conR *ConsensusReactor) sendKBlockEvents(kBlockHeight uint32, nonce, epoch uint64) {
+	if !conR.hasEventSubscriber() {
+		return
+	}
+	ev := &Event{
+		Type:   EventEpoch,
+		Height: kBlockHeight,
+		Epoch:  epoch,
+		Nonce:  nonce,
+	}
+	if conR.curCommittee != nil {
+		ev.Committee = conR.curCommittee.Validators
+	}
+	conR.eventFeed.Send(ev)
+
+	header, err := conR.chain.GetTrunkBlockHeader(kBlockHeight)
+	if err != nil {
+		conR.logger.Warn("get K-block for statistics event", "height", kBlockHeight, "err", err)
+		return
+	}
+	st, err := conR.stateCreator.NewState(header.StateRoot())
+	if err != nil {
+		conR.logger.Warn("get state for statistics event", "height", kBlockHeight, "err", err)
+		return
+	}
+	stats := staking.GetStatisticsListByState(st)
+	inJail := staking.GetInJailListByState(st)
+	conR.eventFeed.Send(&Event{
+		Type:       EventStatistics,
+		Height:     kBlockHeight,
+		Epoch:      epoch,
+		Statistics: stats.ToList(),
+		InJail:     inJail.ToList(),
+	})
+}
@@ -584,6 +584,13 @@ func (p *Pacemaker) UpdateQCHigh(qc *pmQuorumCert) bool {
 		p.QCHigh = qc
 		p.blockLeaf = p.QCHigh.QCNode
 		updated = true
+		p.csReactor.sendEvent(&Event{
+			Type:   EventQC,
+			Height: qc.QC.QCHeight,
+			Round:  qc.QC.QCRound,
+			Epoch:  qc.QC.EpochID,
+			QC:     qc.QC,
+		})
 	}
 	p.logger.Debug("After update QCHigh", "updated", updated, "from", oqc.ToString(), "to", p.QCHigh.ToString())
 
@@ -921,7 +928,7 @@ func (p *Pacemaker) SendCatchUpQuery() {
 	}
 
 	leader := p.csReactor.curCommittee.Validators[0]
-	leaderPeer := newConsensusPeer(leader.Name, leader.NetAddr.IP, leader.NetAddr.Port, p.csReactor.magic)
+	leaderPeer := newConsensusPeer(leader.Name, leader.NetAddr.IP, leader.NetAddr.Port, leader.PubKey, p.csReactor.transport)
 	err = p.sendQueryProposalMsg(p.blockLocked.Height, 0, p.currentRound, bestQC.EpochID, leaderPeer)
 	if err != nil {
 		fmt.Println("could not send query proposal, error:", err)
@@ -1145,6 +1152,13 @@ R:
 
 func (p *Pacemaker) OnRoundTimeout(ti PMRoundTimeoutInfo) {
 	p.logger.Warn("Round Time Out", "round", ti.round, "counter", p.timeoutCounter)
+	p.csReactor.sendEvent(&Event{
+		Type:    EventTimeout,
+		Height:  p.QCHigh.QC.QCHeight + 1,
+		Round:   ti.round,
+		Epoch:   p.csReactor.curEpoch,
+		Counter: ti.counter,
+	})
 
 	updated := p.updateCurrentRound(ti.round+1, UpdateOnTimeout)
 	newTi := &PMRoundTimeoutInfo{
@@ -1181,6 +1195,16 @@ func (p *Pacemaker) updateCurrentRound(round uint32, reason roundUpdateReason) b
 		p.currentRound = round
 		p.logger.Info("update current round", "to", p.currentRound, "reason", reason.String())
 		pmRoundGauge.Set(float64(p.currentRound))
+		ev := &Event{
+			Type:   EventRound,
+			Round:  p.currentRound,
+			Epoch:  p.csReactor.curEpoch,
+			Reason: reason.String(),
+		}
+		if p.QCHigh != nil && p.QCHigh.QC != nil {
+			ev.Height = p.QCHigh.QC.QCHeight + 1
+		}
+		p.csReactor.sendEvent(ev)
 		return true
 	}
 	return false
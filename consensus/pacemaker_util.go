@@ -6,13 +6,12 @@
 package consensus
 
 import (
+	"crypto/ecdsa"
 	sha256 "crypto/sha256"
 	"encoding/hex"
 	"errors"
 	"fmt"
 	"github.com/meterio/meter-pov/meter"
-	"io/ioutil"
-	"net/http"
 	"strings"
 	"time"
 
@@ -54,20 +53,13 @@ func (p *Pacemaker) AddressBlock(height uint32) *pmBlock {
 	return nil
 }
 
-func (p *Pacemaker) receivePacemakerMsg(w http.ResponseWriter, r *http.Request) {
-	defer r.Body.Close()
-
+func (p *Pacemaker) handlePacemakerMsg(pubKey *ecdsa.PublicKey, data []byte) {
 	// handle no msg if pacemaker is stopped already
 	if p.stopped {
 		return
 	}
 
-	data, err := ioutil.ReadAll(r.Body)
-	if err != nil {
-		p.logger.Error("Unrecognized payload", "err", err)
-		return
-	}
-	mi, err := p.csReactor.UnmarshalMsg(data)
+	mi, err := p.csReactor.UnmarshalMsg(data, pubKey)
 	if err != nil {
 		p.logger.Error("Unmarshal error", "err", err)
 		return
@@ -162,7 +154,7 @@ func (p *Pacemaker) GetRelayPeers(round uint32) []*ConsensusPeer {
 		}
 		member := p.csReactor.curActualCommittee[index]
 		name := p.csReactor.GetDelegateNameByIP(member.NetAddr.IP)
-		peers = append(peers, newConsensusPeer(name, member.NetAddr.IP, member.NetAddr.Port, p.csReactor.magic))
+		peers = append(peers, newConsensusPeer(name, member.NetAddr.IP, member.NetAddr.Port, member.PubKey, p.csReactor.transport))
 	}
 	log.Info("get relay peers result", "myIndex", myIndex, "committeeSize", size, "round", round, "indexes", indexes)
 	return peers
@@ -278,7 +270,7 @@ func (p *Pacemaker) ValidateProposal(b *pmBlock) error {
 
 func (p *Pacemaker) getProposerByRound(round uint32) *ConsensusPeer {
 	proposer := p.csReactor.getRoundProposer(round)
-	return newConsensusPeer(proposer.Name, proposer.NetAddr.IP, 8080, p.csReactor.magic)
+	return newConsensusPeer(proposer.Name, proposer.NetAddr.IP, proposer.NetAddr.Port, proposer.PubKey, p.csReactor.transport)
 }
 
 // ------------------------------------------------------
@@ -287,7 +279,7 @@ func (p *Pacemaker) getProposerByRound(round uint32) *ConsensusPeer {
 func (p *Pacemaker) SendConsensusMessage(round uint32, msg ConsensusMessage, copyMyself bool) bool {
 	myNetAddr := p.csReactor.GetMyNetAddr()
 	myName := p.csReactor.GetMyName()
-	myself := newConsensusPeer(myName, myNetAddr.IP, myNetAddr.Port, p.csReactor.magic)
+	myself := newConsensusPeer(myName, myNetAddr.IP, myNetAddr.Port, p.csReactor.myPubKey, p.csReactor.transport)
 
 	peers := make([]*ConsensusPeer, 0)
 	switch msg.(type) {
@@ -420,7 +412,7 @@ func (p *Pacemaker) sendQueryProposalMsg(fromHeight, toHeight, queryRound uint32
 		for _, cm := range p.csReactor.curActualCommittee {
 			if myNetAddr.IP.Equal(cm.NetAddr.IP) == false {
 				p.logger.Warn("Query PMProposal with new node", "NetAddr", cm.NetAddr)
-				peer = newConsensusPeer(cm.Name, cm.NetAddr.IP, cm.NetAddr.Port, p.csReactor.magic)
+				peer = newConsensusPeer(cm.Name, cm.NetAddr.IP, cm.NetAddr.Port, cm.PubKey, p.csReactor.transport)
 				break
 			}
 		}
@@ -16,6 +16,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"math"
 	"net"
@@ -27,12 +28,14 @@ import (
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
 	cli "gopkg.in/urfave/cli.v1"
 
 	crypto "github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/event"
 	"github.com/inconshreveable/log15"
 	"github.com/meterio/meter-pov/block"
 	"github.com/meterio/meter-pov/chain"
@@ -91,6 +94,7 @@ var (
 	ErrMalformattedMsg     = errors.New("Malformatted msg")
 	ErrInvalidSignature    = errors.New("invalid signature")
 	ErrInvalidMsgType      = errors.New("invalid msg type")
+	ErrUnauthorizedPeer    = errors.New("unauthorized peer")
 )
 
 type ConsensusConfig struct {
@@ -160,12 +164,19 @@ type ConsensusReactor struct {
 	newCommittee     *NewCommittee                     //New committee for myself
 	rcvdNewCommittee map[NewCommitteeKey]*NewCommittee // store received new committee info
 
-	msgCache *MsgCache
+	msgCache  *MsgCache
+	transport *consensusTransport
+	// snapshot of the delegate and committee keys allowed to connect, read by the transport
+	// goroutines without the reactor lock
+	authorizedPeers atomic.Value // map[string]types.CommitteeMember
 
 	magic           [4]byte
 	inCommittee     bool
 	allDelegates    []*types.Delegate
 	sourceDelegates int
+
+	eventFeed  event.Feed
+	eventScope event.SubscriptionScope
 }
 
 // Glob Instance
@@ -243,6 +254,7 @@ func NewConsensusReactor(ctx *cli.Context, chain *chain.Chain, state *state.Crea
 
 	conR.myPrivKey = *privKey
 	conR.myPubKey = *pubKey
+	conR.transport = newConsensusTransport(magic, privKey, conR.isAuthorizedPeer, conR.handleTransportFrame, conR.isLegacyTransport)
 
 	SetConsensusGlobInst(conR)
 	return conR
@@ -275,6 +287,8 @@ func (conR *ConsensusReactor) OnStart() error {
 func (conR *ConsensusReactor) OnStop() {
 	// New consensus
 	conR.NewConsensusStop()
+	conR.transport.close()
+	conR.eventScope.Close()
 }
 
 func (conR *ConsensusReactor) GetLastKBlockHeight() uint32 {
@@ -437,6 +451,7 @@ func (conR *ConsensusReactor) UpdateActualCommittee(leaderIndex uint32) bool {
 	}
 
 	conR.curActualCommittee = committee
+	conR.updateAuthorizedPeers()
 
 	// I am Leader, first one should be myself.
 	// if bytes.Equal(crypto.FromECDSAPub(&conR.curActualCommittee[0].PubKey), crypto.FromECDSAPub(&conR.myPubKey)) == false {
@@ -676,7 +691,7 @@ func (conR *ConsensusReactor) GetRelayPeers(round int) ([]*ConsensusPeer, error)
 		}
 		member := conR.curCommittee.Validators[index]
 		name := conR.GetDelegateNameByIP(member.NetAddr.IP)
-		peers = append(peers, newConsensusPeer(name, member.NetAddr.IP, member.NetAddr.Port, conR.magic))
+		peers = append(peers, newConsensusPeer(name, member.NetAddr.IP, member.NetAddr.Port, member.PubKey, conR.transport))
 	}
 	return peers, nil
 }
@@ -765,7 +780,10 @@ func (conR *ConsensusReactor) MarshalMsg(msg *ConsensusMessage) ([]byte, error)
 	return json.Marshal(payload)
 }
 
-func (conR *ConsensusReactor) UnmarshalMsg(data []byte) (*consensusMsgInfo, error) {
+// UnmarshalMsg decodes the message sent by the peer with the key. The sender is derived from the
+// authenticated key, the peer_ip/peer_port in the payload are only trusted for legacy http
+// messages, which come with no key.
+func (conR *ConsensusReactor) UnmarshalMsg(data []byte, pubKey *ecdsa.PublicKey) (*consensusMsgInfo, error) {
 	var params map[string]string
 	err := json.NewDecoder(bytes.NewReader(data)).Decode(&params)
 	if err != nil {
@@ -775,14 +793,30 @@ func (conR *ConsensusReactor) UnmarshalMsg(data []byte) (*consensusMsgInfo, erro
 	if strings.Compare(params["magic"], hex.EncodeToString(conR.magic[:])) != 0 {
 		return nil, ErrMagicMismatch
 	}
-	peerIP := net.ParseIP(params["peer_ip"])
-	peerPort, err := strconv.ParseUint(params["peer_port"], 10, 16)
-	if err != nil {
-		fmt.Println("Unrecognized Payload: ", err)
-		return nil, ErrUnrecognizedPayload
+	var peer *ConsensusPeer
+	if pubKey != nil {
+		member, ok := conR.authorizedPeer(pubKey)
+		if !ok {
+			return nil, ErrUnauthorizedPeer
+		}
+		peer = newConsensusPeer(member.Name, member.NetAddr.IP, member.NetAddr.Port, member.PubKey, conR.transport)
+	} else {
+		peerIP := net.ParseIP(params["peer_ip"])
+		peerPort, err := strconv.ParseUint(params["peer_port"], 10, 16)
+		if err != nil {
+			fmt.Println("Unrecognized Payload: ", err)
+			return nil, ErrUnrecognizedPayload
+		}
+		var peerKey ecdsa.PublicKey
+		for _, d := range conR.allDelegates {
+			if d.NetAddr.IP.String() == peerIP.String() {
+				peerKey = d.PubKey
+				break
+			}
+		}
+		peerName := conR.GetDelegateNameByIP(peerIP)
+		peer = newConsensusPeer(peerName, peerIP, uint16(peerPort), peerKey, conR.transport)
 	}
-	peerName := conR.GetDelegateNameByIP(peerIP)
-	peer := newConsensusPeer(peerName, peerIP, uint16(peerPort), conR.magic)
 	rawMsg, err := hex.DecodeString(params["message"])
 	if err != nil {
 		fmt.Println("could not decode string: ", params["message"])
@@ -798,22 +832,117 @@ func (conR *ConsensusReactor) UnmarshalMsg(data []byte) (*consensusMsgInfo, erro
 	return newConsensusMsgInfo(msg, peer, data), nil
 }
 
+// ReceivePacemakerMsg accepts the legacy http pacemaker message, until the consensus transport fork.
 func (conR *ConsensusReactor) ReceivePacemakerMsg(w http.ResponseWriter, r *http.Request) {
-	if conR.csPacemaker != nil {
-		conR.csPacemaker.receivePacemakerMsg(w, r)
-	} else {
-		conR.logger.Warn("pacemaker is not initialized, dropped message")
+	defer r.Body.Close()
+	if !conR.isLegacyTransport() {
+		http.NotFound(w, r)
+		return
+	}
+	data, err := ioutil.ReadAll(io.LimitReader(r.Body, maxFrameSize))
+	if err != nil {
+		conR.logger.Error("Unrecognized payload", "err", err)
+		return
 	}
+	conR.handlePacemakerMsg(nil, data)
 }
 
+// ReceiveCommitteeMsg accepts the legacy http committee message, until the consensus transport fork.
 func (conR *ConsensusReactor) ReceiveCommitteeMsg(w http.ResponseWriter, r *http.Request) {
 	defer r.Body.Close()
-	data, err := ioutil.ReadAll(r.Body)
+	if !conR.isLegacyTransport() {
+		http.NotFound(w, r)
+		return
+	}
+	data, err := ioutil.ReadAll(io.LimitReader(r.Body, maxFrameSize))
 	if err != nil {
 		fmt.Println(err)
 		return
 	}
-	mi, err := conR.UnmarshalMsg(data)
+	conR.handleCommitteeMsg(nil, data)
+}
+
+// isLegacyTransport returns whether messages are still posted over http, before the consensus
+// transport fork.
+func (conR *ConsensusReactor) isLegacyTransport() bool {
+	return !meter.IsConsensusTransportFork(conR.chain.BestBlock().Header().Number() + 1)
+}
+
+// ReceiveConsensusConn accepts the persistent consensus connection from peers.
+func (conR *ConsensusReactor) ReceiveConsensusConn(w http.ResponseWriter, r *http.Request) {
+	conR.transport.ServeHTTP(w, r)
+}
+
+// handleTransportFrame dispatches the message received over the consensus connection.
+func (conR *ConsensusReactor) handleTransportFrame(pubKey *ecdsa.PublicKey, kind byte, data []byte) {
+	switch kind {
+	case frameKindPacemaker:
+		conR.handlePacemakerMsg(pubKey, data)
+	case frameKindCommittee:
+		conR.handleCommitteeMsg(pubKey, data)
+	default:
+		conR.logger.Debug("unknown consensus frame, dropped ...", "kind", kind)
+	}
+}
+
+// updateAuthorizedPeers takes the snapshot of delegates, committee members and myself by key for
+// isAuthorizedPeer and authorizedPeer, it must be called whenever the delegates or the committee change.
+func (conR *ConsensusReactor) updateAuthorizedPeers() {
+	peers := make(map[string]types.CommitteeMember)
+	if conR.curDelegates != nil {
+		for _, d := range conR.curDelegates.Delegates {
+			peers[string(crypto.FromECDSAPub(&d.PubKey))] = types.CommitteeMember{
+				Name:    string(d.Name),
+				PubKey:  d.PubKey,
+				NetAddr: d.NetAddr,
+			}
+		}
+	}
+	for _, m := range conR.curActualCommittee {
+		peers[string(crypto.FromECDSAPub(&m.PubKey))] = m
+	}
+	myKey := string(crypto.FromECDSAPub(&conR.myPubKey))
+	if _, ok := peers[myKey]; !ok {
+		peers[myKey] = types.CommitteeMember{
+			Name:    conR.GetMyName(),
+			PubKey:  conR.myPubKey,
+			NetAddr: conR.GetMyNetAddr(),
+		}
+	}
+	conR.authorizedPeers.Store(peers)
+}
+
+// authorizedPeer returns the delegate, committee member or myself with the key.
+func (conR *ConsensusReactor) authorizedPeer(pubKey *ecdsa.PublicKey) (types.CommitteeMember, bool) {
+	peers, _ := conR.authorizedPeers.Load().(map[string]types.CommitteeMember)
+	member, ok := peers[string(crypto.FromECDSAPub(pubKey))]
+	return member, ok
+}
+
+// isAuthorizedPeer checks whether the peer key is myself, a delegate or a committee member.
+// All peers but myself are rejected before the delegates are known.
+func (conR *ConsensusReactor) isAuthorizedPeer(pubKey *ecdsa.PublicKey) bool {
+	if bytes.Equal(crypto.FromECDSAPub(pubKey), crypto.FromECDSAPub(&conR.myPubKey)) {
+		return true
+	}
+	_, ok := conR.authorizedPeer(pubKey)
+	return ok
+}
+
+// handlePacemakerMsg handles the message from the peer with the key, or from the legacy http
+// route if the key is nil.
+func (conR *ConsensusReactor) handlePacemakerMsg(pubKey *ecdsa.PublicKey, data []byte) {
+	if conR.csPacemaker != nil {
+		conR.csPacemaker.handlePacemakerMsg(pubKey, data)
+	} else {
+		conR.logger.Warn("pacemaker is not initialized, dropped message")
+	}
+}
+
+// handleCommitteeMsg handles the message from the peer with the key, or from the legacy http
+// route if the key is nil.
+func (conR *ConsensusReactor) handleCommitteeMsg(pubKey *ecdsa.PublicKey, data []byte) {
+	mi, err := conR.UnmarshalMsg(data, pubKey)
 	if err != nil {
 		fmt.Println(err)
 		return
@@ -951,6 +1080,7 @@ func (conR *ConsensusReactor) exitCurCommittee() {
 		conR.curCommittee.Validators = make([]*types.Validator, 0)
 	}
 	conR.curActualCommittee = make([]types.CommitteeMember, 0)
+	conR.updateAuthorizedPeers()
 	conR.curCommitteeIndex = 0
 	conR.kBlockData = nil
 
@@ -995,7 +1125,7 @@ func (conR *ConsensusReactor) GetMyPeers() ([]*ConsensusPeer, error) {
 	myNetAddr := conR.GetMyNetAddr()
 	for _, member := range conR.curActualCommittee {
 		if member.NetAddr.IP.String() != myNetAddr.IP.String() {
-			peers = append(peers, newConsensusPeer(member.Name, member.NetAddr.IP, member.NetAddr.Port, conR.magic))
+			peers = append(peers, newConsensusPeer(member.Name, member.NetAddr.IP, member.NetAddr.Port, member.PubKey, conR.transport))
 		}
 	}
 	return peers, nil
@@ -1232,6 +1362,7 @@ func (conR *ConsensusReactor) UpdateCurDelegates() {
 	delegates, delegateSize, committeeSize := conR.GetConsensusDelegates()
 	conR.allDelegates = delegates
 	conR.curDelegates = types.NewDelegateSet(delegates[:delegateSize])
+	conR.updateAuthorizedPeers()
 	conR.delegateSize = delegateSize
 	conR.committeeSize = uint32(committeeSize)
 	names := make([]string, 0)
@@ -1490,7 +1621,7 @@ func (conR *ConsensusReactor) ConsensusHandleReceivedNonce(kBlockHeight uint32,
 		conR.NewCommitteeInit(kBlockHeight, nonce, replay)
 		newCommittee := conR.newCommittee
 		nl := newCommittee.Committee.Validators[int(newCommittee.Round)%len(newCommittee.Committee.Validators)]
-		leader := newConsensusPeer(nl.Name, nl.NetAddr.IP, nl.NetAddr.Port, conR.magic)
+		leader := newConsensusPeer(nl.Name, nl.NetAddr.IP, nl.NetAddr.Port, nl.PubKey, conR.transport)
 		leaderPubKey := nl.PubKey
 		conR.sendNewCommitteeMessage(leader, leaderPubKey, newCommittee.KblockHeight,
 			newCommittee.Nonce, newCommittee.Round)
@@ -1499,6 +1630,7 @@ func (conR *ConsensusReactor) ConsensusHandleReceivedNonce(kBlockHeight uint32,
 		// even though it is not committee, still initialize NewCommittee for next
 		conR.NewCommitteeInit(kBlockHeight, nonce, replay)
 	}
+	conR.sendKBlockEvents(kBlockHeight, nonce, epoch)
 	return
 }
 
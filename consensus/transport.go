@@ -0,0 +1,497 @@
+// Copyright (c) 2020 The Meter.io developers
+
+// Distributed under the GNU Lesser General Public License v3.0 software license, see the accompanying
+// file LICENSE or <https://www.gnu.org/licenses/lgpl-3.0.html>
+
+package consensus
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/crypto/ecies"
+	"github.com/gorilla/websocket"
+	"github.com/inconshreveable/log15"
+)
+
+const (
+	// DefaultConsensusPort is used for peers which do not advertise a port.
+	DefaultConsensusPort = 8670
+
+	// TransportPath is the http path to upgrade to a consensus connection.
+	TransportPath = "/consensus"
+
+	frameKindPacemaker = byte(1)
+	frameKindCommittee = byte(2)
+
+	nonceLen  = 32
+	pubKeyLen = 65
+	sigLen    = 65
+	macLen    = sha256.Size
+
+	peerSendQueueSize = 64
+	maxFrameSize      = 4 * maxMsgSize // payload is hex encoded in json
+	handshakeTimeout  = 4 * time.Second
+	frameWriteTimeout = 4 * time.Second
+	pingPeriod        = 15 * time.Second
+	pongWait          = 2 * pingPeriod
+	redialBackoff     = 2 * time.Second
+	peerIdleTimeout   = 10 * time.Minute
+)
+
+var (
+	errSendQueueFull   = errors.New("consensus send queue is full")
+	errTransportClosed = errors.New("consensus transport is closed")
+	errUnauthorized    = errors.New("unauthorized consensus peer")
+	errBadHandshake    = errors.New("bad consensus handshake")
+	errUnexpectedPeer  = errors.New("unexpected consensus peer")
+	errBadFrame        = errors.New("bad consensus frame")
+	errPeerUnreachable = errors.New("consensus peer is unreachable")
+)
+
+type transportFrame struct {
+	kind byte
+	data []byte
+}
+
+// consensusTransport carries consensus messages over long-lived websocket connections.
+// Both ends sign the transcript of the handshake with the node master key, so only authorized
+// peers may connect and the client only talks to the peer it dialed, and every frame carries the
+// mac of the session key shared by both ends. Outbound messages are put into a bounded queue
+// per peer, which is drained by its own goroutine over a single connection.
+// Before the consensus transport fork, messages are posted over http as legacy nodes expect.
+type consensusTransport struct {
+	magic     [4]byte
+	privKey   *ecdsa.PrivateKey
+	authorize func(pubKey *ecdsa.PublicKey) bool
+	handle    func(pubKey *ecdsa.PublicKey, kind byte, data []byte)
+	legacy    func() bool
+	logger    log15.Logger
+
+	upgrader websocket.Upgrader
+	dialer   websocket.Dialer
+	client   *http.Client
+
+	lock   sync.Mutex
+	peers  map[string]*transportPeer
+	closed bool
+	done   chan struct{}
+}
+
+// newConsensusTransport creates the transport, handle is called with the key of the peer for
+// each message received, and legacy returns whether messages are still posted over http.
+func newConsensusTransport(magic [4]byte, privKey *ecdsa.PrivateKey, authorize func(*ecdsa.PublicKey) bool, handle func(*ecdsa.PublicKey, byte, []byte), legacy func() bool) *consensusTransport {
+	return &consensusTransport{
+		magic:     magic,
+		privKey:   privKey,
+		authorize: authorize,
+		handle:    handle,
+		legacy:    legacy,
+		logger:    log15.New("pkg", "transport"),
+		upgrader: websocket.Upgrader{
+			HandshakeTimeout: handshakeTimeout,
+			// consensus peers are not browsers
+			CheckOrigin: func(r *http.Request) bool { return true },
+		},
+		dialer: websocket.Dialer{
+			HandshakeTimeout: handshakeTimeout,
+		},
+		// full size message may take longer time (> 2s) to complete the transport.
+		client: &http.Client{Timeout: 4 * time.Second},
+		peers:  make(map[string]*transportPeer),
+		done:   make(chan struct{}),
+	}
+}
+
+// send queues the message to the peer with the key at addr, it never blocks.
+func (t *consensusTransport) send(addr string, pubKey *ecdsa.PublicKey, kind byte, data []byte) error {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	if t.closed {
+		return errTransportClosed
+	}
+	// the key is part of the peer, so a new key at the same address gets its own connection
+	id := addr + "/" + string(crypto.FromECDSAPub(pubKey))
+	tp, ok := t.peers[id]
+	if !ok {
+		tp = &transportPeer{
+			t:      t,
+			id:     id,
+			addr:   addr,
+			pubKey: pubKey,
+			queue:  make(chan transportFrame, peerSendQueueSize),
+			logger: t.logger.New("peer", addr),
+		}
+		t.peers[id] = tp
+		go tp.loop()
+	}
+	select {
+	case tp.queue <- transportFrame{kind, data}:
+		return nil
+	default:
+		return errSendQueueFull
+	}
+}
+
+// close stops all peer goroutines and closes outbound connections.
+func (t *consensusTransport) close() {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	if !t.closed {
+		t.closed = true
+		close(t.done)
+	}
+}
+
+// ServeHTTP accepts an inbound consensus connection.
+func (t *consensusTransport) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	conn, err := t.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		// upgrader already replied with the error
+		return
+	}
+	defer conn.Close()
+
+	s, err := t.acceptHandshake(conn)
+	if err != nil {
+		t.logger.Debug("reject consensus connection", "remote", r.RemoteAddr, "err", err)
+		return
+	}
+
+	conn.SetReadLimit(maxFrameSize)
+	conn.SetPingHandler(func(appData string) error {
+		conn.SetReadDeadline(time.Now().Add(pongWait))
+		return conn.WriteControl(websocket.PongMessage, []byte(appData), time.Now().Add(frameWriteTimeout))
+	})
+	for {
+		conn.SetReadDeadline(time.Now().Add(pongWait))
+		typ, data, err := conn.ReadMessage()
+		if err != nil {
+			return
+		}
+		if typ != websocket.BinaryMessage {
+			continue
+		}
+		kind, payload, err := s.open(data)
+		if err != nil {
+			// frames out of the session can't be skipped, as the sequence is lost
+			t.logger.Debug("drop consensus connection", "remote", r.RemoteAddr, "err", err)
+			return
+		}
+		t.handle(s.pubKey, kind, payload)
+	}
+}
+
+// handshakeHash returns the hash signed by one side of the handshake. It covers both nonces and
+// both keys, so the signature is only valid for the connection between the two peers, and the
+// role keeps the signature of one side from passing as the other.
+func (t *consensusTransport) handshakeHash(role string, serverNonce, clientNonce []byte, clientKey, serverKey *ecdsa.PublicKey) []byte {
+	return crypto.Keccak256([]byte("meter consensus "+role), t.magic[:], serverNonce, clientNonce,
+		crypto.FromECDSAPub(clientKey), crypto.FromECDSAPub(serverKey))
+}
+
+// sessionKey derives the key of the frame macs from the shared secret of both keys, which only
+// the two peers know, and from the nonces, so it's new for each connection.
+func (t *consensusTransport) sessionKey(peerKey *ecdsa.PublicKey, serverNonce, clientNonce []byte) ([]byte, error) {
+	shared, err := ecies.ImportECDSA(t.privKey).GenerateShared(ecies.ImportECDSAPublic(peerKey), 16, 16)
+	if err != nil {
+		return nil, err
+	}
+	return crypto.Keccak256([]byte("meter consensus session"), shared, serverNonce, clientNonce), nil
+}
+
+// verifySig checks the signature of the hash is made by the key.
+func verifySig(hash, sig []byte, pubKey *ecdsa.PublicKey) error {
+	signer, err := crypto.SigToPub(hash, sig)
+	if err != nil {
+		return errBadHandshake
+	}
+	if !bytes.Equal(crypto.FromECDSAPub(signer), crypto.FromECDSAPub(pubKey)) {
+		return errUnexpectedPeer
+	}
+	return nil
+}
+
+func newNonce() ([]byte, error) {
+	nonce := make([]byte, nonceLen)
+	_, err := io.ReadFull(rand.Reader, nonce)
+	return nonce, err
+}
+
+func writeHandshake(conn *websocket.Conn, data []byte) error {
+	conn.SetWriteDeadline(time.Now().Add(handshakeTimeout))
+	return conn.WriteMessage(websocket.BinaryMessage, data)
+}
+
+func readHandshake(conn *websocket.Conn, size int) ([]byte, error) {
+	conn.SetReadDeadline(time.Now().Add(handshakeTimeout))
+	typ, data, err := conn.ReadMessage()
+	if err != nil {
+		return nil, err
+	}
+	if typ != websocket.BinaryMessage || len(data) != size {
+		return nil, errBadHandshake
+	}
+	return data, nil
+}
+
+// acceptHandshake runs the server side of the handshake. The server sends magic and its nonce,
+// the client replies with its own nonce, its key and its signature of the transcript,
+// then the server replies with its own signature of the transcript.
+func (t *consensusTransport) acceptHandshake(conn *websocket.Conn) (*session, error) {
+	nonce, err := newNonce()
+	if err != nil {
+		return nil, err
+	}
+	if err := writeHandshake(conn, append(t.magic[:], nonce...)); err != nil {
+		return nil, err
+	}
+	auth, err := readHandshake(conn, nonceLen+pubKeyLen+sigLen)
+	if err != nil {
+		return nil, err
+	}
+	peerNonce, sig := auth[:nonceLen], auth[nonceLen+pubKeyLen:]
+	peerKey, err := crypto.UnmarshalPubkey(auth[nonceLen : nonceLen+pubKeyLen])
+	if err != nil {
+		return nil, errBadHandshake
+	}
+	myKey := &t.privKey.PublicKey
+	if err := verifySig(t.handshakeHash("client", nonce, peerNonce, peerKey, myKey), sig, peerKey); err != nil {
+		return nil, err
+	}
+	if t.authorize != nil && !t.authorize(peerKey) {
+		return nil, errUnauthorized
+	}
+	sessionKey, err := t.sessionKey(peerKey, nonce, peerNonce)
+	if err != nil {
+		return nil, err
+	}
+	mySig, err := crypto.Sign(t.handshakeHash("server", nonce, peerNonce, peerKey, myKey), t.privKey)
+	if err != nil {
+		return nil, err
+	}
+	if err := writeHandshake(conn, mySig); err != nil {
+		return nil, err
+	}
+	return &session{pubKey: peerKey, key: sessionKey}, nil
+}
+
+// dialHandshake runs the client side of the handshake with the server of the key.
+func (t *consensusTransport) dialHandshake(conn *websocket.Conn, serverKey *ecdsa.PublicKey) (*session, error) {
+	hello, err := readHandshake(conn, len(t.magic)+nonceLen)
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(hello[:len(t.magic)], t.magic[:]) {
+		return nil, ErrMagicMismatch
+	}
+	serverNonce := hello[len(t.magic):]
+	nonce, err := newNonce()
+	if err != nil {
+		return nil, err
+	}
+	myKey := &t.privKey.PublicKey
+	sig, err := crypto.Sign(t.handshakeHash("client", serverNonce, nonce, myKey, serverKey), t.privKey)
+	if err != nil {
+		return nil, err
+	}
+	auth := append(append(nonce, crypto.FromECDSAPub(myKey)...), sig...)
+	if err := writeHandshake(conn, auth); err != nil {
+		return nil, err
+	}
+	peerSig, err := readHandshake(conn, sigLen)
+	if err != nil {
+		return nil, err
+	}
+	if err := verifySig(t.handshakeHash("server", serverNonce, nonce, myKey, serverKey), peerSig, serverKey); err != nil {
+		return nil, err
+	}
+	sessionKey, err := t.sessionKey(serverKey, serverNonce, nonce)
+	if err != nil {
+		return nil, err
+	}
+	return &session{pubKey: serverKey, key: sessionKey}, nil
+}
+
+// session is the authenticated state of a connection. Frames only go from the client to the
+// server, and each carries the mac of its sequence number and content under the session key,
+// so frames can't be injected, replayed or reordered on the connection.
+type session struct {
+	pubKey *ecdsa.PublicKey // of the other side
+	key    []byte
+	seq    uint64
+}
+
+func (s *session) mac(body []byte) []byte {
+	var seq [8]byte
+	binary.BigEndian.PutUint64(seq[:], s.seq)
+	h := hmac.New(sha256.New, s.key)
+	h.Write(seq[:])
+	h.Write(body)
+	return h.Sum(nil)
+}
+
+// seal returns the frame of the message.
+func (s *session) seal(kind byte, data []byte) []byte {
+	body := append([]byte{kind}, data...)
+	frame := append(body, s.mac(body)...)
+	s.seq++
+	return frame
+}
+
+// open checks the frame and returns the message in it.
+func (s *session) open(frame []byte) (byte, []byte, error) {
+	if len(frame) < 1+macLen {
+		return 0, nil, errBadFrame
+	}
+	body := frame[:len(frame)-macLen]
+	if !hmac.Equal(frame[len(frame)-macLen:], s.mac(body)) {
+		return 0, nil, errBadFrame
+	}
+	s.seq++
+	return body[0], body[1:], nil
+}
+
+// transportPeer is the outbound side of a consensus peer.
+type transportPeer struct {
+	t      *consensusTransport
+	id     string
+	addr   string
+	pubKey *ecdsa.PublicKey
+	queue  chan transportFrame
+	logger log15.Logger
+
+	// only accessed by the loop goroutine
+	conn     *websocket.Conn
+	session  *session
+	nextDial time.Time
+}
+
+func (tp *transportPeer) loop() {
+	ping := time.NewTicker(pingPeriod)
+	idle := time.NewTimer(peerIdleTimeout)
+	defer func() {
+		ping.Stop()
+		idle.Stop()
+		tp.closeConn()
+	}()
+
+	for {
+		select {
+		case f := <-tp.queue:
+			if err := tp.write(f); err != nil {
+				tp.logger.Error("Failed to send message to peer", "err", err)
+			}
+			if !idle.Stop() {
+				<-idle.C
+			}
+			idle.Reset(peerIdleTimeout)
+		case <-ping.C:
+			if tp.conn != nil {
+				if err := tp.conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(frameWriteTimeout)); err != nil {
+					tp.closeConn()
+				}
+			}
+		case <-idle.C:
+			if tp.remove() {
+				return
+			}
+			idle.Reset(peerIdleTimeout)
+		case <-tp.t.done:
+			return
+		}
+	}
+}
+
+// remove unregisters the idle peer, unless messages were queued meanwhile.
+func (tp *transportPeer) remove() bool {
+	tp.t.lock.Lock()
+	defer tp.t.lock.Unlock()
+	if len(tp.queue) > 0 {
+		return false
+	}
+	delete(tp.t.peers, tp.id)
+	return true
+}
+
+func (tp *transportPeer) write(f transportFrame) error {
+	if tp.t.legacy != nil && tp.t.legacy() {
+		return tp.post(f)
+	}
+	// retry once over a new connection if the old one is broken
+	for i := 0; i < 2; i++ {
+		if tp.conn == nil {
+			if time.Now().Before(tp.nextDial) {
+				return errPeerUnreachable
+			}
+			if err := tp.dial(); err != nil {
+				tp.nextDial = time.Now().Add(redialBackoff)
+				return err
+			}
+		}
+		tp.conn.SetWriteDeadline(time.Now().Add(frameWriteTimeout))
+		err := tp.conn.WriteMessage(websocket.BinaryMessage, tp.session.seal(f.kind, f.data))
+		if err == nil {
+			return nil
+		}
+		tp.closeConn()
+	}
+	return errPeerUnreachable
+}
+
+func (tp *transportPeer) dial() error {
+	conn, _, err := tp.t.dialer.Dial("ws://"+tp.addr+TransportPath, nil)
+	if err != nil {
+		return err
+	}
+	s, err := tp.t.dialHandshake(conn, tp.pubKey)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	conn.SetReadLimit(maxFrameSize)
+	// the remote never sends data frames, keep reading to process control frames
+	go func() {
+		for {
+			if _, _, err := conn.NextReader(); err != nil {
+				return
+			}
+		}
+	}()
+	tp.conn = conn
+	tp.session = s
+	return nil
+}
+
+func (tp *transportPeer) closeConn() {
+	if tp.conn != nil {
+		tp.conn.Close()
+		tp.conn = nil
+		tp.session = nil
+	}
+}
+
+// post sends the message with the legacy http transport.
+func (tp *transportPeer) post(f transportFrame) error {
+	path := "/pacemaker"
+	if f.kind == frameKindCommittee {
+		path = "/committee"
+	}
+	res, err := tp.t.client.Post("http://"+tp.addr+path, "application/json", bytes.NewReader(f.data))
+	if err != nil {
+		return err
+	}
+	res.Body.Close()
+	return nil
+}
@@ -0,0 +1,222 @@
+// Copyright (c) 2020 The Meter.io developers
+
+// Distributed under the GNU Lesser General Public License v3.0 software license, see the accompanying
+// file LICENSE or <https://www.gnu.org/licenses/lgpl-3.0.html>
+
+package consensus
+
+import (
+	"crypto/ecdsa"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/meterio/meter-pov/types"
+	"github.com/stretchr/testify/assert"
+)
+
+type receivedFrame struct {
+	pubKey string
+	kind   byte
+	data   string
+}
+
+func newTestTransport(t *testing.T, authorize func(*ecdsa.PublicKey) bool, received chan receivedFrame) *consensusTransport {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return newConsensusTransport([4]byte{1, 2, 3, 4}, key, authorize, func(pubKey *ecdsa.PublicKey, kind byte, data []byte) {
+		received <- receivedFrame{string(crypto.FromECDSAPub(pubKey)), kind, string(data)}
+	}, nil)
+}
+
+func expectFrame(t *testing.T, received chan receivedFrame) receivedFrame {
+	select {
+	case f := <-received:
+		return f
+	case <-time.After(5 * time.Second):
+		t.Fatal("timeout waiting for message")
+	}
+	return receivedFrame{}
+}
+
+func TestTransport(t *testing.T) {
+	received := make(chan receivedFrame, 10)
+	server := newTestTransport(t, nil, received)
+	ts := httptest.NewServer(server)
+	defer ts.Close()
+
+	client := newTestTransport(t, nil, nil)
+	defer client.close()
+	addr := strings.TrimPrefix(ts.URL, "http://")
+	serverKey := &server.privKey.PublicKey
+	clientKey := string(crypto.FromECDSAPub(&client.privKey.PublicKey))
+
+	assert.Nil(t, client.send(addr, serverKey, frameKindPacemaker, []byte("proposal")))
+	assert.Nil(t, client.send(addr, serverKey, frameKindCommittee, []byte("announce")))
+	assert.Equal(t, receivedFrame{clientKey, frameKindPacemaker, "proposal"}, expectFrame(t, received))
+	assert.Equal(t, receivedFrame{clientKey, frameKindCommittee, "announce"}, expectFrame(t, received))
+
+	// messages share one connection
+	assert.Equal(t, 1, len(client.peers))
+}
+
+func TestTransportUnauthorized(t *testing.T) {
+	received := make(chan receivedFrame, 10)
+	server := newTestTransport(t, func(*ecdsa.PublicKey) bool { return false }, received)
+	ts := httptest.NewServer(server)
+	defer ts.Close()
+
+	client := newTestTransport(t, nil, nil)
+	defer client.close()
+	tp := &transportPeer{t: client, addr: strings.TrimPrefix(ts.URL, "http://"), pubKey: &server.privKey.PublicKey}
+	assert.NotNil(t, tp.dial())
+	assert.Nil(t, tp.conn)
+}
+
+func TestTransportUnexpectedServer(t *testing.T) {
+	received := make(chan receivedFrame, 10)
+	server := newTestTransport(t, nil, received)
+	ts := httptest.NewServer(server)
+	defer ts.Close()
+
+	// the server at the address is not the member dialed, the client signature is bound to the
+	// member key so the server drops the connection as well
+	client := newTestTransport(t, nil, nil)
+	defer client.close()
+	member, _ := crypto.GenerateKey()
+	tp := &transportPeer{t: client, addr: strings.TrimPrefix(ts.URL, "http://"), pubKey: &member.PublicKey}
+	assert.NotNil(t, tp.dial())
+	assert.Nil(t, tp.conn)
+	assert.Equal(t, 0, len(received))
+}
+
+func TestSession(t *testing.T) {
+	client := &session{key: []byte("session key")}
+	server := &session{key: []byte("session key")}
+
+	first := client.seal(frameKindPacemaker, []byte("proposal"))
+	second := client.seal(frameKindCommittee, []byte("announce"))
+
+	// reordered frames are rejected
+	_, _, err := server.open(second)
+	assert.Equal(t, errBadFrame, err)
+	server.seq = 0
+
+	kind, data, err := server.open(first)
+	assert.Nil(t, err)
+	assert.Equal(t, frameKindPacemaker, kind)
+	assert.Equal(t, "proposal", string(data))
+
+	// replayed frames are rejected
+	_, _, err = server.open(first)
+	assert.Equal(t, errBadFrame, err)
+	server.seq = 1
+
+	// tampered frames are rejected
+	tampered := append([]byte{}, second...)
+	tampered[1] ^= 1
+	_, _, err = server.open(tampered)
+	assert.Equal(t, errBadFrame, err)
+
+	// as are frames under another key
+	other := &session{key: []byte("other key"), seq: 1}
+	_, _, err = server.open(other.seal(frameKindCommittee, []byte("announce")))
+	assert.Equal(t, errBadFrame, err)
+
+	kind, data, err = server.open(second)
+	assert.Nil(t, err)
+	assert.Equal(t, frameKindCommittee, kind)
+	assert.Equal(t, "announce", string(data))
+}
+
+func TestTransportLegacy(t *testing.T) {
+	posted := make(chan receivedFrame, 10)
+	mux := http.NewServeMux()
+	for path, kind := range map[string]byte{"/pacemaker": frameKindPacemaker, "/committee": frameKindCommittee} {
+		kind := kind
+		mux.HandleFunc(path, func(w http.ResponseWriter, r *http.Request) {
+			data, _ := ioutil.ReadAll(r.Body)
+			posted <- receivedFrame{"", kind, string(data)}
+		})
+	}
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	key, _ := crypto.GenerateKey()
+	client := newConsensusTransport([4]byte{1, 2, 3, 4}, key, nil, nil, func() bool { return true })
+	defer client.close()
+	addr := strings.TrimPrefix(ts.URL, "http://")
+	serverKey, _ := crypto.GenerateKey()
+
+	assert.Nil(t, client.send(addr, &serverKey.PublicKey, frameKindPacemaker, []byte("proposal")))
+	assert.Equal(t, receivedFrame{"", frameKindPacemaker, "proposal"}, expectFrame(t, posted))
+	assert.Nil(t, client.send(addr, &serverKey.PublicKey, frameKindCommittee, []byte("announce")))
+	assert.Equal(t, receivedFrame{"", frameKindCommittee, "announce"}, expectFrame(t, posted))
+}
+
+func TestIsAuthorizedPeer(t *testing.T) {
+	myKey, _ := crypto.GenerateKey()
+	delegateKey, _ := crypto.GenerateKey()
+	memberKey, _ := crypto.GenerateKey()
+	conR := &ConsensusReactor{myPubKey: myKey.PublicKey}
+
+	// only myself before the delegates are known
+	assert.True(t, conR.isAuthorizedPeer(&myKey.PublicKey))
+	assert.False(t, conR.isAuthorizedPeer(&delegateKey.PublicKey))
+	conR.curDelegates = types.NewDelegateSet(nil)
+	conR.updateAuthorizedPeers()
+	assert.False(t, conR.isAuthorizedPeer(&delegateKey.PublicKey))
+
+	conR.curDelegates = types.NewDelegateSet([]*types.Delegate{{PubKey: delegateKey.PublicKey}})
+	conR.curActualCommittee = []types.CommitteeMember{{PubKey: memberKey.PublicKey}}
+	conR.updateAuthorizedPeers()
+	assert.True(t, conR.isAuthorizedPeer(&delegateKey.PublicKey))
+	assert.True(t, conR.isAuthorizedPeer(&memberKey.PublicKey))
+	other, _ := crypto.GenerateKey()
+	assert.False(t, conR.isAuthorizedPeer(&other.PublicKey))
+}
+
+func TestUnmarshalMsgSender(t *testing.T) {
+	myKey, _ := crypto.GenerateKey()
+	memberKey, _ := crypto.GenerateKey()
+	member := types.CommitteeMember{
+		Name:    "member",
+		PubKey:  memberKey.PublicKey,
+		NetAddr: types.NetAddress{IP: net.ParseIP("10.0.0.2"), Port: 8670},
+	}
+	conR := &ConsensusReactor{myPubKey: myKey.PublicKey, magic: [4]byte{1, 2, 3, 4}}
+	conR.curActualCommittee = []types.CommitteeMember{member}
+	conR.updateAuthorizedPeers()
+
+	// the payload claims to be from another address
+	data, err := json.Marshal(map[string]string{
+		"message":   hex.EncodeToString(cdc.MustMarshalBinaryBare(&PMQueryProposalMessage{})),
+		"peer_ip":   "10.0.0.3",
+		"peer_port": "8670",
+		"magic":     hex.EncodeToString(conR.magic[:]),
+	})
+	assert.Nil(t, err)
+
+	mi, err := conR.UnmarshalMsg(data, &memberKey.PublicKey)
+	assert.Nil(t, err)
+	assert.Equal(t, "member", mi.Peer.name)
+	assert.Equal(t, "10.0.0.2", mi.Peer.netAddr.IP.String())
+
+	other, _ := crypto.GenerateKey()
+	_, err = conR.UnmarshalMsg(data, &other.PublicKey)
+	assert.Equal(t, ErrUnauthorizedPeer, err)
+
+	// legacy http messages come with no key
+	mi, err = conR.UnmarshalMsg(data, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, "10.0.0.3", mi.Peer.netAddr.IP.String())
+}
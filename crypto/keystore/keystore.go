@@ -0,0 +1,219 @@
+// Copyright (c) 2020 The Meter.io developers
+
+// Distributed under the GNU Lesser General Public License v3.0 software license, see the accompanying
+// file LICENSE or <https://www.gnu.org/licenses/lgpl-3.0.html>
+
+// Package keystore implements a password protected storage format for the node master key,
+// which combines the ECDSA private key and the BLS consensus private key.
+// The layout follows the web3 secret storage (v3) definition: the key is derived with scrypt,
+// the payload is encrypted with aes-128-ctr and authenticated with keccak256(dk[16:32] ++ ciphertext).
+package keystore
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/pborman/uuid"
+	"golang.org/x/crypto/scrypt"
+)
+
+const (
+	// Version of the master keystore format.
+	Version = 1
+
+	// StandardScryptN is the N parameter of scrypt, using 256MB memory and taking approximately 1s CPU time.
+	StandardScryptN = 1 << 18
+	// StandardScryptP is the P parameter of scrypt.
+	StandardScryptP = 1
+
+	scryptR     = 8
+	scryptDKLen = 32
+
+	ecdsaKeyLen = 32
+)
+
+var (
+	// ErrDecrypt is returned when the passphrase does not match.
+	ErrDecrypt = errors.New("could not decrypt master key with given passphrase")
+)
+
+// MasterKey holds the raw private key bytes of the node master key.
+type MasterKey struct {
+	ECDSA []byte // 32 bytes secp256k1 private key
+	BLS   []byte // serialized BLS private key
+}
+
+type keyJSON struct {
+	Version int        `json:"version"`
+	ID      string     `json:"id"`
+	Address string     `json:"address"`
+	Crypto  cryptoJSON `json:"crypto"`
+}
+
+type cryptoJSON struct {
+	Cipher       string                 `json:"cipher"`
+	CipherText   string                 `json:"ciphertext"`
+	CipherParams cipherParamsJSON       `json:"cipherparams"`
+	KDF          string                 `json:"kdf"`
+	KDFParams    map[string]interface{} `json:"kdfparams"`
+	MAC          string                 `json:"mac"`
+}
+
+type cipherParamsJSON struct {
+	IV string `json:"iv"`
+}
+
+// IsEncrypted returns whether data looks like an encrypted master keystore.
+func IsEncrypted(data []byte) bool {
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 || data[0] != '{' {
+		return false
+	}
+	var k keyJSON
+	if err := json.Unmarshal(data, &k); err != nil {
+		return false
+	}
+	return k.Version == Version && k.Crypto.CipherText != ""
+}
+
+// Encrypt encrypts the master key with the passphrase, using the given scrypt parameters.
+func Encrypt(key *MasterKey, passphrase string, scryptN, scryptP int) ([]byte, error) {
+	if len(key.ECDSA) != ecdsaKeyLen {
+		return nil, fmt.Errorf("invalid ECDSA key length %d", len(key.ECDSA))
+	}
+	ecdsaKey, err := crypto.ToECDSA(key.ECDSA)
+	if err != nil {
+		return nil, err
+	}
+
+	salt := make([]byte, 32)
+	if _, err := io.ReadFull(rand.Reader, salt); err != nil {
+		return nil, err
+	}
+	derivedKey, err := scrypt.Key([]byte(passphrase), salt, scryptN, scryptR, scryptP, scryptDKLen)
+	if err != nil {
+		return nil, err
+	}
+
+	iv := make([]byte, aes.BlockSize)
+	if _, err := io.ReadFull(rand.Reader, iv); err != nil {
+		return nil, err
+	}
+	plainText := append(append([]byte{}, key.ECDSA...), key.BLS...)
+	cipherText, err := aesCTRXOR(derivedKey[:16], plainText, iv)
+	if err != nil {
+		return nil, err
+	}
+	mac := crypto.Keccak256(derivedKey[16:32], cipherText)
+
+	return json.Marshal(&keyJSON{
+		Version: Version,
+		ID:      uuid.NewRandom().String(),
+		Address: hex.EncodeToString(crypto.PubkeyToAddress(ecdsaKey.PublicKey).Bytes()),
+		Crypto: cryptoJSON{
+			Cipher:       "aes-128-ctr",
+			CipherText:   hex.EncodeToString(cipherText),
+			CipherParams: cipherParamsJSON{IV: hex.EncodeToString(iv)},
+			KDF:          "scrypt",
+			KDFParams: map[string]interface{}{
+				"n":     scryptN,
+				"r":     scryptR,
+				"p":     scryptP,
+				"dklen": scryptDKLen,
+				"salt":  hex.EncodeToString(salt),
+			},
+			MAC: hex.EncodeToString(mac),
+		},
+	})
+}
+
+// Decrypt decrypts the master keystore with the passphrase.
+func Decrypt(data []byte, passphrase string) (*MasterKey, error) {
+	var k keyJSON
+	if err := json.Unmarshal(data, &k); err != nil {
+		return nil, err
+	}
+	if k.Version != Version {
+		return nil, fmt.Errorf("unsupported master keystore version %d", k.Version)
+	}
+	if k.Crypto.Cipher != "aes-128-ctr" {
+		return nil, fmt.Errorf("unsupported cipher %q", k.Crypto.Cipher)
+	}
+	if k.Crypto.KDF != "scrypt" {
+		return nil, fmt.Errorf("unsupported kdf %q", k.Crypto.KDF)
+	}
+
+	mac, err := hex.DecodeString(k.Crypto.MAC)
+	if err != nil {
+		return nil, err
+	}
+	iv, err := hex.DecodeString(k.Crypto.CipherParams.IV)
+	if err != nil {
+		return nil, err
+	}
+	cipherText, err := hex.DecodeString(k.Crypto.CipherText)
+	if err != nil {
+		return nil, err
+	}
+	salt, err := hex.DecodeString(fmt.Sprint(k.Crypto.KDFParams["salt"]))
+	if err != nil {
+		return nil, err
+	}
+	n, r, p, dkLen := kdfInt(k.Crypto.KDFParams, "n"), kdfInt(k.Crypto.KDFParams, "r"), kdfInt(k.Crypto.KDFParams, "p"), kdfInt(k.Crypto.KDFParams, "dklen")
+	if dkLen != scryptDKLen {
+		return nil, errors.New("invalid kdf dklen")
+	}
+	// bound the params, a crafted key file must not make scrypt exhaust memory or cpu
+	if n <= 1 || n > StandardScryptN || r <= 0 || r > scryptR || p <= 0 || p > StandardScryptP {
+		return nil, fmt.Errorf("unsupported scrypt params n=%d r=%d p=%d", n, r, p)
+	}
+	derivedKey, err := scrypt.Key([]byte(passphrase), salt, n, r, p, dkLen)
+	if err != nil {
+		return nil, err
+	}
+
+	if !bytes.Equal(crypto.Keccak256(derivedKey[16:32], cipherText), mac) {
+		return nil, ErrDecrypt
+	}
+	plainText, err := aesCTRXOR(derivedKey[:16], cipherText, iv)
+	if err != nil {
+		return nil, err
+	}
+	if len(plainText) < ecdsaKeyLen {
+		return nil, errors.New("invalid master key payload")
+	}
+	return &MasterKey{
+		ECDSA: plainText[:ecdsaKeyLen],
+		BLS:   plainText[ecdsaKeyLen:],
+	}, nil
+}
+
+func kdfInt(params map[string]interface{}, name string) int {
+	// json numbers are decoded as float64
+	if f, ok := params[name].(float64); ok {
+		return int(f)
+	}
+	return 0
+}
+
+func aesCTRXOR(key, inText, iv []byte) ([]byte, error) {
+	if len(iv) != aes.BlockSize {
+		return nil, errors.New("invalid iv length")
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	stream := cipher.NewCTR(block, iv)
+	outText := make([]byte, len(inText))
+	stream.XORKeyStream(outText, inText)
+	return outText, nil
+}
@@ -0,0 +1,77 @@
+// Copyright (c) 2020 The Meter.io developers
+
+// Distributed under the GNU Lesser General Public License v3.0 software license, see the accompanying
+// file LICENSE or <https://www.gnu.org/licenses/lgpl-3.0.html>
+
+package keystore_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/meterio/meter-pov/crypto/keystore"
+	"github.com/stretchr/testify/assert"
+)
+
+// light scrypt parameters to keep the test fast
+const (
+	testScryptN = 1 << 4
+	testScryptP = 1
+)
+
+func TestEncryptDecrypt(t *testing.T) {
+	ecdsaKey, _ := crypto.GenerateKey()
+	key := &keystore.MasterKey{
+		ECDSA: crypto.FromECDSA(ecdsaKey),
+		BLS:   []byte("bls private key bytes"),
+	}
+
+	data, err := keystore.Encrypt(key, "passphrase", testScryptN, testScryptP)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.True(t, keystore.IsEncrypted(data))
+	assert.False(t, keystore.IsEncrypted([]byte("cGxhaW4=:::cGxhaW4=")))
+
+	decrypted, err := keystore.Decrypt(data, "passphrase")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, key, decrypted)
+
+	_, err = keystore.Decrypt(data, "wrong")
+	assert.Equal(t, keystore.ErrDecrypt, err)
+}
+
+func TestDecryptInvalidParams(t *testing.T) {
+	ecdsaKey, _ := crypto.GenerateKey()
+	data, err := keystore.Encrypt(&keystore.MasterKey{ECDSA: crypto.FromECDSA(ecdsaKey)}, "passphrase", testScryptN, testScryptP)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tamper := func(f func(c map[string]interface{})) []byte {
+		var k map[string]interface{}
+		if err := json.Unmarshal(data, &k); err != nil {
+			t.Fatal(err)
+		}
+		f(k["crypto"].(map[string]interface{}))
+		tampered, _ := json.Marshal(k)
+		return tampered
+	}
+
+	// short iv must not panic
+	_, err = keystore.Decrypt(tamper(func(c map[string]interface{}) {
+		c["cipherparams"] = map[string]interface{}{"iv": "00"}
+	}), "passphrase")
+	assert.NotNil(t, err)
+
+	// scrypt params above the standard ones
+	for _, param := range []string{"n", "r", "p"} {
+		_, err = keystore.Decrypt(tamper(func(c map[string]interface{}) {
+			c["kdfparams"].(map[string]interface{})[param] = 1 << 30
+		}), "passphrase")
+		assert.NotNil(t, err, param)
+	}
+}
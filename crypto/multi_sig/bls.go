@@ -236,6 +236,16 @@ func GenKeys(system System) (PublicKey, PrivateKey, error) {
 
 }
 
+// Derive the public key from the given private key. This function allocates C
+// structures on the C heap using malloc. It is the responsibility of the caller
+// to prevent memory leaks by arranging for the C structures to be freed.
+func (system System) PubKeyFromPrivKey(privKey PrivateKey) PublicKey {
+	gx := (*C.struct_element_s)(C.malloc(sizeOfElement))
+	C.element_init_G2(gx, system.pairing.get)
+	C.element_pow_zn(gx, system.g.get, privKey.x.get)
+	return PublicKey{system, Element{gx}}
+}
+
 // Generate a key pair from the given cryptosystem and divide each key into n
 // shares such that t shares can combine signatures to recover a threshold
 // signature. This function allocates C structures on the C heap using malloc.
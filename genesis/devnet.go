@@ -79,6 +79,8 @@ func NewDevnet() *Genesis {
 			state.SetCode(builtin.Params.Address, builtin.Params.RuntimeBytecodes())
 			state.SetCode(builtin.Prototype.Address, builtin.Prototype.RuntimeBytecodes())
 			state.SetCode(builtin.Extension.Address, builtin.Extension.RuntimeBytecodes())
+			// the staking contract is deployed at the fork block, which is the genesis here
+			state.SetCode(builtin.Staking.Address, emptyRuntimeBytecode)
 
 			tokenSupply := &big.Int{}
 			energySupply := &big.Int{}
@@ -111,6 +113,8 @@ func NewDevnet() *Genesis {
 	if err != nil {
 		panic(err)
 	}
+	// all forks are enabled from the start
+	meter.RegisterForkConfig(id, meter.ForkConfig{})
 
 	return &Genesis{builder, id, "devnet"}
 }
@@ -3,6 +3,7 @@ module github.com/meterio/meter-pov
 go 1.17
 
 require (
+	github.com/BurntSushi/toml v0.3.1
 	github.com/beevik/ntp v0.2.0
 	github.com/btcsuite/btcd v0.0.0-20190213025234-306aecffea32
 	github.com/davecgh/go-spew v1.1.1
@@ -49,7 +50,7 @@ require (
 	github.com/aristanetworks/goarista v0.0.0-20170210015632-ea17b1a17847 // indirect
 	github.com/beorn7/perks v1.0.0 // indirect
 	github.com/btcsuite/btclog v0.0.0-20170628155309-84c8d2346e9f // indirect
-	github.com/btcsuite/btcutil v0.0.0-20190207003914-4c204d697803 // indirect
+	github.com/btcsuite/btcutil v0.0.0-20190207003914-4c204d697803
 	github.com/btcsuite/go-socks v0.0.0-20170105172521-4720035b7bfd // indirect
 	github.com/btcsuite/websocket v0.0.0-20150119174127-31079b680792 // indirect
 	github.com/cespare/cp v1.1.1 // indirect
@@ -0,0 +1,207 @@
+// Copyright (c) 2020 The Meter.io developers
+
+// Distributed under the GNU Lesser General Public License v3.0 software license, see the accompanying
+// file LICENSE or <https://www.gnu.org/licenses/lgpl-3.0.html>
+
+package lightclient
+
+import (
+	"bytes"
+	b64 "encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/meterio/meter-pov/block"
+	bls "github.com/meterio/meter-pov/crypto/multi_sig"
+	"github.com/meterio/meter-pov/crypto/vrf"
+	"github.com/meterio/meter-pov/meter"
+	"github.com/meterio/meter-pov/script/staking"
+	"github.com/meterio/meter-pov/state"
+	"github.com/meterio/meter-pov/trie"
+)
+
+// Config is the consensus config of the network, which sizes the committee in the same
+// manner as the committee-max-size and delegate-max-size flags of the node.
+type Config struct {
+	MaxCommitteeSize int
+	MaxDelegateSize  int
+}
+
+// Epoch carries the committee of an epoch, and the data which binds the committee to the
+// K-block which ends the previous epoch.
+type Epoch struct {
+	Header    *block.Header // first block after the K-block, which carries the committee
+	Committee block.CommitteeInfos
+
+	// VRF proof of the K-block, which gives the nonce to choose the committee
+	NonceProof []byte
+	// merkle proofs of the staking module account against the state root of the K-block,
+	// and of the delegate list against the storage root of the account
+	AccountProof   [][]byte
+	DelegatesProof [][]byte
+}
+
+// Committee is the committee of an epoch, which signs the blocks proposed in the epoch.
+type Committee struct {
+	Epoch uint64
+	// ID and Number of the first block after the K-block, which carries the committee.
+	ID     meter.Bytes32
+	Number uint32
+
+	pubKeys []bls.PublicKey // BLS public keys by CSIndex
+}
+
+// NewCommittee builds the committee carried by the epoch, after checking it's the committee
+// chosen by consensus with the nonce and the delegates of the K-block.
+func NewCommittee(system *bls.System, config Config, kblock *block.Header, e *Epoch) (*Committee, error) {
+	if len(e.Committee.CommitteeInfo) == 0 {
+		return nil, errNoCommittee
+	}
+	nonce, err := kblockNonce(kblock, e.NonceProof)
+	if err != nil {
+		return nil, err
+	}
+	delegates, err := provenDelegates(kblock.StateRoot(), e)
+	if err != nil {
+		return nil, err
+	}
+	members, err := chooseCommittee(config, delegates, nonce)
+	if err != nil {
+		return nil, err
+	}
+	if len(members) != len(e.Committee.CommitteeInfo) {
+		return nil, errCommitteeMismatch
+	}
+
+	pubKeys := make([]bls.PublicKey, len(members))
+	for i, ci := range e.Committee.CommitteeInfo {
+		if int(ci.CSIndex) != i || !bytes.Equal(ci.PubKey, members[i].pubKey) || !bytes.Equal(ci.CSPubKey, members[i].blsPubKey) {
+			return nil, errCommitteeMismatch
+		}
+		pubKey, err := system.PubKeyFromBytes(ci.CSPubKey)
+		if err != nil {
+			return nil, fmt.Errorf("invalid public key of committee member %v: %v", ci.CSIndex, err)
+		}
+		pubKeys[i] = pubKey
+	}
+	return &Committee{
+		Epoch:   e.Committee.Epoch,
+		ID:      e.Header.ID(),
+		Number:  e.Header.Number(),
+		pubKeys: pubKeys,
+	}, nil
+}
+
+// Size returns the number of committee members.
+func (c *Committee) Size() int {
+	return len(c.pubKeys)
+}
+
+// hasQuorum returns whether the voters are at least two thirds of the committee, in the
+// same manner as consensus.MajorityTwoThird.
+func (c *Committee) hasQuorum(voters int) bool {
+	return voters*3 >= c.Size()*2
+}
+
+// kblockNonce returns the nonce of the K-block, which is given by the VRF proof of the
+// proposer on the parent ID, as checked by ConsensusReactor.validateKBlock.
+func kblockNonce(kblock *block.Header, proof []byte) (uint64, error) {
+	if len(proof) < 4 {
+		return 0, errInvalidNonce
+	}
+	pub, err := crypto.SigToPub(kblock.SigningHash().Bytes(), kblock.Body.Signature)
+	if err != nil {
+		return 0, err
+	}
+	parentID := kblock.ParentID()
+	if _, err := vrf.Verify(pub, parentID[:], proof); err != nil {
+		return 0, errInvalidNonce
+	}
+	return uint64(binary.LittleEndian.Uint32(proof)), nil
+}
+
+// provenDelegates returns the delegate list in the staking state, proved against the state
+// root of the K-block.
+func provenDelegates(stateRoot meter.Bytes32, e *Epoch) ([]*staking.Delegate, error) {
+	value, err := trie.VerifySecureProof(stateRoot, staking.StakingModuleAddr[:], e.AccountProof)
+	if err != nil {
+		return nil, fmt.Errorf("invalid account proof: %v", err)
+	}
+	if value == nil {
+		return nil, errNoDelegates
+	}
+	var acc state.Account
+	if err := rlp.DecodeBytes(value, &acc); err != nil {
+		return nil, err
+	}
+	raw, err := trie.VerifySecureProof(meter.BytesToBytes32(acc.StorageRoot), staking.DelegateListKey[:], e.DelegatesProof)
+	if err != nil {
+		return nil, fmt.Errorf("invalid delegates proof: %v", err)
+	}
+	if raw == nil {
+		return nil, errNoDelegates
+	}
+	var delegates []*staking.Delegate
+	if err := rlp.DecodeBytes(raw, &delegates); err != nil {
+		return nil, err
+	}
+	return delegates, nil
+}
+
+type member struct {
+	pubKey    []byte // ecdsa
+	blsPubKey []byte
+	commitKey []byte
+}
+
+// chooseCommittee returns the committee chosen from the delegates with the nonce, which must
+// be kept the same as ConsensusReactor.CalcCommitteeByNonce.
+func chooseCommittee(config Config, delegates []*staking.Delegate, nonce uint64) ([]*member, error) {
+	delegateSize := len(delegates)
+	if delegateSize > config.MaxDelegateSize {
+		delegateSize = config.MaxDelegateSize
+	}
+	committeeSize := delegateSize
+	if committeeSize > config.MaxCommitteeSize {
+		committeeSize = config.MaxCommitteeSize
+	}
+
+	buf := make([]byte, binary.MaxVarintLen64)
+	binary.PutUvarint(buf, nonce)
+
+	members := make([]*member, 0, delegateSize)
+	for _, d := range delegates[:delegateSize] {
+		// the public key of a delegate is the ecdsa and the BLS public keys in base64,
+		// joined by ":::"
+		split := strings.Split(string(d.PubKey), ":::")
+		if len(split) != 2 {
+			return nil, fmt.Errorf("invalid public key of delegate %v", d.Address)
+		}
+		pubKeyBytes, err := b64.StdEncoding.DecodeString(split[0])
+		if err != nil {
+			return nil, fmt.Errorf("invalid public key of delegate %v: %v", d.Address, err)
+		}
+		pubKey, err := crypto.UnmarshalPubkey(pubKeyBytes)
+		if err != nil {
+			return nil, fmt.Errorf("invalid public key of delegate %v: %v", d.Address, err)
+		}
+		blsPubKey, err := b64.StdEncoding.DecodeString(split[1])
+		if err != nil {
+			return nil, fmt.Errorf("invalid BLS public key of delegate %v: %v", d.Address, err)
+		}
+		members = append(members, &member{
+			pubKey:    crypto.FromECDSAPub(pubKey),
+			blsPubKey: blsPubKey,
+			commitKey: crypto.Keccak256(append(crypto.FromECDSAPub(pubKey), buf...)),
+		})
+	}
+
+	sort.SliceStable(members, func(i, j int) bool {
+		return bytes.Compare(members[i].commitKey, members[j].commitKey) <= 0
+	})
+	return members[:committeeSize], nil
+}
@@ -0,0 +1,189 @@
+// Copyright (c) 2020 The Meter.io developers
+
+// Distributed under the GNU Lesser General Public License v3.0 software license, see the accompanying
+// file LICENSE or <https://www.gnu.org/licenses/lgpl-3.0.html>
+
+// Package lightclient verifies the finality of blocks with the headers and the quorum certs
+// only, without executing transactions.
+//
+// Starting from a trusted K-block, the client learns the committee from the first block after
+// the K-block, and verifies the aggregated BLS signature of the quorum cert of any block in the
+// epoch. Once the next K-block is verified, the client hands off to the committee carried by
+// the child of that K-block.
+//
+// Committee infos are not committed to by the block ID, so they are trusted only if they are
+// the committee consensus chooses for the K-block: the nonce is checked with the VRF proof of
+// the K-block proposer, and the delegates with merkle proofs of the staking state against the
+// state root of the K-block. The committee is then sorted with the nonce and compared with
+// the committee infos member by member.
+package lightclient
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	"github.com/meterio/meter-pov/block"
+	bls "github.com/meterio/meter-pov/crypto/multi_sig"
+)
+
+var (
+	errNoCommittee    = errors.New("no committee info")
+	errNotKBlock      = errors.New("not a K-block")
+	errQCMismatch     = errors.New("qc doesn't match block")
+	errNoQuorum       = errors.New("qc has no quorum")
+	errInvalidSig     = errors.New("invalid qc signature")
+	errUnknownEpoch   = errors.New("unknown epoch")
+	errInvalidHandOff = errors.New("committee is not carried by the child of the verified K-block")
+
+	errInvalidNonce      = errors.New("invalid nonce proof of K-block")
+	errNoDelegates       = errors.New("no delegates in the state of K-block")
+	errCommitteeMismatch = errors.New("committee doesn't match the delegates and nonce of K-block")
+)
+
+// Proof proves the finality of a block.
+type Proof struct {
+	Header *block.Header
+	QC     *block.QuorumCert // QC which certifies the block
+
+	// committee of the epoch, which is only needed to hand off to the next epoch
+	Epoch *Epoch
+}
+
+// LightClient verifies blocks against the committee of the epoch.
+type LightClient struct {
+	system    *bls.System
+	config    Config
+	committee *Committee
+	kblock    *block.Header // latest verified K-block
+}
+
+// New creates a light client from the trusted K-block, and the committee of the next epoch.
+func New(system *bls.System, config Config, kblock *block.Header, epoch *Epoch) (*LightClient, error) {
+	if kblock.BlockType() != block.BLOCK_TYPE_K_BLOCK {
+		return nil, errNotKBlock
+	}
+	lc := &LightClient{system: system, config: config, kblock: kblock}
+	c, err := lc.nextCommittee(epoch)
+	if err != nil {
+		return nil, err
+	}
+	lc.committee = c
+	return lc, nil
+}
+
+// Committee returns the current committee.
+func (lc *LightClient) Committee() *Committee {
+	return lc.committee
+}
+
+// KBlock returns the latest verified K-block.
+func (lc *LightClient) KBlock() *block.Header {
+	return lc.kblock
+}
+
+// nextCommittee returns the committee carried by the child of the latest verified K-block.
+func (lc *LightClient) nextCommittee(e *Epoch) (*Committee, error) {
+	if e.Header.ParentID() != lc.kblock.ID() || e.Header.LastKBlockHeight() != lc.kblock.Number() {
+		return nil, errInvalidHandOff
+	}
+	return NewCommittee(lc.system, lc.config, lc.kblock, e)
+}
+
+// Verify verifies the block of the proof against the committee of the epoch, which is handed
+// off to the committee of the proof if the block is in the epoch after the latest verified
+// K-block. The client advances to the block if it is a K-block.
+func (lc *LightClient) Verify(p *Proof) error {
+	if p.QC == nil {
+		return errQCMismatch
+	}
+	committee := lc.committee
+	if p.QC.EpochID != committee.Epoch {
+		if p.Epoch == nil || p.Epoch.Committee.Epoch != p.QC.EpochID {
+			return errUnknownEpoch
+		}
+		var err error
+		if committee, err = lc.nextCommittee(p.Epoch); err != nil {
+			return err
+		}
+	}
+	// the block is proposed after the committee block, and the epoch ends with the K-block
+	if p.Header.LastKBlockHeight()+1 != committee.Number {
+		return errUnknownEpoch
+	}
+	if err := VerifyQC(lc.system, committee, p.Header, p.QC); err != nil {
+		return err
+	}
+	lc.committee = committee
+	if p.Header.BlockType() == block.BLOCK_TYPE_K_BLOCK {
+		lc.kblock = p.Header
+	}
+	return nil
+}
+
+// VerifyQC verifies that the QC certifies the block, with the signatures of at least two
+// thirds of the committee.
+func VerifyQC(system *bls.System, committee *Committee, header *block.Header, qc *block.QuorumCert) error {
+	if qc.QCHeight != header.Number() || qc.EpochID != committee.Epoch {
+		return errQCMismatch
+	}
+	if qc.VoterMsgHash != MsgHash(header) {
+		return errQCMismatch
+	}
+
+	voters := qc.VoterBitArray()
+	if voters == nil || voters.Size() != committee.Size() {
+		return fmt.Errorf("invalid voter bit array %v", qc.VoterBitArrayStr)
+	}
+	if !committee.hasQuorum(voters.Count()) {
+		return errNoQuorum
+	}
+	hashes := make([][32]byte, 0, voters.Count())
+	pubKeys := make([]bls.PublicKey, 0, voters.Count())
+	for i, pubKey := range committee.pubKeys {
+		if voters.GetIndex(i) {
+			hashes = append(hashes, qc.VoterMsgHash)
+			pubKeys = append(pubKeys, pubKey)
+		}
+	}
+
+	sig, err := system.SigFromBytes(qc.VoterAggSig)
+	if err != nil {
+		return fmt.Errorf("invalid qc signature: %v", err)
+	}
+	defer sig.Free()
+	valid, err := bls.AggregateVerify(sig, hashes, pubKeys)
+	if err != nil || !valid {
+		return errInvalidSig
+	}
+	return nil
+}
+
+// MsgHash returns the hash of the message signed by the committee to vote for the block.
+func MsgHash(header *block.Header) [32]byte {
+	return sha256.Sum256([]byte(signMsg(header)))
+}
+
+// signMsg builds the message signed by the committee, which must be kept the same as
+// ConsensusReactor.BuildProposalBlockSignMsg.
+func signMsg(header *block.Header) string {
+	c := make([]byte, binary.MaxVarintLen32)
+	binary.BigEndian.PutUint32(c, header.BlockType())
+
+	h := make([]byte, binary.MaxVarintLen64)
+	binary.BigEndian.PutUint64(h, uint64(header.Number()))
+
+	var (
+		id        = header.ID()
+		txsRoot   = header.TxsRoot()
+		stateRoot = header.StateRoot()
+	)
+	return fmt.Sprintf("%s %s %s %s %s %s %s %s %s %s",
+		"BlockType", hex.EncodeToString(c),
+		"Height", hex.EncodeToString(h),
+		"BlockID", id.String(),
+		"TxRoot", txsRoot.String(),
+		"StateRoot", stateRoot.String())
+}
@@ -0,0 +1,285 @@
+// Copyright (c) 2020 The Meter.io developers
+
+// Distributed under the GNU Lesser General Public License v3.0 software license, see the accompanying
+// file LICENSE or <https://www.gnu.org/licenses/lgpl-3.0.html>
+
+package lightclient_test
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/sha256"
+	b64 "encoding/base64"
+	"encoding/binary"
+	"math/big"
+	"sort"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/meterio/meter-pov/block"
+	"github.com/meterio/meter-pov/consensus"
+	bls "github.com/meterio/meter-pov/crypto/multi_sig"
+	"github.com/meterio/meter-pov/crypto/vrf"
+	cmn "github.com/meterio/meter-pov/libs/common"
+	"github.com/meterio/meter-pov/lightclient"
+	"github.com/meterio/meter-pov/lvldb"
+	"github.com/meterio/meter-pov/meter"
+	"github.com/meterio/meter-pov/script/staking"
+	"github.com/meterio/meter-pov/state"
+	"github.com/meterio/meter-pov/types"
+	"github.com/stretchr/testify/assert"
+)
+
+var config = lightclient.Config{MaxCommitteeSize: 4, MaxDelegateSize: 10}
+
+type member struct {
+	key     *ecdsa.PrivateKey
+	pubKey  bls.PublicKey
+	privKey bls.PrivateKey
+}
+
+func newSystem(t *testing.T) bls.System {
+	pairing := bls.GenPairing(bls.GenParamsTypeA(160, 512))
+	system, err := bls.GenSystem(pairing)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return system
+}
+
+func newDelegates(t *testing.T, system bls.System, size int) []member {
+	members := make([]member, size)
+	for i := range members {
+		key, _ := crypto.GenerateKey()
+		pubKey, privKey, err := bls.GenKeys(system)
+		if err != nil {
+			t.Fatal(err)
+		}
+		members[i] = member{key, pubKey, privKey}
+	}
+	return members
+}
+
+// newState returns the root of the state with the delegates in the staking state.
+func newState(t *testing.T, kv *lvldb.LevelDB, system bls.System, delegates []member) meter.Bytes32 {
+	st, _ := state.New(meter.Bytes32{}, kv)
+	list := make([]*staking.Delegate, 0, len(delegates))
+	for _, d := range delegates {
+		pubKey := b64.StdEncoding.EncodeToString(crypto.FromECDSAPub(&d.key.PublicKey)) + ":::" +
+			b64.StdEncoding.EncodeToString(system.PubKeyToBytes(d.pubKey))
+		list = append(list, &staking.Delegate{
+			Address:     meter.Address(crypto.PubkeyToAddress(d.key.PublicKey)),
+			PubKey:      []byte(pubKey),
+			VotingPower: big.NewInt(1),
+		})
+	}
+	st.SetBalance(staking.StakingModuleAddr, big.NewInt(1))
+	st.EncodeStorage(staking.StakingModuleAddr, staking.DelegateListKey, func() ([]byte, error) {
+		return rlp.EncodeToBytes(list)
+	})
+	root, err := st.Stage().Commit()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return root
+}
+
+func newHeader(parentID meter.Bytes32, blockType uint32, lastKBlock uint32, stateRoot meter.Bytes32, key *ecdsa.PrivateKey) *block.Header {
+	blk := new(block.Builder).
+		ParentID(parentID).
+		BlockType(blockType).
+		LastKBlockHeight(lastKBlock).
+		StateRoot(stateRoot).
+		Build()
+	sig, _ := crypto.Sign(blk.Header().SigningHash().Bytes(), key)
+	return blk.WithSignature(sig).Header()
+}
+
+// newKBlock returns the K-block proposed by the key, with the VRF proof of the nonce.
+func newKBlock(parentID meter.Bytes32, lastKBlock uint32, stateRoot meter.Bytes32) (*block.Header, []byte) {
+	key, _ := crypto.GenerateKey()
+	_, proof, _ := vrf.Prove(key, parentID[:])
+	return newHeader(parentID, block.BLOCK_TYPE_K_BLOCK, lastKBlock, stateRoot, key), proof
+}
+
+// chooseCommittee returns the committee chosen from the delegates with the nonce of the
+// proof, in the same manner as ConsensusReactor.CalcCommitteeByNonce.
+func chooseCommittee(delegates []member, nonceProof []byte) []member {
+	buf := make([]byte, binary.MaxVarintLen64)
+	binary.PutUvarint(buf, uint64(binary.LittleEndian.Uint32(nonceProof)))
+
+	committee := append([]member{}, delegates...)
+	commitKey := func(m member) []byte {
+		return crypto.Keccak256(append(crypto.FromECDSAPub(&m.key.PublicKey), buf...))
+	}
+	sort.SliceStable(committee, func(i, j int) bool {
+		return bytes.Compare(commitKey(committee[i]), commitKey(committee[j])) <= 0
+	})
+	if len(committee) > config.MaxCommitteeSize {
+		committee = committee[:config.MaxCommitteeSize]
+	}
+	return committee
+}
+
+func committeeInfos(system bls.System, epoch uint64, members []member) block.CommitteeInfos {
+	infos := block.CommitteeInfos{Epoch: epoch}
+	for i, m := range members {
+		ci := block.NewCommitteeInfo("", crypto.FromECDSAPub(&m.key.PublicKey), types.NetAddress{}, system.PubKeyToBytes(m.pubKey), uint32(i))
+		infos.CommitteeInfo = append(infos.CommitteeInfo, *ci)
+	}
+	return infos
+}
+
+// newEpoch returns the epoch of the committee, with the proofs of the delegates against the
+// state of the K-block.
+func newEpoch(t *testing.T, kv *lvldb.LevelDB, kblock *block.Header, header *block.Header, infos block.CommitteeInfos, nonceProof []byte) *lightclient.Epoch {
+	st, _ := state.New(kblock.StateRoot(), kv)
+	_, accountProof, err := st.ProveAccount(staking.StakingModuleAddr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	delegatesProof, err := st.ProveStorage(staking.StakingModuleAddr, staking.DelegateListKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &lightclient.Epoch{
+		Header:         header,
+		Committee:      infos,
+		NonceProof:     nonceProof,
+		AccountProof:   accountProof,
+		DelegatesProof: delegatesProof,
+	}
+}
+
+// newQC returns the QC of the header signed by the voters.
+func newQC(system bls.System, epoch uint64, members []member, voters []int, header *block.Header) *block.QuorumCert {
+	msgHash := lightclient.MsgHash(header)
+	bitArray := cmn.NewBitArray(len(members))
+	sigs := make([]bls.Signature, 0, len(voters))
+	for _, i := range voters {
+		bitArray.SetIndex(i, true)
+		sigs = append(sigs, bls.Sign(msgHash, members[i].privKey))
+	}
+	aggSig, _ := bls.Aggregate(sigs, system)
+	return &block.QuorumCert{
+		QCHeight:         header.Number(),
+		EpochID:          epoch,
+		VoterBitArrayStr: bitArray.String(),
+		VoterMsgHash:     msgHash,
+		VoterAggSig:      system.SigToBytes(aggSig),
+	}
+}
+
+func TestMsgHash(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	header := newHeader(meter.Bytes32{}, block.BLOCK_TYPE_M_BLOCK, 0, meter.BytesToBytes32([]byte("state")), key)
+	var (
+		id        = header.ID()
+		txsRoot   = header.TxsRoot()
+		stateRoot = header.StateRoot()
+	)
+	msg := (*consensus.ConsensusReactor)(nil).BuildProposalBlockSignMsg(header.BlockType(), uint64(header.Number()), &id, &txsRoot, &stateRoot)
+	assert.Equal(t, sha256.Sum256([]byte(msg)), lightclient.MsgHash(header))
+}
+
+func TestLightClient(t *testing.T) {
+	system := newSystem(t)
+	kv, _ := lvldb.NewMem()
+	delegates1 := newDelegates(t, system, 5)
+	delegates2 := newDelegates(t, system, 3)
+	proposer, _ := crypto.GenerateKey()
+
+	// k0 (trusted) <- c1 (committee 1) <- m1 <- k1 <- c2 (committee 2)
+	k0, nonceProof0 := newKBlock(meter.Bytes32{}, 0, newState(t, kv, system, delegates1))
+	c1 := newHeader(k0.ID(), block.BLOCK_TYPE_M_BLOCK, k0.Number(), k0.StateRoot(), proposer)
+	m1 := newHeader(c1.ID(), block.BLOCK_TYPE_M_BLOCK, k0.Number(), k0.StateRoot(), proposer)
+	k1, nonceProof1 := newKBlock(m1.ID(), k0.Number(), newState(t, kv, system, delegates2))
+	c2 := newHeader(k1.ID(), block.BLOCK_TYPE_M_BLOCK, k1.Number(), k1.StateRoot(), proposer)
+
+	members1 := chooseCommittee(delegates1, nonceProof0)
+	members2 := chooseCommittee(delegates2, nonceProof1)
+	epoch1 := newEpoch(t, kv, k0, c1, committeeInfos(system, 1, members1), nonceProof0)
+	epoch2 := newEpoch(t, kv, k1, c2, committeeInfos(system, 2, members2), nonceProof1)
+
+	_, err := lightclient.New(&system, config, c1, epoch1)
+	assert.NotNil(t, err, "not a K-block")
+	_, err = lightclient.New(&system, config, k0, newEpoch(t, kv, k0, m1, epoch1.Committee, nonceProof0))
+	assert.NotNil(t, err, "not the child of the K-block")
+
+	lc, err := lightclient.New(&system, config, k0, epoch1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, uint64(1), lc.Committee().Epoch)
+	assert.Equal(t, 4, lc.Committee().Size())
+
+	// 2 of 4 is not a quorum
+	assert.NotNil(t, lc.Verify(&lightclient.Proof{Header: m1, QC: newQC(system, 1, members1, []int{0, 1}, m1)}))
+	// signed by other keys
+	assert.NotNil(t, lc.Verify(&lightclient.Proof{Header: m1, QC: newQC(system, 1, members2, []int{0, 1, 2}, m1)}))
+	// QC of other block
+	qc := newQC(system, 1, members1, []int{0, 1, 2}, c1)
+	qc.QCHeight = m1.Number()
+	assert.NotNil(t, lc.Verify(&lightclient.Proof{Header: m1, QC: qc}))
+
+	assert.Nil(t, lc.Verify(&lightclient.Proof{Header: c1, QC: newQC(system, 1, members1, []int{0, 1, 2, 3}, c1)}))
+	assert.Nil(t, lc.Verify(&lightclient.Proof{Header: m1, QC: newQC(system, 1, members1, []int{0, 2, 3}, m1)}))
+	assert.Equal(t, k0.ID(), lc.KBlock().ID())
+
+	// committee 2 is not handed off before k1 is verified
+	proof2 := &lightclient.Proof{
+		Header: c2,
+		QC:     newQC(system, 2, members2, []int{0, 1}, c2),
+		Epoch:  epoch2,
+	}
+	assert.NotNil(t, lc.Verify(proof2))
+	assert.Equal(t, uint64(1), lc.Committee().Epoch)
+
+	assert.Nil(t, lc.Verify(&lightclient.Proof{Header: k1, QC: newQC(system, 1, members1, []int{1, 2, 3}, k1)}))
+	assert.Equal(t, k1.ID(), lc.KBlock().ID())
+
+	assert.Nil(t, lc.Verify(proof2))
+	assert.Equal(t, uint64(2), lc.Committee().Epoch)
+	assert.Equal(t, c2.ID(), lc.Committee().ID)
+
+	// blocks of the previous epoch are no longer verified
+	assert.NotNil(t, lc.Verify(&lightclient.Proof{Header: m1, QC: newQC(system, 1, members1, []int{0, 2, 3}, m1)}))
+}
+
+func TestNewCommittee(t *testing.T) {
+	system := newSystem(t)
+	kv, _ := lvldb.NewMem()
+	delegates := newDelegates(t, system, 5)
+	proposer, _ := crypto.GenerateKey()
+
+	kblock, nonceProof := newKBlock(meter.Bytes32{}, 0, newState(t, kv, system, delegates))
+	header := newHeader(kblock.ID(), block.BLOCK_TYPE_M_BLOCK, kblock.Number(), kblock.StateRoot(), proposer)
+	members := chooseCommittee(delegates, nonceProof)
+
+	committee, err := lightclient.NewCommittee(&system, config, kblock, newEpoch(t, kv, kblock, header, committeeInfos(system, 1, members), nonceProof))
+	assert.Nil(t, err)
+	assert.Equal(t, 4, committee.Size())
+
+	invalid := map[string]*lightclient.Epoch{
+		"no committee": newEpoch(t, kv, kblock, header, block.CommitteeInfos{Epoch: 1}, nonceProof),
+		// a committee of the first member only, who would have the quorum alone
+		"fewer members": newEpoch(t, kv, kblock, header, committeeInfos(system, 1, members[:1]), nonceProof),
+		"other keys":    newEpoch(t, kv, kblock, header, committeeInfos(system, 1, newDelegates(t, system, 4)), nonceProof),
+		"other order":   newEpoch(t, kv, kblock, header, committeeInfos(system, 1, []member{members[1], members[0], members[2], members[3]}), nonceProof),
+	}
+	// the nonce proof of another proposer
+	_, otherProof := newKBlock(meter.Bytes32{}, 0, kblock.StateRoot())
+	invalid["other nonce"] = newEpoch(t, kv, kblock, header, committeeInfos(system, 1, members), otherProof)
+	// the delegates of another state
+	other := newEpoch(t, kv, kblock, header, committeeInfos(system, 1, members), nonceProof)
+	otherKBlock, _ := newKBlock(meter.Bytes32{}, 0, newState(t, kv, system, delegates[1:]))
+	otherEpoch := newEpoch(t, kv, otherKBlock, header, committeeInfos(system, 1, members), nonceProof)
+	other.AccountProof, other.DelegatesProof = otherEpoch.AccountProof, otherEpoch.DelegatesProof
+	invalid["other state"] = other
+
+	for name, e := range invalid {
+		_, err := lightclient.NewCommittee(&system, config, kblock, e)
+		assert.NotNil(t, err, name)
+	}
+}
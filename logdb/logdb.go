@@ -10,11 +10,13 @@ import (
 	"database/sql"
 	"fmt"
 	"math/big"
+	"time"
 
 	sqlite3 "github.com/mattn/go-sqlite3"
 	"github.com/meterio/meter-pov/block"
 	"github.com/meterio/meter-pov/meter"
 	"github.com/meterio/meter-pov/tx"
+	"github.com/prometheus/client_golang/prometheus"
 )
 
 type LogDB struct {
@@ -37,6 +39,9 @@ func GetGlobalLogDBInstance() *LogDB {
 
 // New create or open log db at given path.
 func New(path string) (logDB *LogDB, err error) {
+	prometheus.Register(logDBCommitDuration)
+	prometheus.Register(logDBRowsCounter)
+
 	db, err := sql.Open("sqlite3", path)
 	if err != nil {
 		return nil, err
@@ -368,7 +373,10 @@ func (bb *BlockBatch) execInTx(proc func(*sql.Tx) error) (err error) {
 }
 
 func (bb *BlockBatch) Commit(abandonedBlocks ...meter.Bytes32) error {
-	return bb.execInTx(func(tx *sql.Tx) error {
+	start := time.Now()
+	defer func() { logDBCommitDuration.Observe(time.Since(start).Seconds()) }()
+
+	err := bb.execInTx(func(tx *sql.Tx) error {
 		for _, event := range bb.events {
 			if _, err := tx.Exec("INSERT OR REPLACE INTO event(blockID ,eventIndex, blockNumber ,blockTime ,txID ,txOrigin ,address ,topic0 ,topic1 ,topic2 ,topic3 ,topic4, data) VALUES ( ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?);",
 				event.BlockID.Bytes(),
@@ -415,6 +423,11 @@ func (bb *BlockBatch) Commit(abandonedBlocks ...meter.Bytes32) error {
 		}
 		return nil
 	})
+	if err == nil {
+		logDBRowsCounter.WithLabelValues("event").Add(float64(len(bb.events)))
+		logDBRowsCounter.WithLabelValues("transfer").Add(float64(len(bb.transfers)))
+	}
+	return err
 }
 
 func (bb *BlockBatch) ForTransaction(txID meter.Bytes32, txOrigin meter.Address) struct {
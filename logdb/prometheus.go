@@ -0,0 +1,20 @@
+// Copyright (c) 2020 The Meter.io developers
+
+// Distributed under the GNU Lesser General Public License v3.0 software license, see the accompanying
+// file LICENSE or <https://www.gnu.org/licenses/lgpl-3.0.html>
+
+package logdb
+
+import "github.com/prometheus/client_golang/prometheus"
+
+var (
+	logDBCommitDuration = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Name:    "logdb_commit_duration_seconds",
+		Help:    "Time taken to write the events and transfers of a block",
+		Buckets: prometheus.ExponentialBuckets(0.0005, 2, 14),
+	})
+	logDBRowsCounter = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "logdb_rows_written_total",
+		Help: "Counter of rows written to log db by table (event, transfer)",
+	}, []string{"table"})
+)
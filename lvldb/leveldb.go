@@ -101,6 +101,11 @@ func (ldb *LevelDB) Close() error {
 	return ldb.db.Close()
 }
 
+// Compact compacts the whole database, to reclaim the space of deleted keys.
+func (ldb *LevelDB) Compact() error {
+	return ldb.db.CompactRange(util.Range{})
+}
+
 // NewBatch create a batch for writing ops.
 func (ldb *LevelDB) NewBatch() kv.Batch {
 	return &levelDBBatch{
@@ -7,6 +7,7 @@ package meter
 
 import (
 	"fmt"
+	"math"
 
 	"github.com/inconshreveable/log15"
 )
@@ -65,6 +66,39 @@ const (
 //TeslaFork4_MainnetStartNum = 0 // around 9/1/2021 9:30 AM (Beijing)
 )
 
+// Staking storage fork
+// includes feature updates:
+// 1) staking buckets, candidates and stakeholders are saved with one storage slot per entry, instead of whole list blobs
+const (
+	StakingStorageFork_MainnetStartNum = math.MaxUint32 // not scheduled yet
+	StakingStorageFork_TestnetStartNum = math.MaxUint32 // not scheduled yet
+)
+
+// Typed eth tx fork
+// includes feature updates:
+// 1) EIP-2930 access list and EIP-1559 dynamic fee eth txs are accepted, the tx block ref decides the fork
+const (
+	TypedEthTxFork_MainnetStartNum = math.MaxUint32 // not scheduled yet
+	TypedEthTxFork_TestnetStartNum = math.MaxUint32 // not scheduled yet
+)
+
+// Bucket ops fork
+// includes feature updates:
+// 1) staking ops to redelegate a bucket to another candidate, split a bucket and merge buckets
+const (
+	BucketOpsFork_MainnetStartNum = math.MaxUint32 // not scheduled yet
+	BucketOpsFork_TestnetStartNum = math.MaxUint32 // not scheduled yet
+)
+
+// Consensus transport fork
+// includes feature updates:
+// 1) consensus messages are carried over authenticated connections only, the http posts to
+//    /pacemaker and /committee are not sent nor accepted anymore
+const (
+	ConsensusTransportFork_MainnetStartNum = math.MaxUint32 // not scheduled yet
+	ConsensusTransportFork_TestnetStartNum = math.MaxUint32 // not scheduled yet
+)
+
 // start block number support sys-contract
 var (
 	//SysContractStartNum uint32 = EdisonSysContractStartNum
@@ -75,6 +109,12 @@ var (
 	//TeslaFork3StartNum uint32 = TeslaFork3_MainnetStartNum
 	//TeslaFork4StartNum uint32 = TeslaFork4_MainnetStartNum
 
+	StakingStorageForkStartNum uint32 = StakingStorageFork_MainnetStartNum
+	TypedEthTxForkStartNum     uint32 = TypedEthTxFork_MainnetStartNum
+	BucketOpsForkStartNum      uint32 = BucketOpsFork_MainnetStartNum
+
+	ConsensusTransportForkStartNum uint32 = ConsensusTransportFork_MainnetStartNum
+
 	// Genesis hashes to enforce below configs on.
 	//TODO: change me
 	initGenesisHash = MustParseBytes32("0x0000000000000000000000000000000000000000000000000000000000000000")
@@ -176,6 +216,22 @@ func (c *ChainConfig) IsTestnet() bool {
 //return blockNum >= TeslaFork4StartNum
 //}
 
+func (p *ChainConfig) IsStakingStorageFork(blockNum uint32) bool {
+	return blockNum >= StakingStorageForkStartNum
+}
+
+func (p *ChainConfig) IsTypedEthTxFork(blockNum uint32) bool {
+	return blockNum >= TypedEthTxForkStartNum
+}
+
+func (p *ChainConfig) IsBucketOpsFork(blockNum uint32) bool {
+	return blockNum >= BucketOpsForkStartNum
+}
+
+func (p *ChainConfig) IsConsensusTransportFork(blockNum uint32) bool {
+	return blockNum >= ConsensusTransportForkStartNum
+}
+
 func InitBlockChainConfig(genesisID Bytes32, chainFlag string) {
 	BlockChainConfig.ChainGenesisID = genesisID
 	BlockChainConfig.ChainFlag = chainFlag
@@ -190,13 +246,28 @@ func InitBlockChainConfig(genesisID Bytes32, chainFlag string) {
 		//TeslaFork2StartNum = TeslaFork2_MainnetStartNum
 		//TeslaFork3StartNum = TeslaFork3_MainnetStartNum
 		//TeslaFork4StartNum = TeslaFork4_MainnetStartNum
-	} else {
+		StakingStorageForkStartNum = StakingStorageFork_MainnetStartNum
+		TypedEthTxForkStartNum = TypedEthTxFork_MainnetStartNum
+		BucketOpsForkStartNum = BucketOpsFork_MainnetStartNum
+		ConsensusTransportForkStartNum = ConsensusTransportFork_MainnetStartNum
+	} else if BlockChainConfig.IsTestnet() == true {
 		//SysContractStartNum = TestnetSysContractStartNum
 		//EdisonStartNum = EdisonTestnetStartNum
 		//TeslaStartNum = TeslaTestnetStartNum
 		//TeslaFork2StartNum = TeslaFork2_TestnetStartNum
 		//TeslaFork3StartNum = TeslaFork3_TestnetStartNum
 		//TeslaFork4StartNum = TeslaFork4_TestnetStartNum
+		StakingStorageForkStartNum = StakingStorageFork_TestnetStartNum
+		TypedEthTxForkStartNum = TypedEthTxFork_TestnetStartNum
+		BucketOpsForkStartNum = BucketOpsFork_TestnetStartNum
+		ConsensusTransportForkStartNum = ConsensusTransportFork_TestnetStartNum
+	} else {
+		// private networks start with the keyed staking storage, typed eth txs, bucket ops and
+		// the consensus transport
+		StakingStorageForkStartNum = 0
+		TypedEthTxForkStartNum = 0
+		BucketOpsForkStartNum = 0
+		ConsensusTransportForkStartNum = 0
 	}
 }
 
@@ -232,6 +303,22 @@ func InitBlockChainConfig(genesisID Bytes32, chainFlag string) {
 //	return BlockChainConfig.IsTestnet() && BlockChainConfig.IsTeslaFork3(blockNum)
 //}
 
+func IsStakingStorageFork(blockNum uint32) bool {
+	return BlockChainConfig.IsStakingStorageFork(blockNum)
+}
+
+func IsTypedEthTxFork(blockNum uint32) bool {
+	return BlockChainConfig.IsTypedEthTxFork(blockNum)
+}
+
+func IsBucketOpsFork(blockNum uint32) bool {
+	return BlockChainConfig.IsBucketOpsFork(blockNum)
+}
+
+func IsConsensusTransportFork(blockNum uint32) bool {
+	return BlockChainConfig.IsConsensusTransportFork(blockNum)
+}
+
 func IsTestNet() bool {
 	return BlockChainConfig.IsTestnet()
 }
@@ -8,35 +8,71 @@ package meter
 import (
 	"fmt"
 	"math"
+	"strings"
+	"sync"
 )
 
 // ForkConfig config for a fork.
 type ForkConfig struct {
 	FixTransferLog uint32
+	ETH_BERLIN     uint32 // EIP-2565, EIP-2929
+	ETH_LONDON     uint32 // EIP-3198, EIP-3529, EIP-3541
+	ETH_SHANGHAI   uint32 // EIP-3651, EIP-3855, EIP-3860
+
+	STAKING_CONTRACT uint32 // staking contract callable by contracts
 }
 
 func (fc ForkConfig) String() string {
-	return fmt.Sprintf("FTRL: #%v", fc.FixTransferLog)
+	var strs []string
+	push := func(name string, blockNum uint32) {
+		if blockNum != math.MaxUint32 {
+			strs = append(strs, fmt.Sprintf("%v: #%v", name, blockNum))
+		}
+	}
+
+	push("FTRL", fc.FixTransferLog)
+	push("ETH_BERLIN", fc.ETH_BERLIN)
+	push("ETH_LONDON", fc.ETH_LONDON)
+	push("ETH_SHANGHAI", fc.ETH_SHANGHAI)
+	push("STAKING_CONTRACT", fc.STAKING_CONTRACT)
+	return strings.Join(strs, ", ")
 }
 
 // NoFork a special config without any forks.
 var NoFork = ForkConfig{
 	FixTransferLog: math.MaxUint32,
+	ETH_BERLIN:     math.MaxUint32,
+	ETH_LONDON:     math.MaxUint32,
+	ETH_SHANGHAI:   math.MaxUint32,
+
+	STAKING_CONTRACT: math.MaxUint32,
 }
 
-// for well-known networks
-var forkConfigs = map[Bytes32]ForkConfig{
-	// mainnet
-	//MustParseBytes32("0x00000000851caf3cfdb6e899cf5958bfb1ac3413d346d43539627e6be7ec1b4a"): {
-	//	FixTransferLog: 1072000,
-	//},
-	// testnet
-	//MustParseBytes32("0x000000000b2bce3c70bc649a02749e8687721b09ed2e15997f466536b20bb127"): {
-	//	FixTransferLog: 1080000,
-	//},
+var (
+	forkConfigsLock sync.RWMutex
+	// for well-known networks
+	forkConfigs = map[Bytes32]ForkConfig{
+		// mainnet, the evm upgrades and the staking contract are not scheduled yet
+		MustParseBytes32("0x00000000e6bd9f14d1274633f1c7be414bc72641f88cef3a40018e44a223756a"): NoFork,
+		// testnet
+		MustParseBytes32("0x000000003fee9501e312e8340bcadd2f40f97feae246ab027f42ffee9f7103fa"): NoFork,
+	}
+)
+
+// RegisterForkConfig sets the fork config of a private network, for which forks are opt-in.
+func RegisterForkConfig(genesisID Bytes32, fc ForkConfig) {
+	forkConfigsLock.Lock()
+	defer forkConfigsLock.Unlock()
+	forkConfigs[genesisID] = fc
 }
 
 // GetForkConfig get fork config for given genesis ID.
+// Networks neither listed nor registered have no forks.
 func GetForkConfig(genesisID Bytes32) ForkConfig {
-	return forkConfigs[genesisID]
+	forkConfigsLock.RLock()
+	defer forkConfigsLock.RUnlock()
+	if fc, ok := forkConfigs[genesisID]; ok {
+		return fc
+	}
+	return NoFork
 }
@@ -44,6 +44,8 @@ const (
 	TxGas                     uint64 = 5000
 	ClauseGas                 uint64 = params.TxGas - TxGas
 	ClauseGasContractCreation uint64 = params.TxGasContractCreation - TxGas
+	TxAccessListAddressGas    uint64 = 2400 // per address in the access list of eth txs, EIP-2930
+	TxAccessListStorageKeyGas uint64 = 1900 // per storage key in the access list of eth txs, EIP-2930
 
 	// InitialGasLimit was 10 *1000 *100, only accommodates 476 Txs, block size 61k, so change to 200M
 	MinGasLimit          uint64 = 1000 * 1000
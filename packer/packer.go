@@ -72,6 +72,11 @@ func (p *Packer) Mock(parent *block.Header, targetTime uint64, gasLimit uint64,
 		beneficiary = candAddr
 	}
 
+	epoch, err := p.chain.GetBlockEpoch(parent.ID())
+	if err != nil {
+		return nil, errors.Wrap(err, "epoch")
+	}
+
 	rt := runtime.New(
 		p.chain.NewSeeker(parent.ID()),
 		state,
@@ -82,7 +87,9 @@ func (p *Packer) Mock(parent *block.Header, targetTime uint64, gasLimit uint64,
 			Time:        targetTime,
 			GasLimit:    gasLimit,
 			TotalScore:  parent.TotalScore() + 1,
+			Epoch:       epoch,
 		})
+	rt.ApplyForks()
 
 	return newFlow(p, parent, rt), nil
 }
@@ -55,13 +55,23 @@ func (h *ApiHandler) handleRecvPowMessage(w http.ResponseWriter, req *http.Reque
 	log.Debug("Recved Pow Block", "hex", string(hexBytes))
 
 	info := powpool.NewPowBlockInfoFromPowBlock(&newPowBlock)
-	h.powPool.Add(info)
+	if err := h.powPool.Add(info); err != nil {
+		if powpool.IsPowBlockRejected(err) {
+			return utils.BadRequest(err)
+		}
+		return err
+	}
 
 	return nil
 }
 
+func (h *ApiHandler) handleGetRejected(w http.ResponseWriter, req *http.Request) error {
+	return utils.WriteJSON(w, h.powPool.Rejected())
+}
+
 func (h *ApiHandler) Mount(root *mux.Router, pathPrefix string) {
 	sub := root.PathPrefix(pathPrefix).Subrouter()
 
 	sub.Path("").Methods("POST").HandlerFunc(utils.WrapHandlerFunc(h.handleRecvPowMessage))
+	sub.Path("/rejected").Methods("GET").HandlerFunc(utils.WrapHandlerFunc(h.handleGetRejected))
 }
@@ -0,0 +1,27 @@
+// Copyright (c) 2020 The Meter.io developers
+
+// Distributed under the GNU Lesser General Public License v3.0 software license, see the accompanying
+// file LICENSE or <https://www.gnu.org/licenses/lgpl-3.0.html>
+
+package powpool
+
+// powBlockRejectedError is returned when a pow block fails validation.
+// reason is a short tag used to count rejections.
+type powBlockRejectedError struct {
+	reason string
+	msg    string
+}
+
+func (e powBlockRejectedError) Error() string {
+	return "pow block rejected: " + e.msg
+}
+
+func rejected(reason, msg string) error {
+	return powBlockRejectedError{reason, msg}
+}
+
+// IsPowBlockRejected returns whether the given error indicates pow block is rejected.
+func IsPowBlockRejected(err error) bool {
+	_, ok := err.(powBlockRejectedError)
+	return ok
+}
@@ -134,7 +134,8 @@ func DecodeSignatureScript(bs []byte) (uint32, string) {
 	}
 
 	var height uint32
-	for _, b := range reverse(items[0]) {
+	// copy before reverse, items share the script of the tx
+	for _, b := range reverse(append([]byte(nil), items[0]...)) {
 		height = height*256 + uint32(b)
 	}
 
@@ -12,8 +12,10 @@ import (
 	"fmt"
 	"math/big"
 	"net/http"
+	"sync"
 	"time"
 
+	"github.com/btcsuite/btcd/chaincfg"
 	"github.com/btcsuite/btcd/rpcclient"
 	"github.com/ethereum/go-ethereum/event"
 	"github.com/meterio/meter-pov/block"
@@ -32,6 +34,10 @@ var (
 		Name: "pow_block_recved",
 		Help: "Accumulated counter for received pow blocks since last k-block",
 	})
+	powBlockRejectedCounter = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "pow_block_rejected",
+		Help: "Counter for pow blocks rejected by validation",
+	}, []string{"reason"})
 )
 
 // Options options for tx pool.
@@ -43,6 +49,8 @@ type Options struct {
 	Limit           int
 	LimitPerAccount int
 	MaxLifetime     time.Duration
+	// ChainParams params of the pow chain to validate blocks, defaults to main net
+	ChainParams *chaincfg.Params
 }
 
 type PowReward struct {
@@ -70,8 +78,12 @@ type PowPool struct {
 	stateCreator *state.Creator
 	options      Options
 	all          *powObjectMap
+	validator    *validator
 	replaying    bool
 
+	rejectedLock sync.Mutex
+	rejected     map[string]uint64
+
 	done    chan struct{}
 	powFeed event.Feed
 	scope   event.SubscriptionScope
@@ -90,17 +102,24 @@ func GetGlobPowPoolInst() *PowPool {
 // New create a new PowPool instance.
 // Shutdown is required to be called at end.
 func New(options Options, chain *chain.Chain, stateCreator *state.Creator) *PowPool {
+	params := options.ChainParams
+	if params == nil {
+		params = &chaincfg.MainNetParams
+	}
 	pool := &PowPool{
 		chain:        chain,
 		stateCreator: stateCreator,
 		replaying:    false,
 		options:      options,
 		all:          newPowObjectMap(),
+		validator:    newValidator(params),
+		rejected:     make(map[string]uint64),
 		done:         make(chan struct{}),
 	}
 	pool.goes.Go(pool.housekeeping)
 	SetGlobPowPoolInst(pool)
 	prometheus.MustRegister(powBlockRecvedGauge)
+	prometheus.MustRegister(powBlockRejectedCounter)
 
 	return pool
 }
@@ -200,30 +219,63 @@ func (p *PowPool) submitPosKblock(powHex, posHex string) (string, string) {
 
 // Add add new pow block into pool.
 // It's not assumed as an error if the pow to be added is already in the pool,
+// The block is validated against the pow chain rules, and rejected if invalid.
 func (p *PowPool) Add(newPowBlockInfo *PowBlockInfo) error {
 	if p.all.Contains(newPowBlockInfo.HeaderHash) {
 		// pow already in the pool
 		log.Debug("PowPool Add, hash already in PowPool", "hash", newPowBlockInfo.HeaderHash)
 		return nil
 	}
+	if !p.all.isKframeInitialAdded() {
+		return fmt.Errorf("Kframe is not added")
+	}
+
+	if err := p.validator.validate(p.all, newPowBlockInfo); err != nil {
+		// if parent is not in powpool, replay blocks since last kframe,
+		// which adds the parent and this block in order.
+		if e, ok := err.(powBlockRejectedError); ok && e.reason == "unknown_parent" &&
+			newPowBlockInfo.PowHeight > p.all.lastKframePowObj.Height() {
+			p.ReplayFrom(int32(p.all.lastKframePowObj.Height()) + 1)
+			if p.all.Contains(newPowBlockInfo.HeaderHash) {
+				return nil
+			}
+		}
+		p.reject(newPowBlockInfo, err)
+		return err
+	}
 
 	// XXX: disable powpool gossip
 	//p.goes.Go(func() {
 	//	p.powFeed.Send(&PowBlockEvent{BlockInfo: newPowBlockInfo})
 	//})
 	powObj := NewPowObject(newPowBlockInfo)
-	err := p.all.Add(powObj)
-
-	// if parent is not genesis and it's not contained in powpool
-	// fetch the block immediately in a coroutine
-	// Here err is set ONLY kframe is not added (not in committee).
-	// XXX: a fat chance --- the powObj is already in chain, the parent block fetch is still sent.
-	if err == nil && p.all.isKframeInitialAdded() && powObj.Height() >= p.all.lastKframePowObj.Height() && !p.all.Contains(powObj.blockInfo.HashPrevBlock) {
-		// go p.FetchPowBlock(powObj.Height() - uint32(1))
-		p.ReplayFrom(int32(p.all.lastKframePowObj.Height()) + 1)
+	return p.all.Add(powObj)
+}
+
+// reject counts the rejected pow block by reason.
+func (p *PowPool) reject(info *PowBlockInfo, err error) {
+	reason := "other"
+	if e, ok := err.(powBlockRejectedError); ok {
+		reason = e.reason
 	}
+	log.Warn("pow block rejected", "hash", info.HeaderHash, "height", info.PowHeight, "reason", reason, "err", err)
 
-	return err
+	p.rejectedLock.Lock()
+	defer p.rejectedLock.Unlock()
+	p.rejected[reason]++
+	powBlockRejectedCounter.WithLabelValues(reason).Inc()
+}
+
+// Rejected returns the number of rejected pow blocks by reason.
+func (p *PowPool) Rejected() map[string]uint64 {
+	p.rejectedLock.Lock()
+	defer p.rejectedLock.Unlock()
+
+	rejected := make(map[string]uint64, len(p.rejected))
+	for reason, n := range p.rejected {
+		rejected[reason] = n
+	}
+	return rejected
 }
 
 // Remove removes powObj from pool by its ID.
@@ -0,0 +1,201 @@
+// Copyright (c) 2020 The Meter.io developers
+
+// Distributed under the GNU Lesser General Public License v3.0 software license, see the accompanying
+// file LICENSE or <https://www.gnu.org/licenses/lgpl-3.0.html>
+
+package powpool
+
+import (
+	"bytes"
+	"fmt"
+	"math/big"
+	"sort"
+	"time"
+
+	"github.com/btcsuite/btcd/blockchain"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/btcsuite/btcutil"
+)
+
+// number of previous blocks used to calculate the median time past
+const medianTimeBlocks = 11
+
+// validator checks pow blocks before they enter the pool.
+type validator struct {
+	params     *chaincfg.Params
+	timeSource blockchain.MedianTimeSource
+}
+
+func newValidator(params *chaincfg.Params) *validator {
+	return &validator{
+		params:     params,
+		timeSource: blockchain.NewMedianTime(),
+	}
+}
+
+// header decodes the pow block header from the raw block.
+func (info *PowBlockInfo) header() (*wire.BlockHeader, error) {
+	var hdr wire.BlockHeader
+	if err := hdr.Deserialize(bytes.NewReader(info.PowRaw)); err != nil {
+		return nil, err
+	}
+	return &hdr, nil
+}
+
+// validate checks the pow block against the consensus rules of the pow chain.
+// Blocks in the pool are linked from the last kframe, so the parent must be in the pool.
+func (v *validator) validate(m *powObjectMap, info *PowBlockInfo) error {
+	blk, err := v.validateSanity(info)
+	if err != nil {
+		return err
+	}
+
+	parent := m.Get(info.HashPrevBlock)
+	if parent == nil {
+		return rejected("unknown_parent", fmt.Sprintf("parent %v not in pool", info.HashPrevBlock))
+	}
+	if info.PowHeight != parent.Height()+1 {
+		return rejected("bad_height", fmt.Sprintf("height %v does not follow parent height %v", info.PowHeight, parent.Height()))
+	}
+
+	header := &blk.Header
+	if err := v.validateTimestamp(m, header, parent); err != nil {
+		return err
+	}
+	return v.validateDifficulty(m, header, info.PowHeight, parent)
+}
+
+// validateSanity performs the checks which do not depend on other blocks, i.e.
+// the decoded info matches the raw block, the hash meets the nBits target,
+// the merkle root matches the transactions and the timestamp is not too far in future.
+func (v *validator) validateSanity(info *PowBlockInfo) (*wire.MsgBlock, error) {
+	var blk wire.MsgBlock
+	if err := blk.Deserialize(bytes.NewReader(info.PowRaw)); err != nil {
+		return nil, rejected("malformed", err.Error())
+	}
+
+	decoded := NewPowBlockInfoFromPowBlock(&blk)
+	if decoded.HeaderHash != info.HeaderHash ||
+		decoded.HashPrevBlock != info.HashPrevBlock ||
+		decoded.HashMerkleRoot != info.HashMerkleRoot ||
+		decoded.NBits != info.NBits ||
+		decoded.PowHeight != info.PowHeight ||
+		decoded.Beneficiary != info.Beneficiary {
+		return nil, rejected("malformed", "block info does not match raw block")
+	}
+
+	if err := blockchain.CheckBlockSanity(btcutil.NewBlock(&blk), v.params.PowLimit, v.timeSource); err != nil {
+		if ruleErr, ok := err.(blockchain.RuleError); ok {
+			return nil, rejected(ruleErr.ErrorCode.String(), ruleErr.Description)
+		}
+		return nil, rejected("sanity", err.Error())
+	}
+	return &blk, nil
+}
+
+// validateTimestamp ensures the timestamp is after the median time of previous blocks in the pool.
+func (v *validator) validateTimestamp(m *powObjectMap, header *wire.BlockHeader, parent *powObject) error {
+	timestamps := make([]int64, 0, medianTimeBlocks)
+	for obj := parent; obj != nil && len(timestamps) < medianTimeBlocks; obj = m.Get(obj.blockInfo.HashPrevBlock) {
+		hdr, err := obj.blockInfo.header()
+		if err != nil {
+			return err
+		}
+		timestamps = append(timestamps, hdr.Timestamp.Unix())
+	}
+	sort.Slice(timestamps, func(i, j int) bool { return timestamps[i] < timestamps[j] })
+	median := timestamps[len(timestamps)/2]
+
+	if header.Timestamp.Unix() <= median {
+		return rejected("time_too_old", fmt.Sprintf("timestamp %v is not after median time %v",
+			header.Timestamp, time.Unix(median, 0)))
+	}
+	return nil
+}
+
+// validateDifficulty ensures the nBits follows the retarget rules from the parent.
+func (v *validator) validateDifficulty(m *powObjectMap, header *wire.BlockHeader, height uint32, parent *powObject) error {
+	parentHeader, err := parent.blockInfo.header()
+	if err != nil {
+		return err
+	}
+	blocksPerRetarget := uint32(v.params.TargetTimespan / v.params.TargetTimePerBlock)
+
+	if height%blocksPerRetarget != 0 {
+		expected := parentHeader.Bits
+		if v.params.ReduceMinDifficulty {
+			allowMinTime := parentHeader.Timestamp.Add(v.params.MinDiffReductionTime)
+			if header.Timestamp.After(allowMinTime) {
+				expected = v.params.PowLimitBits
+			} else {
+				bits, ok := v.lastNonMinBits(m, parent, blocksPerRetarget)
+				if !ok {
+					// not determinable by blocks in the pool
+					return nil
+				}
+				expected = bits
+			}
+		}
+		if header.Bits != expected {
+			return rejected("bad_difficulty", fmt.Sprintf("bits 0x%x, expected 0x%x", header.Bits, expected))
+		}
+		return nil
+	}
+
+	targetTimespan := int64(v.params.TargetTimespan / time.Second)
+	factor := v.params.RetargetAdjustmentFactor
+	oldTarget := blockchain.CompactToBig(parentHeader.Bits)
+
+	// the first block of the retarget interval
+	first := parent
+	for i := uint32(0); i < blocksPerRetarget-1 && first != nil; i++ {
+		first = m.Get(first.blockInfo.HashPrevBlock)
+	}
+	if first == nil {
+		// the interval is not fully in the pool, only the adjustment limits can be checked
+		minTarget := new(big.Int).Div(oldTarget, big.NewInt(factor))
+		maxTarget := new(big.Int).Mul(oldTarget, big.NewInt(factor))
+		if maxTarget.Cmp(v.params.PowLimit) > 0 {
+			maxTarget.Set(v.params.PowLimit)
+		}
+		target := blockchain.CompactToBig(header.Bits)
+		if target.Cmp(blockchain.CompactToBig(blockchain.BigToCompact(minTarget))) < 0 ||
+			target.Cmp(maxTarget) > 0 {
+			return rejected("bad_difficulty", fmt.Sprintf("bits 0x%x exceeds retarget limits of parent bits 0x%x", header.Bits, parentHeader.Bits))
+		}
+		return nil
+	}
+
+	firstHeader, err := first.blockInfo.header()
+	if err != nil {
+		return err
+	}
+	actualTimespan := parentHeader.Timestamp.Unix() - firstHeader.Timestamp.Unix()
+	adjustedTimespan := actualTimespan
+	if minTimespan := targetTimespan / factor; actualTimespan < minTimespan {
+		adjustedTimespan = minTimespan
+	} else if maxTimespan := targetTimespan * factor; actualTimespan > maxTimespan {
+		adjustedTimespan = maxTimespan
+	}
+	newTarget := new(big.Int).Mul(oldTarget, big.NewInt(adjustedTimespan))
+	newTarget.Div(newTarget, big.NewInt(targetTimespan))
+	if newTarget.Cmp(v.params.PowLimit) > 0 {
+		newTarget.Set(v.params.PowLimit)
+	}
+	if expected := blockchain.BigToCompact(newTarget); header.Bits != expected {
+		return rejected("bad_difficulty", fmt.Sprintf("bits 0x%x, expected 0x%x", header.Bits, expected))
+	}
+	return nil
+}
+
+// lastNonMinBits returns the bits of the last block which did not use the min difficulty rule.
+// It returns false if no such block in the pool.
+func (v *validator) lastNonMinBits(m *powObjectMap, parent *powObject, blocksPerRetarget uint32) (uint32, bool) {
+	for obj := parent; obj != nil; obj = m.Get(obj.blockInfo.HashPrevBlock) {
+		if obj.Height()%blocksPerRetarget == 0 || obj.blockInfo.NBits != v.params.PowLimitBits {
+			return obj.blockInfo.NBits, true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,139 @@
+// Copyright (c) 2020 The Meter.io developers
+
+// Distributed under the GNU Lesser General Public License v3.0 software license, see the accompanying
+// file LICENSE or <https://www.gnu.org/licenses/lgpl-3.0.html>
+
+package powpool
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/hex"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/stretchr/testify/assert"
+)
+
+func loadBlocks(t *testing.T) []*PowBlockInfo {
+	f, err := os.Open("blocks.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	var infos []*PowBlockInfo
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		raw, err := hex.DecodeString(line)
+		if err != nil {
+			t.Fatal(err)
+		}
+		infos = append(infos, NewPowBlockInfo(raw))
+	}
+	return infos
+}
+
+func newTestPool() *PowPool {
+	return &PowPool{
+		all:       newPowObjectMap(),
+		validator: newValidator(&chaincfg.MainNetParams),
+		rejected:  make(map[string]uint64),
+		replaying: true, // no pow node to replay from
+	}
+}
+
+func modify(t *testing.T, info *PowBlockInfo, f func(blk *wire.MsgBlock)) *PowBlockInfo {
+	var blk wire.MsgBlock
+	if err := blk.Deserialize(bytes.NewReader(info.PowRaw)); err != nil {
+		t.Fatal(err)
+	}
+	f(&blk)
+	return NewPowBlockInfoFromPowBlock(&blk)
+}
+
+func TestValidate(t *testing.T) {
+	infos := loadBlocks(t)
+	pool := newTestPool()
+
+	assert.Nil(t, pool.all.InitialAddKframe(NewPowObject(infos[0])))
+	for _, info := range infos[1:] {
+		assert.Nil(t, pool.Add(info))
+	}
+	assert.Equal(t, len(infos), pool.all.Len())
+	assert.Empty(t, pool.Rejected())
+}
+
+func TestValidateRejects(t *testing.T) {
+	infos := loadBlocks(t)
+	pool := newTestPool()
+	assert.Nil(t, pool.all.InitialAddKframe(NewPowObject(infos[0])))
+	assert.Nil(t, pool.Add(infos[1]))
+
+	next := infos[2]
+	tests := []struct {
+		name   string
+		info   *PowBlockInfo
+		reason string
+	}{
+		{"orphan", infos[3], "unknown_parent"},
+		{"high hash", modify(t, next, func(blk *wire.MsgBlock) { blk.Header.Nonce++ }), "ErrHighHash"},
+		{"easy target", modify(t, next, func(blk *wire.MsgBlock) { blk.Header.Bits = 0x207fffff }), "ErrUnexpectedDifficulty"},
+		{"merkle root", modify(t, next, func(blk *wire.MsgBlock) { blk.Transactions[0].LockTime++ }), "ErrBadMerkleRoot"},
+	}
+	for _, tt := range tests {
+		err := pool.Add(tt.info)
+		assert.True(t, IsPowBlockRejected(err), tt.name)
+		assert.Equal(t, tt.reason, err.(powBlockRejectedError).reason, tt.name)
+		assert.False(t, pool.all.Contains(tt.info.HeaderHash), tt.name)
+	}
+
+	// info not matching the raw block
+	forged := *next
+	forged.Beneficiary[0]++
+	err := pool.Add(&forged)
+	assert.True(t, IsPowBlockRejected(err))
+	assert.Equal(t, "malformed", err.(powBlockRejectedError).reason)
+
+	assert.Equal(t, map[string]uint64{
+		"unknown_parent":          1,
+		"ErrHighHash":             1,
+		"ErrUnexpectedDifficulty": 1,
+		"ErrBadMerkleRoot":        1,
+		"malformed":               1,
+	}, pool.Rejected())
+
+	assert.Nil(t, pool.Add(next))
+}
+
+func TestValidateContext(t *testing.T) {
+	infos := loadBlocks(t)
+	pool := newTestPool()
+	assert.Nil(t, pool.all.InitialAddKframe(NewPowObject(infos[0])))
+	assert.Nil(t, pool.Add(infos[1]))
+	parent, err := infos[1].header()
+	assert.Nil(t, err)
+
+	// headers below are not mined, so check the contextual rules directly
+	hdr := *parent // same timestamp as parent
+	err = pool.validator.validateTimestamp(pool.all, &hdr, pool.all.Get(infos[1].HeaderHash))
+	assert.Equal(t, "time_too_old", err.(powBlockRejectedError).reason)
+
+	hdr.Bits = 0x1c00ffff
+	err = pool.validator.validateDifficulty(pool.all, &hdr, infos[1].PowHeight+1, pool.all.Get(infos[1].HeaderHash))
+	assert.Equal(t, "bad_difficulty", err.(powBlockRejectedError).reason)
+
+	// retarget out of the adjustment limits
+	hdr.Bits = 0x1b00ffff
+	err = pool.validator.validateDifficulty(pool.all, &hdr, 2016*100, pool.all.Get(infos[1].HeaderHash))
+	assert.Equal(t, "bad_difficulty", err.(powBlockRejectedError).reason)
+	hdr.Bits = 0x1c7fffff
+	assert.Nil(t, pool.validator.validateDifficulty(pool.all, &hdr, 2016*100, pool.all.Get(infos[1].HeaderHash)))
+}
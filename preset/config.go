@@ -37,6 +37,6 @@ var (
 )
 
 func (p *PresetConfig) ToString() string {
-	return fmt.Sprintf("CommitteeMaxSize: %v DelegateMaxSize: %v DiscoServer: %v : DiscoTopic%v",
+	return fmt.Sprintf("CommitteeMinSize: %v CommitteeMaxSize: %v DelegateMaxSize: %v DiscoServer: %v : DiscoTopic%v",
 		p.CommitteeMinSize, p.CommitteeMaxSize, p.DelegateMaxSize, p.DiscoServer, p.DiscoTopic)
 }
@@ -0,0 +1,110 @@
+// Copyright (c) 2020 The Meter.io developers
+
+// Distributed under the GNU Lesser General Public License v3.0 software license, see the accompanying
+// file LICENSE or <https://www.gnu.org/licenses/lgpl-3.0.html>
+
+package pruner
+
+import (
+	"sync"
+
+	"github.com/meterio/meter-pov/kv"
+)
+
+var _ kv.GetPutter = (*Guard)(nil)
+
+// Guard wraps the main database when pruning online. While a pruning cycle is running, it records
+// keys of trie nodes written by the node, so that a node recreated after the sweep started is never deleted.
+type Guard struct {
+	kv.GetPutter
+
+	rw      sync.RWMutex // held for writing by sweep, for reading by other writers
+	lock    sync.Mutex   // protects written
+	written map[string]struct{}
+}
+
+// NewGuard creates a guard over db.
+func NewGuard(db kv.GetPutter) *Guard {
+	return &Guard{GetPutter: db}
+}
+
+// Put implements kv.Putter.
+func (g *Guard) Put(key, value []byte) error {
+	g.rw.RLock()
+	defer g.rw.RUnlock()
+	g.record(key)
+	return g.GetPutter.Put(key, value)
+}
+
+// NewBatch implements kv.Putter.
+func (g *Guard) NewBatch() kv.Batch {
+	return &guardBatch{Batch: g.GetPutter.NewBatch(), g: g}
+}
+
+func (g *Guard) startRecording() {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+	g.written = make(map[string]struct{})
+}
+
+func (g *Guard) stopRecording() {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+	g.written = nil
+}
+
+func (g *Guard) record(key []byte) {
+	// only trie nodes and codes are swept
+	if len(key) != nodeKeyLen {
+		return
+	}
+	g.lock.Lock()
+	defer g.lock.Unlock()
+	if g.written != nil {
+		g.written[string(key)] = struct{}{}
+	}
+}
+
+// deleteUnwritten deletes keys which are not written since recording started.
+func (g *Guard) deleteUnwritten(keys [][]byte) (int, error) {
+	g.rw.Lock()
+	defer g.rw.Unlock()
+
+	g.lock.Lock()
+	batch := g.GetPutter.NewBatch()
+	for _, key := range keys {
+		if _, ok := g.written[string(key)]; ok {
+			continue
+		}
+		if err := batch.Delete(key); err != nil {
+			g.lock.Unlock()
+			return 0, err
+		}
+	}
+	g.lock.Unlock()
+
+	n := batch.Len()
+	return n, batch.Write()
+}
+
+type guardBatch struct {
+	kv.Batch
+	g    *Guard
+	keys [][]byte
+}
+
+func (b *guardBatch) Put(key, value []byte) error {
+	if len(key) == nodeKeyLen {
+		b.keys = append(b.keys, append([]byte(nil), key...))
+	}
+	return b.Batch.Put(key, value)
+}
+
+func (b *guardBatch) Write() error {
+	b.g.rw.RLock()
+	defer b.g.rw.RUnlock()
+	for _, key := range b.keys {
+		b.g.record(key)
+	}
+	return b.Batch.Write()
+}
@@ -0,0 +1,403 @@
+// Copyright (c) 2020 The Meter.io developers
+
+// Distributed under the GNU Lesser General Public License v3.0 software license, see the accompanying
+// file LICENSE or <https://www.gnu.org/licenses/lgpl-3.0.html>
+
+// Package pruner removes trie nodes and codes which are no longer reachable from retained blocks.
+//
+// A pruning cycle marks every node reachable from the state and block index tries of the last
+// KeepBlocks blocks, the last KeepKBlocks K-block checkpoints and the genesis block, then sweeps
+// all unmarked trie nodes and codes from the main database.
+package pruner
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"time"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/inconshreveable/log15"
+	"github.com/meterio/meter-pov/block"
+	"github.com/meterio/meter-pov/chain"
+	"github.com/meterio/meter-pov/co"
+	"github.com/meterio/meter-pov/kv"
+	"github.com/meterio/meter-pov/lvldb"
+	"github.com/meterio/meter-pov/meter"
+	"github.com/meterio/meter-pov/state"
+	"github.com/meterio/meter-pov/trie"
+	"github.com/pkg/errors"
+)
+
+var log = log15.New("pkg", "pruner")
+
+const (
+	// trie nodes and codes are saved with their 32 bytes hash as key
+	nodeKeyLen = 32
+
+	sweepBatchSize = 1024
+)
+
+var emptyRoot = meter.Blake2b(rlp.EmptyString)
+
+// Options options for pruner.
+type Options struct {
+	KeepBlocks  uint32        // number of recent blocks whose state is kept
+	KeepKBlocks uint32        // number of recent K-blocks whose state is kept as checkpoints
+	Interval    uint32        // online pruning runs every Interval blocks
+	GracePeriod time.Duration // wait time after recording started, for pending blocks to be added to chain
+	MarkDir     string        // directory of the temporary mark database, in memory if empty
+}
+
+// Stats statistics of a pruning cycle.
+type Stats struct {
+	Marked  int
+	Swept   int
+	Elapsed time.Duration
+}
+
+// Pruner prunes the main database.
+type Pruner struct {
+	chain *chain.Chain
+	guard *Guard
+	opts  Options
+	goes  co.Goes
+	done  chan struct{}
+}
+
+// New creates a pruner. All writes to the main database must go through guard when pruning online.
+func New(chain *chain.Chain, guard *Guard, opts Options) *Pruner {
+	return &Pruner{
+		chain: chain,
+		guard: guard,
+		opts:  opts,
+		done:  make(chan struct{}),
+	}
+}
+
+// Start starts pruning in background.
+func (p *Pruner) Start() {
+	p.goes.Go(p.loop)
+}
+
+// Stop stops background pruning, and waits for the running cycle to exit.
+func (p *Pruner) Stop() {
+	close(p.done)
+	p.goes.Wait()
+}
+
+func (p *Pruner) loop() {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		<-p.done
+		cancel()
+	}()
+
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+
+	var lastPruned uint32
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			best := p.chain.BestBlock().Header().Number()
+			if best < p.opts.KeepBlocks || best-lastPruned < p.opts.Interval {
+				continue
+			}
+			stats, err := p.Prune(ctx)
+			if err != nil {
+				if ctx.Err() == nil {
+					log.Warn("failed to prune", "err", err)
+				}
+				continue
+			}
+			lastPruned = best
+			log.Info("pruned", "best", best, "marked", stats.Marked, "swept", stats.Swept, "elapsed", stats.Elapsed)
+		}
+	}
+}
+
+// Prune runs a pruning cycle.
+func (p *Pruner) Prune(ctx context.Context) (*Stats, error) {
+	start := time.Now()
+
+	p.guard.startRecording()
+	defer p.guard.stopRecording()
+
+	if p.opts.GracePeriod > 0 {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(p.opts.GracePeriod):
+		}
+	}
+
+	marks, closeMarks, err := p.openMarks()
+	if err != nil {
+		return nil, err
+	}
+	defer closeMarks()
+
+	m := &marker{ctx: ctx, db: p.guard.GetPutter, marks: marks}
+
+	headers, err := p.retainedHeaders()
+	if err != nil {
+		return nil, err
+	}
+	for _, header := range headers {
+		if err := p.markBlock(m, header); err != nil {
+			return nil, err
+		}
+	}
+	// the trunk index of the best block covers every block number
+	if err := p.markBlock(m, p.chain.BestBlock().Header()); err != nil {
+		return nil, err
+	}
+
+	swept, err := p.sweep(m)
+	if err != nil {
+		return nil, err
+	}
+	return &Stats{Marked: m.count, Swept: swept, Elapsed: time.Since(start)}, nil
+}
+
+func (p *Pruner) openMarks() (kv.GetPutter, func(), error) {
+	if p.opts.MarkDir == "" {
+		db, err := lvldb.NewMem()
+		if err != nil {
+			return nil, nil, err
+		}
+		return db, func() { db.Close() }, nil
+	}
+	os.RemoveAll(p.opts.MarkDir)
+	db, err := lvldb.New(p.opts.MarkDir, lvldb.Options{})
+	if err != nil {
+		return nil, nil, err
+	}
+	return db, func() {
+		db.Close()
+		os.RemoveAll(p.opts.MarkDir)
+	}, nil
+}
+
+// retainedHeaders returns headers of blocks whose state is kept.
+func (p *Pruner) retainedHeaders() ([]*block.Header, error) {
+	best := p.chain.BestBlock().Header()
+	var from uint32
+	if best.Number() > p.opts.KeepBlocks {
+		from = best.Number() - p.opts.KeepBlocks
+	}
+
+	headers := []*block.Header{p.chain.GenesisBlock().Header()}
+
+	// recent blocks, including those after best block which are not finalized yet
+	header := p.chain.LeafBlock().Header()
+	for header.Number() > from {
+		headers = append(headers, header)
+		parent, err := p.chain.GetBlockHeader(header.ParentID())
+		if err != nil {
+			return nil, errors.Wrap(err, "get parent header")
+		}
+		header = parent
+	}
+	headers = append(headers, header)
+
+	// K-block checkpoints
+	num := best.LastKBlockHeight()
+	for i := uint32(0); i < p.opts.KeepKBlocks && num > 0; i++ {
+		kHeader, err := p.chain.GetTrunkBlockHeader(num)
+		if err != nil {
+			return nil, errors.Wrap(err, "get k-block header")
+		}
+		headers = append(headers, kHeader)
+		if kHeader.LastKBlockHeight() >= num {
+			break
+		}
+		num = kHeader.LastKBlockHeight()
+	}
+	return headers, nil
+}
+
+// markBlock marks the state trie and the block index trie of the block.
+func (p *Pruner) markBlock(m *marker, header *block.Header) error {
+	if err := m.markState(header.StateRoot()); err != nil {
+		return errors.Wrapf(err, "mark state of block %v", header.Number())
+	}
+	root, err := p.chain.GetBlockIndexRoot(header.ID())
+	if err != nil {
+		return errors.Wrapf(err, "get index root of block %v", header.Number())
+	}
+	if err := m.markTrie(root, nil); err != nil {
+		return errors.Wrapf(err, "mark index of block %v", header.Number())
+	}
+	return nil
+}
+
+// markNewBlocks marks blocks added to the chain after the mark phase, by walking back from the leaf block
+// until a block is met whose state and index are both marked.
+func (p *Pruner) markNewBlocks(m *marker) error {
+	header := p.chain.LeafBlock().Header()
+	for {
+		root, err := p.chain.GetBlockIndexRoot(header.ID())
+		if err != nil {
+			return err
+		}
+		stateMarked, err := m.marks.Has(header.StateRoot().Bytes())
+		if err != nil {
+			return err
+		}
+		indexMarked, err := m.marks.Has(root.Bytes())
+		if err != nil {
+			return err
+		}
+		if stateMarked && indexMarked {
+			return nil
+		}
+		if err := p.markBlock(m, header); err != nil {
+			return err
+		}
+		if header.Number() == 0 {
+			return nil
+		}
+		if header, err = p.chain.GetBlockHeader(header.ParentID()); err != nil {
+			return err
+		}
+	}
+}
+
+// sweep deletes unmarked trie nodes and codes.
+func (p *Pruner) sweep(m *marker) (int, error) {
+	it := p.guard.GetPutter.NewIterator(kv.Range{})
+	defer it.Release()
+
+	swept := 0
+	flush := func(keys [][]byte) error {
+		if err := p.markNewBlocks(m); err != nil {
+			return errors.Wrap(err, "mark new blocks")
+		}
+		unmarked := keys[:0]
+		for _, key := range keys {
+			marked, err := m.marks.Has(key)
+			if err != nil {
+				return err
+			}
+			if !marked {
+				unmarked = append(unmarked, key)
+			}
+		}
+		n, err := p.guard.deleteUnwritten(unmarked)
+		swept += n
+		return err
+	}
+
+	keys := make([][]byte, 0, sweepBatchSize)
+	for it.Next() {
+		if err := m.ctx.Err(); err != nil {
+			return swept, err
+		}
+		key := it.Key()
+		if len(key) != nodeKeyLen || !isContentAddressed(key, it.Value()) {
+			continue
+		}
+		marked, err := m.marks.Has(key)
+		if err != nil {
+			return swept, err
+		}
+		if marked {
+			continue
+		}
+		keys = append(keys, append([]byte(nil), key...))
+		if len(keys) == sweepBatchSize {
+			if err := flush(keys); err != nil {
+				return swept, err
+			}
+			keys = keys[:0]
+		}
+	}
+	if err := it.Error(); err != nil {
+		return swept, err
+	}
+	if len(keys) > 0 {
+		if err := flush(keys); err != nil {
+			return swept, err
+		}
+	}
+	return swept, nil
+}
+
+// isContentAddressed returns whether key is the hash of value, i.e. a trie node or a contract code.
+func isContentAddressed(key, value []byte) bool {
+	h := meter.Blake2b(value)
+	return bytes.Equal(key, h[:]) || bytes.Equal(key, crypto.Keccak256(value))
+}
+
+// marker marks reachable trie nodes and codes.
+type marker struct {
+	ctx   context.Context
+	db    kv.GetPutter
+	marks kv.GetPutter
+	count int
+}
+
+func (m *marker) mark(key []byte) error {
+	m.count++
+	return m.marks.Put(key, nil)
+}
+
+// markTrie marks all nodes of the trie, a subtrie is skipped if its root is already marked.
+// onLeaf is called with the value of every leaf not skipped.
+func (m *marker) markTrie(root meter.Bytes32, onLeaf func(blob []byte) error) error {
+	if root.IsZero() || root == emptyRoot {
+		return nil
+	}
+	tr, err := trie.New(root, m.db)
+	if err != nil {
+		return err
+	}
+	it := tr.NodeIterator(nil)
+	for descend := true; it.Next(descend); {
+		descend = true
+		if err := m.ctx.Err(); err != nil {
+			return err
+		}
+		if h := it.Hash(); !h.IsZero() {
+			marked, err := m.marks.Has(h[:])
+			if err != nil {
+				return err
+			}
+			if marked {
+				descend = false
+				continue
+			}
+			if err := m.mark(h[:]); err != nil {
+				return err
+			}
+		}
+		if it.Leaf() && onLeaf != nil {
+			if err := onLeaf(it.LeafBlob()); err != nil {
+				return err
+			}
+		}
+	}
+	return it.Error()
+}
+
+// markState marks the account trie, and storage tries and codes of accounts.
+func (m *marker) markState(root meter.Bytes32) error {
+	return m.markTrie(root, func(blob []byte) error {
+		var acc state.Account
+		if err := rlp.DecodeBytes(blob, &acc); err != nil {
+			return err
+		}
+		if len(acc.CodeHash) > 0 {
+			if err := m.mark(acc.CodeHash); err != nil {
+				return err
+			}
+		}
+		return m.markTrie(meter.BytesToBytes32(acc.StorageRoot), nil)
+	})
+}
@@ -0,0 +1,122 @@
+// Copyright (c) 2020 The Meter.io developers
+
+// Distributed under the GNU Lesser General Public License v3.0 software license, see the accompanying
+// file LICENSE or <https://www.gnu.org/licenses/lgpl-3.0.html>
+
+package pruner
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/meterio/meter-pov/block"
+	"github.com/meterio/meter-pov/chain"
+	"github.com/meterio/meter-pov/chain/testchain"
+	"github.com/meterio/meter-pov/genesis"
+	"github.com/meterio/meter-pov/lvldb"
+	"github.com/meterio/meter-pov/meter"
+	"github.com/meterio/meter-pov/state"
+	"github.com/meterio/meter-pov/tx"
+	"github.com/stretchr/testify/assert"
+)
+
+func newBlock(t *testing.T, c *chain.Chain, stateC *state.Creator, nonce uint64) *block.Block {
+	recipient := meter.BytesToAddress(big.NewInt(int64(nonce)).Bytes())
+	trx := testchain.NewTx(c.Tag(), tx.NewClause(&recipient).WithToken(meter.STPT).WithValue(big.NewInt(1)), 300000, nonce)
+	blk, _, err := testchain.Mint(c, stateC, trx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return blk
+}
+
+func TestPrune(t *testing.T) {
+	db, _ := lvldb.NewMem()
+	guard := NewGuard(db)
+	c, stateC, err := testchain.New(guard)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var blocks []*block.Block
+	for i := 1; i <= 5; i++ {
+		blocks = append(blocks, newBlock(t, c, stateC, uint64(i)))
+	}
+
+	p := New(c, guard, Options{KeepBlocks: 2})
+	stats, err := p.Prune(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.True(t, stats.Swept > 0)
+
+	addr := genesis.DevAccounts()[0].Address
+	stateAt := func(blk *block.Block) (*big.Int, error) {
+		st, err := state.New(blk.Header().StateRoot(), db)
+		if err != nil {
+			return nil, err
+		}
+		balance := st.GetBalance(addr)
+		return balance, st.Err()
+	}
+
+	// state of retained blocks is complete
+	for _, blk := range append([]*block.Block{c.GenesisBlock()}, blocks[2:]...) {
+		_, err := stateAt(blk)
+		assert.Nil(t, err, "block %v", blk.Header().Number())
+	}
+	// state of pruned blocks is gone
+	_, err = stateAt(blocks[0])
+	assert.NotNil(t, err)
+
+	// the chain is still readable
+	for _, blk := range blocks {
+		id, err := c.GetTrunkBlockID(blk.Header().Number())
+		assert.Nil(t, err)
+		assert.Equal(t, blk.Header().ID(), id)
+	}
+
+	// blocks can be built on the pruned state
+	newBlock(t, c, stateC, 6)
+
+	// pruning again sweeps nothing retained
+	if _, err := p.Prune(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	_, err = stateAt(c.BestBlock())
+	assert.Nil(t, err)
+}
+
+func TestGuard(t *testing.T) {
+	db, _ := lvldb.NewMem()
+	guard := NewGuard(db)
+
+	written := meter.Blake2b([]byte("written")).Bytes()
+	batchWritten := meter.Blake2b([]byte("batch")).Bytes()
+	old := meter.Blake2b([]byte("old")).Bytes()
+	for _, key := range [][]byte{written, batchWritten, old} {
+		db.Put(key, []byte("value"))
+	}
+
+	guard.startRecording()
+	guard.Put(written, []byte("value"))
+	batch := guard.NewBatch()
+	batch.Put(batchWritten, []byte("value"))
+	batch.Write()
+
+	n, err := guard.deleteUnwritten([][]byte{written, batchWritten, old})
+	if err != nil {
+		t.Fatal(err)
+	}
+	guard.stopRecording()
+	assert.Equal(t, 1, n)
+
+	has := func(key []byte) bool {
+		ok, _ := db.Has(key)
+		return ok
+	}
+	assert.True(t, has(written))
+	assert.True(t, has(batchWritten))
+	assert.False(t, has(old))
+}
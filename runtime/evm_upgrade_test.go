@@ -0,0 +1,236 @@
+// Copyright (c) 2020 The Meter.io developers
+
+// Distributed under the GNU Lesser General Public License v3.0 software license, see the accompanying
+// file LICENSE or <https://www.gnu.org/licenses/lgpl-3.0.html>
+
+package runtime_test
+
+import (
+	"math"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/meterio/meter-pov/chain"
+	"github.com/meterio/meter-pov/genesis"
+	"github.com/meterio/meter-pov/lvldb"
+	"github.com/meterio/meter-pov/meter"
+	"github.com/meterio/meter-pov/runtime"
+	"github.com/meterio/meter-pov/state"
+	"github.com/meterio/meter-pov/tx"
+	"github.com/meterio/meter-pov/vm"
+	"github.com/meterio/meter-pov/xenv"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	berlin   = meter.ForkConfig{FixTransferLog: math.MaxUint32, ETH_BERLIN: 0, ETH_LONDON: math.MaxUint32, ETH_SHANGHAI: math.MaxUint32}
+	london   = meter.ForkConfig{FixTransferLog: math.MaxUint32, ETH_BERLIN: 0, ETH_LONDON: 0, ETH_SHANGHAI: math.MaxUint32}
+	shanghai = meter.ForkConfig{FixTransferLog: math.MaxUint32, ETH_BERLIN: 0, ETH_LONDON: 0, ETH_SHANGHAI: 0}
+
+	contractAddr = meter.BytesToAddress([]byte("contract"))
+	beneficiary  = meter.BytesToAddress([]byte("beneficiary"))
+)
+
+const clauseGas = 1000000
+
+// executeCode runs code deployed at contractAddr, or creates a contract with the code
+// if create is set, and returns the output and the gas used.
+func executeCode(t *testing.T, fc meter.ForkConfig, code []byte, create bool, original *meter.Bytes32) (*runtime.Output, uint64) {
+	return executeCodeWithAccessList(t, fc, code, create, original, nil)
+}
+
+// executeCodeWithAccessList is executeCode within a tx of the access list.
+func executeCodeWithAccessList(t *testing.T, fc meter.ForkConfig, code []byte, create bool, original *meter.Bytes32, accessList tx.AccessList) (*runtime.Output, uint64) {
+	kv, _ := lvldb.NewMem()
+	b0, _, err := genesis.NewDevnet().Build(state.NewCreator(kv))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ch, _ := chain.New(kv, b0, true)
+	st, _ := state.New(b0.Header().StateRoot(), kv)
+
+	clause := tx.NewClause(nil).WithData(code)
+	if !create {
+		st.SetCode(contractAddr, code)
+		if original != nil {
+			st.SetStorage(contractAddr, meter.Bytes32{}, *original)
+		}
+		clause = tx.NewClause(&contractAddr)
+	}
+
+	rt := runtime.New(ch.NewSeeker(b0.Header().ID()), st, &xenv.BlockContext{Beneficiary: beneficiary}).SetForkConfig(fc)
+	out := rt.ExecuteClause(clause, 0, clauseGas, &xenv.TransactionContext{Origin: genesis.DevAccounts()[0].Address, AccessList: accessList})
+	return out, clauseGas - out.LeftOverGas
+}
+
+func TestForkConfigOfDevnet(t *testing.T) {
+	kv, _ := lvldb.NewMem()
+	b0, _, _ := genesis.NewDevnet().Build(state.NewCreator(kv))
+	assert.Equal(t, meter.ForkConfig{}, meter.GetForkConfig(b0.Header().ID()))
+
+	// well-known networks are listed, other networks have no forks unless registered
+	assert.Equal(t, meter.NoFork, meter.GetForkConfig(genesis.NewMainnet().ID()))
+	assert.Equal(t, meter.NoFork, meter.GetForkConfig(meter.BytesToBytes32([]byte("private"))))
+}
+
+// net gas metering of SSTORE with EIP-2929 and EIP-3529 applied, in the same manner as
+// the EIP-2200 test cases.
+func TestSStoreEIP2929(t *testing.T) {
+	tests := []struct {
+		original byte
+		code     string
+		used     uint64
+		refund   uint64
+		fc       meter.ForkConfig
+	}{
+		{0, "0x60016000556000600055", 25012, 15000, meter.NoFork}, // 0 -> 1 -> 0, before Berlin
+
+		{0, "0x60006000556000600055", 2312, 0, berlin},      // 0 -> 0 -> 0
+		{0, "0x60006000556001600055", 22212, 0, berlin},     // 0 -> 0 -> 1
+		{0, "0x60016000556000600055", 22212, 19900, berlin}, // 0 -> 1 -> 0
+		{1, "0x60006000556000600055", 5112, 15000, berlin},  // 1 -> 0 -> 0
+		{1, "0x60016000556000600055", 5112, 15000, berlin},  // 1 -> 1 -> 0
+		{1, "0x60006000556001600055", 5112, 2800, berlin},   // 1 -> 0 -> 1
+		{1, "0x60026000556001600055", 5112, 2800, berlin},   // 1 -> 2 -> 1
+
+		{0, "0x60016000556000600055", 22212, 19900, london}, // 0 -> 1 -> 0
+		{1, "0x60006000556000600055", 5112, 4800, london},   // 1 -> 0 -> 0
+		{1, "0x60016000556000600055", 5112, 4800, london},   // 1 -> 1 -> 0
+		{1, "0x60006000556001600055", 5112, 2800, london},   // 1 -> 0 -> 1
+	}
+	for i, tt := range tests {
+		original := meter.BytesToBytes32([]byte{tt.original})
+		out, used := executeCode(t, tt.fc, common.FromHex(tt.code), false, &original)
+		assert.Nil(t, out.VMErr, "test %d", i)
+		assert.Equal(t, tt.used, used, "test %d: gas used", i)
+		assert.Equal(t, tt.refund, out.RefundGas, "test %d: refund", i)
+	}
+}
+
+func TestAccessListEIP2929(t *testing.T) {
+	var (
+		coldAddr        = "73" + common.Bytes2Hex(meter.BytesToAddress([]byte("cold")).Bytes())
+		beneficiaryAddr = "73" + common.Bytes2Hex(beneficiary.Bytes())
+	)
+	tests := []struct {
+		code string
+		used uint64
+		fc   meter.ForkConfig
+	}{
+		// PUSH20 cold BALANCE POP, twice
+		{coldAddr + "3150" + coldAddr + "3150", 810, meter.NoFork},
+		{coldAddr + "3150" + coldAddr + "3150", 2710, berlin},
+		// ADDRESS BALANCE POP, the destination is warm
+		{"303150", 104, berlin},
+		// PUSH1 1 BALANCE POP, precompiles are warm
+		{"60013150", 105, berlin},
+		// PUSH1 0 SLOAD, twice
+		{"60005450600054", 408, meter.NoFork},
+		{"60005450600054", 2208, berlin},
+		// PUSH20 beneficiary BALANCE POP, the coinbase is warm since Shanghai, see EIP-3651
+		{beneficiaryAddr + "3150", 2605, london},
+		{beneficiaryAddr + "3150", 105, shanghai},
+	}
+	for i, tt := range tests {
+		out, used := executeCode(t, tt.fc, common.FromHex(tt.code), false, nil)
+		assert.Nil(t, out.VMErr, "test %d", i)
+		assert.Equal(t, tt.used, used, "test %d: gas used", i)
+	}
+}
+
+func TestTxAccessListEIP2930(t *testing.T) {
+	cold := meter.BytesToAddress([]byte("cold"))
+	accessList := tx.AccessList{
+		{Address: common.Address(cold)},
+		{Address: common.Address(contractAddr), StorageKeys: []common.Hash{{}}},
+	}
+	tests := []struct {
+		code string
+		used uint64
+		fc   meter.ForkConfig
+	}{
+		// PUSH20 cold BALANCE POP, the address is in the access list
+		{"73" + common.Bytes2Hex(cold.Bytes()) + "3150", 105, berlin},
+		// PUSH1 0 SLOAD, the slot is in the access list
+		{"600054", 103, berlin},
+		// PUSH1 1 SLOAD, other slots are cold
+		{"600154", 2103, berlin},
+		// no access list before Berlin
+		{"600054", 203, meter.NoFork},
+	}
+	for i, tt := range tests {
+		out, used := executeCodeWithAccessList(t, tt.fc, common.FromHex(tt.code), false, nil, accessList)
+		assert.Nil(t, out.VMErr, "test %d", i)
+		assert.Equal(t, tt.used, used, "test %d: gas used", i)
+	}
+}
+
+func TestSelfdestructRefundEIP3529(t *testing.T) {
+	// CALLER SELFDESTRUCT
+	out, used := executeCode(t, berlin, common.FromHex("0x33ff"), false, nil)
+	assert.Nil(t, out.VMErr)
+	assert.Equal(t, uint64(5002), used)
+	assert.Equal(t, uint64(24000), out.RefundGas)
+
+	out, used = executeCode(t, london, common.FromHex("0x33ff"), false, nil)
+	assert.Nil(t, out.VMErr)
+	assert.Equal(t, uint64(5002), used)
+	assert.Equal(t, uint64(0), out.RefundGas)
+}
+
+func TestBaseFeeEIP3198(t *testing.T) {
+	// BASEFEE PUSH1 0 MSTORE PUSH1 32 PUSH1 0 RETURN
+	code := common.FromHex("0x4860005260206000f3")
+	out, _ := executeCode(t, berlin, code, false, nil)
+	assert.NotNil(t, out.VMErr)
+
+	out, _ = executeCode(t, london, code, false, nil)
+	assert.Nil(t, out.VMErr)
+	assert.Equal(t, meter.InitialBaseGasPrice, new(big.Int).SetBytes(out.Data))
+}
+
+func TestRejectCodeEIP3541(t *testing.T) {
+	// PUSH1 0xef PUSH1 0 MSTORE8 PUSH1 1 PUSH1 0 RETURN, which deploys 0xef
+	code := common.FromHex("0x60ef60005360016000f3")
+	out, _ := executeCode(t, berlin, code, true, nil)
+	assert.Nil(t, out.VMErr)
+
+	out, used := executeCode(t, london, code, true, nil)
+	assert.Equal(t, vm.ErrInvalidCode, out.VMErr)
+	assert.Equal(t, uint64(clauseGas), used)
+}
+
+func TestPush0EIP3855(t *testing.T) {
+	// PUSH0 PUSH1 0 SSTORE, which stores 0 to 0
+	code := common.FromHex("0x5f600055")
+	out, _ := executeCode(t, london, code, false, nil)
+	assert.NotNil(t, out.VMErr)
+
+	out, used := executeCode(t, shanghai, code, false, nil)
+	assert.Nil(t, out.VMErr)
+	assert.Equal(t, uint64(2+3+2200), used)
+}
+
+func TestInitCodeEIP3860(t *testing.T) {
+	// PUSH3 size PUSH1 0 PUSH1 0 CREATE
+	create := func(size int) []byte {
+		return append([]byte{byte(vm.PUSH3), byte(size >> 16), byte(size >> 8), byte(size)}, common.FromHex("0x60006000f0")...)
+	}
+
+	out, _ := executeCode(t, london, create(vm.MaxInitCodeSize+1), false, nil)
+	assert.Nil(t, out.VMErr)
+	out, _ = executeCode(t, shanghai, create(vm.MaxInitCodeSize+1), false, nil)
+	assert.Equal(t, vm.ErrOutOfGas, out.VMErr)
+
+	// the init code is charged per word
+	out, londonUsed := executeCode(t, london, create(vm.MaxInitCodeSize), false, nil)
+	assert.Nil(t, out.VMErr)
+	out, shanghaiUsed := executeCode(t, shanghai, create(vm.MaxInitCodeSize), false, nil)
+	assert.Nil(t, out.VMErr)
+	assert.Equal(t, vm.InitCodeWordGas*vm.MaxInitCodeSize/32, shanghaiUsed-londonUsed)
+
+	// clause creating contract
+	out, _ = executeCode(t, shanghai, make([]byte, vm.MaxInitCodeSize+1), true, nil)
+	assert.Equal(t, vm.ErrMaxInitCodeSizeExceeded, out.VMErr)
+}
@@ -155,5 +155,6 @@ func (r *ResolvedTransaction) ToContext(gasPrice *big.Int, blockNumber uint32, g
 		BlockRef:   r.tx.BlockRef(),
 		Expiration: r.tx.Expiration(),
 		Nonce:      r.tx.Nonce(),
+		AccessList: r.tx.AccessList(),
 	}
 }
@@ -135,15 +135,24 @@ func New(
 		state:  state,
 		ctx:    ctx,
 	}
-	//if seeker != nil {
-	//	rt.forkConfig = meter.GetForkConfig(seeker.GenesisID())
-	//} else {
-	// for genesis building stage
-	rt.forkConfig = meter.NoFork
-	//}
+	if seeker != nil {
+		rt.forkConfig = meter.GetForkConfig(seeker.GenesisID())
+	} else {
+		// for genesis building stage
+		rt.forkConfig = meter.NoFork
+	}
 	return &rt
 }
 
+// ApplyForks applies the state changes scheduled at the block of the runtime. It's called
+// once per block while processing blocks, before executing the transactions of the block.
+func (rt *Runtime) ApplyForks() {
+	// deploy the staking contract, the code only fails calls to unknown methods since the methods are native
+	if rt.ctx.Number == rt.forkConfig.STAKING_CONTRACT {
+		rt.state.SetCode(builtin.Staking.Address, EmptyRuntimeBytecode)
+	}
+}
+
 func (rt *Runtime) Seeker() *chain.Seeker       { return rt.seeker }
 func (rt *Runtime) State() *state.State         { return rt.state }
 func (rt *Runtime) Context() *xenv.BlockContext { return rt.ctx }
@@ -210,7 +219,7 @@ func (rt *Runtime) FromNativeContract(caller meter.Address) bool {
 
 // retrict enforcement ONLY applies to meterGov, not meter
 func (rt *Runtime) restrictTransfer(stateDB *statedb.StateDB, addr meter.Address, amount *big.Int, token byte, blockNum uint32) bool {
-	restrict, _, lockMtrg := accountlock.RestrictByAccountLock(addr, rt.State())
+	restrict, _, lockMtrg := accountlock.RestrictByAccountLock(addr, rt.State(), uint32(rt.ctx.Epoch))
 	// lock is not there or token meter
 	if restrict == false || token == meter.STPT {
 		return false
@@ -243,6 +252,22 @@ func (rt *Runtime) SetVMConfig(config vm.Config) *Runtime {
 	return rt
 }
 
+// SetForkConfig config fork blocks, which decide the evm upgrades.
+// Returns this runtime.
+func (rt *Runtime) SetForkConfig(config meter.ForkConfig) *Runtime {
+	rt.forkConfig = config
+	return rt
+}
+
+// chainConfig returns the evm chain config with the evm upgrades of the fork config.
+func (rt *Runtime) chainConfig() *vm.ChainConfig {
+	config := chainConfig
+	config.BerlinBlock = new(big.Int).SetUint64(uint64(rt.forkConfig.ETH_BERLIN))
+	config.LondonBlock = new(big.Int).SetUint64(uint64(rt.forkConfig.ETH_LONDON))
+	config.ShanghaiBlock = new(big.Int).SetUint64(uint64(rt.forkConfig.ETH_SHANGHAI))
+	return &config
+}
+
 func (rt *Runtime) newEVM(stateDB *statedb.StateDB, clauseIndex uint32, txCtx *xenv.TransactionContext) *vm.EVM {
 	var lastNonNativeCallGas uint64
 	return vm.NewEVM(vm.Context{
@@ -336,6 +361,22 @@ func (rt *Runtime) newEVM(stateDB *statedb.StateDB, clauseIndex uint32, txCtx *x
 			return addr
 		},
 		InterceptContractCall: func(evm *vm.EVM, contract *vm.Contract, readonly bool) ([]byte, error, bool) {
+			// the staking contract is called by any account, which is the holder of buckets
+			if contract.Address() == common.Address(builtin.Staking.Address) && rt.ctx.Number >= rt.forkConfig.STAKING_CONTRACT {
+				abi, run, found := builtin.FindNativeCall(builtin.Staking.Address, contract.Input)
+				if !found {
+					return nil, nil, false
+				}
+				if readonly && !abi.Const() {
+					return nil, errors.New("invoke non-const method in readonly env"), true
+				}
+				if contract.Value().Sign() != 0 {
+					return nil, errors.New("value transfer not allowed"), true
+				}
+				ret, err := xenv.New(abi, rt.seeker, rt.state, rt.ctx, txCtx, evm, contract).Call(run)
+				return ret, err, true
+			}
+
 			if evm.Depth() < 2 {
 				lastNonNativeCallGas = contract.Gas
 				// skip direct calls
@@ -450,7 +491,8 @@ func (rt *Runtime) newEVM(stateDB *statedb.StateDB, clauseIndex uint32, txCtx *x
 		BlockNumber: new(big.Int).SetUint64(uint64(rt.ctx.Number)),
 		Time:        new(big.Int).SetUint64(rt.ctx.Time),
 		Difficulty:  &big.Int{},
-	}, stateDB, &chainConfig, rt.vmConfig)
+		BaseFee:     builtin.Params.Native(rt.state).Get(meter.KeyBaseGasPrice),
+	}, stateDB, rt.chainConfig(), rt.vmConfig)
 }
 
 // ExecuteClause executes single clause.
@@ -494,7 +536,7 @@ func (rt *Runtime) PrepareClause(
 			}
 			// exclude 4 bytes of clause data
 			// fmt.Println("Exec Clause: ", hex.EncodeToString(clause.Data()))
-			seOutput, leftOverGas, vmErr = se.HandleScriptData(clause.Data()[4:], clause.To(), txCtx, gas, rt.state)
+			seOutput, leftOverGas, vmErr = se.HandleScriptData(clause.Data()[4:], clause.To(), rt.ctx, txCtx, gas, rt.state)
 			// fmt.Println("scriptEngine handling return", data, leftOverGas, vmErr)
 
 			var data []byte
@@ -540,6 +582,18 @@ func (rt *Runtime) PrepareClause(
 			return output, false
 		}
 
+		// each clause runs in its own evm, so the access list is scoped to the clause
+		evm.PrepareAccessList(common.Address(txCtx.Origin), (*common.Address)(clause.To()))
+		if evm.ChainConfig().IsBerlin(evm.BlockNumber) {
+			// the access list of the tx is paid by intrinsic gas
+			for _, tuple := range txCtx.AccessList {
+				stateDB.AddAddressToAccessList(tuple.Address)
+				for _, key := range tuple.StorageKeys {
+					stateDB.AddSlotToAccessList(tuple.Address, key)
+				}
+			}
+		}
+
 		if clause.To() == nil {
 			var caddr common.Address
 			data, caddr, leftOverGas, vmErr = evm.Create(vm.AccountRef(txCtx.Origin), clause.Data(), gas, clause.Value(), clause.Token())
@@ -622,8 +676,12 @@ func (rt *Runtime) PrepareTransaction(tx *tx.Transaction) (*TransactionExecutor,
 			gasUsed = leftOverGas - output.LeftOverGas
 			leftOverGas = output.LeftOverGas
 
-			// Apply refund counter, capped to half of the used gas.
-			refund := gasUsed / 2
+			// Apply refund counter, capped to half of the used gas, or a fifth since EIP-3529.
+			refundQuotient := vm.RefundQuotient
+			if rt.ctx.Number >= rt.forkConfig.ETH_LONDON {
+				refundQuotient = vm.RefundQuotientEIP3529
+			}
+			refund := gasUsed / refundQuotient
 			if refund > output.RefundGas {
 				refund = output.RefundGas
 			}
@@ -34,8 +34,16 @@ type (
 	eventKey       struct{}
 	transferKey    struct{}
 	stateRevKey    struct{}
+	originKey      storageKey
+	accessAddrKey  common.Address
+	accessSlotKey  storageKey
 )
 
+type storageKey struct {
+	addr common.Address
+	key  common.Hash
+}
+
 // New create a statedb object.
 func New(state *state.State) *StateDB {
 	getter := func(k interface{}) (interface{}, bool) {
@@ -44,6 +52,10 @@ func New(state *state.State) *StateDB {
 			return false, true
 		case refundKey:
 			return uint64(0), true
+		case originKey:
+			return nil, false
+		case accessAddrKey, accessSlotKey:
+			return false, true
 		}
 		panic(fmt.Sprintf("unknown type of key %+v", k))
 	}
@@ -288,8 +300,23 @@ func (s *StateDB) GetState(addr common.Address, key common.Hash) common.Hash {
 	return common.Hash(s.state.GetStorage(meter.Address(addr), meter.Bytes32(key)))
 }
 
+// GetCommittedState returns the value of the storage slot before the first write of this statedb.
+// A statedb is created per clause, so unlike ethereum, the original value is tracked per clause,
+// not per transaction. Net gas metering of later clauses sees writes of earlier clauses as original.
+func (s *StateDB) GetCommittedState(addr common.Address, key common.Hash) common.Hash {
+	if v, ok := s.repo.Get(originKey{addr, key}); ok {
+		return v.(common.Hash)
+	}
+	return s.GetState(addr, key)
+}
+
 // SetState stub.
 func (s *StateDB) SetState(addr common.Address, key, value common.Hash) {
+	// keep the original value for net gas metering, it's dropped along with
+	// the write when reverted.
+	if _, ok := s.repo.Get(originKey{addr, key}); !ok {
+		s.repo.Put(originKey{addr, key}, s.GetState(addr, key))
+	}
 	s.state.SetStorage(meter.Address(addr), meter.Bytes32(key), meter.Bytes32(value))
 }
 
@@ -310,6 +337,43 @@ func (s *StateDB) AddRefund(gas uint64) {
 	s.repo.Put(refundKey{}, total)
 }
 
+// SubRefund stub.
+func (s *StateDB) SubRefund(gas uint64) {
+	v, _ := s.repo.Get(refundKey{})
+	total := v.(uint64)
+	if gas > total {
+		panic(fmt.Sprintf("refund counter below zero (gas: %d > refund: %d)", gas, total))
+	}
+	s.repo.Put(refundKey{}, total-gas)
+}
+
+// AddressInAccessList returns whether the address is in the access list.
+func (s *StateDB) AddressInAccessList(addr common.Address) bool {
+	v, _ := s.repo.Get(accessAddrKey(addr))
+	return v.(bool)
+}
+
+// SlotInAccessList returns whether the address and the slot are in the access list.
+func (s *StateDB) SlotInAccessList(addr common.Address, slot common.Hash) (addressOk bool, slotOk bool) {
+	v, _ := s.repo.Get(accessSlotKey{addr, slot})
+	return s.AddressInAccessList(addr), v.(bool)
+}
+
+// AddAddressToAccessList adds the address to the access list.
+func (s *StateDB) AddAddressToAccessList(addr common.Address) {
+	if !s.AddressInAccessList(addr) {
+		s.repo.Put(accessAddrKey(addr), true)
+	}
+}
+
+// AddSlotToAccessList adds the address and the slot to the access list.
+func (s *StateDB) AddSlotToAccessList(addr common.Address, slot common.Hash) {
+	s.AddAddressToAccessList(addr)
+	if _, slotOk := s.SlotInAccessList(addr, slot); !slotOk {
+		s.repo.Put(accessSlotKey{addr, slot}, true)
+	}
+}
+
 // AddPreimage stub.
 func (s *StateDB) AddPreimage(hash common.Hash, preimage []byte) {
 	s.repo.Put(preimageKey(hash), preimage)
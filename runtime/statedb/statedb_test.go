@@ -245,3 +245,24 @@ func (test *snapshotTest) checkEqual(state, checkstate *statedb.StateDB) error {
 	}
 	return nil
 }
+
+func TestAccessListRevert(t *testing.T) {
+	db, _ := lvldb.NewMem()
+	state, _ := State.NewCreator(db).NewState(meter.Bytes32{})
+	stateDB := statedb.New(state)
+
+	addr := common.BytesToAddress([]byte("addr"))
+	slot := common.BytesToHash([]byte("slot"))
+
+	stateDB.AddAddressToAccessList(addr)
+	rev := stateDB.Snapshot()
+	stateDB.AddSlotToAccessList(addr, slot)
+	if addrOk, slotOk := stateDB.SlotInAccessList(addr, slot); !addrOk || !slotOk {
+		t.Fatalf("got SlotInAccessList() == (%v, %v), want (true, true)", addrOk, slotOk)
+	}
+
+	stateDB.RevertToSnapshot(rev)
+	if addrOk, slotOk := stateDB.SlotInAccessList(addr, slot); !addrOk || slotOk {
+		t.Fatalf("got SlotInAccessList() == (%v, %v), want (true, false)", addrOk, slotOk)
+	}
+}
@@ -52,9 +52,9 @@ func (a *AccountLock) Start() error {
 	return nil
 }
 
-func (a *AccountLock) PrepareAccountLockHandler() (AccountLockHandler func([]byte, *meter.Address, *xenv.TransactionContext, uint64, *state.State) (*setypes.ScriptEngineOutput, uint64, error)) {
+func (a *AccountLock) PrepareAccountLockHandler() (AccountLockHandler func([]byte, *meter.Address, *xenv.BlockContext, *xenv.TransactionContext, uint64, *state.State) (*setypes.ScriptEngineOutput, uint64, error)) {
 
-	AccountLockHandler = func(data []byte, to *meter.Address, txCtx *xenv.TransactionContext, gas uint64, state *state.State) (seOutput *setypes.ScriptEngineOutput, leftOverGas uint64, err error) {
+	AccountLockHandler = func(data []byte, to *meter.Address, blockCtx *xenv.BlockContext, txCtx *xenv.TransactionContext, gas uint64, state *state.State) (seOutput *setypes.ScriptEngineOutput, leftOverGas uint64, err error) {
 
 		ab, err := AccountLockDecodeFromBytes(data)
 		if err != nil {
@@ -62,7 +62,7 @@ func (a *AccountLock) PrepareAccountLockHandler() (AccountLockHandler func([]byt
 			return nil, gas, err
 		}
 
-		env := NewAccountLockEnviroment(a, state, txCtx, to)
+		env := NewAccountLockEnviroment(a, state, blockCtx, txCtx, to)
 		if env == nil {
 			panic("create AccountLock enviroment failed")
 		}
@@ -18,10 +18,10 @@ type AccountLockEnviroment struct {
 	accountLock *AccountLock
 }
 
-func NewAccountLockEnviroment(accountLock *AccountLock, state *state.State, txCtx *xenv.TransactionContext, to *meter.Address) *AccountLockEnviroment {
+func NewAccountLockEnviroment(accountLock *AccountLock, state *state.State, blockCtx *xenv.BlockContext, txCtx *xenv.TransactionContext, to *meter.Address) *AccountLockEnviroment {
 	return &AccountLockEnviroment{
 		accountLock: accountLock,
-		ScriptEnv:   setypes.NewScriptEnv(state, txCtx, to),
+		ScriptEnv:   setypes.NewScriptEnv(state, blockCtx, txCtx, to),
 	}
 }
 
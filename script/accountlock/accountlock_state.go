@@ -14,7 +14,13 @@ import (
 )
 
 // Profile List
-func (a *AccountLock) GetProfileList(state *state.State) (result *ProfileList) {
+func (a *AccountLock) GetProfileList(state *state.State) *ProfileList {
+	return GetProfileListByState(state)
+}
+
+// GetProfileListByState returns the account lock profiles in the state, it works without the
+// account lock module started.
+func GetProfileListByState(state *state.State) (result *ProfileList) {
 	state.DecodeStorage(AccountLockAddr, AccountLockProfileKey, func(raw []byte) error {
 		profiles := make([]*Profile, 0)
 
@@ -38,18 +38,7 @@ func (sb *AccountLockBody) String() string {
 }
 
 func (a *AccountLockBody) GetOpName(op uint32) string {
-	switch op {
-	case OP_ADDLOCK:
-		return "addlock"
-	case OP_REMOVELOCK:
-		return "removelock"
-	case OP_TRANSFER:
-		return "transfer"
-	case OP_GOVERNING:
-		return "governing"
-	default:
-		return "Unknown"
-	}
+	return GetOpName(op)
 }
 
 func AccountLockEncodeBytes(sb *AccountLockBody) []byte {
@@ -230,7 +219,7 @@ func (ab *AccountLockBody) GoverningHandler(env *AccountLockEnviroment, gas uint
 	}
 
 	toRemove := []meter.Address{}
-	curEpoch := AccountLock.GetCurrentEpoch()
+	curEpoch := uint32(env.GetBlockCtx().Epoch)
 	for _, p := range pList.Profiles {
 		if p.ReleaseEpoch <= curEpoch {
 			toRemove = append(toRemove, p.Addr)
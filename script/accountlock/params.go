@@ -9,6 +9,21 @@ const (
 	OP_GOVERNING  = uint32(100)
 )
 
+func GetOpName(op uint32) string {
+	switch op {
+	case OP_ADDLOCK:
+		return "addlock"
+	case OP_REMOVELOCK:
+		return "removelock"
+	case OP_TRANSFER:
+		return "transfer"
+	case OP_GOVERNING:
+		return "governing"
+	default:
+		return "Unknown"
+	}
+}
+
 // the global variables in AccountLock
 var (
 	//0x6163636f756e742d6c6f636b2d61646472657373
@@ -163,7 +163,9 @@ func GetLatestProfileList() (*ProfileList, error) {
 	return list, nil
 }
 
-func RestrictByAccountLock(addr meter.Address, state *state.State) (bool, *big.Int, *big.Int) {
+// RestrictByAccountLock returns whether transfers of the address are restricted by its profile at the epoch,
+// along with the locked amounts.
+func RestrictByAccountLock(addr meter.Address, state *state.State, epoch uint32) (bool, *big.Int, *big.Int) {
 	accountlock := GetAccountLockGlobInst()
 	if accountlock == nil {
 		//log.Debug("accountlock is not initialized...")
@@ -181,7 +183,7 @@ func RestrictByAccountLock(addr meter.Address, state *state.State) (bool, *big.I
 		return false, nil, nil
 	}
 
-	if accountlock.GetCurrentEpoch() >= p.ReleaseEpoch {
+	if epoch >= p.ReleaseEpoch {
 		return false, nil, nil
 	}
 
@@ -51,9 +51,9 @@ func (a *Auction) Start() error {
 	return nil
 }
 
-func (a *Auction) PrepareAuctionHandler() (AuctionHandler func([]byte, *meter.Address, *xenv.TransactionContext, uint64, *state.State) (*setypes.ScriptEngineOutput, uint64, error)) {
+func (a *Auction) PrepareAuctionHandler() (AuctionHandler func([]byte, *meter.Address, *xenv.BlockContext, *xenv.TransactionContext, uint64, *state.State) (*setypes.ScriptEngineOutput, uint64, error)) {
 
-	AuctionHandler = func(data []byte, to *meter.Address, txCtx *xenv.TransactionContext, gas uint64, state *state.State) (seOutput *setypes.ScriptEngineOutput, leftOverGas uint64, err error) {
+	AuctionHandler = func(data []byte, to *meter.Address, blockCtx *xenv.BlockContext, txCtx *xenv.TransactionContext, gas uint64, state *state.State) (seOutput *setypes.ScriptEngineOutput, leftOverGas uint64, err error) {
 
 		ab, err := AuctionDecodeFromBytes(data)
 		if err != nil {
@@ -61,7 +61,7 @@ func (a *Auction) PrepareAuctionHandler() (AuctionHandler func([]byte, *meter.Ad
 			return nil, gas, err
 		}
 
-		env := NewAuctionEnv(a, state, txCtx, to)
+		env := NewAuctionEnv(a, state, blockCtx, txCtx, to)
 		if env == nil {
 			panic("create auction enviroment failed")
 		}
@@ -18,10 +18,10 @@ type AuctionEnv struct {
 	auction *Auction
 }
 
-func NewAuctionEnv(auction *Auction, state *state.State, txCtx *xenv.TransactionContext, to *meter.Address) *AuctionEnv {
+func NewAuctionEnv(auction *Auction, state *state.State, blockCtx *xenv.BlockContext, txCtx *xenv.TransactionContext, to *meter.Address) *AuctionEnv {
 	return &AuctionEnv{
 		auction:   auction,
-		ScriptEnv: setypes.NewScriptEnv(state, txCtx, to),
+		ScriptEnv: setypes.NewScriptEnv(state, blockCtx, txCtx, to),
 	}
 }
 
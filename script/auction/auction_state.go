@@ -23,7 +23,11 @@ import (
 )
 
 // Candidate List
-func (a *Auction) GetAuctionCB(state *state.State) (result *AuctionCB) {
+func (a *Auction) GetAuctionCB(state *state.State) *AuctionCB {
+	return getAuctionCB(state)
+}
+
+func getAuctionCB(state *state.State) (result *AuctionCB) {
 	state.DecodeStorage(AuctionAccountAddr, AuctionCBKey, func(raw []byte) error {
 		auctionCB := &AuctionCB{}
 
@@ -52,7 +56,11 @@ func (a *Auction) SetAuctionCB(auctionCB *AuctionCB, state *state.State) {
 }
 
 // summary List
-func (a *Auction) GetSummaryList(state *state.State) (result *AuctionSummaryList) {
+func (a *Auction) GetSummaryList(state *state.State) *AuctionSummaryList {
+	return getSummaryList(state)
+}
+
+func getSummaryList(state *state.State) (result *AuctionSummaryList) {
 	state.DecodeStorage(AuctionAccountAddr, SummaryListKey, func(raw []byte) error {
 		summaries := make([]*AuctionSummary, 0)
 
@@ -152,10 +160,9 @@ func (a *Auction) TransferMTRToValidatorBenefit(amount *big.Int, state *state.St
 
 ////////////////////////
 // called when auction is over
-func (a *Auction) ClearAuction(cb *AuctionCB, state *state.State, env *AuctionEnv) (*big.Int, *big.Int, []*DistMtrg, error) {
-	stateDB := statedb.New(state)
-	ValidatorBenefitRatio := builtin.Params.Native(state).Get(meter.KeyValidatorBenefitRatio)
-
+// CalcSettlement returns the actual price of the auction and the MTRG each bidder receives when
+// it's cleared, bids of the same bidder are summed up and bidders are sorted by address.
+func CalcSettlement(cb *AuctionCB) (*big.Int, []*DistMtrg) {
 	actualPrice := new(big.Int).Mul(cb.RcvdMTR, big.NewInt(1e18))
 	if cb.RlsdMTRG.Cmp(big.NewInt(0)) > 0 {
 		actualPrice = actualPrice.Div(actualPrice, cb.RlsdMTRG)
@@ -166,48 +173,41 @@ func (a *Auction) ClearAuction(cb *AuctionCB, state *state.State, env *AuctionEn
 		actualPrice = cb.RsvdPrice
 	}
 
-	//blockNum := env.GetTxCtx().BlockRef.Number()
-	total := big.NewInt(0)
-	distMtrg := []*DistMtrg{}
-	//if meter.IsTestChainTeslaFork3(blockNum) || meter.IsMainChainTeslaFork3(blockNum) {
-
-		groupTxMap := make(map[meter.Address]*big.Int)
-		sortedAddresses := make([]meter.Address, 0)
-		for _, tx := range cb.AuctionTxs {
-			mtrg := new(big.Int).Mul(tx.Amount, big.NewInt(1e18))
-			mtrg = new(big.Int).Div(mtrg, actualPrice)
-
-			if _, ok := groupTxMap[tx.Address]; ok == true {
-				groupTxMap[tx.Address] = new(big.Int).Add(groupTxMap[tx.Address], mtrg)
-			} else {
-				groupTxMap[tx.Address] = new(big.Int).Set(mtrg)
-				sortedAddresses = append(sortedAddresses, tx.Address)
-			}
+	groupTxMap := make(map[meter.Address]*big.Int)
+	sortedAddresses := make([]meter.Address, 0)
+	for _, tx := range cb.AuctionTxs {
+		mtrg := new(big.Int).Mul(tx.Amount, big.NewInt(1e18))
+		mtrg = new(big.Int).Div(mtrg, actualPrice)
+
+		if _, ok := groupTxMap[tx.Address]; ok == true {
+			groupTxMap[tx.Address] = new(big.Int).Add(groupTxMap[tx.Address], mtrg)
+		} else {
+			groupTxMap[tx.Address] = new(big.Int).Set(mtrg)
+			sortedAddresses = append(sortedAddresses, tx.Address)
 		}
+	}
 
-		sort.SliceStable(sortedAddresses, func(i, j int) bool {
-			return bytes.Compare(sortedAddresses[i].Bytes(), sortedAddresses[j].Bytes()) <= 0
-		})
+	sort.SliceStable(sortedAddresses, func(i, j int) bool {
+		return bytes.Compare(sortedAddresses[i].Bytes(), sortedAddresses[j].Bytes()) <= 0
+	})
 
-		for _, addr := range sortedAddresses {
-			mtrg := groupTxMap[addr]
-			a.SendMTRGToBidder(addr, mtrg, stateDB, env)
-			total = total.Add(total, mtrg)
-			distMtrg = append(distMtrg, &DistMtrg{Addr: addr, Amount: mtrg})
-		}
-	//} else {
-		//for _, tx := range cb.AuctionTxs {
-		//	mtrg := new(big.Int).Mul(tx.Amount, big.NewInt(1e18))
-		//	mtrg = new(big.Int).Div(mtrg, actualPrice)
-		//
-		//	a.SendMTRGToBidder(tx.Address, mtrg, stateDB, env)
-		//	if (meter.IsMainNet() && blockNum < meter.TeslaFork3_MainnetAuctionDefectStartNum) || meter.IsTestNet() {
-		//		total = total.Add(total, mtrg)
-		//	}
-		//	distMtrg = append(distMtrg, &DistMtrg{Addr: tx.Address, Amount: mtrg})
-		//}
-
-	//}
+	distMtrg := []*DistMtrg{}
+	for _, addr := range sortedAddresses {
+		distMtrg = append(distMtrg, &DistMtrg{Addr: addr, Amount: groupTxMap[addr]})
+	}
+	return actualPrice, distMtrg
+}
+
+func (a *Auction) ClearAuction(cb *AuctionCB, state *state.State, env *AuctionEnv) (*big.Int, *big.Int, []*DistMtrg, error) {
+	stateDB := statedb.New(state)
+	ValidatorBenefitRatio := builtin.Params.Native(state).Get(meter.KeyValidatorBenefitRatio)
+
+	actualPrice, distMtrg := CalcSettlement(cb)
+	total := big.NewInt(0)
+	for _, d := range distMtrg {
+		a.SendMTRGToBidder(d.Addr, d.Amount, stateDB, env)
+		total = total.Add(total, d.Amount)
+	}
 
 	// sometimes accuracy cause negative value
 	leftOver := new(big.Int).Sub(cb.RlsdMTRG, total)
@@ -4,6 +4,7 @@ import (
 	"errors"
 
 	"github.com/meterio/meter-pov/block"
+	"github.com/meterio/meter-pov/state"
 )
 
 //  api routine interface
@@ -72,3 +73,23 @@ func GetAuctionSummaryListByHeader(header *block.Header) (*AuctionSummaryList, e
 	}
 	return summaryList, nil
 }
+
+// GetAuctionCBByState returns the auction control block in the state, it works without the
+// auction module started.
+func GetAuctionCBByState(state *state.State) *AuctionCB {
+	cb := getAuctionCB(state)
+	if cb == nil {
+		return &AuctionCB{}
+	}
+	return cb
+}
+
+// GetSummaryListByState returns the auction summaries in the state, it works without the
+// auction module started.
+func GetSummaryListByState(state *state.State) *AuctionSummaryList {
+	summaryList := getSummaryList(state)
+	if summaryList == nil {
+		return NewAuctionSummaryList(nil)
+	}
+	return summaryList
+}
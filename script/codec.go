@@ -0,0 +1,233 @@
+// Copyright (c) 2020 The Meter.io developers
+
+// Distributed under the GNU Lesser General Public License v3.0 software license, see the accompanying
+// file LICENSE or <https://www.gnu.org/licenses/lgpl-3.0.html>
+
+package script
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"reflect"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+var (
+	// clauses run by the script engine start with the prefix, followed by the script pattern
+	clauseDataPrefix = []byte{0xff, 0xff, 0xff, 0xff}
+
+	bigIntType = reflect.TypeOf((*big.Int)(nil))
+	bytesType  = reflect.TypeOf([]byte(nil))
+)
+
+// Codec encodes and decodes the payload of a module, which is the rlp encoded body of the module.
+type Codec struct {
+	NewBody func() interface{} // returns a pointer to an empty body, which has the Opcode field
+	OpName  func(op uint32) string
+	Ops     []uint32
+}
+
+// ScriptData is the typed description of the script data in a clause. Fields of the body
+// are keyed by their names in lower camel case, except Opcode which is described by Opcode
+// and Op. Amounts are decimal strings, and bytes are hex strings.
+type ScriptData struct {
+	Module   string                     `json:"module"`
+	ModuleID uint32                     `json:"moduleID"`
+	Version  uint32                     `json:"version"`
+	Opcode   uint32                     `json:"opcode"`
+	Op       string                     `json:"op"`
+	Body     map[string]json.RawMessage `json:"body"`
+}
+
+// DecodeScriptData describes the script data of a clause, with or without the clause prefix.
+func DecodeScriptData(data []byte) (*ScriptData, error) {
+	data = bytes.TrimPrefix(data, clauseDataPrefix)
+	if !bytes.HasPrefix(data, ScriptPattern[:]) {
+		return nil, errors.New("script pattern mismatch")
+	}
+	script, err := ScriptDecodeFromBytes(data[len(ScriptPattern):])
+	if err != nil {
+		return nil, err
+	}
+	mod, found := modules.Find(script.Header.GetModID())
+	if !found {
+		return nil, fmt.Errorf("unknown module %v", script.Header.GetModID())
+	}
+
+	body := mod.codec.NewBody()
+	if err := rlp.DecodeBytes(script.Payload, body); err != nil {
+		return nil, err
+	}
+	v := reflect.ValueOf(body).Elem()
+	opcode := uint32(v.FieldByName("Opcode").Uint())
+	sd := &ScriptData{
+		Module:   mod.modName,
+		ModuleID: mod.modID,
+		Version:  script.Header.GetVersion(),
+		Opcode:   opcode,
+		Op:       mod.codec.OpName(opcode),
+		Body:     make(map[string]json.RawMessage),
+	}
+	for i := 0; i < v.NumField(); i++ {
+		name := v.Type().Field(i).Name
+		if name == "Opcode" {
+			continue
+		}
+		value, err := marshalField(v.Field(i))
+		if err != nil {
+			return nil, fmt.Errorf("body field %v: %v", name, err)
+		}
+		sd.Body[fieldKey(name)] = value
+	}
+	return sd, nil
+}
+
+// EncodeScriptData builds the script data of a clause, with the clause prefix. The module is
+// given by Module or ModuleID, and the op by Op or Opcode. Fields missing in the body are zero.
+func EncodeScriptData(sd *ScriptData) ([]byte, error) {
+	mod, err := findCodecModule(sd)
+	if err != nil {
+		return nil, err
+	}
+	opcode, err := resolveOpcode(mod, sd)
+	if err != nil {
+		return nil, err
+	}
+
+	body := mod.codec.NewBody()
+	v := reflect.ValueOf(body).Elem()
+	fields := make(map[string]reflect.Value, v.NumField())
+	for i := 0; i < v.NumField(); i++ {
+		fields[fieldKey(v.Type().Field(i).Name)] = v.Field(i)
+		if v.Field(i).Type() == bigIntType {
+			v.Field(i).Set(reflect.ValueOf(new(big.Int)))
+		}
+	}
+	for key, value := range sd.Body {
+		field, ok := fields[key]
+		if !ok || key == "opcode" {
+			return nil, fmt.Errorf("unknown body field %q", key)
+		}
+		if err := unmarshalField(field, value); err != nil {
+			return nil, fmt.Errorf("body field %q: %v", key, err)
+		}
+	}
+	v.FieldByName("Opcode").SetUint(uint64(opcode))
+
+	payload, err := rlp.EncodeToBytes(body)
+	if err != nil {
+		return nil, err
+	}
+	script := &Script{
+		Header: ScriptHeader{
+			Version: sd.Version,
+			ModID:   mod.modID,
+		},
+		Payload: payload,
+	}
+	data, err := rlp.EncodeToBytes(script)
+	if err != nil {
+		return nil, err
+	}
+	data = append(ScriptPattern[:], data...)
+	return append(append([]byte{}, clauseDataPrefix...), data...), nil
+}
+
+func findCodecModule(sd *ScriptData) (*Module, error) {
+	if sd.Module == "" {
+		mod, found := modules.Find(sd.ModuleID)
+		if !found {
+			return nil, fmt.Errorf("unknown module %v", sd.ModuleID)
+		}
+		return mod, nil
+	}
+	for _, mod := range modules.All() {
+		if mod.modName == sd.Module {
+			if sd.ModuleID != 0 && sd.ModuleID != mod.modID {
+				return nil, fmt.Errorf("module %v has ID %v, not %v", mod.modName, mod.modID, sd.ModuleID)
+			}
+			return &mod, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown module %q", sd.Module)
+}
+
+// resolveOpcode returns the opcode of the op name, names are case insensitive.
+func resolveOpcode(mod *Module, sd *ScriptData) (uint32, error) {
+	if sd.Op == "" {
+		for _, op := range mod.codec.Ops {
+			if op == sd.Opcode {
+				return op, nil
+			}
+		}
+		return 0, fmt.Errorf("unknown %v opcode %v", mod.modName, sd.Opcode)
+	}
+	for _, op := range mod.codec.Ops {
+		if strings.EqualFold(mod.codec.OpName(op), sd.Op) {
+			if sd.Opcode != 0 && sd.Opcode != op {
+				return 0, fmt.Errorf("%v op %v has opcode %v, not %v", mod.modName, sd.Op, op, sd.Opcode)
+			}
+			return op, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown %v op %q", mod.modName, sd.Op)
+}
+
+// fieldKey returns the lower camel case of the field name.
+func fieldKey(name string) string {
+	r, n := utf8.DecodeRuneInString(name)
+	return string(unicode.ToLower(r)) + name[n:]
+}
+
+func marshalField(field reflect.Value) (json.RawMessage, error) {
+	switch field.Type() {
+	case bigIntType:
+		if field.IsNil() {
+			return json.Marshal("0")
+		}
+		return json.Marshal(field.Interface().(*big.Int).String())
+	case bytesType:
+		return json.Marshal(hexutil.Bytes(field.Bytes()))
+	default:
+		return json.Marshal(field.Addr().Interface())
+	}
+}
+
+func unmarshalField(field reflect.Value, value json.RawMessage) error {
+	switch field.Type() {
+	case bigIntType:
+		// both decimal and hex strings, or numbers
+		var str string
+		if err := json.Unmarshal(value, &str); err != nil {
+			var num json.Number
+			if err := json.Unmarshal(value, &num); err != nil {
+				return err
+			}
+			str = num.String()
+		}
+		n, ok := math.ParseBig256(str)
+		if !ok || n.Sign() < 0 {
+			return fmt.Errorf("invalid amount %v", str)
+		}
+		field.Set(reflect.ValueOf(n))
+		return nil
+	case bytesType:
+		var b hexutil.Bytes
+		if err := json.Unmarshal(value, &b); err != nil {
+			return err
+		}
+		field.SetBytes(b)
+		return nil
+	default:
+		return json.Unmarshal(value, field.Addr().Interface())
+	}
+}
@@ -0,0 +1,114 @@
+// Copyright (c) 2020 The Meter.io developers
+
+// Distributed under the GNU Lesser General Public License v3.0 software license, see the accompanying
+// file LICENSE or <https://www.gnu.org/licenses/lgpl-3.0.html>
+
+package script_test
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/meterio/meter-pov/chain/testchain"
+	"github.com/meterio/meter-pov/lvldb"
+	"github.com/meterio/meter-pov/meter"
+	"github.com/meterio/meter-pov/reward"
+	"github.com/meterio/meter-pov/script"
+	"github.com/meterio/meter-pov/script/accountlock"
+	"github.com/meterio/meter-pov/script/auction"
+	"github.com/meterio/meter-pov/script/staking"
+	"github.com/meterio/meter-pov/xenv"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScriptDataCodec(t *testing.T) {
+	// built by the reward package
+	data := reward.BuildAccountLockGoverningTx(0, 0, 7).Clauses()[0].Data()
+	sd, err := script.DecodeScriptData(data)
+	assert.Nil(t, err)
+	assert.Equal(t, script.ACCOUNTLOCK_MODULE_NAME, sd.Module)
+	assert.Equal(t, accountlock.OP_GOVERNING, sd.Opcode)
+	assert.Equal(t, "governing", sd.Op)
+	assert.Equal(t, json.RawMessage("7"), sd.Body["version"])
+	assert.Equal(t, json.RawMessage(`"0"`), sd.Body["meterAmount"])
+	assert.Equal(t, json.RawMessage(`"0x"`), sd.Body["memo"])
+
+	encoded, err := script.EncodeScriptData(sd)
+	assert.Nil(t, err)
+	assert.Equal(t, data, encoded)
+
+	// staking bound from JSON, the op name is case insensitive
+	holder := meter.BytesToAddress([]byte("holder"))
+	var req script.ScriptData
+	assert.Nil(t, json.Unmarshal([]byte(`{
+		"module": "staking",
+		"op": "bound",
+		"body": {
+			"holderAddr": "`+holder.String()+`",
+			"amount": "2000000000000000000000",
+			"token": 1,
+			"candName": "0x6e616d65",
+			"timestamp": 1600000000,
+			"nonce": 3
+		}
+	}`), &req))
+	encoded, err = script.EncodeScriptData(&req)
+	assert.Nil(t, err)
+
+	var s script.Script
+	assert.Nil(t, rlp.DecodeBytes(encoded[8:], &s))
+	assert.Equal(t, script.STAKING_MODULE_ID, s.Header.ModID)
+	sb, err := staking.StakingDecodeFromBytes(s.Payload)
+	assert.Nil(t, err)
+	assert.Equal(t, staking.OP_BOUND, sb.Opcode)
+	assert.Equal(t, holder, sb.HolderAddr)
+	assert.Equal(t, new(big.Int).Mul(big.NewInt(2000), big.NewInt(1e18)), sb.Amount)
+	assert.Equal(t, []byte("name"), sb.CandName)
+	assert.Equal(t, uint64(1600000000), sb.Timestamp)
+
+	sd, err = script.DecodeScriptData(encoded)
+	assert.Nil(t, err)
+	assert.Equal(t, "Bound", sd.Op)
+	assert.Equal(t, json.RawMessage(`"`+holder.String()+`"`), sd.Body["holderAddr"])
+
+	// errors
+	_, err = script.DecodeScriptData([]byte{0xff, 0xff, 0xff, 0xff, 0x01})
+	assert.NotNil(t, err)
+	_, err = script.EncodeScriptData(&script.ScriptData{Module: "staking", Op: "nope"})
+	assert.NotNil(t, err)
+	_, err = script.EncodeScriptData(&script.ScriptData{Module: "staking", Op: "bound", Opcode: staking.OP_UNBOUND})
+	assert.NotNil(t, err)
+	_, err = script.EncodeScriptData(&script.ScriptData{Module: "staking", Op: "bound", Body: map[string]json.RawMessage{"opcode": json.RawMessage("2")}})
+	assert.NotNil(t, err)
+	_, err = script.EncodeScriptData(&script.ScriptData{ModuleID: 999, Opcode: 1})
+	assert.NotNil(t, err)
+}
+
+func TestScriptDataCodecWithEngine(t *testing.T) {
+	kv, _ := lvldb.NewMem()
+	ch, stateC, err := testchain.New(kv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the modules are attached again by a new engine
+	script.NewScriptEngine(ch, stateC)
+	se := script.NewScriptEngine(ch, stateC)
+
+	// codecs of modules not started by the engine are still available, but not their handlers
+	encoded, err := script.EncodeScriptData(&script.ScriptData{Module: script.AUCTION_MODULE_NAME, Op: "bid"})
+	assert.Nil(t, err)
+	_, _, err = se.HandleScriptData(encoded[4:], nil, &xenv.BlockContext{}, &xenv.TransactionContext{}, 0, nil)
+	assert.NotNil(t, err)
+	sd, err := script.DecodeScriptData(encoded)
+	assert.Nil(t, err)
+	assert.Equal(t, script.AUCTION_MODULE_ID, sd.ModuleID)
+	assert.Equal(t, auction.OP_BID, sd.Opcode)
+
+	encoded, err = script.EncodeScriptData(&script.ScriptData{Module: script.STAKING_MODULE_NAME, Op: "bound"})
+	assert.Nil(t, err)
+	sd, err = script.DecodeScriptData(encoded)
+	assert.Nil(t, err)
+	assert.Equal(t, staking.OP_BOUND, sd.Opcode)
+}
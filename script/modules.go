@@ -22,19 +22,58 @@ const (
 	ACCOUNTLOCK_MODULE_ID   = uint32(1002)
 )
 
+var (
+	// modules is the registry of all modules. It starts with the codecs of the modules, so
+	// script data can be encoded and decoded without a running engine, and the engine attaches
+	// the handlers of the modules it starts.
+	modules = newRegistry()
+
+	stakingCodec = &Codec{
+		NewBody: func() interface{} { return &staking.StakingBody{} },
+		OpName:  staking.GetOpName,
+		Ops: []uint32{
+			staking.OP_BOUND, staking.OP_UNBOUND, staking.OP_CANDIDATE, staking.OP_UNCANDIDATE,
+			staking.OP_DELEGATE, staking.OP_UNDELEGATE, staking.OP_CANDIDATE_UPDT, staking.OP_BUCKET_UPDT,
+			staking.OP_BUCKET_REDELEGATE, staking.OP_BUCKET_SPLIT, staking.OP_BUCKET_MERGE,
+			staking.OP_DELEGATE_STATISTICS, staking.OP_DELEGATE_EXITJAIL, staking.OP_FLUSH_ALL_STATISTICS,
+			staking.OP_GOVERNING,
+		},
+	}
+
+	auctionCodec = &Codec{
+		NewBody: func() interface{} { return &auction.AuctionBody{} },
+		OpName:  auction.GetOpName,
+		Ops:     []uint32{auction.OP_START, auction.OP_STOP, auction.OP_BID},
+	}
+
+	accountLockCodec = &Codec{
+		NewBody: func() interface{} { return &accountlock.AccountLockBody{} },
+		OpName:  accountlock.GetOpName,
+		Ops:     []uint32{accountlock.OP_ADDLOCK, accountlock.OP_REMOVELOCK, accountlock.OP_TRANSFER, accountlock.OP_GOVERNING},
+	}
+)
+
+func newRegistry() *Registry {
+	r := &Registry{}
+	registerCodecs(r)
+	return r
+}
+
+// registerCodecs registers all modules with their codecs only, replacing the attached ones.
+func registerCodecs(r *Registry) {
+	r.ForceRegister(STAKING_MODULE_ID, &Module{modName: STAKING_MODULE_NAME, modID: STAKING_MODULE_ID, codec: stakingCodec})
+	r.ForceRegister(AUCTION_MODULE_ID, &Module{modName: AUCTION_MODULE_NAME, modID: AUCTION_MODULE_ID, codec: auctionCodec})
+	r.ForceRegister(ACCOUNTLOCK_MODULE_ID, &Module{modName: ACCOUNTLOCK_MODULE_NAME, modID: ACCOUNTLOCK_MODULE_ID, codec: accountLockCodec})
+}
+
 func ModuleStakingInit(se *ScriptEngine) *staking.Staking {
 	stk := staking.NewStaking(se.chain, se.stateCreator)
 	if stk == nil {
 		panic("init staking module failed")
 	}
 
-	mod := &Module{
-		modName:    STAKING_MODULE_NAME,
-		modID:      STAKING_MODULE_ID,
-		modPtr:     stk,
-		modHandler: stk.PrepareStakingHandler(),
-	}
-	if err := se.modReg.Register(STAKING_MODULE_ID, mod); err != nil {
+	mod, err := modules.Attach(STAKING_MODULE_ID, stk, stk.PrepareStakingHandler())
+	if err != nil {
 		panic("register staking module failed")
 	}
 
@@ -49,13 +88,8 @@ func ModuleAuctionInit(se *ScriptEngine) *auction.Auction {
 		panic("init acution module failed")
 	}
 
-	mod := &Module{
-		modName:    AUCTION_MODULE_NAME,
-		modID:      AUCTION_MODULE_ID,
-		modPtr:     a,
-		modHandler: a.PrepareAuctionHandler(),
-	}
-	if err := se.modReg.Register(AUCTION_MODULE_ID, mod); err != nil {
+	mod, err := modules.Attach(AUCTION_MODULE_ID, a, a.PrepareAuctionHandler())
+	if err != nil {
 		panic("register auction module failed")
 	}
 
@@ -70,13 +104,8 @@ func ModuleAccountLockInit(se *ScriptEngine) *accountlock.AccountLock {
 		panic("init accountlock module failed")
 	}
 
-	mod := &Module{
-		modName:    ACCOUNTLOCK_MODULE_NAME,
-		modID:      ACCOUNTLOCK_MODULE_ID,
-		modPtr:     a,
-		modHandler: a.PrepareAccountLockHandler(),
-	}
-	if err := se.modReg.Register(ACCOUNTLOCK_MODULE_ID, mod); err != nil {
+	mod, err := modules.Attach(ACCOUNTLOCK_MODULE_ID, a, a.PrepareAccountLockHandler())
+	if err != nil {
 		panic("register accountlock module failed")
 	}
 
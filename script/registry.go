@@ -21,9 +21,12 @@ type Module struct {
 	modName    string
 	modID      uint32
 	modPtr     interface{} // unsafe.Pointer // main instance of moudle
-	modHandler func(data []byte, to *meter.Address, txCtx *xenv.TransactionContext, gas uint64, state *state.State) (seOutput *setypes.ScriptEngineOutput, leftOverGas uint64, err error)
+	modHandler ModuleHandler
+	codec      *Codec
 }
 
+type ModuleHandler func(data []byte, to *meter.Address, blockCtx *xenv.BlockContext, txCtx *xenv.TransactionContext, gas uint64, state *state.State) (seOutput *setypes.ScriptEngineOutput, leftOverGas uint64, err error)
+
 func (m *Module) ToString() string {
 	return fmt.Sprintf("Module::: Name: %v, ID: %v", m.modName, m.modID)
 }
@@ -46,6 +49,22 @@ func (r *Registry) ForceRegister(modID uint32, p *Module) error {
 	return nil
 }
 
+// Attach sets the instance and handler of a registered module, it fails if the module is
+// unknown or already attached
+func (r *Registry) Attach(modID uint32, modPtr interface{}, modHandler ModuleHandler) (*Module, error) {
+	mod, ok := r.Find(modID)
+	if !ok {
+		return nil, fmt.Errorf("Module with ID %v is not registered", modID)
+	}
+	if mod.modHandler != nil {
+		return nil, fmt.Errorf("Module with ID %v is already attached", modID)
+	}
+	mod.modPtr = modPtr
+	mod.modHandler = modHandler
+	r.Modules.Store(modID, *mod)
+	return mod, nil
+}
+
 // Find by ID
 func (r *Registry) Find(modID uint32) (*Module, bool) {
 	value, ok := r.Modules.Load(modID)
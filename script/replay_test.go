@@ -0,0 +1,82 @@
+// Copyright (c) 2020 The Meter.io developers
+
+// Distributed under the GNU Lesser General Public License v3.0 software license, see the accompanying
+// file LICENSE or <https://www.gnu.org/licenses/lgpl-3.0.html>
+
+package script_test
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/meterio/meter-pov/block"
+	"github.com/meterio/meter-pov/chain"
+	"github.com/meterio/meter-pov/genesis"
+	"github.com/meterio/meter-pov/lvldb"
+	"github.com/meterio/meter-pov/meter"
+	"github.com/meterio/meter-pov/reward"
+	"github.com/meterio/meter-pov/runtime"
+	"github.com/meterio/meter-pov/script"
+	"github.com/meterio/meter-pov/script/accountlock"
+	"github.com/meterio/meter-pov/state"
+	"github.com/meterio/meter-pov/tx"
+	"github.com/meterio/meter-pov/xenv"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestReplayIndependentOfHead replays the same block on top of the same parent state while the
+// head of the chain moves, the receipts and state of script engine clauses must not change.
+func TestReplayIndependentOfHead(t *testing.T) {
+	kv, _ := lvldb.NewMem()
+	stateCreator := state.NewCreator(kv)
+	b0, _, err := genesis.NewDevnet().Build(stateCreator)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ch, _ := chain.New(kv, b0, true)
+	script.NewScriptEngine(ch, stateCreator)
+
+	const releaseEpoch = 3
+	acc := genesis.DevAccounts()[0].Address
+	blockCtx := &xenv.BlockContext{Number: 100, Time: b0.Header().Timestamp() + 1000, Epoch: releaseEpoch}
+	clauses := []*tx.Clause{
+		// restricted by the account lock until the release epoch
+		tx.NewClause(&genesis.DevAccounts()[1].Address).WithValue(big.NewInt(1)).WithToken(meter.STPD),
+		// removes the released profile
+		reward.BuildAccountLockGoverningTx(ch.Tag(), 99, releaseEpoch).Clauses()[0],
+	}
+
+	replay := func() ([]*runtime.Output, meter.Bytes32, *state.State) {
+		st, _ := stateCreator.NewState(b0.Header().StateRoot())
+		profile := accountlock.NewProfile(acc, []byte("replay"), 0, releaseEpoch, new(big.Int), st.GetBalance(acc))
+		accountlock.GetAccountLockGlobInst().SetProfileList(accountlock.NewProfileList([]*accountlock.Profile{profile}), st)
+
+		rt := runtime.New(ch.NewSeeker(b0.Header().ID()), st, blockCtx)
+		outputs := make([]*runtime.Output, 0, len(clauses))
+		for i, clause := range clauses {
+			outputs = append(outputs, rt.ExecuteClause(clause, uint32(i), 1000000, &xenv.TransactionContext{Origin: acc}))
+		}
+		root, err := st.Stage().Hash()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return outputs, root, st
+	}
+
+	outputs, root, st := replay()
+	assert.Nil(t, outputs[0].VMErr)
+	assert.Nil(t, outputs[1].VMErr)
+	assert.Nil(t, accountlock.GetAccountLockGlobInst().GetProfileList(st).Get(acc))
+
+	// move the head to a block of an earlier epoch than the replayed one
+	b1 := new(block.Builder).ParentID(b0.Header().ID()).Timestamp(b0.Header().Timestamp() + 10).GasLimit(10000000).TotalScore(1).Build()
+	b1.SetQC(&block.QuorumCert{QCHeight: 1, QCRound: 1, EpochID: 0})
+	if _, err := ch.AddBlock(b1, nil, true); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, b1.Header().ID(), ch.BestBlock().Header().ID())
+
+	replayedOutputs, replayedRoot, _ := replay()
+	assert.Equal(t, outputs, replayedOutputs)
+	assert.Equal(t, root, replayedRoot)
+}
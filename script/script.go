@@ -32,7 +32,6 @@ type ScriptEngine struct {
 	chain        *chain.Chain
 	stateCreator *state.Creator
 	logger       log15.Logger
-	modReg       Registry
 }
 
 // Glob Instance
@@ -52,6 +51,9 @@ func NewScriptEngine(chain *chain.Chain, state *state.Creator) *ScriptEngine {
 	}
 	SetScriptGlobInst(se)
 
+	// detach the modules of a previous engine
+	registerCodecs(modules)
+
 	// initGobEncode()
 
 	// start all sub modules
@@ -130,7 +132,7 @@ func (se *ScriptEngine) StartTeslaForkModules() {
 	ModuleAuctionInit(se)
 }
 
-func (se *ScriptEngine) HandleScriptData(data []byte, to *meter.Address, txCtx *xenv.TransactionContext, gas uint64, state *state.State) (seOutput *setypes.ScriptEngineOutput, leftOverGas uint64, err error) {
+func (se *ScriptEngine) HandleScriptData(data []byte, to *meter.Address, blockCtx *xenv.BlockContext, txCtx *xenv.TransactionContext, gas uint64, state *state.State) (seOutput *setypes.ScriptEngineOutput, leftOverGas uint64, err error) {
 	// se.logger.Info("received script data", "to", to, "gas", gas, "txHash", txCtx.ID.String()) //"data", hex.EncodeToString(data))
 	if bytes.Compare(data[:len(ScriptPattern)], ScriptPattern[:]) != 0 {
 		err := fmt.Errorf("Pattern mismatch, pattern = %v", hex.EncodeToString(data[:len(ScriptPattern)]))
@@ -145,8 +147,8 @@ func (se *ScriptEngine) HandleScriptData(data []byte, to *meter.Address, txCtx *
 
 	header := script.Header
 
-	mod, find := se.modReg.Find(header.GetModID())
-	if find == false {
+	mod, find := modules.Find(header.GetModID())
+	if find == false || mod.modHandler == nil {
 		err := fmt.Errorf("could not address module %v", header.GetModID())
 		fmt.Println(err)
 		return nil, gas, err
@@ -154,6 +156,6 @@ func (se *ScriptEngine) HandleScriptData(data []byte, to *meter.Address, txCtx *
 	// se.logger.Info("script header", "header", header.ToString(), "module", mod.ToString())
 
 	//module handler
-	seOutput, leftOverGas, err = mod.modHandler(script.Payload, to, txCtx, gas, state)
+	seOutput, leftOverGas, err = mod.modHandler(script.Payload, to, blockCtx, txCtx, gas, state)
 	return
 }
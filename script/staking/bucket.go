@@ -97,6 +97,12 @@ func (b *Bucket) UpdateLockOption(opt uint32, rate uint8) {
 
 type BucketList struct {
 	buckets []*Bucket
+
+	// store is set if the list is backed by keyed storage, buckets then
+	// only holds the entries loaded so far, sorted by ID as well.
+	store   *keyedStore
+	removed map[meter.Bytes32]bool
+	loaded  bool
 }
 
 func newBucketList(buckets []*Bucket) *BucketList {
@@ -106,6 +112,14 @@ func newBucketList(buckets []*Bucket) *BucketList {
 	return &BucketList{buckets: buckets}
 }
 
+func newKeyedBucketList(store *keyedStore) *BucketList {
+	return &BucketList{
+		buckets: make([]*Bucket, 0),
+		store:   store,
+		removed: make(map[meter.Bytes32]bool),
+	}
+}
+
 func (bl *BucketList) indexOf(bucketID meter.Bytes32) (int, int) {
 	// return values:
 	//     first parameter: if found, the index of the item
@@ -129,20 +143,45 @@ func (bl *BucketList) indexOf(bucketID meter.Bytes32) (int, int) {
 	return -1, r
 }
 
+// load reads the bucket from keyed storage into the list.
+func (l *BucketList) load(id meter.Bytes32) *Bucket {
+	if l.store == nil || l.removed[id] {
+		return nil
+	}
+	b := &Bucket{}
+	if !l.store.get(id, b) {
+		return nil
+	}
+	l.insert(b)
+	return b
+}
+
+// all returns every bucket in the list, loading the missing ones from keyed storage.
+func (l *BucketList) all() []*Bucket {
+	if l.store != nil && !l.loaded {
+		for _, id := range l.store.keys() {
+			if index, _ := l.indexOf(id); index < 0 {
+				l.load(id)
+			}
+		}
+		l.loaded = true
+	}
+	return l.buckets
+}
+
 func (l *BucketList) Get(id meter.Bytes32) *Bucket {
 	index, _ := l.indexOf(id)
 	if index >= 0 {
 		return l.buckets[index]
 	}
-	return nil
+	return l.load(id)
 }
 
 func (l *BucketList) Exist(id meter.Bytes32) bool {
-	index, _ := l.indexOf(id)
-	return index >= 0
+	return l.Get(id) != nil
 }
 
-func (l *BucketList) Add(b *Bucket) {
+func (l *BucketList) insert(b *Bucket) {
 	index, insertIndex := l.indexOf(b.BucketID)
 	if index < 0 {
 		if len(l.buckets) == 0 {
@@ -160,16 +199,27 @@ func (l *BucketList) Add(b *Bucket) {
 	return
 }
 
+func (l *BucketList) Add(b *Bucket) {
+	l.insert(b)
+	if l.store != nil {
+		delete(l.removed, b.BucketID)
+	}
+	return
+}
+
 func (l *BucketList) Remove(id meter.Bytes32) {
 	index, _ := l.indexOf(id)
 	if index >= 0 {
 		l.buckets = append(l.buckets[:index], l.buckets[index+1:]...)
 	}
+	if l.store != nil {
+		l.removed[id] = true
+	}
 	return
 }
 
 func (l *BucketList) ToString() string {
-	if l == nil || len(l.buckets) == 0 {
+	if l == nil || len(l.all()) == 0 {
 		return "BucketList (size:0)"
 	}
 	s := []string{fmt.Sprintf("BucketList (size:%v) {", len(l.buckets))}
@@ -182,7 +232,7 @@ func (l *BucketList) ToString() string {
 
 func (l *BucketList) ToList() []Bucket {
 	result := make([]Bucket, 0)
-	for _, v := range l.buckets {
+	for _, v := range l.all() {
 		result = append(result, *v)
 	}
 	return result
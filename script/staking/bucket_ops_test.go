@@ -0,0 +1,145 @@
+// Copyright (c) 2020 The Meter.io developers
+
+// Distributed under the GNU Lesser General Public License v3.0 software license, see the accompanying
+// file LICENSE or <https://www.gnu.org/licenses/lgpl-3.0.html>
+
+package staking
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/meterio/meter-pov/lvldb"
+	"github.com/meterio/meter-pov/meter"
+	setypes "github.com/meterio/meter-pov/script/types"
+	"github.com/meterio/meter-pov/state"
+	"github.com/meterio/meter-pov/xenv"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBucketOps(t *testing.T) {
+	t.Run("legacy", func(t *testing.T) { testBucketOps(t, false) })
+	t.Run("keyed", func(t *testing.T) { testBucketOps(t, true) })
+}
+
+func testBucketOps(t *testing.T, keyed bool) {
+	db, _ := lvldb.NewMem()
+	st, _ := state.New(meter.Bytes32{}, db)
+	s := &Staking{}
+
+	mtrg := func(n int64) *big.Int { return new(big.Int).Mul(big.NewInt(n), big.NewInt(1e18)) }
+	owner := meter.BytesToAddress([]byte("owner"))
+	candA := NewCandidate(meter.BytesToAddress([]byte("candA")), []byte("a"), []byte("a"), []byte("a"), []byte("1.2.3.4"), 8670, 0, 1)
+	candB := NewCandidate(meter.BytesToAddress([]byte("candB")), []byte("b"), []byte("b"), []byte("b"), []byte("1.2.3.5"), 8670, 0, 1)
+	holder := NewStakeholder(owner)
+
+	bucketList := newBucketList(nil)
+	selfIDs := make([]meter.Bytes32, 0)
+	for _, c := range []*Candidate{candA, candB} {
+		self := NewBucket(c.Addr, c.Addr, mtrg(2000), meter.STPD, FOREVER_LOCK, 0, 0, 1, 0)
+		bucketList.Add(self)
+		c.AddBucket(self)
+		selfIDs = append(selfIDs, self.BucketID)
+	}
+	opt, rate, _ := GetBoundLockOption(ONE_WEEK_LOCK)
+	b := NewBucket(owner, candA.Addr, mtrg(1000), meter.STPD, opt, rate, 0, 1, 0)
+	bucketList.Add(b)
+	candA.AddBucket(b)
+	holder.AddBucket(b)
+	s.SetBucketList(bucketList, st)
+	s.SetCandidateList(NewCandidateList([]*Candidate{candA, candB}), st)
+	s.SetStakeHolderList(newStakeholderList([]*Stakeholder{holder}), st)
+	if keyed {
+		s.MigrateToKeyedStorage(st)
+	}
+
+	// runs the handler in a block at the time
+	run := func(handler func(*StakingBody, *StakingEnv, uint64) (uint64, error), sb *StakingBody, time uint64) (*StakingEnv, error) {
+		env := NewStakingEnv(s, st, &xenv.BlockContext{Time: time}, &xenv.TransactionContext{Origin: owner}, &StakingModuleAddr)
+		_, err := handler(sb, env, meter.ClauseGas)
+		return env, err
+	}
+	// votes of candidates must be the sum of their buckets
+	checkVotes := func() {
+		bucketList := s.GetBucketList(st)
+		for _, c := range s.GetCandidateList(st).ToList() {
+			sum := new(big.Int)
+			for _, id := range c.Buckets {
+				sum.Add(sum, bucketList.Get(id).TotalVotes)
+			}
+			assert.Equal(t, sum, c.TotalVotes, c.Addr.String())
+		}
+		assert.Equal(t, mtrg(1000), s.GetStakeHolderList(st).Get(owner).TotalStake)
+	}
+	eventID := func(name string) meter.Bytes32 {
+		ev, _ := setypes.ScriptEngine.ABI.EventByName(name)
+		return ev.ID()
+	}
+
+	// redelegate
+	ts := uint64(1 + 3600*24*30)
+	_, err := run((*StakingBody).BucketRedelegateHandler, &StakingBody{HolderAddr: owner, CandAddr: candB.Addr, StakingID: b.BucketID, Autobid: 101}, ts)
+	assert.Equal(t, errInvalidParams, err)
+	env, err := run((*StakingBody).BucketRedelegateHandler, &StakingBody{HolderAddr: owner, CandAddr: candB.Addr, StakingID: b.BucketID}, ts)
+	assert.Nil(t, err)
+	b = s.GetBucketList(st).Get(b.BucketID)
+	assert.Equal(t, candB.Addr, b.Candidate)
+	assert.True(t, b.BonusVotes > 0)
+	assert.Equal(t, ts, b.CalcLastTime)
+	assert.Equal(t, 2, len(s.GetCandidateList(st).Get(candB.Addr).Buckets))
+	assert.Equal(t, 1, len(s.GetCandidateList(st).Get(candA.Addr).Buckets))
+	assert.Equal(t, 1, len(env.GetEvents()))
+	assert.Equal(t, eventID("BucketRedelegated"), env.GetEvents()[0].Topics[0])
+	assert.Equal(t, b.BucketID, env.GetEvents()[0].Topics[2])
+	checkVotes()
+
+	_, err = run((*StakingBody).BucketRedelegateHandler, &StakingBody{HolderAddr: owner, CandAddr: candA.Addr, StakingID: b.BucketID}, ts+1)
+	assert.Equal(t, errUpdateTooFrequent, err)
+	// the timestamp of the script is not the time of the op
+	_, err = run((*StakingBody).BucketRedelegateHandler, &StakingBody{HolderAddr: owner, CandAddr: candA.Addr, StakingID: b.BucketID, Timestamp: ts + MIN_REDELEGATE_INTV}, ts+1)
+	assert.Equal(t, errUpdateTooFrequent, err)
+	_, err = run((*StakingBody).BucketRedelegateHandler, &StakingBody{HolderAddr: owner, CandAddr: candB.Addr, StakingID: b.BucketID}, ts+MIN_REDELEGATE_INTV)
+	assert.Equal(t, errCandidateNotChanged, err)
+
+	// split
+	ts += 3600 * 24
+	_, err = run((*StakingBody).BucketSplitHandler, &StakingBody{HolderAddr: owner, StakingID: b.BucketID, Amount: mtrg(950), Nonce: 1}, ts)
+	assert.Equal(t, errLessThanMinBoundBalance, err)
+	env, err = run((*StakingBody).BucketSplitHandler, &StakingBody{HolderAddr: owner, StakingID: b.BucketID, Amount: mtrg(400), Nonce: 1}, ts)
+	assert.Nil(t, err)
+	newID := NewBucket(owner, candB.Addr, mtrg(400), meter.STPD, opt, rate, 0, ts, 1).BucketID
+	b1, b2 := s.GetBucketList(st).Get(b.BucketID), s.GetBucketList(st).Get(newID)
+	assert.NotNil(t, b2)
+	assert.Equal(t, mtrg(600), b1.Value)
+	assert.Equal(t, mtrg(400), b2.Value)
+	assert.Equal(t, b1.Option, b2.Option)
+	assert.Equal(t, candB.Addr, b2.Candidate)
+	assert.Equal(t, new(big.Int).Add(b2.Value, new(big.Int).SetUint64(b2.BonusVotes)), b2.TotalVotes)
+	assert.Equal(t, eventID("BucketSplit"), env.GetEvents()[0].Topics[0])
+	assert.Equal(t, ts-3600*24, s.GetRedelegateTime(newID, st))
+	assert.Equal(t, 2, len(s.GetStakeHolderList(st).Get(owner).Buckets))
+	checkVotes()
+
+	// merge
+	ts += 3600 * 24
+	_, err = run((*StakingBody).BucketMergeHandler, &StakingBody{HolderAddr: owner, StakingID: b.BucketID, ExtraData: b.BucketID.Bytes()}, ts)
+	assert.Equal(t, errInvalidParams, err)
+	_, err = run((*StakingBody).BucketMergeHandler, &StakingBody{HolderAddr: owner, StakingID: b.BucketID, ExtraData: selfIDs[1].Bytes()}, ts)
+	assert.Equal(t, errBucketOwnerMismatch, err)
+	env, err = run((*StakingBody).BucketMergeHandler, &StakingBody{HolderAddr: owner, StakingID: b.BucketID, ExtraData: newID.Bytes()}, ts)
+	assert.Nil(t, err)
+	b = s.GetBucketList(st).Get(b.BucketID)
+	assert.False(t, s.GetBucketList(st).Exist(newID))
+	assert.Equal(t, ts-3600*48, s.GetRedelegateTime(b.BucketID, st))
+	assert.Zero(t, st.GetStorage(StakingModuleAddr, meter.Blake2b(RedelegateTimeKey[:], newID[:])))
+	assert.Equal(t, mtrg(1000), b.Value)
+	assert.Equal(t, new(big.Int).Add(b.Value, new(big.Int).SetUint64(b.BonusVotes)), b.TotalVotes)
+	assert.Equal(t, ts, b.CalcLastTime)
+	assert.Equal(t, eventID("BucketMerged"), env.GetEvents()[0].Topics[0])
+	assert.Equal(t, []meter.Bytes32{b.BucketID}, s.GetStakeHolderList(st).Get(owner).Buckets)
+	checkVotes()
+	if keyed {
+		assert.Equal(t, uint64(3), newBucketStore(st).count())
+		assert.Equal(t, 0, len(st.GetRawStorage(StakingModuleAddr, BucketListKey)))
+	}
+}
@@ -76,6 +76,12 @@ func (c *Candidate) RemoveBucket(bucket *Bucket) {
 
 type CandidateList struct {
 	candidates []*Candidate
+
+	// store is set if the list is backed by keyed storage, candidates then
+	// only holds the entries loaded so far, sorted by address as well.
+	store   *keyedStore
+	removed map[meter.Bytes32]bool
+	loaded  bool
 }
 
 func NewCandidateList(candidates []*Candidate) *CandidateList {
@@ -88,6 +94,14 @@ func NewCandidateList(candidates []*Candidate) *CandidateList {
 	return &CandidateList{candidates: candidates}
 }
 
+func newKeyedCandidateList(store *keyedStore) *CandidateList {
+	return &CandidateList{
+		candidates: make([]*Candidate, 0),
+		store:      store,
+		removed:    make(map[meter.Bytes32]bool),
+	}
+}
+
 func (cl *CandidateList) indexOf(addr meter.Address) (int, int) {
 	// return values:
 	//     first parameter: if found, the index of the item
@@ -111,20 +125,47 @@ func (cl *CandidateList) indexOf(addr meter.Address) (int, int) {
 	return -1, r
 }
 
+// load reads the candidate from keyed storage into the list.
+func (cl *CandidateList) load(addr meter.Address) *Candidate {
+	key := addressKey(addr)
+	if cl.store == nil || cl.removed[key] {
+		return nil
+	}
+	c := &Candidate{}
+	if !cl.store.get(key, c) {
+		return nil
+	}
+	cl.insert(c)
+	return c
+}
+
+// all returns every candidate in the list, loading the missing ones from keyed storage.
+func (cl *CandidateList) all() []*Candidate {
+	if cl.store != nil && !cl.loaded {
+		for _, key := range cl.store.keys() {
+			addr := meter.BytesToAddress(key.Bytes())
+			if index, _ := cl.indexOf(addr); index < 0 {
+				cl.load(addr)
+			}
+		}
+		cl.loaded = true
+	}
+	return cl.candidates
+}
+
 func (cl *CandidateList) Get(addr meter.Address) *Candidate {
 	index, _ := cl.indexOf(addr)
 	if index < 0 {
-		return nil
+		return cl.load(addr)
 	}
 	return cl.candidates[index]
 }
 
 func (cl *CandidateList) Exist(addr meter.Address) bool {
-	index, _ := cl.indexOf(addr)
-	return index >= 0
+	return cl.Get(addr) != nil
 }
 
-func (cl *CandidateList) Add(c *Candidate) {
+func (cl *CandidateList) insert(c *Candidate) {
 	index, insertIndex := cl.indexOf(c.Addr)
 	if index < 0 {
 		if len(cl.candidates) == 0 {
@@ -143,20 +184,31 @@ func (cl *CandidateList) Add(c *Candidate) {
 	return
 }
 
+func (cl *CandidateList) Add(c *Candidate) {
+	cl.insert(c)
+	if cl.store != nil {
+		delete(cl.removed, addressKey(c.Addr))
+	}
+	return
+}
+
 func (cl *CandidateList) Remove(addr meter.Address) {
 	index, _ := cl.indexOf(addr)
 	if index >= 0 {
 		cl.candidates = append(cl.candidates[:index], cl.candidates[index+1:]...)
 	}
+	if cl.store != nil {
+		cl.removed[addressKey(addr)] = true
+	}
 	return
 }
 
 func (cl *CandidateList) Count() int {
-	return len(cl.candidates)
+	return len(cl.all())
 }
 
 func (cl *CandidateList) ToString() string {
-	if cl == nil || len(cl.candidates) == 0 {
+	if cl == nil || len(cl.all()) == 0 {
 		return "CandidateList (size:0)"
 	}
 	s := []string{fmt.Sprintf("CandiateList (size:%v) {", len(cl.candidates))}
@@ -169,7 +221,7 @@ func (cl *CandidateList) ToString() string {
 
 func (l *CandidateList) ToList() []Candidate {
 	result := make([]Candidate, 0)
-	for _, v := range l.candidates {
+	for _, v := range l.all() {
 		result = append(result, *v)
 	}
 	return result
@@ -19,6 +19,7 @@ import (
 	"github.com/ethereum/go-ethereum/rlp"
 	"github.com/meterio/meter-pov/builtin"
 	"github.com/meterio/meter-pov/meter"
+	setypes "github.com/meterio/meter-pov/script/types"
 )
 
 var (
@@ -35,6 +36,10 @@ var (
 	errBucketTokenMismatch  = errors.New("bucket token mismatch")
 	errBucketInUse          = errors.New("bucket in used (address is not zero)")
 	errUpdateForeverBucket  = errors.New("can't update forever bucket")
+	errBucketUnbounded      = errors.New("bucket is unbounded")
+	errBucketNotInUse       = errors.New("bucket not in use (address is zero)")
+	errBucketAlreadyExists  = errors.New("bucket already exists")
+	errBucketsNotMergeable  = errors.New("buckets have different candidate, option or token")
 
 	// amount
 	errLessThanMinimalBalance  = errors.New("amount less than minimal balance (" + new(big.Int).Div(MIN_REQUIRED_BY_DELEGATE, big.NewInt(1e18)).String() + " STPD)")
@@ -351,7 +356,7 @@ func (sb *StakingBody) CandidateHandler(env *StakingEnv, gas uint64) (leftOverGa
 		return
 	}
 
-	for _, record := range candidateList.candidates {
+	for _, record := range candidateList.all() {
 		pkListed := bytes.Equal(record.PubKey, []byte(candidatePubKey))
 		ipListed := bytes.Equal(record.IPAddr, sb.CandIP)
 		nameListed := bytes.Equal(record.Name, sb.CandName)
@@ -670,7 +675,7 @@ func (sb *StakingBody) GoverningHandler(env *StakingEnv, gas uint64) (leftOverGa
 
 	// start to calc next round delegates
 	ts := sb.Timestamp
-	for _, bkt := range bucketList.buckets {
+	for _, bkt := range bucketList.all() {
 
 		log.Debug("before handling", "bucket", bkt.ToString())
 		// handle unbound first
@@ -689,7 +694,7 @@ func (sb *StakingBody) GoverningHandler(env *StakingEnv, gas uint64) (leftOverGa
 				cand := candidateList.Get(bkt.Candidate)
 				if cand != nil {
 					cand.RemoveBucket(bkt)
-					if len(candidateList.candidates) == 0 {
+					if candidateList.Count() == 0 {
 						candidateList.Remove(cand.Addr)
 					}
 				}
@@ -735,7 +740,7 @@ func (sb *StakingBody) GoverningHandler(env *StakingEnv, gas uint64) (leftOverGa
 
 	// handle delegateList
 	delegates := []*Delegate{}
-	for _, c := range candidateList.candidates {
+	for _, c := range candidateList.all() {
 		delegate := &Delegate{
 			Address:     c.Addr,
 			PubKey:      c.PubKey,
@@ -1159,8 +1164,6 @@ func (sb *StakingBody) BucketUpdateHandler(env *StakingEnv, gas uint64) (leftOve
 	bucketList := staking.GetBucketList(state)
 	stakeholderList := staking.GetStakeHolderList(state)
 
-	number := staking.Chain().BestBlock().BlockHeader.Number()
-
 	if gas < meter.ClauseGas {
 		leftOverGas = 0
 	} else {
@@ -1356,6 +1359,301 @@ func (sb *StakingBody) BucketUpdateHandler(env *StakingEnv, gas uint64) (leftOve
 	return
 }
 
+// redelegate the bucket to another candidate without unbounding it, the bucket can be
+// redelegated once in MIN_REDELEGATE_INTV
+func (sb *StakingBody) BucketRedelegateHandler(env *StakingEnv, gas uint64) (leftOverGas uint64, err error) {
+	var ret []byte
+	defer func() {
+		if err != nil {
+			ret = []byte(err.Error())
+		}
+		env.SetReturnData(ret)
+	}()
+	staking := env.GetStaking()
+	state := env.GetState()
+	candidateList := staking.GetCandidateList(state)
+	bucketList := staking.GetBucketList(state)
+	stakeholderList := staking.GetStakeHolderList(state)
+
+	if gas < meter.ClauseGas {
+		leftOverGas = 0
+	} else {
+		leftOverGas = gas - meter.ClauseGas
+	}
+
+	b := bucketList.Get(sb.StakingID)
+	if b == nil {
+		return leftOverGas, errBucketNotFound
+	}
+	if b.Owner != sb.HolderAddr {
+		return leftOverGas, errBucketOwnerMismatch
+	}
+	if b.IsForeverLock() == true {
+		return leftOverGas, errUpdateForeverBucket
+	}
+	if b.Unbounded == true {
+		return leftOverGas, errBucketUnbounded
+	}
+	if b.Candidate.IsZero() {
+		return leftOverGas, errBucketNotInUse
+	}
+	if b.Candidate == sb.CandAddr {
+		return leftOverGas, errCandidateNotChanged
+	}
+	if sb.Autobid > 100 {
+		log.Error(fmt.Sprintf("invalid parameter: autobid %d (should be in [0， 100])", sb.Autobid))
+		return leftOverGas, errInvalidParams
+	}
+	now := env.GetBlockCtx().Time
+	if last := staking.GetRedelegateTime(b.BucketID, state); last != 0 && now < last+MIN_REDELEGATE_INTV {
+		log.Error("redelegate too frequently", "curTime", now, "recordedTime", last)
+		return leftOverGas, errUpdateTooFrequent
+	}
+
+	cand := candidateList.Get(sb.CandAddr)
+	if cand == nil {
+		return leftOverGas, errCandidateNotListed
+	}
+
+	// the bonus is touched after the bucket leaves the old candidate, so the votes of both
+	// candidates stay the sum of their buckets, and before the self votes check, so the check
+	// counts the bonus. The lists are not saved if the check fails.
+	from := b.Candidate
+	if old := candidateList.Get(from); old != nil {
+		old.RemoveBucket(b)
+	}
+	TouchBucketBonus(now, b)
+	if CheckCandEnoughSelfVotes(b.TotalVotes, cand, bucketList, TESLA1_1_SELF_VOTE_RATIO) == false {
+		log.Error(errCandidateNotEnoughSelfVotes.Error(), "candidate", cand.Addr.String())
+		return leftOverGas, errCandidateNotEnoughSelfVotes
+	}
+
+	// sanity check done, take actions
+	b.Candidate = sb.CandAddr
+	b.Autobid = sb.Autobid
+	cand.AddBucket(b)
+	staking.SetRedelegateTime(b.BucketID, now, state)
+
+	staking.SetCandidateList(candidateList, state)
+	staking.SetBucketList(bucketList, state)
+	staking.SetStakeHolderList(stakeholderList, state)
+
+	addBucketEvent(env, "BucketRedelegated", b.Owner, b.BucketID, from, sb.CandAddr)
+	return
+}
+
+// split the amount out of the bucket into a new bucket with the same candidate and lock option,
+// the bonus votes are shared in proportion to the values
+func (sb *StakingBody) BucketSplitHandler(env *StakingEnv, gas uint64) (leftOverGas uint64, err error) {
+	var ret []byte
+	defer func() {
+		if err != nil {
+			ret = []byte(err.Error())
+		}
+		env.SetReturnData(ret)
+	}()
+	staking := env.GetStaking()
+	state := env.GetState()
+	candidateList := staking.GetCandidateList(state)
+	bucketList := staking.GetBucketList(state)
+	stakeholderList := staking.GetStakeHolderList(state)
+
+	if gas < meter.ClauseGas {
+		leftOverGas = 0
+	} else {
+		leftOverGas = gas - meter.ClauseGas
+	}
+
+	b := bucketList.Get(sb.StakingID)
+	if b == nil {
+		return leftOverGas, errBucketNotFound
+	}
+	if b.Owner != sb.HolderAddr {
+		return leftOverGas, errBucketOwnerMismatch
+	}
+	if b.IsForeverLock() == true {
+		return leftOverGas, errUpdateForeverBucket
+	}
+	if b.Unbounded == true {
+		return leftOverGas, errBucketUnbounded
+	}
+	// both buckets keep at least the minimal balance
+	if sb.Amount == nil || sb.Amount.Cmp(MIN_BOUND_BALANCE) < 0 {
+		return leftOverGas, errLessThanMinBoundBalance
+	}
+	if new(big.Int).Sub(b.Value, sb.Amount).Cmp(MIN_BOUND_BALANCE) < 0 {
+		return leftOverGas, errLessThanMinBoundBalance
+	}
+
+	now := env.GetBlockCtx().Time
+	newBucket := NewBucket(b.Owner, b.Candidate, new(big.Int).Set(sb.Amount), b.Token, b.Option, b.Rate, b.Autobid, now, sb.Nonce)
+	if bucketList.Exist(newBucket.BucketID) {
+		return leftOverGas, errBucketAlreadyExists
+	}
+
+	// sanity check done, take actions
+	// the bucket is removed from its candidate and holder before any change, and both buckets
+	// are added back afterwards, so votes and stakes stay the sum of the buckets
+	var cand *Candidate
+	if b.Candidate.IsZero() == false {
+		cand = candidateList.Get(b.Candidate)
+	}
+	if cand != nil {
+		cand.RemoveBucket(b)
+	}
+	stakeholder := stakeholderList.Get(b.Owner)
+	if stakeholder == nil {
+		stakeholder = NewStakeholder(b.Owner)
+		stakeholderList.Add(stakeholder)
+	} else {
+		stakeholder.RemoveBucket(b)
+	}
+
+	TouchBucketBonus(now, b)
+	bonus := new(big.Int).SetUint64(b.BonusVotes)
+	bonus.Mul(bonus, sb.Amount)
+	bonus.Div(bonus, b.Value)
+
+	b.Value.Sub(b.Value, sb.Amount)
+	b.BonusVotes -= bonus.Uint64()
+	b.TotalVotes.Sub(b.TotalVotes, sb.Amount)
+	b.TotalVotes.Sub(b.TotalVotes, bonus)
+
+	newBucket.BonusVotes = bonus.Uint64()
+	newBucket.TotalVotes = new(big.Int).Add(sb.Amount, bonus)
+	newBucket.CalcLastTime = b.CalcLastTime
+	bucketList.Add(newBucket)
+
+	stakeholder.AddBucket(b)
+	stakeholder.AddBucket(newBucket)
+	if cand != nil {
+		cand.AddBucket(b)
+		cand.AddBucket(newBucket)
+	}
+	// the new bucket can't be redelegated earlier than the bucket
+	if last := staking.GetRedelegateTime(b.BucketID, state); last != 0 {
+		staking.SetRedelegateTime(newBucket.BucketID, last, state)
+	}
+
+	staking.SetCandidateList(candidateList, state)
+	staking.SetBucketList(bucketList, state)
+	staking.SetStakeHolderList(stakeholderList, state)
+
+	addBucketEvent(env, "BucketSplit", b.Owner, b.BucketID, newBucket.BucketID, sb.Amount)
+	return
+}
+
+// merge the bucket given by the extra data into the bucket, both buckets must have the same
+// owner, candidate, lock option and token
+func (sb *StakingBody) BucketMergeHandler(env *StakingEnv, gas uint64) (leftOverGas uint64, err error) {
+	var ret []byte
+	defer func() {
+		if err != nil {
+			ret = []byte(err.Error())
+		}
+		env.SetReturnData(ret)
+	}()
+	staking := env.GetStaking()
+	state := env.GetState()
+	candidateList := staking.GetCandidateList(state)
+	bucketList := staking.GetBucketList(state)
+	stakeholderList := staking.GetStakeHolderList(state)
+
+	if gas < meter.ClauseGas {
+		leftOverGas = 0
+	} else {
+		leftOverGas = gas - meter.ClauseGas
+	}
+
+	if len(sb.ExtraData) != len(meter.Bytes32{}) {
+		return leftOverGas, errInvalidParams
+	}
+	fromID := meter.BytesToBytes32(sb.ExtraData)
+	if fromID == sb.StakingID {
+		return leftOverGas, errInvalidParams
+	}
+
+	b := bucketList.Get(sb.StakingID)
+	from := bucketList.Get(fromID)
+	if b == nil || from == nil {
+		return leftOverGas, errBucketNotFound
+	}
+	if b.Owner != sb.HolderAddr || from.Owner != sb.HolderAddr {
+		return leftOverGas, errBucketOwnerMismatch
+	}
+	if b.IsForeverLock() == true || from.IsForeverLock() == true {
+		return leftOverGas, errUpdateForeverBucket
+	}
+	if b.Unbounded == true || from.Unbounded == true {
+		return leftOverGas, errBucketUnbounded
+	}
+	if b.Candidate != from.Candidate || b.Option != from.Option || b.Token != from.Token {
+		return leftOverGas, errBucketsNotMergeable
+	}
+
+	// sanity check done, take actions
+	var cand *Candidate
+	if b.Candidate.IsZero() == false {
+		cand = candidateList.Get(b.Candidate)
+	}
+	if cand != nil {
+		cand.RemoveBucket(b)
+		cand.RemoveBucket(from)
+	}
+	stakeholder := stakeholderList.Get(b.Owner)
+	if stakeholder == nil {
+		stakeholder = NewStakeholder(b.Owner)
+		stakeholderList.Add(stakeholder)
+	} else {
+		stakeholder.RemoveBucket(b)
+		stakeholder.RemoveBucket(from)
+	}
+
+	// bonus votes of both buckets are calculated up to now before they are summed up
+	now := env.GetBlockCtx().Time
+	TouchBucketBonus(now, b)
+	TouchBucketBonus(now, from)
+	b.Value.Add(b.Value, from.Value)
+	b.BonusVotes += from.BonusVotes
+	b.TotalVotes.Add(b.TotalVotes, from.TotalVotes)
+	if from.CalcLastTime > b.CalcLastTime {
+		b.CalcLastTime = from.CalcLastTime
+	}
+	bucketList.Remove(from.BucketID)
+
+	stakeholder.AddBucket(b)
+	if cand != nil {
+		cand.AddBucket(b)
+	}
+	// the merged bucket can't be redelegated earlier than either of the buckets, and the
+	// redelegate time of the merged away bucket is cleared along with the bucket
+	if last := staking.GetRedelegateTime(from.BucketID, state); last > staking.GetRedelegateTime(b.BucketID, state) {
+		staking.SetRedelegateTime(b.BucketID, last, state)
+	}
+	staking.SetRedelegateTime(from.BucketID, 0, state)
+
+	staking.SetCandidateList(candidateList, state)
+	staking.SetBucketList(bucketList, state)
+	staking.SetStakeHolderList(stakeholderList, state)
+
+	addBucketEvent(env, "BucketMerged", b.Owner, b.BucketID, from.BucketID, from.Value)
+	return
+}
+
+// addBucketEvent adds the script engine event of bucket ops, with the owner and bucket ID indexed.
+func addBucketEvent(env *StakingEnv, name string, owner meter.Address, bucketID meter.Bytes32, args ...interface{}) {
+	ev, found := setypes.ScriptEngine.ABI.EventByName(name)
+	if !found {
+		panic("event not found: " + name)
+	}
+	data, err := ev.Encode(args...)
+	if err != nil {
+		log.Error("could not encode data for "+name, "err", err)
+		return
+	}
+	env.AddEvent(StakingModuleAddr, []meter.Bytes32{ev.ID(), meter.BytesToBytes32(owner.Bytes()), bucketID}, data)
+}
+
 func (sb *StakingBody) UniteHash() (hash meter.Bytes32) {
 	//if cached := c.cache.signingHash.Load(); cached != nil {
 	//	return cached.(meter.Bytes32)
@@ -0,0 +1,224 @@
+// Copyright (c) 2020 The Meter.io developers
+
+// Distributed under the GNU Lesser General Public License v3.0 software license, see the accompanying
+// file LICENSE or <https://www.gnu.org/licenses/lgpl-3.0.html>
+
+package staking
+
+import (
+	"bytes"
+	"encoding/binary"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/meterio/meter-pov/meter"
+	"github.com/meterio/meter-pov/state"
+)
+
+// storage layout versions of buckets, candidates and stakeholders
+const (
+	legacyStorageVersion = uint64(0) // whole list RLP encoded under one storage key
+	keyedStorageVersion  = uint64(1) // one storage slot per entry
+)
+
+// keyedStore keeps the entries of a staking list in separate storage slots of the staking module account.
+// Entries are enumerable through an array like index: the count slot holds the number of entries,
+// index slot i holds the key of the i-th entry, and the position slot of a key holds i+1,
+// so removing an entry moves the last one into its place.
+type keyedStore struct {
+	prefix []byte
+	state  *state.State
+}
+
+func newKeyedStore(prefix string, state *state.State) *keyedStore {
+	return &keyedStore{prefix: []byte(prefix), state: state}
+}
+
+func newBucketStore(state *state.State) *keyedStore      { return newKeyedStore("bucket", state) }
+func newCandidateStore(state *state.State) *keyedStore   { return newKeyedStore("candidate", state) }
+func newStakeholderStore(state *state.State) *keyedStore { return newKeyedStore("stakeholder", state) }
+
+func (ks *keyedStore) slot(name string, key []byte) meter.Bytes32 {
+	return meter.Blake2b(ks.prefix, []byte(name), key)
+}
+
+func (ks *keyedStore) entrySlot(key meter.Bytes32) meter.Bytes32 {
+	return ks.slot("entry", key[:])
+}
+
+func (ks *keyedStore) positionSlot(key meter.Bytes32) meter.Bytes32 {
+	return ks.slot("position", key[:])
+}
+
+func (ks *keyedStore) indexSlot(i uint64) meter.Bytes32 {
+	var b [8]byte
+	binary.BigEndian.PutUint64(b[:], i)
+	return ks.slot("index", b[:])
+}
+
+func (ks *keyedStore) countSlot() meter.Bytes32 {
+	return ks.slot("count", nil)
+}
+
+// decode decodes the raw value of slot into val, returns false if the slot is empty.
+func (ks *keyedStore) decode(slot meter.Bytes32, val interface{}) bool {
+	found := false
+	ks.state.DecodeStorage(StakingModuleAddr, slot, func(raw []byte) error {
+		if len(raw) == 0 {
+			return nil
+		}
+		found = true
+		return rlp.DecodeBytes(raw, val)
+	})
+	return found
+}
+
+// encode sets the slot to the RLP encoding of val, or clears it if val is nil.
+func (ks *keyedStore) encode(slot meter.Bytes32, val interface{}) {
+	if val == nil {
+		ks.state.SetRawStorage(StakingModuleAddr, slot, nil)
+		return
+	}
+	ks.state.EncodeStorage(StakingModuleAddr, slot, func() ([]byte, error) {
+		return rlp.EncodeToBytes(val)
+	})
+}
+
+func (ks *keyedStore) count() (n uint64) {
+	ks.decode(ks.countSlot(), &n)
+	return
+}
+
+func (ks *keyedStore) position(key meter.Bytes32) (pos uint64) {
+	ks.decode(ks.positionSlot(key), &pos)
+	return
+}
+
+func (ks *keyedStore) keyAt(i uint64) (key meter.Bytes32) {
+	ks.decode(ks.indexSlot(i), &key)
+	return
+}
+
+func (ks *keyedStore) setCount(n uint64) {
+	if n == 0 {
+		ks.encode(ks.countSlot(), nil)
+		return
+	}
+	ks.encode(ks.countSlot(), n)
+}
+
+// get decodes the entry of key into val, returns false if not found.
+func (ks *keyedStore) get(key meter.Bytes32, val interface{}) bool {
+	return ks.decode(ks.entrySlot(key), val)
+}
+
+// put saves the entry of key, the key is appended to the index if new.
+func (ks *keyedStore) put(key meter.Bytes32, val interface{}) {
+	if ks.position(key) == 0 {
+		n := ks.count()
+		ks.encode(ks.indexSlot(n), key)
+		ks.encode(ks.positionSlot(key), n+1)
+		ks.setCount(n + 1)
+	}
+	ks.encode(ks.entrySlot(key), val)
+}
+
+// remove deletes the entry of key, and moves the last key of the index into its position.
+func (ks *keyedStore) remove(key meter.Bytes32) {
+	pos := ks.position(key)
+	if pos == 0 {
+		return
+	}
+	last := ks.count()
+	if pos != last {
+		lastKey := ks.keyAt(last - 1)
+		ks.encode(ks.indexSlot(pos-1), lastKey)
+		ks.encode(ks.positionSlot(lastKey), pos)
+	}
+	ks.encode(ks.indexSlot(last-1), nil)
+	ks.encode(ks.positionSlot(key), nil)
+	ks.encode(ks.entrySlot(key), nil)
+	ks.setCount(last - 1)
+}
+
+// keys returns all keys in the index.
+func (ks *keyedStore) keys() []meter.Bytes32 {
+	n := ks.count()
+	keys := make([]meter.Bytes32, 0, n)
+	for i := uint64(0); i < n; i++ {
+		keys = append(keys, ks.keyAt(i))
+	}
+	return keys
+}
+
+// sortedKeys returns keys of the set in ascending order, so that removals touch storage deterministically.
+func sortedKeys(set map[meter.Bytes32]bool) []meter.Bytes32 {
+	keys := make([]meter.Bytes32, 0, len(set))
+	for k := range set {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return bytes.Compare(keys[i][:], keys[j][:]) < 0
+	})
+	return keys
+}
+
+func addressKey(addr meter.Address) meter.Bytes32 {
+	return meter.BytesToBytes32(addr.Bytes())
+}
+
+// storage version
+func getStorageVersion(state *state.State) (version uint64) {
+	state.DecodeStorage(StakingModuleAddr, StorageVersionKey, func(raw []byte) error {
+		if len(raw) == 0 {
+			return nil
+		}
+		return rlp.DecodeBytes(raw, &version)
+	})
+	return
+}
+
+func setStorageVersion(version uint64, state *state.State) {
+	state.EncodeStorage(StakingModuleAddr, StorageVersionKey, func() ([]byte, error) {
+		return rlp.EncodeToBytes(version)
+	})
+}
+
+// IsKeyedStorage returns whether buckets, candidates and stakeholders are saved in keyed storage.
+func IsKeyedStorage(state *state.State) bool {
+	return getStorageVersion(state) >= keyedStorageVersion
+}
+
+// MigrateToKeyedStorage moves buckets, candidates and stakeholders from the whole-list blobs into
+// keyed storage. It does nothing if already migrated.
+func (s *Staking) MigrateToKeyedStorage(state *state.State) {
+	if IsKeyedStorage(state) {
+		return
+	}
+
+	bucketList := s.GetBucketList(state)
+	candidateList := s.GetCandidateList(state)
+	stakeholderList := s.GetStakeHolderList(state)
+
+	// entries are sorted by key in the legacy lists, which keeps the index layout deterministic
+	buckets := newBucketStore(state)
+	for _, b := range bucketList.buckets {
+		buckets.put(b.BucketID, b)
+	}
+	candidates := newCandidateStore(state)
+	for _, c := range candidateList.candidates {
+		candidates.put(addressKey(c.Addr), c)
+	}
+	stakeholders := newStakeholderStore(state)
+	for _, h := range stakeholderList.holders {
+		stakeholders.put(addressKey(h.Holder), h)
+	}
+
+	state.SetRawStorage(StakingModuleAddr, BucketListKey, nil)
+	state.SetRawStorage(StakingModuleAddr, CandidateListKey, nil)
+	state.SetRawStorage(StakingModuleAddr, StakeHolderListKey, nil)
+	setStorageVersion(keyedStorageVersion, state)
+
+	log.Info("migrated staking storage to keyed layout", "buckets", len(bucketList.buckets),
+		"candidates", len(candidateList.candidates), "stakeholders", len(stakeholderList.holders))
+}
@@ -0,0 +1,102 @@
+// Copyright (c) 2020 The Meter.io developers
+
+// Distributed under the GNU Lesser General Public License v3.0 software license, see the accompanying
+// file LICENSE or <https://www.gnu.org/licenses/lgpl-3.0.html>
+
+package staking
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/meterio/meter-pov/lvldb"
+	"github.com/meterio/meter-pov/meter"
+	"github.com/meterio/meter-pov/state"
+	"github.com/meterio/meter-pov/xenv"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKeyedStorageMigration(t *testing.T) {
+	db, _ := lvldb.NewMem()
+	st, _ := state.New(meter.Bytes32{}, db)
+	s := &Staking{}
+
+	owner := meter.BytesToAddress([]byte("owner"))
+	cand := NewCandidate(meter.BytesToAddress([]byte("candidate")), []byte("name"), []byte("desc"), []byte("pubkey"), []byte("1.2.3.4"), 8670, 0, 1)
+	holder := NewStakeholder(owner)
+
+	bucketList := newBucketList(nil)
+	for i := 0; i < 5; i++ {
+		b := NewBucket(owner, cand.Addr, big.NewInt(int64(i+1)), meter.STPD, ONE_WEEK_LOCK, 0, 0, 1, uint64(i))
+		bucketList.Add(b)
+		cand.AddBucket(b)
+		holder.AddBucket(b)
+	}
+	s.SetBucketList(bucketList, st)
+	s.SetCandidateList(NewCandidateList([]*Candidate{cand}), st)
+	s.SetStakeHolderList(newStakeholderList([]*Stakeholder{holder}), st)
+	legacy := s.GetBucketList(st).ToList()
+
+	s.MigrateToKeyedStorage(st)
+	assert.True(t, IsKeyedStorage(st))
+	assert.Equal(t, 0, len(st.GetRawStorage(StakingModuleAddr, BucketListKey)))
+
+	keyed := s.GetBucketList(st)
+	assert.Equal(t, legacy, keyed.ToList())
+	assert.Equal(t, 1, s.GetCandidateList(st).Count())
+	assert.Equal(t, holder.TotalStake, s.GetStakeHolderList(st).Get(owner).TotalStake)
+
+	// update one bucket and remove another without loading the rest
+	bucketList = s.GetBucketList(st)
+	bucketList.Get(legacy[1].BucketID).Value = big.NewInt(100)
+	bucketList.Remove(legacy[3].BucketID)
+	assert.Equal(t, 1, len(bucketList.buckets))
+	s.SetBucketList(bucketList, st)
+
+	bucketList = s.GetBucketList(st)
+	assert.False(t, bucketList.Exist(legacy[3].BucketID))
+	assert.Equal(t, big.NewInt(100), bucketList.Get(legacy[1].BucketID).Value)
+	assert.Equal(t, 4, len(bucketList.ToList()))
+	assert.Equal(t, uint64(4), newBucketStore(st).count())
+}
+
+func TestKeyedStorageBoundUnbound(t *testing.T) {
+	db, _ := lvldb.NewMem()
+	st, _ := state.New(meter.Bytes32{}, db)
+	s := NewStaking(nil, nil)
+
+	amount := new(big.Int).Mul(big.NewInt(1000), big.NewInt(1e18))
+	holder := meter.BytesToAddress([]byte("holder"))
+	cand := NewCandidate(meter.BytesToAddress([]byte("candidate")), []byte("name"), []byte("desc"), []byte("pubkey"), []byte("1.2.3.4"), 8670, 0, 1)
+	self := NewBucket(cand.Addr, cand.Addr, new(big.Int).Mul(amount, big.NewInt(2)), meter.STPD, FOREVER_LOCK, 0, 0, 1, 0)
+	cand.AddBucket(self)
+	s.SetBucketList(newBucketList([]*Bucket{self}), st)
+	s.SetCandidateList(NewCandidateList([]*Candidate{cand}), st)
+	s.SetStakeHolderList(newStakeholderList(nil), st)
+	s.MigrateToKeyedStorage(st)
+	st.SetBalance(holder, amount)
+
+	env := NewStakingEnv(s, st, &xenv.BlockContext{}, &xenv.TransactionContext{Origin: holder}, &StakingModuleAddr)
+	_, err := (&StakingBody{HolderAddr: holder, CandAddr: cand.Addr, Amount: amount, Token: meter.STPD, Option: ONE_WEEK_LOCK, Timestamp: 1, Nonce: 1}).BoundHandler(env, meter.ClauseGas)
+	assert.Nil(t, err)
+	opt, rate, _ := GetBoundLockOption(ONE_WEEK_LOCK)
+	id := NewBucket(holder, cand.Addr, amount, meter.STPD, opt, rate, 0, 1, 1).BucketID
+
+	// the bucket is saved in its own slot, and the legacy blobs stay empty
+	assert.True(t, IsKeyedStorage(st))
+	assert.Equal(t, 0, len(st.GetRawStorage(StakingModuleAddr, BucketListKey)))
+	assert.Equal(t, 0, len(st.GetRawStorage(StakingModuleAddr, CandidateListKey)))
+	assert.Equal(t, 0, len(st.GetRawStorage(StakingModuleAddr, StakeHolderListKey)))
+	assert.Equal(t, uint64(2), newBucketStore(st).count())
+	assert.Equal(t, amount, s.GetBucketList(st).Get(id).Value)
+	assert.Equal(t, []meter.Bytes32{self.BucketID, id}, s.GetCandidateList(st).Get(cand.Addr).Buckets)
+	assert.Equal(t, amount, s.GetStakeHolderList(st).Get(holder).TotalStake)
+	assert.Equal(t, amount, st.GetBoundedBalance(holder))
+
+	_, err = (&StakingBody{HolderAddr: holder, StakingID: id, Amount: amount, Token: meter.STPD, Timestamp: 2}).UnBoundHandler(env, meter.ClauseGas)
+	assert.Nil(t, err)
+	b := s.GetBucketList(st).Get(id)
+	assert.True(t, b.Unbounded)
+	assert.Equal(t, 2+GetBoundLocktime(b.Option), b.MatureTime)
+	assert.Equal(t, 0, len(st.GetRawStorage(StakingModuleAddr, BucketListKey)))
+}
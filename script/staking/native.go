@@ -0,0 +1,258 @@
+// Copyright (c) 2020 The Meter.io developers
+
+// Distributed under the GNU Lesser General Public License v3.0 software license, see the accompanying
+// file LICENSE or <https://www.gnu.org/licenses/lgpl-3.0.html>
+
+package staking
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/meterio/meter-pov/builtin"
+	"github.com/meterio/meter-pov/meter"
+	"github.com/meterio/meter-pov/xenv"
+)
+
+// nonceKey is the storage key of the staking contract for the nonce of buckets created by
+// the contract, which is the counterpart of the nonce in staking scripts.
+var nonceKey = meter.BytesToBytes32([]byte("nonce"))
+
+// natives of the staking contract, which run the same handlers as staking scripts with the
+// caller as the holder. Handler errors revert the call with the error as the reason.
+func init() {
+	defines := []struct {
+		name string
+		run  func(env *xenv.Environment) []interface{}
+	}{
+		{"bound", func(env *xenv.Environment) []interface{} {
+			var args struct {
+				Candidate common.Address
+				Amount    *big.Int
+				Token     uint8
+				Option    uint32
+				Autobid   uint8
+			}
+			env.ParseArgsOrFail(&args)
+			// the script handler skips the bound silently
+			if args.Autobid > 100 {
+				env.Revert("autobid > 100 %")
+			}
+
+			sb := &StakingBody{
+				Opcode:     OP_BOUND,
+				Option:     args.Option,
+				HolderAddr: env.Caller(),
+				CandAddr:   meter.Address(args.Candidate),
+				Amount:     args.Amount,
+				Token:      args.Token,
+				Autobid:    args.Autobid,
+				Timestamp:  env.BlockContext().Time,
+				Nonce:      nextNonce(env),
+			}
+			bucketID := (&Bucket{Owner: sb.HolderAddr, Nonce: sb.Nonce, CreateTime: sb.Timestamp}).ID()
+			runNative(env, sb, (*StakingBody).BoundHandler)
+
+			logNative(env, "BucketBound", sb.HolderAddr, bucketID, args.Candidate, args.Amount, args.Token)
+			return []interface{}{bucketID}
+		}},
+		{"unbound", func(env *xenv.Environment) []interface{} {
+			var bucketID common.Hash
+			env.ParseArgsOrFail(&bucketID)
+
+			b := mustGetBucket(env, meter.Bytes32(bucketID))
+			sb := &StakingBody{
+				Opcode:     OP_UNBOUND,
+				HolderAddr: env.Caller(),
+				StakingID:  b.BucketID,
+				Amount:     new(big.Int).Set(b.Value),
+				Token:      b.Token,
+				Timestamp:  env.BlockContext().Time,
+			}
+			runNative(env, sb, (*StakingBody).UnBoundHandler)
+
+			logNative(env, "BucketUnbound", sb.HolderAddr, b.BucketID, sb.Amount, sb.Token)
+			return nil
+		}},
+		{"delegate", func(env *xenv.Environment) []interface{} {
+			var args struct {
+				BucketID  common.Hash
+				Candidate common.Address
+				Autobid   uint8
+			}
+			env.ParseArgsOrFail(&args)
+			if args.Autobid > 100 {
+				env.Revert("autobid > 100 %")
+			}
+
+			b := mustGetBucket(env, meter.Bytes32(args.BucketID))
+			sb := &StakingBody{
+				Opcode:     OP_DELEGATE,
+				HolderAddr: env.Caller(),
+				CandAddr:   meter.Address(args.Candidate),
+				StakingID:  b.BucketID,
+				Amount:     new(big.Int).Set(b.Value),
+				Token:      b.Token,
+				Autobid:    args.Autobid,
+				Timestamp:  env.BlockContext().Time,
+			}
+			runNative(env, sb, (*StakingBody).DelegateHandler)
+
+			logNative(env, "BucketDelegated", sb.HolderAddr, b.BucketID, args.Candidate)
+			return nil
+		}},
+		{"undelegate", func(env *xenv.Environment) []interface{} {
+			var bucketID common.Hash
+			env.ParseArgsOrFail(&bucketID)
+
+			b := mustGetBucket(env, meter.Bytes32(bucketID))
+			candidate := b.Candidate
+			sb := &StakingBody{
+				Opcode:     OP_UNDELEGATE,
+				HolderAddr: env.Caller(),
+				StakingID:  b.BucketID,
+				Amount:     new(big.Int).Set(b.Value),
+				Token:      b.Token,
+				Timestamp:  env.BlockContext().Time,
+			}
+			runNative(env, sb, (*StakingBody).UnDelegateHandler)
+
+			logNative(env, "BucketUndelegated", sb.HolderAddr, b.BucketID, common.Address(candidate))
+			return nil
+		}},
+		{"bucketUpdate", func(env *xenv.Environment) []interface{} {
+			var args struct {
+				BucketID common.Hash
+				Option   uint32
+				Amount   *big.Int
+			}
+			env.ParseArgsOrFail(&args)
+
+			b := mustGetBucket(env, meter.Bytes32(args.BucketID))
+			sb := &StakingBody{
+				Opcode:     OP_BUCKET_UPDT,
+				Option:     args.Option,
+				HolderAddr: env.Caller(),
+				CandAddr:   b.Candidate,
+				StakingID:  b.BucketID,
+				Amount:     args.Amount,
+				Timestamp:  env.BlockContext().Time,
+			}
+			if args.Option == BUCKET_SUB_OPT {
+				// a new bucket is created for the amount subtracted
+				sb.Nonce = nextNonce(env)
+			}
+			runNative(env, sb, (*StakingBody).BucketUpdateHandler)
+
+			logNative(env, "BucketUpdated", sb.HolderAddr, b.BucketID, args.Option, args.Amount)
+			return nil
+		}},
+		{"bucketsOf", func(env *xenv.Environment) []interface{} {
+			var owner common.Address
+			env.ParseArgsOrFail(&owner)
+
+			env.UseGas(meter.SloadGas)
+			ids := make([][32]byte, 0)
+			if holder := mustGetStaking(env).GetStakeHolderList(env.State()).Get(meter.Address(owner)); holder != nil {
+				env.UseGas(meter.SloadGas * uint64(len(holder.Buckets)))
+				for _, id := range holder.Buckets {
+					ids = append(ids, id)
+				}
+			}
+			return []interface{}{ids}
+		}},
+		{"bucket", func(env *xenv.Environment) []interface{} {
+			var bucketID common.Hash
+			env.ParseArgsOrFail(&bucketID)
+
+			b := mustGetBucket(env, meter.Bytes32(bucketID))
+			return []interface{}{
+				common.Address(b.Owner),
+				common.Address(b.Candidate),
+				b.Value,
+				b.TotalVotes,
+				b.Token,
+				b.Option,
+				b.Unbounded,
+				b.MatureTime,
+				b.CreateTime,
+			}
+		}},
+		{"candidate", func(env *xenv.Environment) []interface{} {
+			var addr common.Address
+			env.ParseArgsOrFail(&addr)
+
+			env.UseGas(meter.SloadGas)
+			if c := mustGetStaking(env).GetCandidateList(env.State()).Get(meter.Address(addr)); c != nil {
+				return []interface{}{true, c.TotalVotes}
+			}
+			return []interface{}{false, new(big.Int)}
+		}},
+	}
+	for _, def := range defines {
+		builtin.Staking.RegisterNative(def.name, def.run)
+	}
+}
+
+func mustGetStaking(env *xenv.Environment) *Staking {
+	staking := GetStakingGlobInst()
+	if staking == nil {
+		env.Revert("staking is not initialized")
+	}
+	return staking
+}
+
+func mustGetBucket(env *xenv.Environment, id meter.Bytes32) *Bucket {
+	env.UseGas(meter.SloadGas)
+	b := mustGetStaking(env).GetBucketList(env.State()).Get(id)
+	if b == nil {
+		env.Revert(errBucketNotFound.Error())
+	}
+	return b
+}
+
+// nextNonce returns the nonce for the bucket to be created, and increases the nonce.
+func nextNonce(env *xenv.Environment) uint64 {
+	env.UseGas(meter.SloadGas + meter.SstoreResetGas)
+	v := env.State().GetStorage(builtin.Staking.Address, nonceKey)
+	nonce := new(big.Int).SetBytes(v[:]).Uint64()
+	env.State().SetStorage(builtin.Staking.Address, nonceKey, meter.BytesToBytes32(new(big.Int).SetUint64(nonce+1).Bytes()))
+	return nonce
+}
+
+// runNative runs the handler in the same way as the script engine, charges the gas used by the
+// handler, and passes the events and transfers of the handler to the evm.
+func runNative(env *xenv.Environment, sb *StakingBody, handler func(*StakingBody, *StakingEnv, uint64) (uint64, error)) {
+	staking := mustGetStaking(env)
+	gas := env.Gas()
+	if gas < meter.ClauseGas {
+		// handlers run with less than the clause gas, which is out of gas for the evm
+		env.UseGas(meter.ClauseGas)
+	}
+
+	if meter.IsStakingStorageFork(env.BlockContext().Number) {
+		staking.MigrateToKeyedStorage(env.State())
+	}
+	senv := NewStakingEnv(staking, env.State(), env.BlockContext(), env.TransactionContext(), &StakingModuleAddr)
+	log.Info("Entering staking native "+GetOpName(sb.Opcode), "tx", env.TransactionContext().ID.String(), "caller", env.Caller())
+	leftOverGas, err := handler(sb, senv, gas)
+	env.UseGas(gas - leftOverGas)
+	if err != nil {
+		env.Revert(err.Error())
+	}
+
+	for _, ev := range senv.GetEvents() {
+		env.RawLog(ev.Address, ev.Topics, ev.Data)
+	}
+	for _, tr := range senv.GetTransfers() {
+		env.Transfer(tr)
+	}
+}
+
+func logNative(env *xenv.Environment, name string, owner meter.Address, bucketID meter.Bytes32, args ...interface{}) {
+	ev, found := builtin.Staking.ABI.EventByName(name)
+	if !found {
+		panic("event not found: " + name)
+	}
+	env.Log(ev, builtin.Staking.Address, []meter.Bytes32{meter.BytesToBytes32(owner.Bytes()), bucketID}, args...)
+}
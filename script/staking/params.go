@@ -8,6 +8,7 @@ import (
 
 const (
 	MIN_CANDIDATE_UPDATE_INTV = uint64(3600 * 24) // 1 day
+	MIN_REDELEGATE_INTV       = uint64(3600 * 24) // 1 day
 	TESLA1_0_SELF_VOTE_RATIO  = 10                // max candidate total votes / self votes ratio < 10x in Tesla 1.0
 	TESLA1_1_SELF_VOTE_RATIO  = 100               // max candidate total votes / self votes ratio < 100x in Tesla 1.1
 
@@ -36,6 +37,8 @@ var (
 	StatisticsEpochKey     = meter.Blake2b([]byte("delegate-statistics-epoch-key"))
 	InJailListKey          = meter.Blake2b([]byte("delegate-injail-list-key"))
 	ValidatorRewardListKey = meter.Blake2b([]byte("validator-reward-list-key"))
+	StorageVersionKey      = meter.Blake2b([]byte("staking-storage-version-key"))
+	RedelegateTimeKey      = meter.Blake2b([]byte("bucket-redelegate-time-key"))
 )
 
 const (
@@ -48,6 +51,10 @@ const (
 	OP_CANDIDATE_UPDT = uint32(7)
 	OP_BUCKET_UPDT    = uint32(8)
 
+	OP_BUCKET_REDELEGATE = uint32(9)
+	OP_BUCKET_SPLIT      = uint32(10)
+	OP_BUCKET_MERGE      = uint32(11)
+
 	OP_DELEGATE_STATISTICS  = uint32(101)
 	OP_DELEGATE_EXITJAIL    = uint32(102)
 	OP_FLUSH_ALL_STATISTICS = uint32(103)
@@ -73,6 +80,12 @@ func GetOpName(op uint32) string {
 		return "CandidateUpdate"
 	case OP_BUCKET_UPDT:
 		return "BucketUpdate"
+	case OP_BUCKET_REDELEGATE:
+		return "BucketRedelegate"
+	case OP_BUCKET_SPLIT:
+		return "BucketSplit"
+	case OP_BUCKET_MERGE:
+		return "BucketMerge"
 	case OP_DELEGATE_STATISTICS:
 		return "DelegateStatistics"
 	case OP_DELEGATE_EXITJAIL:
@@ -59,6 +59,12 @@ func (s *Stakeholder) RemoveBucket(bucket *Bucket) {
 
 type StakeholderList struct {
 	holders []*Stakeholder
+
+	// store is set if the list is backed by keyed storage, holders then
+	// only holds the entries loaded so far, sorted by address as well.
+	store   *keyedStore
+	removed map[meter.Bytes32]bool
+	loaded  bool
 }
 
 func newStakeholderList(holders []*Stakeholder) *StakeholderList {
@@ -68,6 +74,14 @@ func newStakeholderList(holders []*Stakeholder) *StakeholderList {
 	return &StakeholderList{holders: holders}
 }
 
+func newKeyedStakeholderList(store *keyedStore) *StakeholderList {
+	return &StakeholderList{
+		holders: make([]*Stakeholder, 0),
+		store:   store,
+		removed: make(map[meter.Bytes32]bool),
+	}
+}
+
 func (sl *StakeholderList) indexOf(addr meter.Address) (int, int) {
 	// return values:
 	//     first parameter: if found, the index of the item
@@ -91,20 +105,47 @@ func (sl *StakeholderList) indexOf(addr meter.Address) (int, int) {
 	return -1, r
 }
 
+// load reads the stakeholder from keyed storage into the list.
+func (l *StakeholderList) load(addr meter.Address) *Stakeholder {
+	key := addressKey(addr)
+	if l.store == nil || l.removed[key] {
+		return nil
+	}
+	s := &Stakeholder{}
+	if !l.store.get(key, s) {
+		return nil
+	}
+	l.insert(s)
+	return s
+}
+
+// all returns every stakeholder in the list, loading the missing ones from keyed storage.
+func (l *StakeholderList) all() []*Stakeholder {
+	if l.store != nil && !l.loaded {
+		for _, key := range l.store.keys() {
+			addr := meter.BytesToAddress(key.Bytes())
+			if index, _ := l.indexOf(addr); index < 0 {
+				l.load(addr)
+			}
+		}
+		l.loaded = true
+	}
+	return l.holders
+}
+
 func (l *StakeholderList) Get(addr meter.Address) *Stakeholder {
 	index, _ := l.indexOf(addr)
 	if index >= 0 {
 		return l.holders[index]
 	}
-	return nil
+	return l.load(addr)
 }
 
 func (l *StakeholderList) Exist(addr meter.Address) bool {
-	index, _ := l.indexOf(addr)
-	return index >= 0
+	return l.Get(addr) != nil
 }
 
-func (l *StakeholderList) Add(s *Stakeholder) {
+func (l *StakeholderList) insert(s *Stakeholder) {
 	index, insertIndex := l.indexOf(s.Holder)
 	if index < 0 {
 		if len(l.holders) == 0 {
@@ -123,16 +164,27 @@ func (l *StakeholderList) Add(s *Stakeholder) {
 	return
 }
 
+func (l *StakeholderList) Add(s *Stakeholder) {
+	l.insert(s)
+	if l.store != nil {
+		delete(l.removed, addressKey(s.Holder))
+	}
+	return
+}
+
 func (l *StakeholderList) Remove(addr meter.Address) {
 	index, _ := l.indexOf(addr)
 	if index >= 0 {
 		l.holders = append(l.holders[:index], l.holders[index+1:]...)
 	}
+	if l.store != nil {
+		l.removed[addressKey(addr)] = true
+	}
 	return
 }
 
 func (l *StakeholderList) ToString() string {
-	if l == nil || len(l.holders) == 0 {
+	if l == nil || len(l.all()) == 0 {
 		return "StakeholderList (size:0)"
 	}
 	s := []string{fmt.Sprintf("StakeholderList (size:%v) {", len(l.holders))}
@@ -145,7 +197,7 @@ func (l *StakeholderList) ToString() string {
 
 func (l *StakeholderList) ToList() []Stakeholder {
 	result := make([]Stakeholder, 0)
-	for _, v := range l.holders {
+	for _, v := range l.all() {
 		result = append(result, *v)
 	}
 	return result
@@ -79,9 +79,9 @@ func (s *Staking) Start() error {
 	return nil
 }
 
-func (s *Staking) PrepareStakingHandler() (StakingHandler func([]byte, *meter.Address, *xenv.TransactionContext, uint64, *state.State) (*setypes.ScriptEngineOutput, uint64, error)) {
+func (s *Staking) PrepareStakingHandler() (StakingHandler func([]byte, *meter.Address, *xenv.BlockContext, *xenv.TransactionContext, uint64, *state.State) (*setypes.ScriptEngineOutput, uint64, error)) {
 
-	StakingHandler = func(data []byte, to *meter.Address, txCtx *xenv.TransactionContext, gas uint64, state *state.State) (seOutput *setypes.ScriptEngineOutput, leftOverGas uint64, err error) {
+	StakingHandler = func(data []byte, to *meter.Address, blockCtx *xenv.BlockContext, txCtx *xenv.TransactionContext, gas uint64, state *state.State) (seOutput *setypes.ScriptEngineOutput, leftOverGas uint64, err error) {
 
 		sb, err := StakingDecodeFromBytes(data)
 		if err != nil {
@@ -89,7 +89,7 @@ func (s *Staking) PrepareStakingHandler() (StakingHandler func([]byte, *meter.Ad
 			return nil, gas, err
 		}
 
-		senv := NewStakingEnv(s, state, txCtx, to)
+		senv := NewStakingEnv(s, state, blockCtx, txCtx, to)
 		if senv == nil {
 			panic("create staking enviroment failed")
 		}
@@ -103,6 +103,11 @@ func (s *Staking) PrepareStakingHandler() (StakingHandler func([]byte, *meter.Ad
 		}
 		*/
 
+		// move buckets, candidates and stakeholders to keyed storage once the fork is reached
+		if meter.IsStakingStorageFork(blockCtx.Number) {
+			s.MigrateToKeyedStorage(state)
+		}
+
 		log.Info("Entering staking handler "+GetOpName(sb.Opcode), "tx", txCtx.ID.String())
 		switch sb.Opcode {
 		case OP_BOUND:
@@ -162,6 +167,22 @@ func (s *Staking) PrepareStakingHandler() (StakingHandler func([]byte, *meter.Ad
 			}
 			leftOverGas, err = sb.BucketUpdateHandler(senv, gas)
 
+		case OP_BUCKET_REDELEGATE, OP_BUCKET_SPLIT, OP_BUCKET_MERGE:
+			if !meter.IsBucketOpsFork(senv.GetBlockCtx().Number) {
+				return nil, gas, errors.New("unknow staking opcode")
+			}
+			if senv.GetTxCtx().Origin != sb.HolderAddr {
+				return nil, gas, errors.New("holder address is not the same from transaction")
+			}
+			switch sb.Opcode {
+			case OP_BUCKET_REDELEGATE:
+				leftOverGas, err = sb.BucketRedelegateHandler(senv, gas)
+			case OP_BUCKET_SPLIT:
+				leftOverGas, err = sb.BucketSplitHandler(senv, gas)
+			case OP_BUCKET_MERGE:
+				leftOverGas, err = sb.BucketMergeHandler(senv, gas)
+			}
+
 		case OP_DELEGATE_STATISTICS:
 			if senv.GetTxCtx().Origin.IsZero() == false {
 				return nil, gas, errors.New("not from kblock")
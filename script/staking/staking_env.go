@@ -18,10 +18,10 @@ type StakingEnv struct {
 	staking *Staking
 }
 
-func NewStakingEnv(staking *Staking, state *state.State, txCtx *xenv.TransactionContext, to *meter.Address) *StakingEnv {
+func NewStakingEnv(staking *Staking, state *state.State, blockCtx *xenv.BlockContext, txCtx *xenv.TransactionContext, to *meter.Address) *StakingEnv {
 	return &StakingEnv{
 		staking:   staking,
-		ScriptEnv: setypes.NewScriptEnv(state, txCtx, to),
+		ScriptEnv: setypes.NewScriptEnv(state, blockCtx, txCtx, to),
 	}
 }
 
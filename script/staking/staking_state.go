@@ -19,6 +19,10 @@ import (
 
 // Candidate List
 func (s *Staking) GetCandidateList(state *state.State) (result *CandidateList) {
+	if IsKeyedStorage(state) {
+		return newKeyedCandidateList(newCandidateStore(state))
+	}
+
 	state.DecodeStorage(StakingModuleAddr, CandidateListKey, func(raw []byte) error {
 		candidates := make([]*Candidate, 0)
 
@@ -51,6 +55,17 @@ func (s *Staking) SetCandidateList(candList *CandidateList, state *state.State)
 }
 
 func SetCandidateList(candList *CandidateList, state *state.State) {
+	if candList.store != nil {
+		store := newCandidateStore(state)
+		for _, key := range sortedKeys(candList.removed) {
+			store.remove(key)
+		}
+		for _, c := range candList.candidates {
+			store.put(addressKey(c.Addr), c)
+		}
+		return
+	}
+
 	state.EncodeStorage(StakingModuleAddr, CandidateListKey, func() ([]byte, error) {
 		return rlp.EncodeToBytes(candList.candidates)
 	})
@@ -58,6 +73,10 @@ func SetCandidateList(candList *CandidateList, state *state.State) {
 
 // StakeHolder List
 func (s *Staking) GetStakeHolderList(state *state.State) (result *StakeholderList) {
+	if IsKeyedStorage(state) {
+		return newKeyedStakeholderList(newStakeholderStore(state))
+	}
+
 	state.DecodeStorage(StakingModuleAddr, StakeHolderListKey, func(raw []byte) error {
 		stakeholders := make([]*Stakeholder, 0)
 
@@ -90,6 +109,17 @@ func (s *Staking) SetStakeHolderList(holderList *StakeholderList, state *state.S
 }
 
 func SetStakeHolderList(holderList *StakeholderList, state *state.State) {
+	if holderList.store != nil {
+		store := newStakeholderStore(state)
+		for _, key := range sortedKeys(holderList.removed) {
+			store.remove(key)
+		}
+		for _, h := range holderList.holders {
+			store.put(addressKey(h.Holder), h)
+		}
+		return
+	}
+
 	state.EncodeStorage(StakingModuleAddr, StakeHolderListKey, func() ([]byte, error) {
 		return rlp.EncodeToBytes(holderList.holders)
 	})
@@ -97,6 +127,10 @@ func SetStakeHolderList(holderList *StakeholderList, state *state.State) {
 
 // Bucket List
 func (s *Staking) GetBucketList(state *state.State) (result *BucketList) {
+	if IsKeyedStorage(state) {
+		return newKeyedBucketList(newBucketStore(state))
+	}
+
 	state.DecodeStorage(StakingModuleAddr, BucketListKey, func(raw []byte) error {
 		buckets := make([]*Bucket, 0)
 
@@ -129,6 +163,18 @@ func (s *Staking) SetBucketList(bucketList *BucketList, state *state.State) {
 }
 
 func SetBucketList(bucketList *BucketList, state *state.State) {
+	if bucketList.store != nil {
+		// only the buckets loaded or added are written back
+		store := newBucketStore(state)
+		for _, id := range sortedKeys(bucketList.removed) {
+			store.remove(id)
+		}
+		for _, b := range bucketList.buckets {
+			store.put(b.BucketID, b)
+		}
+		return
+	}
+
 	state.EncodeStorage(StakingModuleAddr, BucketListKey, func() ([]byte, error) {
 		return rlp.EncodeToBytes(bucketList.buckets)
 	})
@@ -171,7 +217,13 @@ func (s *Staking) SetDelegateList(delegateList *DelegateList, state *state.State
 
 //====
 // Statistics List, unlike others, save/get list
-func (s *Staking) GetStatisticsList(state *state.State) (result *StatisticsList) {
+func (s *Staking) GetStatisticsList(state *state.State) *StatisticsList {
+	return GetStatisticsListByState(state)
+}
+
+// GetStatisticsListByState returns the delegate statistics in the state, it works without the
+// staking module started.
+func GetStatisticsListByState(state *state.State) (result *StatisticsList) {
 	state.DecodeStorage(StakingModuleAddr, StatisticsListKey, func(raw []byte) error {
 		stats := make([]*DelegateStatistics, 0)
 
@@ -239,7 +291,13 @@ func (s *Staking) SetStatisticsEpoch(phaseOutEpoch uint32, state *state.State) {
 }
 
 // inJail List
-func (s *Staking) GetInJailList(state *state.State) (result *DelegateInJailList) {
+func (s *Staking) GetInJailList(state *state.State) *DelegateInJailList {
+	return GetInJailListByState(state)
+}
+
+// GetInJailListByState returns the delegates in jail in the state, it works without the
+// staking module started.
+func GetInJailListByState(state *state.State) (result *DelegateInJailList) {
 	state.DecodeStorage(StakingModuleAddr, InJailListKey, func(raw []byte) error {
 		inJails := make([]*DelegateJailed, 0)
 
@@ -307,6 +365,16 @@ func (s *Staking) SetValidatorRewardList(list *ValidatorRewardList, state *state
 	})
 }
 
+// Redelegate time, the last time the bucket is redelegated, 0 if never
+func (s *Staking) GetRedelegateTime(bucketID meter.Bytes32, state *state.State) uint64 {
+	v := state.GetStorage(StakingModuleAddr, meter.Blake2b(RedelegateTimeKey[:], bucketID[:]))
+	return new(big.Int).SetBytes(v[:]).Uint64()
+}
+
+func (s *Staking) SetRedelegateTime(bucketID meter.Bytes32, ts uint64, state *state.State) {
+	state.SetStorage(StakingModuleAddr, meter.Blake2b(RedelegateTimeKey[:], bucketID[:]), meter.BytesToBytes32(new(big.Int).SetUint64(ts).Bytes()))
+}
+
 //==================== bound/unbound account ===========================
 func (s *Staking) BoundAccountMeter(addr meter.Address, amount *big.Int, state *state.State, env *StakingEnv) error {
 	if amount.Sign() == 0 {
@@ -411,12 +479,12 @@ func (s *Staking) UnboundAccountMeterGov(addr meter.Address, amount *big.Int, st
 	meterGov := state.GetBalance(addr)
 	meterGovBounded := state.GetBoundedBalance(addr)
 
-	number := s.Chain().BestBlock().BlockHeader.Number()
+	number := env.GetBlockCtx().Number
 
 	// meterGovBounded should >= amount
 	if meterGovBounded.Cmp(amount) < 0 {
 		log.Error("not enough bounded meter-gov balance", "account", addr, "Bounded", meterGovBounded, "unbound amount", amount, "number", number)
-		if number + 1 == 3418000 {
+		if number == 3418000 {
 			meterGovBounded = amount
 		} else {
 			return errors.New("not enough bounded meter-gov balance")
@@ -69,7 +69,7 @@ func (fi bindataFileInfo) Sys() interface{} {
 	return nil
 }
 
-var _compiledScriptengineeventAbi = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\xd4\xcf\xb1\x0a\xc2\x40\x10\x04\xd0\x7f\x99\xfa\x2a\x41\x8b\x2b\xfd\x07\xab\x90\xe2\xe4\x56\x08\x9a\xd9\x70\xb7\xab\x86\xe0\xbf\x8b\x12\x8c\x45\xb0\xb7\x1c\x98\x37\x30\xcd\x84\x44\xe5\xd8\xab\x57\xc4\x53\xba\x54\x09\xe8\x38\xb8\x55\xc4\x66\x42\xc7\x2c\x77\xc9\x88\x56\x5c\x02\x98\x7a\x41\x84\xde\x28\x05\x01\x36\x0e\xaf\x98\x72\x2e\x52\x2b\x1e\xe1\x0b\xcc\x5b\xb3\x48\xbd\x3a\x6d\x21\xde\xd1\x36\xdb\xdd\x2f\x62\x7a\x16\xae\x88\xf6\xd3\xd8\xab\x33\x2f\x0d\xb9\x0a\xed\xbd\xf8\xbf\x97\x0e\x3c\xae\x9e\x6a\x9f\x01\x00\x00\xff\xff\x45\x51\x86\xa3\xa9\x01\x00\x00")
+var _compiledScriptengineeventAbi = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\xd4\x94\x31\x4b\x03\x41\x10\x85\xff\xcb\xd4\x57\x45\xb4\xb8\x32\xda\x58\xd8\x28\x56\x21\xc5\x9c\xf3\x12\x96\xdc\xce\x1c\xbb\xb3\xc6\x23\xf8\xdf\xc5\x10\xbc\x44\xe2\x29\x4a\x84\x94\x0b\xf3\xbd\xdd\xf9\x58\xde\x6c\x43\xac\xa6\x7d\xb4\x92\xa9\x5e\x70\x9b\x51\x51\xd0\xae\x78\xa6\x7a\xb6\xa1\xa0\x82\x17\x08\xd5\x9e\x0a\x2a\x52\x8e\xa0\x9a\x6c\xad\x48\x54\x91\xf7\xdd\xfb\x91\x45\x12\x72\xa6\xd7\x6a\x0f\xd8\x65\xed\x08\x8e\x56\xd4\x07\xa4\x04\xf5\xc9\xe5\xd5\x18\xe2\xb6\x82\x1e\x21\xe6\x1f\x13\x53\x2b\x2a\xc3\x04\x9e\xa1\xbe\x4d\x3c\xd1\x4a\x07\x40\x53\x9e\x56\xf0\xdb\x9b\x81\x69\x7a\x47\xbe\x98\x8c\xed\xb4\x48\x16\xa7\xbf\x01\xbf\xf4\xb7\x67\x63\x9b\x7b\x87\xb4\xc4\xf9\x49\xb9\x66\x95\x20\xec\xf8\xe6\xb6\x43\xd2\x6d\x8c\xfb\xac\xe6\x1e\x82\x16\x4b\xf6\x33\xf3\xa3\x58\x9f\xf6\xcf\x3c\x74\x6d\xf0\x7f\x54\xf2\xc3\x67\xfe\xa5\x1a\x1e\xb5\x39\x5a\x0e\xf3\xb7\x01\x00\x8c\xa1\x34\xc6\xf1\x04\x00\x00")
 
 func compiledScriptengineeventAbiBytes() ([]byte, error) {
 	return bindataRead(
@@ -7,5 +7,8 @@ pragma solidity 0.4.24;
 
 contract ScriptEngineEvent {
     event Bound(address indexed owner, uint256 amount, uint256 token);
+    event BucketMerged(address indexed owner, bytes32 indexed bucketID, bytes32 fromBucketID, uint256 amount);
+    event BucketRedelegated(address indexed owner, bytes32 indexed bucketID, address fromCandidate, address toCandidate);
+    event BucketSplit(address indexed owner, bytes32 indexed bucketID, bytes32 newBucketID, uint256 amount);
     event Unbound(address indexed owner, uint256 amount, uint256 token);
 }
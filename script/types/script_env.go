@@ -16,18 +16,20 @@ import (
 
 //
 type ScriptEnv struct {
-	state  *state.State
-	txCtx  *xenv.TransactionContext
-	toAddr *meter.Address
+	state    *state.State
+	blockCtx *xenv.BlockContext
+	txCtx    *xenv.TransactionContext
+	toAddr   *meter.Address
 
 	returnData []byte
 	transfers  []*tx.Transfer
 	events     []*tx.Event
 }
 
-func NewScriptEnv(state *state.State, txCtx *xenv.TransactionContext, to *meter.Address) *ScriptEnv {
+func NewScriptEnv(state *state.State, blockCtx *xenv.BlockContext, txCtx *xenv.TransactionContext, to *meter.Address) *ScriptEnv {
 	return &ScriptEnv{
 		state:      state,
+		blockCtx:   blockCtx,
 		txCtx:      txCtx,
 		toAddr:     to,
 		returnData: make([]byte, 0),
@@ -37,6 +39,7 @@ func NewScriptEnv(state *state.State, txCtx *xenv.TransactionContext, to *meter.
 }
 
 func (env *ScriptEnv) GetState() *state.State             { return env.state }
+func (env *ScriptEnv) GetBlockCtx() *xenv.BlockContext    { return env.blockCtx }
 func (env *ScriptEnv) GetTxCtx() *xenv.TransactionContext { return env.txCtx }
 func (env *ScriptEnv) GetToAddr() *meter.Address          { return env.toAddr }
 
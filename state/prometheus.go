@@ -0,0 +1,17 @@
+// Copyright (c) 2020 The Meter.io developers
+
+// Distributed under the GNU Lesser General Public License v3.0 software license, see the accompanying
+// file LICENSE or <https://www.gnu.org/licenses/lgpl-3.0.html>
+
+package state
+
+import "github.com/prometheus/client_golang/prometheus"
+
+var trieCacheCounter = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Name: "state_trie_cache_total",
+	Help: "Counter of trie cache lookups by result (hit, miss)",
+}, []string{"result"})
+
+func init() {
+	prometheus.Register(trieCacheCounter)
+}
@@ -519,6 +519,42 @@ func (s *State) BuildStorageTrie(addr meter.Address) (*trie.SecureTrie, error) {
 	return trie, nil
 }
 
+// ProveAccount returns the account and its merkle proof against the root the state was
+// created on, changes made to the state are not included.
+func (s *State) ProveAccount(addr meter.Address) (*Account, [][]byte, error) {
+	acc, err := loadAccount(s.trie, addr)
+	if err != nil {
+		return nil, nil, err
+	}
+	accountTrie, err := trCache.Get(s.root, s.kv, true)
+	if err != nil {
+		return nil, nil, err
+	}
+	var proof trie.ProofList
+	if err := accountTrie.Prove(addr[:], 0, &proof); err != nil {
+		return nil, nil, err
+	}
+	return acc, proof, nil
+}
+
+// ProveStorage returns the merkle proof of the storage slot against the storage root of
+// the account, changes made to the state are not included.
+func (s *State) ProveStorage(addr meter.Address, key meter.Bytes32) ([][]byte, error) {
+	acc, err := loadAccount(s.trie, addr)
+	if err != nil {
+		return nil, err
+	}
+	storageTrie, err := trCache.Get(meter.BytesToBytes32(acc.StorageRoot), s.kv, true)
+	if err != nil {
+		return nil, err
+	}
+	var proof trie.ProofList
+	if err := storageTrie.Prove(key[:], 0, &proof); err != nil {
+		return nil, err
+	}
+	return proof, nil
+}
+
 // Stage makes a stage object to compute hash of trie or commit all changes.
 func (s *State) Stage() *Stage {
 	if s.err != nil {
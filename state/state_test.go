@@ -13,6 +13,7 @@ import (
 	"github.com/ethereum/go-ethereum/rlp"
 	"github.com/meterio/meter-pov/lvldb"
 	"github.com/meterio/meter-pov/meter"
+	"github.com/meterio/meter-pov/trie"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -151,3 +152,55 @@ func TestStorage(t *testing.T) {
 
 	assert.Equal(t, meter.Blake2b(data), st.GetStorage(addr, key))
 }
+
+func TestStateProof(t *testing.T) {
+	kv, _ := lvldb.NewMem()
+	state, _ := New(meter.Bytes32{}, kv)
+
+	addr := meter.BytesToAddress([]byte("account1"))
+	storageKey := meter.BytesToBytes32([]byte("storageKey"))
+	storageValue := meter.BytesToBytes32([]byte("storageValue"))
+	state.SetBalance(addr, big.NewInt(1))
+	state.SetStorage(addr, storageKey, storageValue)
+	for i := 0; i < 100; i++ {
+		state.SetEnergy(meter.BytesToAddress([]byte{byte(i)}), big.NewInt(int64(i+1)))
+	}
+	root, err := state.Stage().Commit()
+	assert.Nil(t, err)
+
+	state, _ = New(root, kv)
+	acc, proof, err := state.ProveAccount(addr)
+	assert.Nil(t, err)
+	assert.Equal(t, big.NewInt(1), acc.Balance)
+
+	value, err := trie.VerifySecureProof(root, addr[:], proof)
+	assert.Nil(t, err)
+	var proved Account
+	assert.Nil(t, rlp.DecodeBytes(value, &proved))
+	assert.Equal(t, acc, &proved)
+
+	proof, err = state.ProveStorage(addr, storageKey)
+	assert.Nil(t, err)
+	value, err = trie.VerifySecureProof(meter.BytesToBytes32(acc.StorageRoot), storageKey[:], proof)
+	assert.Nil(t, err)
+	assert.Equal(t, state.GetRawStorage(addr, storageKey), rlp.RawValue(value))
+
+	// absent account and storage
+	absent := meter.BytesToAddress([]byte("absent"))
+	_, proof, err = state.ProveAccount(absent)
+	assert.Nil(t, err)
+	value, err = trie.VerifySecureProof(root, absent[:], proof)
+	assert.Nil(t, err)
+	assert.Nil(t, value)
+
+	proof, err = state.ProveStorage(absent, storageKey)
+	assert.Nil(t, err)
+	value, err = trie.VerifySecureProof(meter.Bytes32{}, storageKey[:], proof)
+	assert.Nil(t, err)
+	assert.Nil(t, value)
+
+	// the proof without the root node
+	_, proof, _ = state.ProveAccount(addr)
+	_, err = trie.VerifySecureProof(root, addr[:], proof[1:])
+	assert.NotNil(t, err)
+}
@@ -37,12 +37,14 @@ func (tc *trieCache) Get(root meter.Bytes32, kv kv.GetPutter, copy bool) (*trie.
 	if v, ok := tc.cache.Get(root); ok {
 		entry := v.(*trieCacheEntry)
 		if entry.kv == kv {
+			trieCacheCounter.WithLabelValues("hit").Inc()
 			if copy {
 				return entry.trie.Copy(), nil
 			}
 			return entry.trie, nil
 		}
 	}
+	trieCacheCounter.WithLabelValues("miss").Inc()
 	tr, err := trie.NewSecure(root, kv, 16)
 	if err != nil {
 		return nil, err
@@ -0,0 +1,137 @@
+// Copyright (c) 2020 The Meter.io developers
+
+// Distributed under the GNU Lesser General Public License v3.0 software license, see the accompanying
+// file LICENSE or <https://www.gnu.org/licenses/lgpl-3.0.html>
+
+package tracedb
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/inconshreveable/log15"
+	"github.com/meterio/meter-pov/chain"
+	"github.com/meterio/meter-pov/co"
+	"github.com/pkg/errors"
+)
+
+var log = log15.New("pkg", "tracedb")
+
+// progress is logged every progressInterval blocks when catching up
+const progressInterval = 1000
+
+// Indexer traces trunk blocks into the trace db. It follows the best block of the chain in the
+// background, so that tracing never holds up committing blocks, and keeps the traced blocks
+// contiguous along the trunk, from the last traced block, or from the given block if nothing is
+// traced yet.
+type Indexer struct {
+	chain  *chain.Chain
+	tracer *BlockTracer
+	db     *TraceDB
+	from   uint32
+	lock   sync.Mutex
+	goes   co.Goes
+	done   chan struct{}
+}
+
+// NewIndexer creates an indexer.
+func NewIndexer(chain *chain.Chain, tracer *BlockTracer, db *TraceDB, from uint32) *Indexer {
+	return &Indexer{
+		chain:  chain,
+		tracer: tracer,
+		db:     db,
+		from:   from,
+		done:   make(chan struct{}),
+	}
+}
+
+// Start starts indexing in background.
+func (ix *Indexer) Start() {
+	ix.goes.Go(ix.loop)
+}
+
+// Stop stops indexing, and waits for the running block to finish.
+func (ix *Indexer) Stop() {
+	close(ix.done)
+	ix.goes.Wait()
+}
+
+func (ix *Indexer) loop() {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		<-ix.done
+		cancel()
+	}()
+
+	ticker := ix.chain.NewTicker()
+	for {
+		if err := ix.CatchUp(ctx); err != nil && ctx.Err() == nil {
+			log.Warn("failed to index traces", "err", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C():
+		case <-time.After(time.Minute):
+			// retry after failures even if no new block
+		}
+	}
+}
+
+// CatchUp traces trunk blocks up to the best block. Traced blocks no longer on the trunk, as
+// reverted by a fork, are traced again.
+func (ix *Indexer) CatchUp(ctx context.Context) error {
+	ix.lock.Lock()
+	defer ix.lock.Unlock()
+
+	first, last, ok, err := ix.db.Range()
+	if err != nil {
+		return err
+	}
+	from := ix.from
+	if ok {
+		from = last + 1
+		// rewind to the last traced block still on the trunk
+		for from > first {
+			id, err := ix.db.BlockID(from - 1)
+			if err != nil {
+				return err
+			}
+			trunkID, err := ix.chain.GetTrunkBlockID(from - 1)
+			if err != nil {
+				return errors.Wrapf(err, "get block id %v", from-1)
+			}
+			if id == trunkID {
+				break
+			}
+			from--
+		}
+	}
+	return ix.indexRange(ctx, from, ix.chain.BestBlock().Header().Number())
+}
+
+func (ix *Indexer) indexRange(ctx context.Context, from, to uint32) error {
+	start := time.Now()
+	for num := from; num <= to; num++ {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		blk, err := ix.chain.GetTrunkBlock(num)
+		if err != nil {
+			return errors.Wrapf(err, "get block %v", num)
+		}
+		traces, err := ix.tracer.TraceBlock(ctx, blk)
+		if err != nil {
+			return errors.Wrapf(err, "trace block %v", num)
+		}
+		if err := ix.db.Prepare(blk.Header()).Insert(traces...).Commit(); err != nil {
+			return errors.Wrapf(err, "save traces of block %v", num)
+		}
+		if (num-from+1)%progressInterval == 0 {
+			log.Info("indexing traces", "number", num, "to", to, "elapsed", time.Since(start))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,32 @@
+// Copyright (c) 2020 The Meter.io developers
+
+// Distributed under the GNU Lesser General Public License v3.0 software license, see the accompanying
+// file LICENSE or <https://www.gnu.org/licenses/lgpl-3.0.html>
+
+package tracedb
+
+const (
+	// create a table for traces, the trace is json encoded in data
+	traceTableSchema = `CREATE TABLE IF NOT EXISTS trace (
+	blockID BLOB(32),
+	traceIndex INTEGER,
+	blockNumber INTEGER,
+	blockTime INTEGER,
+	txID BLOB(32),
+	sender BLOB(20),
+	recipient BLOB(20),
+	data BLOB
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS prim ON trace(blockID, traceIndex);
+
+CREATE INDEX IF NOT EXISTS blockNumberIndex ON trace(blockNumber);
+CREATE INDEX IF NOT EXISTS senderIndex ON trace(sender);
+CREATE INDEX IF NOT EXISTS recipientIndex ON trace(recipient);`
+
+	// create a table for traced blocks, including those without traces
+	blockTableSchema = `CREATE TABLE IF NOT EXISTS block (
+	blockNumber INTEGER PRIMARY KEY,
+	blockID BLOB(32)
+);`
+)
@@ -0,0 +1,234 @@
+// Copyright (c) 2020 The Meter.io developers
+
+// Distributed under the GNU Lesser General Public License v3.0 software license, see the accompanying
+// file LICENSE or <https://www.gnu.org/licenses/lgpl-3.0.html>
+
+// Package tracedb stores flattened call traces of blocks, indexed by block number, sender and recipient.
+package tracedb
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
+
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/meterio/meter-pov/block"
+	"github.com/meterio/meter-pov/meter"
+)
+
+type TraceDB struct {
+	path string
+	db   *sql.DB
+}
+
+// New create or open trace db at given path.
+func New(path string) (traceDB *TraceDB, err error) {
+	db, err := sql.Open("sqlite3", path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if traceDB == nil {
+			if err := db.Close(); err != nil {
+				fmt.Println("could not close tracedb error:", err)
+			}
+		}
+	}()
+	if _, err := db.Exec(traceTableSchema + blockTableSchema); err != nil {
+		return nil, err
+	}
+	return &TraceDB{path, db}, nil
+}
+
+// NewMem create a trace db in ram.
+func NewMem() (*TraceDB, error) {
+	return New(":memory:")
+}
+
+// Close close the trace db.
+func (db *TraceDB) Close() {
+	if err := db.db.Close(); err != nil {
+		fmt.Println("could not close tracedb error:", err)
+	}
+}
+
+func (db *TraceDB) Path() string {
+	return db.path
+}
+
+// Range returns the range of traced blocks. ok is false if no block is traced.
+func (db *TraceDB) Range() (first uint32, last uint32, ok bool, err error) {
+	var min, max sql.NullInt64
+	if err := db.db.QueryRow("SELECT MIN(blockNumber), MAX(blockNumber) FROM block").Scan(&min, &max); err != nil {
+		return 0, 0, false, err
+	}
+	if !min.Valid {
+		return 0, 0, false, nil
+	}
+	return uint32(min.Int64), uint32(max.Int64), true, nil
+}
+
+// Covers returns whether all blocks in range [from, to] are traced.
+func (db *TraceDB) Covers(from, to uint32) (bool, error) {
+	var count uint64
+	if err := db.db.QueryRow("SELECT COUNT(*) FROM block WHERE blockNumber >= ? AND blockNumber <= ?", from, to).Scan(&count); err != nil {
+		return false, err
+	}
+	return count == uint64(to)-uint64(from)+1, nil
+}
+
+// BlockID returns the id of the traced block with the number, zero if the block is not traced.
+func (db *TraceDB) BlockID(num uint32) (meter.Bytes32, error) {
+	var id []byte
+	if err := db.db.QueryRow("SELECT blockID FROM block WHERE blockNumber = ?", num).Scan(&id); err != nil {
+		if err == sql.ErrNoRows {
+			return meter.Bytes32{}, nil
+		}
+		return meter.Bytes32{}, err
+	}
+	return meter.BytesToBytes32(id), nil
+}
+
+// FilterTraces returns traces matched by the filter, ordered by block number and index.
+func (db *TraceDB) FilterTraces(ctx context.Context, filter *TraceFilter) ([]*Trace, error) {
+	args := []interface{}{filter.From, filter.To}
+	stmt := "SELECT data FROM trace WHERE blockNumber >= ? AND blockNumber <= ? "
+	if len(filter.FromAddress) > 0 {
+		stmt += " AND sender IN (" + placeholders(len(filter.FromAddress)) + ") "
+		for _, addr := range filter.FromAddress {
+			args = append(args, addr.Bytes())
+		}
+	}
+	if len(filter.ToAddress) > 0 {
+		stmt += " AND recipient IN (" + placeholders(len(filter.ToAddress)) + ") "
+		for _, addr := range filter.ToAddress {
+			args = append(args, addr.Bytes())
+		}
+	}
+	stmt += " ORDER BY blockNumber ASC, traceIndex ASC "
+
+	limit := int64(-1)
+	if filter.Limit > 0 && filter.Limit <= math.MaxInt64 {
+		limit = int64(filter.Limit)
+	}
+	stmt += " limit ?, ? "
+	args = append(args, filter.Offset, limit)
+	return db.queryTraces(ctx, stmt, args...)
+}
+
+func (db *TraceDB) queryTraces(ctx context.Context, stmt string, args ...interface{}) ([]*Trace, error) {
+	rows, err := db.db.QueryContext(ctx, stmt, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	traces := make([]*Trace, 0)
+	for rows.Next() {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		default:
+		}
+		var data []byte
+		if err := rows.Scan(&data); err != nil {
+			return nil, err
+		}
+		var trace Trace
+		if err := json.Unmarshal(data, &trace); err != nil {
+			return nil, err
+		}
+		traces = append(traces, &trace)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return traces, nil
+}
+
+func placeholders(n int) string {
+	return strings.TrimSuffix(strings.Repeat("?,", n), ",")
+}
+
+func addressValue(addr *meter.Address) []byte {
+	if addr == nil {
+		return nil
+	}
+	return addr.Bytes()
+}
+
+func bytes32Value(b *meter.Bytes32) []byte {
+	if b == nil {
+		return nil
+	}
+	return b.Bytes()
+}
+
+// Prepare prepares a batch to save traces of the block.
+func (db *TraceDB) Prepare(header *block.Header) *BlockBatch {
+	return &BlockBatch{
+		db:     db.db,
+		header: header,
+	}
+}
+
+type BlockBatch struct {
+	db     *sql.DB
+	header *block.Header
+	traces []*Trace
+}
+
+// Insert adds traces to the batch.
+func (bb *BlockBatch) Insert(traces ...*Trace) *BlockBatch {
+	bb.traces = append(bb.traces, traces...)
+	return bb
+}
+
+// Commit saves the traces and marks the block as traced. Traces of blocks with the same or
+// higher number are removed, so that re-tracing after a fork leaves no stale traces.
+func (bb *BlockBatch) Commit() error {
+	tx, err := bb.db.Begin()
+	if err != nil {
+		return err
+	}
+	if err := bb.commit(tx); err != nil {
+		if e := tx.Rollback(); e != nil {
+			fmt.Println("could not rollback, error:", e)
+		}
+		return err
+	}
+	return tx.Commit()
+}
+
+func (bb *BlockBatch) commit(tx *sql.Tx) error {
+	num := bb.header.Number()
+	if _, err := tx.Exec("DELETE FROM trace WHERE blockNumber >= ?;", num); err != nil {
+		return err
+	}
+	if _, err := tx.Exec("DELETE FROM block WHERE blockNumber >= ?;", num); err != nil {
+		return err
+	}
+	for _, trace := range bb.traces {
+		data, err := json.Marshal(trace)
+		if err != nil {
+			return err
+		}
+		if _, err := tx.Exec("INSERT INTO trace(blockID, traceIndex, blockNumber, blockTime, txID, sender, recipient, data) VALUES (?, ?, ?, ?, ?, ?, ?, ?);",
+			trace.BlockID.Bytes(),
+			trace.Index,
+			trace.BlockNumber,
+			trace.BlockTime,
+			bytes32Value(trace.TxID),
+			addressValue(trace.From),
+			addressValue(trace.To),
+			data,
+		); err != nil {
+			return err
+		}
+	}
+	_, err := tx.Exec("INSERT INTO block(blockNumber, blockID) VALUES (?, ?);", num, bb.header.ID().Bytes())
+	return err
+}
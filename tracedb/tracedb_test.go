@@ -0,0 +1,133 @@
+// Copyright (c) 2020 The Meter.io developers
+
+// Distributed under the GNU Lesser General Public License v3.0 software license, see the accompanying
+// file LICENSE or <https://www.gnu.org/licenses/lgpl-3.0.html>
+
+package tracedb_test
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/meterio/meter-pov/block"
+	"github.com/meterio/meter-pov/meter"
+	"github.com/meterio/meter-pov/tracedb"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTraces(t *testing.T) {
+	db, err := tracedb.NewMem()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	_, _, ok, err := db.Range()
+	assert.Nil(t, err)
+	assert.False(t, ok)
+
+	from := meter.BytesToAddress([]byte("from"))
+	to := meter.BytesToAddress([]byte("to"))
+	other := meter.BytesToAddress([]byte("other"))
+	txID := meter.BytesToBytes32([]byte("txID"))
+
+	var headers []*block.Header
+	header := new(block.Builder).Build().Header()
+	for i := 0; i < 10; i++ {
+		headers = append(headers, header)
+		batch := db.Prepare(header)
+		// no traces for even blocks
+		if i%2 == 1 {
+			batch.Insert(&tracedb.Trace{
+				BlockID:      header.ID(),
+				Index:        0,
+				BlockNumber:  header.Number(),
+				TxID:         &txID,
+				TraceAddress: []uint32{},
+				Subtraces:    1,
+				Type:         tracedb.TypeCall,
+				CallType:     "call",
+				From:         &from,
+				To:           &to,
+				Value:        big.NewInt(int64(i)),
+			}, &tracedb.Trace{
+				BlockID:      header.ID(),
+				Index:        1,
+				BlockNumber:  header.Number(),
+				TxID:         &txID,
+				TraceAddress: []uint32{0},
+				Type:         tracedb.TypeCall,
+				CallType:     "call",
+				From:         &to,
+				To:           &other,
+				Value:        new(big.Int),
+			})
+		}
+		if err := batch.Commit(); err != nil {
+			t.Fatal(err)
+		}
+		header = new(block.Builder).ParentID(header.ID()).Build().Header()
+	}
+
+	first, last, ok, err := db.Range()
+	assert.Nil(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, headers[0].Number(), first)
+	assert.Equal(t, headers[9].Number(), last)
+
+	id, err := db.BlockID(headers[3].Number())
+	assert.Nil(t, err)
+	assert.Equal(t, headers[3].ID(), id)
+	id, err = db.BlockID(headers[9].Number() + 1)
+	assert.Nil(t, err)
+	assert.Equal(t, meter.Bytes32{}, id)
+
+	covered, err := db.Covers(first+1, last)
+	assert.Nil(t, err)
+	assert.True(t, covered)
+	covered, err = db.Covers(first, last+1)
+	assert.Nil(t, err)
+	assert.False(t, covered)
+
+	traces, err := db.FilterTraces(context.Background(), &tracedb.TraceFilter{From: first, To: last})
+	assert.Nil(t, err)
+	assert.Equal(t, 10, len(traces))
+	assert.Equal(t, headers[1].Number(), traces[0].BlockNumber)
+	assert.Equal(t, big.NewInt(1), traces[0].Value)
+	assert.Equal(t, []uint32{0}, traces[1].TraceAddress)
+
+	traces, err = db.FilterTraces(context.Background(), &tracedb.TraceFilter{From: first, To: last, FromAddress: []meter.Address{to}})
+	assert.Nil(t, err)
+	assert.Equal(t, 5, len(traces))
+	for _, trace := range traces {
+		assert.Equal(t, other, *trace.To)
+	}
+
+	traces, err = db.FilterTraces(context.Background(), &tracedb.TraceFilter{From: first, To: last, ToAddress: []meter.Address{to}, Offset: 1, Limit: 2})
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(traces))
+	assert.Equal(t, headers[3].Number(), traces[0].BlockNumber)
+	assert.Equal(t, headers[5].Number(), traces[1].BlockNumber)
+
+	// re-committing a block removes traces of it and all later blocks
+	if err := db.Prepare(headers[5]).Commit(); err != nil {
+		t.Fatal(err)
+	}
+	_, last, _, _ = db.Range()
+	assert.Equal(t, headers[5].Number(), last)
+	traces, err = db.FilterTraces(context.Background(), &tracedb.TraceFilter{From: first, To: headers[9].Number()})
+	assert.Nil(t, err)
+	assert.Equal(t, 4, len(traces))
+
+	// blocks missing in the middle are not covered
+	if err := db.Prepare(headers[7]).Commit(); err != nil {
+		t.Fatal(err)
+	}
+	covered, err = db.Covers(first, headers[7].Number())
+	assert.Nil(t, err)
+	assert.False(t, covered)
+	covered, err = db.Covers(first, headers[5].Number())
+	assert.Nil(t, err)
+	assert.True(t, covered)
+}
@@ -0,0 +1,266 @@
+// Copyright (c) 2020 The Meter.io developers
+
+// Distributed under the GNU Lesser General Public License v3.0 software license, see the accompanying
+// file LICENSE or <https://www.gnu.org/licenses/lgpl-3.0.html>
+
+package tracedb
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/meterio/meter-pov/block"
+	"github.com/meterio/meter-pov/builtin"
+	"github.com/meterio/meter-pov/chain"
+	"github.com/meterio/meter-pov/meter"
+	"github.com/meterio/meter-pov/runtime"
+	"github.com/meterio/meter-pov/state"
+	"github.com/meterio/meter-pov/tracers"
+	"github.com/meterio/meter-pov/tx"
+	"github.com/meterio/meter-pov/vm"
+	"github.com/meterio/meter-pov/xenv"
+)
+
+// callTrace is the result of the call tracer.
+type callTrace struct {
+	Type    string      `json:"type"`
+	From    string      `json:"from"`
+	To      string      `json:"to"`
+	Value   string      `json:"value"`
+	Gas     string      `json:"gas"`
+	GasUsed string      `json:"gasUsed"`
+	Input   string      `json:"input"`
+	Output  string      `json:"output"`
+	Error   string      `json:"error"`
+	Calls   []callTrace `json:"calls"`
+}
+
+// BlockTracer traces blocks by re-executing them with the call tracer.
+type BlockTracer struct {
+	chain  *chain.Chain
+	stateC *state.Creator
+}
+
+// NewBlockTracer creates a block tracer.
+func NewBlockTracer(chain *chain.Chain, stateC *state.Creator) *BlockTracer {
+	return &BlockTracer{chain, stateC}
+}
+
+func (bt *BlockTracer) newRuntimeOnBlock(header *block.Header) (*runtime.Runtime, error) {
+	signer, err := header.Signer()
+	if err != nil {
+		return nil, err
+	}
+	parentHeader, err := bt.chain.GetBlockHeader(header.ParentID())
+	if err != nil {
+		if !bt.chain.IsNotFound(err) {
+			return nil, err
+		}
+		return nil, errors.New("parent missing for " + header.String())
+	}
+	state, err := bt.stateC.NewState(parentHeader.StateRoot())
+	if err != nil {
+		return nil, err
+	}
+	epoch, err := bt.chain.GetBlockEpoch(header.ParentID())
+	if err != nil {
+		return nil, err
+	}
+
+	rt := runtime.New(
+		bt.chain.NewSeeker(header.ParentID()),
+		state,
+		&xenv.BlockContext{
+			Beneficiary: header.Beneficiary(),
+			Signer:      signer,
+			Number:      header.Number(),
+			Time:        header.Timestamp(),
+			GasLimit:    header.GasLimit(),
+			TotalScore:  header.TotalScore(),
+			Epoch:       epoch,
+		})
+	rt.ApplyForks()
+	return rt, nil
+}
+
+// isScriptEngineClause returns whether the clause is executed by the script engine instead of the vm.
+func isScriptEngineClause(rt *runtime.Runtime, clause *tx.Clause) bool {
+	return clause.Value().Sign() == 0 && len(clause.Data()) > runtime.MinScriptEngDataLen && rt.ScriptEngineCheck(clause.Data())
+}
+
+// TraceBlock re-executes transactions of the block with the native call tracer. It returns call traces
+// of all clauses in depth first order, followed by the transaction fee rewards of the block.
+func (bt *BlockTracer) TraceBlock(ctx context.Context, blk *block.Block) ([]*Trace, error) {
+	traces := make([]*Trace, 0)
+	// also skips the genesis block, which has no parent to run on
+	if len(blk.Transactions()) == 0 {
+		return traces, nil
+	}
+	header := blk.Header()
+	rt, err := bt.newRuntimeOnBlock(header)
+	if err != nil {
+		return nil, err
+	}
+	newTrace := func() *Trace {
+		trace := &Trace{
+			BlockID:     header.ID(),
+			Index:       uint32(len(traces)),
+			BlockNumber: header.Number(),
+			BlockTime:   header.Timestamp(),
+		}
+		traces = append(traces, trace)
+		return trace
+	}
+
+	var (
+		authors []meter.Address
+		rewards = make(map[meter.Address]*big.Int)
+	)
+	for i, tx := range blk.Transactions() {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		default:
+		}
+
+		txExec, err := rt.PrepareTransaction(tx)
+		if err != nil {
+			return nil, err
+		}
+		txID := tx.ID()
+		clauses := tx.Clauses()
+		for clauseIndex := 0; txExec.HasNextClause(); clauseIndex++ {
+			clause := clauses[clauseIndex]
+			if isScriptEngineClause(rt, clause) {
+				rt.SetVMConfig(vm.Config{})
+				if _, _, err := txExec.NextClause(); err != nil {
+					return nil, err
+				}
+				continue
+			}
+
+			tracer, _, err := tracers.NewNative("callTracer", nil)
+			if err != nil {
+				return nil, err
+			}
+			rt.SetVMConfig(vm.Config{Debug: true, Tracer: tracer})
+			_, output, err := txExec.NextClause()
+			if err != nil {
+				return nil, err
+			}
+			res, err := tracer.GetResult()
+			if err != nil {
+				// the clause is rejected before entering the vm, e.g. transfer from a locked account
+				if output.VMErr != nil {
+					continue
+				}
+				return nil, err
+			}
+			var call callTrace
+			if err := json.Unmarshal(res, &call); err != nil {
+				return nil, err
+			}
+			flatten(&call, []uint32{}, clause.Token(), func() *Trace {
+				trace := newTrace()
+				trace.TxID = &txID
+				trace.TxIndex = uint32(i)
+				trace.ClauseIndex = uint32(clauseIndex)
+				return trace
+			})
+		}
+		rt.SetVMConfig(vm.Config{})
+
+		receipt, err := txExec.Finalize()
+		if err != nil {
+			return nil, err
+		}
+		// mint transaction gas is not prepaid, so no reward
+		if origin, _ := tx.Signer(); origin.IsZero() || receipt.Reward.Sign() == 0 {
+			continue
+		}
+		author := builtin.Params.Native(rt.State()).GetAddress(meter.KeyTransactionFeeAddress)
+		if author.IsZero() {
+			author = header.Beneficiary()
+		}
+		if _, ok := rewards[author]; !ok {
+			authors = append(authors, author)
+			rewards[author] = new(big.Int)
+		}
+		rewards[author].Add(rewards[author], receipt.Reward)
+	}
+
+	for i := range authors {
+		trace := newTrace()
+		trace.TraceAddress = []uint32{}
+		trace.Type = TypeReward
+		trace.To = &authors[i]
+		trace.Value = rewards[authors[i]]
+	}
+	return traces, nil
+}
+
+// flatten converts the call trace tree into traces in depth first order.
+func flatten(call *callTrace, traceAddress []uint32, token byte, newTrace func() *Trace) {
+	trace := newTrace()
+	trace.TraceAddress = traceAddress
+	trace.Subtraces = uint32(len(call.Calls))
+	trace.From = parseAddress(call.From)
+	trace.To = parseAddress(call.To)
+	trace.Value = parseBig(call.Value)
+	trace.Token = token
+	trace.Gas = parseUint64(call.Gas)
+	trace.GasUsed = parseUint64(call.GasUsed)
+	trace.Input = parseBytes(call.Input)
+	trace.Output = parseBytes(call.Output)
+	trace.Error = call.Error
+
+	switch callType := strings.ToLower(call.Type); callType {
+	case "create", "create2":
+		trace.Type = TypeCreate
+		// no contract is created
+		if call.Error != "" {
+			trace.To = nil
+		}
+	case "selfdestruct":
+		trace.Type = TypeSuicide
+	default:
+		trace.Type = TypeCall
+		trace.CallType = callType
+	}
+
+	for i := range call.Calls {
+		// full slice expression, so that siblings never share the backing array
+		subAddress := append(traceAddress[:len(traceAddress):len(traceAddress)], uint32(i))
+		// value of internal calls is always MTR
+		flatten(&call.Calls[i], subAddress, meter.STPT, newTrace)
+	}
+}
+
+func parseAddress(s string) *meter.Address {
+	addr, err := meter.ParseAddress(s)
+	if err != nil {
+		return nil
+	}
+	return &addr
+}
+
+func parseBig(s string) *big.Int {
+	if v, err := hexutil.DecodeBig(s); err == nil {
+		return v
+	}
+	return new(big.Int)
+}
+
+func parseUint64(s string) uint64 {
+	v, _ := hexutil.DecodeUint64(s)
+	return v
+}
+
+func parseBytes(s string) []byte {
+	b, _ := hexutil.Decode(s)
+	return b
+}
@@ -0,0 +1,74 @@
+// Copyright (c) 2020 The Meter.io developers
+
+// Distributed under the GNU Lesser General Public License v3.0 software license, see the accompanying
+// file LICENSE or <https://www.gnu.org/licenses/lgpl-3.0.html>
+
+package tracedb
+
+import (
+	"math/big"
+
+	"github.com/meterio/meter-pov/meter"
+)
+
+// trace types, same as OpenEthereum
+const (
+	TypeCall    = "call"
+	TypeCreate  = "create"
+	TypeSuicide = "suicide"
+	TypeReward  = "reward"
+)
+
+// Trace a flattened call trace that can be stored in db.
+type Trace struct {
+	BlockID      meter.Bytes32
+	Index        uint32 // index of the trace in the block
+	BlockNumber  uint32
+	BlockTime    uint64
+	TxID         *meter.Bytes32 // nil for rewards
+	TxIndex      uint32
+	ClauseIndex  uint32
+	TraceAddress []uint32 // path of the call in the call tree of the clause
+	Subtraces    uint32
+	Type         string
+	CallType     string         // call, callcode, delegatecall or staticcall
+	From         *meter.Address // caller, the contract for suicides, nil for rewards
+	To           *meter.Address // callee, the created contract, the refund address or the reward author
+	Value        *big.Int
+	Token        byte // token of Value, internal calls always transfer MTR
+	Gas          uint64
+	GasUsed      uint64
+	Input        []byte // init code for creates
+	Output       []byte // contract code for creates
+	Error        string
+}
+
+// TraceFilter filters traces in the block range. An empty address list matches all.
+type TraceFilter struct {
+	From        uint32
+	To          uint32
+	FromAddress []meter.Address
+	ToAddress   []meter.Address
+	Offset      uint64
+	Limit       uint64 // no limit if zero
+}
+
+// Match returns whether the trace matches the addresses of the filter.
+func (f *TraceFilter) Match(trace *Trace) bool {
+	return matchAddress(f.FromAddress, trace.From) && matchAddress(f.ToAddress, trace.To)
+}
+
+func matchAddress(addrs []meter.Address, addr *meter.Address) bool {
+	if len(addrs) == 0 {
+		return true
+	}
+	if addr == nil {
+		return false
+	}
+	for _, a := range addrs {
+		if a == *addr {
+			return true
+		}
+	}
+	return false
+}
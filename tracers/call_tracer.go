@@ -0,0 +1,306 @@
+// Copyright (c) 2020 The Meter.io developers
+
+// Distributed under the GNU Lesser General Public License v3.0 software license, see the accompanying
+// file LICENSE or <https://www.gnu.org/licenses/lgpl-3.0.html>
+
+package tracers
+
+import (
+	"encoding/json"
+	"errors"
+	"math/big"
+	"sync/atomic"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/meterio/meter-pov/vm"
+)
+
+const errExecutionReverted = "execution reverted"
+
+// callFrame is a call reported by the call tracer, the field order follows call_tracer.js.
+type callFrame struct {
+	Type         string          `json:"type"`
+	From         common.Address  `json:"from"`
+	To           *common.Address `json:"to,omitempty"`
+	Value        *hexutil.Big    `json:"value,omitempty"`
+	Token        *hexutil.Uint64 `json:"token,omitempty"`
+	Gas          *hexutil.Uint64 `json:"gas,omitempty"`
+	GasUsed      *hexutil.Uint64 `json:"gasUsed,omitempty"`
+	Input        hexutil.Bytes   `json:"input"`
+	Output       hexutil.Bytes   `json:"output,omitempty"`
+	Error        string          `json:"error,omitempty"`
+	RevertReason string          `json:"revertReason,omitempty"`
+	Time         string          `json:"time,omitempty"`
+	Calls        []*callFrame    `json:"calls,omitempty"`
+
+	// fields to complete the call when it returns
+	gasIn, gasCost uint64
+	outOff, outLen *big.Int
+}
+
+func (f *callFrame) isCreate() bool {
+	return f.Type == vm.CREATE.String() || f.Type == vm.CREATE2.String()
+}
+
+// setRevertReason decodes the revert reason from the output of the reverted call.
+func (f *callFrame) setRevertReason() {
+	if f.Error != errExecutionReverted {
+		return
+	}
+	if reason, err := unpackRevert(f.Output); err == nil {
+		f.RevertReason = reason
+	}
+}
+
+// callTracer is the native implementation of call_tracer.js. It reports the internal calls
+// made by a clause, along with the token of the clause and the revert reasons.
+type callTracer struct {
+	callstack []*callFrame
+	descended bool // whether we've just descended from an outer call into an inner call
+	token     byte
+
+	// context of the clause
+	create  bool
+	from    common.Address
+	to      common.Address
+	input   []byte
+	gas     uint64
+	value   *big.Int
+	output  []byte
+	gasUsed uint64
+	time    time.Duration
+	err     error
+
+	interrupt uint32 // atomic flag to signal execution interruption
+	reason    error  // textual reason for the interruption
+}
+
+func newCallTracer(cfg json.RawMessage) (ResultTracer, error) {
+	return &callTracer{callstack: []*callFrame{{}}}, nil
+}
+
+// SetToken sets the token transferred by the clause.
+func (t *callTracer) SetToken(token byte) {
+	t.token = token
+}
+
+// Stop terminates execution of the tracer at the first opportune moment.
+func (t *callTracer) Stop(err error) {
+	t.reason = err
+	atomic.StoreUint32(&t.interrupt, 1)
+}
+
+func (t *callTracer) top() *callFrame {
+	return t.callstack[len(t.callstack)-1]
+}
+
+func (t *callTracer) pop() *callFrame {
+	call := t.top()
+	t.callstack = t.callstack[:len(t.callstack)-1]
+	return call
+}
+
+// CaptureStart implements the Tracer interface to initialize the tracing operation.
+func (t *callTracer) CaptureStart(from common.Address, to common.Address, create bool, input []byte, gas uint64, value *big.Int) error {
+	t.create = create
+	t.from = from
+	t.to = to
+	t.input = common.CopyBytes(input)
+	t.gas = gas
+	t.value = new(big.Int).Set(value)
+	return nil
+}
+
+// CaptureState implements the Tracer interface to trace a single step of VM execution.
+func (t *callTracer) CaptureState(env *vm.EVM, pc uint64, op vm.OpCode, gas, cost uint64, memory *vm.Memory, stack *vm.Stack, contract *vm.Contract, depth int, err error) error {
+	if atomic.LoadUint32(&t.interrupt) > 0 {
+		return nil
+	}
+	// capture any errors immediately
+	if err != nil {
+		return t.CaptureFault(env, pc, op, gas, cost, memory, stack, contract, depth, err)
+	}
+
+	switch op {
+	case vm.CREATE, vm.CREATE2:
+		// a new contract is being created, add to the call stack
+		t.callstack = append(t.callstack, &callFrame{
+			Type:    op.String(),
+			From:    contract.Address(),
+			Input:   memorySlice(memory, stack.Back(1), stack.Back(2)),
+			Value:   (*hexutil.Big)(new(big.Int).Set(stack.Back(0))),
+			gasIn:   gas,
+			gasCost: cost,
+		})
+		t.descended = true
+		return nil
+	case vm.SELFDESTRUCT:
+		// a contract is being self destructed, gather that as a subcall too
+		to := common.BigToAddress(stack.Back(0))
+		top := t.top()
+		top.Calls = append(top.Calls, &callFrame{
+			Type:  op.String(),
+			From:  contract.Address(),
+			To:    &to,
+			Value: (*hexutil.Big)(new(big.Int).Set(env.StateDB.GetEnergy(contract.Address()))),
+		})
+		return nil
+	case vm.CALL, vm.CALLCODE, vm.DELEGATECALL, vm.STATICCALL:
+		to := common.BigToAddress(stack.Back(1))
+		// skip any pre-compile invocations, those are just fancy opcodes
+		if isPrecompiled(to) {
+			return nil
+		}
+		off := 1
+		if op == vm.DELEGATECALL || op == vm.STATICCALL {
+			off = 0
+		}
+		call := &callFrame{
+			Type:    op.String(),
+			From:    contract.Address(),
+			To:      &to,
+			Input:   memorySlice(memory, stack.Back(2+off), stack.Back(3+off)),
+			gasIn:   gas,
+			gasCost: cost,
+			outOff:  new(big.Int).Set(stack.Back(4 + off)),
+			outLen:  new(big.Int).Set(stack.Back(5 + off)),
+		}
+		if off == 1 {
+			call.Value = (*hexutil.Big)(new(big.Int).Set(stack.Back(2)))
+		}
+		t.callstack = append(t.callstack, call)
+		t.descended = true
+		return nil
+	}
+
+	// just descended into an inner call, retrieve its true allowance, which may differ from
+	// the requested gas due to the 2300 stipend and the 63/64 rule. The allowance is unknown
+	// if the call was made to a plain account.
+	if t.descended {
+		if depth >= len(t.callstack) {
+			allowance := hexutil.Uint64(gas)
+			t.top().Gas = &allowance
+		}
+		t.descended = false
+	}
+	if op == vm.REVERT {
+		t.top().Error = errExecutionReverted
+		t.top().Output = memorySlice(memory, stack.Back(0), stack.Back(1))
+		return nil
+	}
+	if depth == len(t.callstack)-1 {
+		// an inner call is returning, pop off the call stack and get the execution results
+		call := t.pop()
+		ret := stack.Back(0)
+		if call.isCreate() {
+			gasUsed := hexutil.Uint64(call.gasIn - call.gasCost - gas)
+			call.GasUsed = &gasUsed
+			if ret.Sign() != 0 {
+				to := common.BigToAddress(ret)
+				call.To = &to
+				call.Output = common.CopyBytes(env.StateDB.GetCode(to))
+			} else if call.Error == "" {
+				call.Error = "internal failure"
+			}
+		} else {
+			if call.Gas != nil {
+				gasUsed := hexutil.Uint64(call.gasIn - call.gasCost + uint64(*call.Gas) - gas)
+				call.GasUsed = &gasUsed
+			}
+			if ret.Sign() != 0 {
+				call.Output = memorySlice(memory, call.outOff, call.outLen)
+			} else if call.Error == "" {
+				call.Error = "internal failure"
+			}
+		}
+		if call.Error != errExecutionReverted && call.Error != "" {
+			call.Output = nil
+		}
+		call.setRevertReason()
+		top := t.top()
+		top.Calls = append(top.Calls, call)
+	}
+	return nil
+}
+
+// CaptureFault implements the Tracer interface to trace an execution fault
+// while running an opcode.
+func (t *callTracer) CaptureFault(env *vm.EVM, pc uint64, op vm.OpCode, gas, cost uint64, memory *vm.Memory, stack *vm.Stack, contract *vm.Contract, depth int, err error) error {
+	// the topmost call already reverted, don't handle the additional fault again
+	if t.top().Error != "" {
+		return nil
+	}
+	// pop off the just failed call, which consumes all available gas
+	call := t.pop()
+	call.Error = err.Error()
+	call.Output = nil
+	if call.Gas != nil {
+		call.GasUsed = call.Gas
+	}
+
+	// flatten the failed call into its parent
+	if len(t.callstack) > 0 {
+		top := t.top()
+		top.Calls = append(top.Calls, call)
+		return nil
+	}
+	// last call failed too, leave it in the stack
+	t.callstack = append(t.callstack, call)
+	return nil
+}
+
+// CaptureEnd is called after the call finishes to finalize the tracing.
+func (t *callTracer) CaptureEnd(output []byte, gasUsed uint64, d time.Duration, err error) error {
+	t.output = common.CopyBytes(output)
+	t.gasUsed = gasUsed
+	t.time = d
+	t.err = err
+	return nil
+}
+
+// GetResult returns the call of the clause in json, with the internal calls nested.
+func (t *callTracer) GetResult() (json.RawMessage, error) {
+	// the clause is rejected before entering the vm
+	if t.value == nil {
+		return nil, errors.New("no call captured")
+	}
+	var (
+		to      = t.to
+		token   = hexutil.Uint64(t.token)
+		gas     = hexutil.Uint64(t.gas)
+		gasUsed = hexutil.Uint64(t.gasUsed)
+		root    = t.callstack[0]
+	)
+	result := &callFrame{
+		Type:    vm.CALL.String(),
+		From:    t.from,
+		To:      &to,
+		Value:   (*hexutil.Big)(t.value),
+		Token:   &token,
+		Gas:     &gas,
+		GasUsed: &gasUsed,
+		Input:   t.input,
+		Output:  t.output,
+		Error:   root.Error,
+		Time:    t.time.String(),
+		Calls:   root.Calls,
+	}
+	if t.create {
+		result.Type = vm.CREATE.String()
+	}
+	if result.Error == "" && t.err != nil {
+		result.Error = t.err.Error()
+	}
+	if result.Error != "" && (result.Error != errExecutionReverted || len(result.Output) == 0) {
+		result.Output = nil
+	}
+	result.setRevertReason()
+
+	res, err := json.Marshal(result)
+	if err != nil {
+		return nil, err
+	}
+	return res, t.reason
+}
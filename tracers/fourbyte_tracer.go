@@ -0,0 +1,105 @@
+// Copyright (c) 2020 The Meter.io developers
+
+// Distributed under the GNU Lesser General Public License v3.0 software license, see the accompanying
+// file LICENSE or <https://www.gnu.org/licenses/lgpl-3.0.html>
+
+package tracers
+
+import (
+	"encoding/json"
+	"math/big"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/meterio/meter-pov/vm"
+)
+
+// fourByteTracer is the native implementation of 4byte_tracer.js. It collects the method
+// identifiers of the calls along with the size of the supplied data, so a reversed signature
+// can be matched against the size of the data, e.g.
+//
+//	{
+//	  "0x27dc297e-128": 1,
+//	  "0x38cc4831-0": 2
+//	}
+type fourByteTracer struct {
+	ids map[string]int
+
+	interrupt uint32 // atomic flag to signal execution interruption
+	reason    error  // textual reason for the interruption
+}
+
+func newFourByteTracer(cfg json.RawMessage) (ResultTracer, error) {
+	return &fourByteTracer{ids: make(map[string]int)}, nil
+}
+
+// Stop terminates execution of the tracer at the first opportune moment.
+func (t *fourByteTracer) Stop(err error) {
+	t.reason = err
+	atomic.StoreUint32(&t.interrupt, 1)
+}
+
+// store saves the given identifier and data size.
+func (t *fourByteTracer) store(id []byte, size int) {
+	t.ids[hexutil.Encode(id)+"-"+strconv.Itoa(size)]++
+}
+
+// CaptureStart implements the Tracer interface to initialize the tracing operation.
+func (t *fourByteTracer) CaptureStart(from common.Address, to common.Address, create bool, input []byte, gas uint64, value *big.Int) error {
+	// save the outer calldata also
+	if len(input) >= 4 {
+		t.store(input[:4], len(input)-4)
+	}
+	return nil
+}
+
+// CaptureState implements the Tracer interface to trace a single step of VM execution.
+func (t *fourByteTracer) CaptureState(env *vm.EVM, pc uint64, op vm.OpCode, gas, cost uint64, memory *vm.Memory, stack *vm.Stack, contract *vm.Contract, depth int, err error) error {
+	if err != nil || atomic.LoadUint32(&t.interrupt) > 0 {
+		return nil
+	}
+	// stack index of the input offset, which follows the value for CALL and CALLCODE
+	var inIndex int
+	switch op {
+	case vm.CALL, vm.CALLCODE:
+		inIndex = 3
+	case vm.DELEGATECALL, vm.STATICCALL:
+		inIndex = 2
+	default:
+		return nil
+	}
+	// skip any pre-compile invocations, those are just fancy opcodes
+	if isPrecompiled(common.BigToAddress(stack.Back(1))) {
+		return nil
+	}
+	inOff, inSize := stack.Back(inIndex), stack.Back(inIndex+1)
+	if inSize.Cmp(big.NewInt(4)) >= 0 && inSize.IsInt64() {
+		if id := memorySlice(memory, inOff, big.NewInt(4)); id != nil {
+			t.store(id, int(inSize.Int64()-4))
+		}
+	}
+	return nil
+}
+
+// CaptureFault implements the Tracer interface to trace an execution fault
+// while running an opcode.
+func (t *fourByteTracer) CaptureFault(env *vm.EVM, pc uint64, op vm.OpCode, gas, cost uint64, memory *vm.Memory, stack *vm.Stack, contract *vm.Contract, depth int, err error) error {
+	return nil
+}
+
+// CaptureEnd is called after the call finishes to finalize the tracing.
+func (t *fourByteTracer) CaptureEnd(output []byte, gasUsed uint64, d time.Duration, err error) error {
+	return nil
+}
+
+// GetResult returns the collected identifiers in json.
+func (t *fourByteTracer) GetResult() (json.RawMessage, error) {
+	res, err := json.Marshal(t.ids)
+	if err != nil {
+		return nil, err
+	}
+	return res, t.reason
+}
@@ -0,0 +1,90 @@
+// Copyright (c) 2020 The Meter.io developers
+
+// Distributed under the GNU Lesser General Public License v3.0 software license, see the accompanying
+// file LICENSE or <https://www.gnu.org/licenses/lgpl-3.0.html>
+
+package tracers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/meterio/meter-pov/vm"
+)
+
+// ResultTracer is a vm.Tracer which produces the tracing result in json, implemented
+// either natively in Go or in JavaScript.
+type ResultTracer interface {
+	vm.Tracer
+	GetResult() (json.RawMessage, error)
+	Stop(err error)
+}
+
+// TokenTracer is implemented by tracers which report the token transferred by the traced
+// clause, since the token is unknown to the vm tracer hooks.
+type TokenTracer interface {
+	SetToken(token byte)
+}
+
+// natives contains the native tracers by name, which take precedence over the JavaScript
+// tracers of the same name.
+var natives = map[string]func(cfg json.RawMessage) (ResultTracer, error){
+	"callTracer":     newCallTracer,
+	"prestateTracer": newPrestateTracer,
+	"4byteTracer":    newFourByteTracer,
+}
+
+// NewNative creates the native tracer by name, with the tracer specific config in json.
+// It returns false if there's no native tracer of the name.
+func NewNative(name string, cfg json.RawMessage) (ResultTracer, bool, error) {
+	ctor, ok := natives[name]
+	if !ok {
+		return nil, false, nil
+	}
+	tracer, err := ctor(cfg)
+	return tracer, true, err
+}
+
+var _ ResultTracer = (*Tracer)(nil)
+
+// isPrecompiled returns whether the address is a precompiled contract.
+func isPrecompiled(addr common.Address) bool {
+	_, ok := vm.PrecompiledContractsIstanbul[addr]
+	return ok
+}
+
+// memorySlice returns a copy of the memory in the range, or nil if it is out of bound.
+func memorySlice(memory *vm.Memory, offset, size *big.Int) []byte {
+	if !offset.IsInt64() || !size.IsInt64() || size.Sign() == 0 {
+		return nil
+	}
+	begin, end := offset.Int64(), offset.Int64()+size.Int64()
+	if begin < 0 || end < begin || int64(memory.Len()) < end {
+		return nil
+	}
+	return memory.Get(begin, end-begin)
+}
+
+// revertSelector is the selector of Error(string), which wraps the revert reason.
+var revertSelector = []byte{0x08, 0xc3, 0x79, 0xa0}
+
+// unpackRevert decodes the revert reason of the abi encoded Error(string).
+func unpackRevert(data []byte) (string, error) {
+	if len(data) < 4+32+32 || !bytes.Equal(data[:4], revertSelector) {
+		return "", errors.New("invalid revert data")
+	}
+	data = data[4:]
+	offset := new(big.Int).SetBytes(data[:32])
+	if !offset.IsUint64() || offset.Uint64()+32 > uint64(len(data)) {
+		return "", errors.New("invalid revert data")
+	}
+	start := offset.Uint64() + 32
+	size := new(big.Int).SetBytes(data[start-32 : start])
+	if !size.IsUint64() || start+size.Uint64() > uint64(len(data)) {
+		return "", errors.New("invalid revert data")
+	}
+	return string(data[start : start+size.Uint64()]), nil
+}
@@ -0,0 +1,183 @@
+// Copyright (c) 2020 The Meter.io developers
+
+// Distributed under the GNU Lesser General Public License v3.0 software license, see the accompanying
+// file LICENSE or <https://www.gnu.org/licenses/lgpl-3.0.html>
+
+package tracers_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/meterio/meter-pov/chain"
+	"github.com/meterio/meter-pov/genesis"
+	"github.com/meterio/meter-pov/lvldb"
+	"github.com/meterio/meter-pov/meter"
+	"github.com/meterio/meter-pov/runtime"
+	"github.com/meterio/meter-pov/state"
+	"github.com/meterio/meter-pov/tracers"
+	"github.com/meterio/meter-pov/tx"
+	"github.com/meterio/meter-pov/vm"
+	"github.com/meterio/meter-pov/xenv"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	caller   = meter.BytesToAddress([]byte("caller"))
+	callee   = meter.BytesToAddress([]byte("callee"))
+	origin   = genesis.DevAccounts()[0].Address
+	selector = common.FromHex("0xa9059cbb")
+)
+
+// revertData is the abi encoded Error("no").
+var revertData = common.FromHex("0x08c379a0" +
+	"0000000000000000000000000000000000000000000000000000000000000020" +
+	"0000000000000000000000000000000000000000000000000000000000000002" +
+	"6e6f000000000000000000000000000000000000000000000000000000000000")
+
+// revertCode returns the code which reverts with the data.
+func revertCode(data []byte) []byte {
+	var code []byte
+	for i := 0; i < len(data); i += 32 {
+		word := make([]byte, 32)
+		copy(word, data[i:])
+		code = append(code, byte(vm.PUSH32))
+		code = append(code, word...)
+		code = append(code, byte(vm.PUSH1), byte(i), byte(vm.MSTORE))
+	}
+	return append(code, byte(vm.PUSH1), byte(len(data)), byte(vm.PUSH1), 0, byte(vm.REVERT))
+}
+
+// callerCode returns the code which stores 1 to slot 1 and calls the callee with the selector.
+func callerCode() []byte {
+	code := common.FromHex("0x6001600155")
+	// PUSH32 selector PUSH1 0 MSTORE
+	word := make([]byte, 32)
+	copy(word, selector)
+	code = append(code, byte(vm.PUSH32))
+	code = append(code, word...)
+	code = append(code, common.FromHex("0x600052")...)
+	// PUSH1 100 PUSH1 0 PUSH1 4 PUSH1 0 PUSH1 0 PUSH20 callee GAS CALL STOP
+	code = append(code, common.FromHex("0x60646000600460006000")...)
+	code = append(code, byte(vm.PUSH20))
+	code = append(code, callee.Bytes()...)
+	return append(code, byte(vm.GAS), byte(vm.CALL), byte(vm.STOP))
+}
+
+func traceClause(t *testing.T, tracer tracers.ResultTracer, token byte) json.RawMessage {
+	kv, _ := lvldb.NewMem()
+	b0, _, err := genesis.NewDevnet().Build(state.NewCreator(kv))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ch, _ := chain.New(kv, b0, true)
+	st, _ := state.New(b0.Header().StateRoot(), kv)
+	st.SetCode(caller, callerCode())
+	st.SetCode(callee, revertCode(revertData))
+
+	if tr, ok := tracer.(tracers.TokenTracer); ok {
+		tr.SetToken(token)
+	}
+	rt := runtime.New(ch.NewSeeker(b0.Header().ID()), st, &xenv.BlockContext{})
+	rt.SetVMConfig(vm.Config{Debug: true, Tracer: tracer})
+	clause := tx.NewClause(&caller).WithData(append(selector, make([]byte, 32)...))
+	out := rt.ExecuteClause(clause, 0, 1000000, &xenv.TransactionContext{Origin: origin})
+	assert.Nil(t, out.VMErr)
+
+	res, err := tracer.GetResult()
+	assert.Nil(t, err)
+	return res
+}
+
+func TestNativeTracerByName(t *testing.T) {
+	for _, name := range []string{"callTracer", "prestateTracer", "4byteTracer"} {
+		tracer, ok, err := tracers.NewNative(name, nil)
+		assert.Nil(t, err)
+		assert.True(t, ok, name)
+		assert.NotNil(t, tracer)
+	}
+	_, ok, _ := tracers.NewNative("opcountTracer", nil)
+	assert.False(t, ok)
+
+	_, _, err := tracers.NewNative("prestateTracer", json.RawMessage(`{"diffMode":1}`))
+	assert.NotNil(t, err)
+}
+
+func TestCallTracer(t *testing.T) {
+	tracer, _, _ := tracers.NewNative("callTracer", nil)
+	res := traceClause(t, tracer, meter.STPD)
+
+	var call struct {
+		Type  string         `json:"type"`
+		From  common.Address `json:"from"`
+		To    common.Address `json:"to"`
+		Token hexutil.Uint64 `json:"token"`
+		Error string         `json:"error"`
+		Calls []struct {
+			Type         string         `json:"type"`
+			From         common.Address `json:"from"`
+			To           common.Address `json:"to"`
+			Value        *hexutil.Big   `json:"value"`
+			Input        hexutil.Bytes  `json:"input"`
+			Output       hexutil.Bytes  `json:"output"`
+			Error        string         `json:"error"`
+			RevertReason string         `json:"revertReason"`
+		} `json:"calls"`
+	}
+	assert.Nil(t, json.Unmarshal(res, &call))
+	assert.Equal(t, "CALL", call.Type)
+	assert.Equal(t, common.Address(origin), call.From)
+	assert.Equal(t, common.Address(caller), call.To)
+	assert.Equal(t, hexutil.Uint64(meter.STPD), call.Token)
+	assert.Equal(t, "", call.Error)
+
+	assert.Len(t, call.Calls, 1)
+	inner := call.Calls[0]
+	assert.Equal(t, "CALL", inner.Type)
+	assert.Equal(t, common.Address(caller), inner.From)
+	assert.Equal(t, common.Address(callee), inner.To)
+	assert.Equal(t, int64(0), inner.Value.ToInt().Int64())
+	assert.Equal(t, hexutil.Bytes(selector), inner.Input)
+	assert.Equal(t, hexutil.Bytes(revertData), inner.Output)
+	assert.Equal(t, "execution reverted", inner.Error)
+	assert.Equal(t, "no", inner.RevertReason)
+}
+
+func TestPrestateTracer(t *testing.T) {
+	type account struct {
+		Code    hexutil.Bytes               `json:"code"`
+		Storage map[common.Hash]common.Hash `json:"storage"`
+	}
+	slot := common.BigToHash(common.Big1)
+
+	tracer, _, _ := tracers.NewNative("prestateTracer", nil)
+	var prestate map[common.Address]account
+	assert.Nil(t, json.Unmarshal(traceClause(t, tracer, meter.STPT), &prestate))
+	assert.Len(t, prestate, 3)
+	assert.Contains(t, prestate, common.Address(origin))
+	assert.Equal(t, hexutil.Bytes(callerCode()), prestate[common.Address(caller)].Code)
+	assert.Equal(t, map[common.Hash]common.Hash{slot: {}}, prestate[common.Address(caller)].Storage)
+	assert.Equal(t, hexutil.Bytes(revertCode(revertData)), prestate[common.Address(callee)].Code)
+
+	// only the modified slot is reported in diff mode
+	tracer, _, _ = tracers.NewNative("prestateTracer", json.RawMessage(`{"diffMode":true}`))
+	var diff struct {
+		Pre  map[common.Address]account `json:"pre"`
+		Post map[common.Address]account `json:"post"`
+	}
+	assert.Nil(t, json.Unmarshal(traceClause(t, tracer, meter.STPT), &diff))
+	assert.Len(t, diff.Pre, 1)
+	assert.Equal(t, map[common.Hash]common.Hash{slot: {}}, diff.Pre[common.Address(caller)].Storage)
+	assert.Len(t, diff.Post, 1)
+	assert.Nil(t, diff.Post[common.Address(caller)].Code)
+	assert.Equal(t, map[common.Hash]common.Hash{slot: common.BigToHash(common.Big1)}, diff.Post[common.Address(caller)].Storage)
+}
+
+func TestFourByteTracer(t *testing.T) {
+	tracer, _, _ := tracers.NewNative("4byteTracer", nil)
+	var ids map[string]int
+	assert.Nil(t, json.Unmarshal(traceClause(t, tracer, meter.STPT), &ids))
+	assert.Equal(t, map[string]int{"0xa9059cbb-32": 1, "0xa9059cbb-0": 1}, ids)
+}
@@ -0,0 +1,256 @@
+// Copyright (c) 2020 The Meter.io developers
+
+// Distributed under the GNU Lesser General Public License v3.0 software license, see the accompanying
+// file LICENSE or <https://www.gnu.org/licenses/lgpl-3.0.html>
+
+package tracers
+
+import (
+	"bytes"
+	"encoding/json"
+	"math/big"
+	"sync/atomic"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/meterio/meter-pov/meter"
+	"github.com/meterio/meter-pov/vm"
+)
+
+// account is the state of an account reported by the prestate tracer. As in the accounts api,
+// balance is in MTRG and energy is in MTR, which is the value transferred by the vm.
+type account struct {
+	Balance *hexutil.Big                `json:"balance,omitempty"`
+	Energy  *hexutil.Big                `json:"energy,omitempty"`
+	Code    hexutil.Bytes               `json:"code,omitempty"`
+	Storage map[common.Hash]common.Hash `json:"storage,omitempty"`
+}
+
+type prestateConfig struct {
+	// DiffMode reports the accounts modified by the clause, with both the states before and after.
+	DiffMode bool `json:"diffMode"`
+}
+
+// prestateTracer is the native implementation of prestate_tracer.js. It reports the states
+// accessed by a clause, which is sufficient to re-execute the clause locally. In diff mode,
+// only the modified states are reported, both before and after the execution.
+type prestateTracer struct {
+	config  prestateConfig
+	pre     map[common.Address]*account
+	post    map[common.Address]*account
+	created map[common.Address]bool
+	db      vm.StateDB
+	token   byte
+
+	// context of the clause
+	from  common.Address
+	to    common.Address
+	value *big.Int
+
+	// depths of the pending CREATE ops, which complete when the execution returns to the depth
+	createDepths []int
+
+	interrupt uint32 // atomic flag to signal execution interruption
+	reason    error  // textual reason for the interruption
+}
+
+func newPrestateTracer(cfg json.RawMessage) (ResultTracer, error) {
+	var config prestateConfig
+	if len(cfg) > 0 {
+		if err := json.Unmarshal(cfg, &config); err != nil {
+			return nil, err
+		}
+	}
+	return &prestateTracer{
+		config:  config,
+		pre:     make(map[common.Address]*account),
+		post:    make(map[common.Address]*account),
+		created: make(map[common.Address]bool),
+	}, nil
+}
+
+// SetToken sets the token transferred by the clause.
+func (t *prestateTracer) SetToken(token byte) {
+	t.token = token
+}
+
+// Stop terminates execution of the tracer at the first opportune moment.
+func (t *prestateTracer) Stop(err error) {
+	t.reason = err
+	atomic.StoreUint32(&t.interrupt, 1)
+}
+
+// lookupAccount injects the account into the prestate.
+func (t *prestateTracer) lookupAccount(addr common.Address) {
+	if _, ok := t.pre[addr]; ok || t.created[addr] {
+		return
+	}
+	t.pre[addr] = &account{
+		Balance: (*hexutil.Big)(new(big.Int).Set(t.db.GetBalance(addr))),
+		Energy:  (*hexutil.Big)(new(big.Int).Set(t.db.GetEnergy(addr))),
+		Code:    common.CopyBytes(t.db.GetCode(addr)),
+		Storage: make(map[common.Hash]common.Hash),
+	}
+}
+
+// lookupStorage injects the storage slot of the account into the prestate.
+func (t *prestateTracer) lookupStorage(addr common.Address, key common.Hash) {
+	acc, ok := t.pre[addr]
+	if !ok {
+		return
+	}
+	if _, ok := acc.Storage[key]; !ok {
+		acc.Storage[key] = t.db.GetState(addr, key)
+	}
+}
+
+// CaptureStart implements the Tracer interface to initialize the tracing operation.
+func (t *prestateTracer) CaptureStart(from common.Address, to common.Address, create bool, input []byte, gas uint64, value *big.Int) error {
+	t.from = from
+	t.to = to
+	t.value = new(big.Int).Set(value)
+	if create {
+		t.created[to] = true
+	}
+	return nil
+}
+
+// CaptureState implements the Tracer interface to trace a single step of VM execution.
+func (t *prestateTracer) CaptureState(env *vm.EVM, pc uint64, op vm.OpCode, gas, cost uint64, memory *vm.Memory, stack *vm.Stack, contract *vm.Contract, depth int, err error) error {
+	if err != nil || atomic.LoadUint32(&t.interrupt) > 0 {
+		return nil
+	}
+	if t.db == nil {
+		// balances here already include the value sent along with the clause, which is
+		// fixed in CaptureEnd
+		t.db = env.StateDB
+		t.lookupAccount(t.from)
+		t.lookupAccount(t.to)
+	}
+	// the contract created by the CREATE op is known when the execution returns, and
+	// it may have been looked up during the creation
+	if n := len(t.createDepths); n > 0 && t.createDepths[n-1] == depth {
+		t.createDepths = t.createDepths[:n-1]
+		if ret := stack.Back(0); ret.Sign() != 0 {
+			addr := common.BigToAddress(ret)
+			t.created[addr] = true
+			delete(t.pre, addr)
+		}
+	}
+
+	switch op {
+	case vm.EXTCODECOPY, vm.EXTCODESIZE, vm.EXTCODEHASH, vm.BALANCE:
+		t.lookupAccount(common.BigToAddress(stack.Back(0)))
+	case vm.CREATE, vm.CREATE2:
+		t.createDepths = append(t.createDepths, depth)
+	case vm.CALL, vm.CALLCODE, vm.DELEGATECALL, vm.STATICCALL:
+		t.lookupAccount(common.BigToAddress(stack.Back(1)))
+	case vm.SELFDESTRUCT:
+		t.lookupAccount(common.BigToAddress(stack.Back(0)))
+	case vm.SLOAD, vm.SSTORE:
+		t.lookupStorage(contract.Address(), common.BigToHash(stack.Back(0)))
+	}
+	return nil
+}
+
+// CaptureFault implements the Tracer interface to trace an execution fault
+// while running an opcode.
+func (t *prestateTracer) CaptureFault(env *vm.EVM, pc uint64, op vm.OpCode, gas, cost uint64, memory *vm.Memory, stack *vm.Stack, contract *vm.Contract, depth int, err error) error {
+	return nil
+}
+
+// CaptureEnd is called after the call finishes to finalize the tracing.
+func (t *prestateTracer) CaptureEnd(output []byte, gasUsed uint64, d time.Duration, err error) error {
+	if t.db == nil {
+		return nil
+	}
+	// move the value of the clause back to the origin
+	if from, ok := t.pre[t.from]; ok {
+		t.addValue(from, t.value)
+	}
+	if to, ok := t.pre[t.to]; ok {
+		t.addValue(to, new(big.Int).Neg(t.value))
+	}
+	if t.config.DiffMode {
+		t.processDiff()
+	}
+	return nil
+}
+
+func (t *prestateTracer) addValue(acc *account, value *big.Int) {
+	if t.token == meter.STPD {
+		acc.Balance = (*hexutil.Big)(new(big.Int).Add(acc.Balance.ToInt(), value))
+	} else {
+		acc.Energy = (*hexutil.Big)(new(big.Int).Add(acc.Energy.ToInt(), value))
+	}
+}
+
+// processDiff keeps the modified fields of the accounts in the post state, and drops the
+// unmodified accounts and storage slots from the prestate.
+func (t *prestateTracer) processDiff() {
+	for addr, pre := range t.pre {
+		// deleted accounts are only reported in the prestate
+		if t.db.HasSuicided(addr) {
+			continue
+		}
+		post := &account{Storage: make(map[common.Hash]common.Hash)}
+		modified := false
+		if balance := t.db.GetBalance(addr); balance.Cmp(pre.Balance.ToInt()) != 0 {
+			post.Balance = (*hexutil.Big)(new(big.Int).Set(balance))
+			modified = true
+		}
+		if energy := t.db.GetEnergy(addr); energy.Cmp(pre.Energy.ToInt()) != 0 {
+			post.Energy = (*hexutil.Big)(new(big.Int).Set(energy))
+			modified = true
+		}
+		if code := t.db.GetCode(addr); !bytes.Equal(code, pre.Code) {
+			post.Code = common.CopyBytes(code)
+			modified = true
+		}
+		for key, val := range pre.Storage {
+			if newVal := t.db.GetState(addr, key); newVal != val {
+				post.Storage[key] = newVal
+				modified = true
+			} else {
+				delete(pre.Storage, key)
+			}
+		}
+		if modified {
+			t.post[addr] = post
+		} else {
+			delete(t.pre, addr)
+		}
+	}
+	// created accounts are only reported in the post state
+	for addr := range t.created {
+		if t.db.HasSuicided(addr) || !t.db.Exist(addr) {
+			continue
+		}
+		t.post[addr] = &account{
+			Balance: (*hexutil.Big)(new(big.Int).Set(t.db.GetBalance(addr))),
+			Energy:  (*hexutil.Big)(new(big.Int).Set(t.db.GetEnergy(addr))),
+			Code:    common.CopyBytes(t.db.GetCode(addr)),
+		}
+	}
+}
+
+// GetResult returns the prestate in json, or both the pre and post states in diff mode.
+func (t *prestateTracer) GetResult() (json.RawMessage, error) {
+	var (
+		res []byte
+		err error
+	)
+	if t.config.DiffMode {
+		res, err = json.Marshal(struct {
+			Pre  map[common.Address]*account `json:"pre"`
+			Post map[common.Address]*account `json:"post"`
+		}{t.pre, t.post})
+	} else {
+		res, err = json.Marshal(t.pre)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return res, t.reason
+}
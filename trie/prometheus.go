@@ -0,0 +1,24 @@
+// Copyright (c) 2020 The Meter.io developers
+
+// Distributed under the GNU Lesser General Public License v3.0 software license, see the accompanying
+// file LICENSE or <https://www.gnu.org/licenses/lgpl-3.0.html>
+
+package trie
+
+import "github.com/prometheus/client_golang/prometheus"
+
+var (
+	cacheMissesCounter = prometheus.NewCounterFunc(prometheus.CounterOpts{
+		Name: "trie_cache_misses_total",
+		Help: "Counter of trie nodes resolved from the database",
+	}, func() float64 { return float64(CacheMisses()) })
+	cacheUnloadsCounter = prometheus.NewCounterFunc(prometheus.CounterOpts{
+		Name: "trie_cache_unloads_total",
+		Help: "Counter of trie nodes unloaded from memory",
+	}, func() float64 { return float64(CacheUnloads()) })
+)
+
+func init() {
+	prometheus.Register(cacheMissesCounter)
+	prometheus.Register(cacheUnloadsCounter)
+}
@@ -20,6 +20,7 @@ import (
 	"bytes"
 	"fmt"
 
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/rlp"
 	"github.com/meterio/meter-pov/meter"
@@ -139,6 +140,45 @@ func VerifyProof(rootHash meter.Bytes32, key []byte, proofDb DatabaseReader) (va
 	}
 }
 
+// ProofList is a merkle proof as the list of encoded nodes, in the order from the root.
+// It implements DatabaseWriter to collect the proof.
+type ProofList [][]byte
+
+// Put appends the encoded node to the proof.
+func (l *ProofList) Put(key []byte, value []byte) error {
+	*l = append(*l, common.CopyBytes(value))
+	return nil
+}
+
+// proofReader implements DatabaseReader on the nodes of a proof, keyed by the node hash.
+type proofReader map[meter.Bytes32][]byte
+
+func (r proofReader) Get(key []byte) ([]byte, error) {
+	return r[meter.BytesToBytes32(key)], nil
+}
+
+func (r proofReader) Has(key []byte) (bool, error) {
+	_, ok := r[meter.BytesToBytes32(key)]
+	return ok, nil
+}
+
+// VerifySecureProof checks the merkle proof of key in a secure trie with the given root
+// hash, e.g. the accounts trie or a storage trie, where key is hashed before looking up.
+// It's standalone for light clients, the proof is the list of encoded nodes. It returns
+// nil value if the proof proves the absence of the key.
+func VerifySecureProof(rootHash meter.Bytes32, key []byte, proof [][]byte) ([]byte, error) {
+	// the empty trie contains no key
+	if rootHash.IsZero() || rootHash == emptyRoot {
+		return nil, nil
+	}
+	proofDb := make(proofReader, len(proof))
+	for _, node := range proof {
+		proofDb[meter.Blake2b(node)] = node
+	}
+	value, err, _ := VerifyProof(rootHash, meter.Blake2b(key).Bytes(), proofDb)
+	return value, err
+}
+
 func get(tn node, key []byte) ([]byte, node) {
 	for {
 		switch n := tn.(type) {
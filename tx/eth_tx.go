@@ -0,0 +1,328 @@
+// Copyright (c) 2020 The Meter.io developers
+
+// Distributed under the GNU Lesser General Public License v3.0 software license, see the accompanying
+// file LICENSE or <https://www.gnu.org/licenses/lgpl-3.0.html>
+
+package tx
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/crypto/sha3"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// eth tx types, see EIP-2718.
+const (
+	LegacyTxType     = 0x00
+	AccessListTxType = 0x01 // EIP-2930
+	DynamicFeeTxType = 0x02 // EIP-1559
+)
+
+// AccessTuple is the element type of an access list.
+type AccessTuple struct {
+	Address     common.Address `json:"address"`
+	StorageKeys []common.Hash  `json:"storageKeys"`
+}
+
+// AccessList is an EIP-2930 access list.
+type AccessList []AccessTuple
+
+// EthTx is a signed ethereum tx of any type, which is carried by meter tx in reserved fields.
+type EthTx struct {
+	Type       uint8
+	ChainID    *big.Int
+	Nonce      uint64
+	GasPrice   *big.Int // gas price of legacy and access list txs
+	GasTipCap  *big.Int // max priority fee per gas of dynamic fee txs
+	GasFeeCap  *big.Int // max fee per gas of dynamic fee txs
+	Gas        uint64
+	To         *common.Address
+	Value      *big.Int
+	Data       []byte
+	AccessList AccessList
+	V, R, S    *big.Int
+}
+
+type legacyTxData struct {
+	Nonce    uint64
+	GasPrice *big.Int
+	Gas      uint64
+	To       *common.Address `rlp:"nil"`
+	Value    *big.Int
+	Data     []byte
+	V, R, S  *big.Int
+}
+
+type accessListTxData struct {
+	ChainID    *big.Int
+	Nonce      uint64
+	GasPrice   *big.Int
+	Gas        uint64
+	To         *common.Address `rlp:"nil"`
+	Value      *big.Int
+	Data       []byte
+	AccessList AccessList
+	V, R, S    *big.Int
+}
+
+type dynamicFeeTxData struct {
+	ChainID    *big.Int
+	Nonce      uint64
+	GasTipCap  *big.Int
+	GasFeeCap  *big.Int
+	Gas        uint64
+	To         *common.Address `rlp:"nil"`
+	Value      *big.Int
+	Data       []byte
+	AccessList AccessList
+	V, R, S    *big.Int
+}
+
+// DecodeEthTx decodes an ethereum tx from its binary encoding, which is the rlp list for
+// legacy txs, or the type byte followed by the rlp payload for typed txs.
+func DecodeEthTx(raw []byte) (*EthTx, error) {
+	if len(raw) == 0 {
+		return nil, errors.New("empty eth tx")
+	}
+	// legacy tx starts with a rlp list prefix
+	if raw[0] > 0x7f {
+		var d legacyTxData
+		if err := rlp.DecodeBytes(raw, &d); err != nil {
+			return nil, err
+		}
+		return &EthTx{
+			Type:     LegacyTxType,
+			ChainID:  deriveChainId(d.V),
+			Nonce:    d.Nonce,
+			GasPrice: d.GasPrice,
+			Gas:      d.Gas,
+			To:       d.To,
+			Value:    d.Value,
+			Data:     d.Data,
+			V:        d.V,
+			R:        d.R,
+			S:        d.S,
+		}, nil
+	}
+
+	switch raw[0] {
+	case AccessListTxType:
+		var d accessListTxData
+		if err := rlp.DecodeBytes(raw[1:], &d); err != nil {
+			return nil, err
+		}
+		return &EthTx{
+			Type:       AccessListTxType,
+			ChainID:    d.ChainID,
+			Nonce:      d.Nonce,
+			GasPrice:   d.GasPrice,
+			Gas:        d.Gas,
+			To:         d.To,
+			Value:      d.Value,
+			Data:       d.Data,
+			AccessList: d.AccessList,
+			V:          d.V,
+			R:          d.R,
+			S:          d.S,
+		}, nil
+	case DynamicFeeTxType:
+		var d dynamicFeeTxData
+		if err := rlp.DecodeBytes(raw[1:], &d); err != nil {
+			return nil, err
+		}
+		if d.GasTipCap.Cmp(d.GasFeeCap) > 0 {
+			return nil, errors.New("max priority fee per gas higher than max fee per gas")
+		}
+		return &EthTx{
+			Type:       DynamicFeeTxType,
+			ChainID:    d.ChainID,
+			Nonce:      d.Nonce,
+			GasTipCap:  d.GasTipCap,
+			GasFeeCap:  d.GasFeeCap,
+			Gas:        d.Gas,
+			To:         d.To,
+			Value:      d.Value,
+			Data:       d.Data,
+			AccessList: d.AccessList,
+			V:          d.V,
+			R:          d.R,
+			S:          d.S,
+		}, nil
+	default:
+		return nil, fmt.Errorf("unsupported eth tx type %d", raw[0])
+	}
+}
+
+// MarshalBinary returns the binary encoding of the tx, see DecodeEthTx.
+func (t *EthTx) MarshalBinary() ([]byte, error) {
+	var payload interface{}
+	switch t.Type {
+	case LegacyTxType:
+		return rlp.EncodeToBytes(&legacyTxData{t.Nonce, t.GasPrice, t.Gas, t.To, t.Value, t.Data, t.V, t.R, t.S})
+	case AccessListTxType:
+		payload = &accessListTxData{t.ChainID, t.Nonce, t.GasPrice, t.Gas, t.To, t.Value, t.Data, t.AccessList, t.V, t.R, t.S}
+	case DynamicFeeTxType:
+		payload = &dynamicFeeTxData{t.ChainID, t.Nonce, t.GasTipCap, t.GasFeeCap, t.Gas, t.To, t.Value, t.Data, t.AccessList, t.V, t.R, t.S}
+	default:
+		return nil, fmt.Errorf("unsupported eth tx type %d", t.Type)
+	}
+	var buf bytes.Buffer
+	buf.WriteByte(t.Type)
+	if err := rlp.Encode(&buf, payload); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Hash returns the ethereum tx hash, which is keccak256 of the binary encoding.
+func (t *EthTx) Hash() (h common.Hash) {
+	data, err := t.MarshalBinary()
+	if err != nil {
+		return
+	}
+	return crypto.Keccak256Hash(data)
+}
+
+// IsProtected returns whether the tx is replay protected by chain id.
+// Typed txs are always protected.
+func (t *EthTx) IsProtected() bool {
+	return t.Type != LegacyTxType || isProtectedV(t.V)
+}
+
+// SigningHash returns the hash signed by the sender.
+func (t *EthTx) SigningHash() common.Hash {
+	switch t.Type {
+	case AccessListTxType:
+		return prefixedRlpHash(t.Type, []interface{}{t.ChainID, t.Nonce, t.GasPrice, t.Gas, t.To, t.Value, t.Data, t.AccessList})
+	case DynamicFeeTxType:
+		return prefixedRlpHash(t.Type, []interface{}{t.ChainID, t.Nonce, t.GasTipCap, t.GasFeeCap, t.Gas, t.To, t.Value, t.Data, t.AccessList})
+	}
+	if t.IsProtected() {
+		// EIP-155
+		return rlpHash([]interface{}{t.Nonce, t.GasPrice, t.Gas, t.To, t.Value, t.Data, t.ChainID, uint(0), uint(0)})
+	}
+	return rlpHash([]interface{}{t.Nonce, t.GasPrice, t.Gas, t.To, t.Value, t.Data})
+}
+
+// Sender recovers the sender from the signature.
+func (t *EthTx) Sender() (common.Address, error) {
+	if t.V == nil || t.R == nil || t.S == nil {
+		return common.Address{}, types.ErrInvalidSig
+	}
+	var v *big.Int
+	switch {
+	case t.Type != LegacyTxType:
+		// y parity
+		v = t.V
+	case isProtectedV(t.V):
+		v = new(big.Int).Sub(t.V, new(big.Int).Add(big.NewInt(35), new(big.Int).Mul(t.ChainID, big.NewInt(2))))
+	default:
+		v = new(big.Int).Sub(t.V, big.NewInt(27))
+	}
+	if v.BitLen() > 8 {
+		return common.Address{}, types.ErrInvalidSig
+	}
+	return recoverPlain(t.SigningHash(), t.R, t.S, byte(v.Uint64()))
+}
+
+// GasPriceCoef maps the eth fee fields onto the gas price model of meter, where
+// gasPrice = baseGasPrice + baseGasPrice * gasPriceCoef / 255, and the base gas price is set by governance.
+//
+// Legacy and access list txs pay the base gas price, i.e. the coef is 0, and their gas price is informational.
+// Dynamic fee txs take maxFeePerGas - maxPriorityFeePerGas as the part for the base gas price, and
+// maxPriorityFeePerGas as the premium on top of it, so that
+// gasPriceCoef = 255 * maxPriorityFeePerGas / (maxFeePerGas - maxPriorityFeePerGas), capped at 255.
+// The resulting gas price must not exceed maxFeePerGas, which is checked when the tx enters the pool.
+func (t *EthTx) GasPriceCoef() uint8 {
+	if t.Type != DynamicFeeTxType || t.GasTipCap.Sign() <= 0 {
+		return 0
+	}
+	base := new(big.Int).Sub(t.GasFeeCap, t.GasTipCap)
+	if base.Sign() <= 0 {
+		return math.MaxUint8
+	}
+	coef := new(big.Int).Mul(t.GasTipCap, big.NewInt(math.MaxUint8))
+	coef.Div(coef, base)
+	if coef.Cmp(big.NewInt(math.MaxUint8)) > 0 {
+		return math.MaxUint8
+	}
+	return uint8(coef.Uint64())
+}
+
+// MaxFeePerGas returns the highest gas price the sender is willing to pay.
+func (t *EthTx) MaxFeePerGas() *big.Int {
+	if t.Type == DynamicFeeTxType {
+		return t.GasFeeCap
+	}
+	return t.GasPrice
+}
+
+func rlpHash(x interface{}) (h common.Hash) {
+	hw := sha3.NewKeccak256()
+	rlp.Encode(hw, x)
+	hw.Sum(h[:0])
+	return h
+}
+
+func prefixedRlpHash(prefix byte, x interface{}) (h common.Hash) {
+	hw := sha3.NewKeccak256()
+	hw.Write([]byte{prefix})
+	rlp.Encode(hw, x)
+	hw.Sum(h[:0])
+	return h
+}
+
+// reference to github.com/ethereum/go-ethereum/
+func isProtectedV(V *big.Int) bool {
+	if V.BitLen() <= 8 {
+		v := V.Uint64()
+		return v != 27 && v != 28
+	}
+	// anything not 27 or 28 is considered protected
+	return true
+}
+
+// deriveChainId derives the chain id from the given v parameter
+func deriveChainId(v *big.Int) *big.Int {
+	if v.BitLen() <= 64 {
+		v := v.Uint64()
+		if v == 27 || v == 28 {
+			return new(big.Int)
+		}
+		return new(big.Int).SetUint64((v - 35) / 2)
+	}
+	v = new(big.Int).Sub(v, big.NewInt(35))
+	return v.Div(v, big.NewInt(2))
+}
+
+func recoverPlain(sighash common.Hash, R, S *big.Int, y byte) (common.Address, error) {
+	if !crypto.ValidateSignatureValues(y, R, S, true) {
+		return common.Address{}, types.ErrInvalidSig
+	}
+
+	// encode the snature in uncompressed format
+	r, s := R.Bytes(), S.Bytes()
+	sig := make([]byte, 65)
+	copy(sig[32-len(r):32], r)
+	copy(sig[64-len(s):64], s)
+	sig[64] = y
+	// recover the public key from the snature
+	pub, err := crypto.Ecrecover(sighash[:], sig)
+	if err != nil {
+		return common.Address{}, err
+	}
+	if len(pub) == 0 || pub[0] != 4 {
+		return common.Address{}, errors.New("invalid public key")
+	}
+	var addr common.Address
+	copy(addr[:], crypto.Keccak256(pub[1:])[12:])
+	return addr, nil
+}
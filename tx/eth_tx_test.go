@@ -0,0 +1,167 @@
+// Copyright (c) 2020 The Meter.io developers
+
+// Distributed under the GNU Lesser General Public License v3.0 software license, see the accompanying
+// file LICENSE or <https://www.gnu.org/licenses/lgpl-3.0.html>
+
+package tx_test
+
+import (
+	"crypto/ecdsa"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/meterio/meter-pov/meter"
+	"github.com/meterio/meter-pov/tx"
+	"github.com/stretchr/testify/assert"
+)
+
+func signEthTx(t *testing.T, ethTx *tx.EthTx, key *ecdsa.PrivateKey) []byte {
+	h := ethTx.SigningHash()
+	sig, err := crypto.Sign(h[:], key)
+	assert.Nil(t, err)
+	ethTx.R = new(big.Int).SetBytes(sig[:32])
+	ethTx.S = new(big.Int).SetBytes(sig[32:64])
+	if ethTx.Type == tx.LegacyTxType {
+		ethTx.V = new(big.Int).SetUint64(uint64(sig[64]) + 35 + 2*ethTx.ChainID.Uint64())
+	} else {
+		ethTx.V = new(big.Int).SetUint64(uint64(sig[64]))
+	}
+	raw, err := ethTx.MarshalBinary()
+	assert.Nil(t, err)
+	return raw
+}
+
+func TestLegacyEthTx(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	to := common.HexToAddress("0x7567d83b7b8d80addcb281a71d54fc7b3364ffed")
+	chainID := big.NewInt(83)
+
+	// signed by go-ethereum
+	gethTx, err := types.SignTx(types.NewTransaction(1, to, big.NewInt(10), 21000, big.NewInt(500e9), nil), types.NewEIP155Signer(chainID), key)
+	assert.Nil(t, err)
+	raw, _ := rlp.EncodeToBytes(gethTx)
+
+	ethTx, err := tx.DecodeEthTx(raw)
+	assert.Nil(t, err)
+	assert.Equal(t, uint8(tx.LegacyTxType), ethTx.Type)
+	assert.Equal(t, chainID, ethTx.ChainID)
+	assert.Equal(t, gethTx.Hash(), ethTx.Hash())
+	assert.Equal(t, uint8(0), ethTx.GasPriceCoef())
+
+	sender, err := ethTx.Sender()
+	assert.Nil(t, err)
+	assert.Equal(t, crypto.PubkeyToAddress(key.PublicKey), sender)
+
+	encoded, err := ethTx.MarshalBinary()
+	assert.Nil(t, err)
+	assert.Equal(t, raw, encoded)
+
+	trx, err := tx.NewTransactionFromEthTx(ethTx, 1, tx.BlockRef{})
+	assert.Nil(t, err)
+	signer, err := trx.Signer()
+	assert.Nil(t, err)
+	assert.Equal(t, meter.Address(sender), signer)
+	_, err = trx.EthTxValidate()
+	assert.Nil(t, err)
+}
+
+func TestTypedEthTx(t *testing.T) {
+	meter.TypedEthTxForkStartNum = 0
+	defer func() { meter.TypedEthTxForkStartNum = meter.TypedEthTxFork_MainnetStartNum }()
+
+	key, _ := crypto.GenerateKey()
+	to := common.HexToAddress("0x7567d83b7b8d80addcb281a71d54fc7b3364ffed")
+	accessList := tx.AccessList{{Address: to, StorageKeys: []common.Hash{{1}}}}
+
+	tests := []struct {
+		ethTx *tx.EthTx
+		coef  uint8
+	}{
+		{&tx.EthTx{Type: tx.AccessListTxType, ChainID: big.NewInt(83), Nonce: 1, GasPrice: big.NewInt(500e9), Gas: 30000, To: &to, Value: big.NewInt(10), AccessList: accessList}, 0},
+		{&tx.EthTx{Type: tx.DynamicFeeTxType, ChainID: big.NewInt(83), Nonce: 2, GasTipCap: big.NewInt(0), GasFeeCap: big.NewInt(500e9), Gas: 30000, Value: big.NewInt(0), Data: []byte{0x60}}, 0},
+		{&tx.EthTx{Type: tx.DynamicFeeTxType, ChainID: big.NewInt(83), Nonce: 3, GasTipCap: big.NewInt(250e9), GasFeeCap: big.NewInt(750e9), Gas: 30000, To: &to, Value: big.NewInt(10)}, 127},
+		{&tx.EthTx{Type: tx.DynamicFeeTxType, ChainID: big.NewInt(83), Nonce: 4, GasTipCap: big.NewInt(500e9), GasFeeCap: big.NewInt(500e9), Gas: 30000, To: &to, Value: big.NewInt(10)}, 255},
+	}
+	for _, tt := range tests {
+		raw := signEthTx(t, tt.ethTx, key)
+		assert.Equal(t, tt.ethTx.Type, raw[0])
+
+		ethTx, err := tx.DecodeEthTx(raw)
+		assert.Nil(t, err)
+		assert.Equal(t, tt.ethTx.Hash(), ethTx.Hash())
+		assert.Equal(t, tt.coef, ethTx.GasPriceCoef())
+
+		sender, err := ethTx.Sender()
+		assert.Nil(t, err)
+		assert.Equal(t, crypto.PubkeyToAddress(key.PublicKey), sender)
+
+		trx, err := tx.NewTransactionFromEthTx(ethTx, 1, tx.BlockRef{})
+		assert.Nil(t, err)
+		assert.Equal(t, tt.coef, trx.GasPriceCoef())
+		signer, err := trx.Signer()
+		assert.Nil(t, err)
+		assert.Equal(t, meter.Address(sender), signer)
+		_, err = trx.EthTxValidate()
+		assert.Nil(t, err)
+
+		// survives the meter tx encoding
+		data, _ := rlp.EncodeToBytes(trx)
+		var decoded tx.Transaction
+		assert.Nil(t, rlp.DecodeBytes(data, &decoded))
+		signer, err = decoded.Signer()
+		assert.Nil(t, err)
+		assert.Equal(t, meter.Address(sender), signer)
+	}
+
+	// typed txs are rejected before the fork
+	meter.TypedEthTxForkStartNum = 100
+	trx, err := tx.NewTransactionFromEthTx(tests[0].ethTx, 1, tx.BlockRef{})
+	assert.Nil(t, err)
+	_, err = trx.EthTxValidate()
+	assert.NotNil(t, err)
+}
+
+func TestEthTxAccessListGas(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	to := common.HexToAddress("0x7567d83b7b8d80addcb281a71d54fc7b3364ffed")
+	accessList := tx.AccessList{
+		{Address: to, StorageKeys: []common.Hash{{1}, {2}}},
+		{Address: common.Address{1}, StorageKeys: []common.Hash{{3}}},
+	}
+
+	tests := []struct {
+		ethTx *tx.EthTx
+		gas   uint64
+	}{
+		{&tx.EthTx{Type: tx.AccessListTxType, ChainID: big.NewInt(83), Nonce: 1, GasPrice: big.NewInt(500e9), Gas: 50000, To: &to, Value: big.NewInt(10), AccessList: accessList}, 21000 + 2*2400 + 3*1900},
+		{&tx.EthTx{Type: tx.DynamicFeeTxType, ChainID: big.NewInt(83), Nonce: 2, GasTipCap: big.NewInt(0), GasFeeCap: big.NewInt(500e9), Gas: 50000, To: &to, Value: big.NewInt(10), AccessList: accessList}, 21000 + 2*2400 + 3*1900},
+		{&tx.EthTx{Type: tx.DynamicFeeTxType, ChainID: big.NewInt(83), Nonce: 3, GasTipCap: big.NewInt(0), GasFeeCap: big.NewInt(500e9), Gas: 50000, To: &to, Value: big.NewInt(10)}, 21000},
+	}
+	for _, tt := range tests {
+		ethTx, err := tx.DecodeEthTx(signEthTx(t, tt.ethTx, key))
+		assert.Nil(t, err)
+		trx, err := tx.NewTransactionFromEthTx(ethTx, 1, tx.BlockRef{})
+		assert.Nil(t, err)
+		assert.Equal(t, len(tt.ethTx.AccessList), len(trx.AccessList()))
+
+		gas, err := trx.IntrinsicGas()
+		assert.Nil(t, err)
+		assert.Equal(t, tt.gas, gas)
+	}
+}
+
+func TestDecodeEthTxErrors(t *testing.T) {
+	_, err := tx.DecodeEthTx(nil)
+	assert.NotNil(t, err)
+	_, err = tx.DecodeEthTx([]byte{0x03, 0xc0})
+	assert.NotNil(t, err)
+
+	key, _ := crypto.GenerateKey()
+	raw := signEthTx(t, &tx.EthTx{Type: tx.DynamicFeeTxType, ChainID: big.NewInt(83), GasTipCap: big.NewInt(2), GasFeeCap: big.NewInt(1), Gas: 21000, Value: big.NewInt(0)}, key)
+	_, err = tx.DecodeEthTx(raw)
+	assert.NotNil(t, err)
+}
@@ -13,12 +13,9 @@ import (
 	"fmt"
 	"io"
 	"math/big"
-	"strings"
 	"sync/atomic"
 
-	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/math"
-	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/params"
 	"github.com/ethereum/go-ethereum/rlp"
@@ -31,61 +28,6 @@ var (
 	RESERVED_PREFIX         = []byte{0xee, 0xff}
 )
 
-// reference to github.com/ethereum/go-ethereum/
-func isProtectedV(V *big.Int) bool {
-	if V.BitLen() <= 8 {
-		v := V.Uint64()
-		return v != 27 && v != 28
-	}
-	// anything not 27 or 28 is considered protected
-	return true
-}
-
-// deriveChainId derives the chain id from the given v parameter
-func deriveChainId(v *big.Int) *big.Int {
-	if v.BitLen() <= 64 {
-		v := v.Uint64()
-		if v == 27 || v == 28 {
-			return new(big.Int)
-		}
-		return new(big.Int).SetUint64((v - 35) / 2)
-	}
-	v = new(big.Int).Sub(v, big.NewInt(35))
-	return v.Div(v, big.NewInt(2))
-}
-
-func recoverPlain(sighash common.Hash, R, S, V *big.Int, homestead bool) (common.Address, error) {
-	var y byte
-	if isProtectedV(V) {
-		chainID := deriveChainId(V).Uint64()
-		y = byte(V.Uint64() - 35 - 2*chainID)
-	} else {
-		y = byte(V.Uint64() - 27)
-	}
-
-	if !crypto.ValidateSignatureValues(y, R, S, homestead) {
-		return common.Address{}, types.ErrInvalidSig
-	}
-
-	// encode the snature in uncompressed format
-	r, s := R.Bytes(), S.Bytes()
-	sig := make([]byte, 65)
-	copy(sig[32-len(r):32], r)
-	copy(sig[64-len(s):64], s)
-	sig[64] = y
-	// recover the public key from the snature
-	pub, err := crypto.Ecrecover(sighash[:], sig)
-	if err != nil {
-		return common.Address{}, err
-	}
-	if len(pub) == 0 || pub[0] != 4 {
-		return common.Address{}, errors.New("invalid public key")
-	}
-	var addr common.Address
-	copy(addr[:], crypto.Keccak256(pub[1:])[12:])
-	return addr, nil
-}
-
 // Transaction is an immutable tx type.
 type Transaction struct {
 	body body
@@ -114,109 +56,54 @@ type body struct {
 	Signature    []byte
 }
 
-func NewTransactionFromEthTx(ethTx *types.Transaction, chainTag byte, blockRef BlockRef) (*Transaction, error) {
-	msg, err := ethTx.AsMessage(types.NewEIP155Signer(ethTx.ChainId()))
-	fmt.Println(err)
-	if err != nil {
+// NewTransactionFromEthTx wraps a signed ethereum tx into a meter tx, with the sender and
+// the binary encoding of the eth tx carried in reserved fields.
+func NewTransactionFromEthTx(ethTx *EthTx, chainTag byte, blockRef BlockRef) (*Transaction, error) {
+	if _, err := ChainIdValidate(ethTx.ChainID); err != nil {
 		return nil, err
 	}
-	fmt.Println("eth tx from:", msg.From().Hex())
-	if msg.To() != nil {
-
-	}
-	from, err := meter.ParseAddress(msg.From().Hex())
+	from, err := ethTx.Sender()
 	if err != nil {
 		return nil, err
 	}
-	to := meter.Address{}
-	if msg.To() != nil {
-		to, err = meter.ParseAddress(msg.To().Hex())
-		if err != nil {
-			return nil, err
-		}
-		fmt.Println("to:", msg.To().Hex())
-	} else {
-		fmt.Println("to:", to.String())
-	}
-	fmt.Println("value:", msg.Value())
-	chainId := ethTx.ChainId()
-	fmt.Println("chainId:", fmt.Sprintf("0x%x", chainId))
-
-	if _, err = ChainIdValidate(chainId); err != nil {
-		return nil, err
-	}
-
-	signer := types.NewEIP155Signer(ethTx.ChainId())
-	value := msg.Value()
-	V, R, S := ethTx.RawSignatureValues()
-	msgHash := signer.Hash(ethTx)
-	fmt.Println("eth tx msgHash:", hex.EncodeToString(msgHash[:]))
-	var rawEthTx bytes.Buffer
-	err = ethTx.EncodeRLP(&rawEthTx)
+	rawEthTx, err := ethTx.MarshalBinary()
 	if err != nil {
 		return nil, err
 	}
 
-	// padding 0 for S to 32bytes
-	sBytes := make([]byte, 0)
-	if len(S.Bytes()) < 32 {
-		for i := 0; i < 32-len(S.Bytes()); i++ {
-			sBytes = append(sBytes, 0)
-		}
-	}
-	sBytes = append(sBytes, S.Bytes()...)
-
-	// padding 0 for R to 32bytes
-	rBytes := make([]byte, 0)
-	if len(R.Bytes()) < 32 {
-		for i := 0; i < 32-len(R.Bytes()); i++ {
-			rBytes = append(rBytes, 0)
-		}
+	// signature is r (32 bytes) + s (32 bytes) + v, where v is the raw V of legacy txs, or the y parity of typed txs
+	sig := make([]byte, 64)
+	copy(sig[32-len(ethTx.R.Bytes()):32], ethTx.R.Bytes())
+	copy(sig[64-len(ethTx.S.Bytes()):64], ethTx.S.Bytes())
+	if ethTx.Type == LegacyTxType {
+		sig = append(sig, ethTx.V.Bytes()...)
+	} else {
+		sig = append(sig, byte(ethTx.V.Uint64()))
 	}
-	rBytes = append(rBytes, R.Bytes()...)
 
-	ethSignature := append(append(rBytes, sBytes...), V.Bytes()...)
-	// fmt.Println("eth tx signature:", hex.EncodeToString(ethSignature))
-
-	origin, err := recoverPlain(msgHash, R, S, V, false)
-	if err != nil {
-		fmt.Println("invalid ethereum tx: incorrect signature")
-		return nil, err
-	}
-	if strings.ToLower(origin.Hex()) != strings.ToLower(from.String()) {
-		fmt.Println("ethereum tx origin != from, this tx is using homestead style")
-		// return nil, errors.New("invalid ethereum tx: origin is not the same as from")
+	var to *meter.Address
+	if ethTx.To != nil && !meter.Address(*ethTx.To).IsZero() {
+		addr := meter.Address(*ethTx.To)
+		to = &addr
 	}
-	var toto *meter.Address
-	if to.String() == "0x0000000000000000000000000000000000000000" {
-		toto = nil
-	} else {
-		toto = &to
+	value := ethTx.Value
+	if value == nil {
+		value = new(big.Int)
 	}
 	tx := &Transaction{
 		body: body{
 			ChainTag:     chainTag,
 			BlockRef:     blockRef.Uint64(),
 			Expiration:   320,
-			Clauses:      []*Clause{&Clause{body: clauseBody{To: toto, Value: value, Token: meter.STPT, Data: ethTx.Data()}}},
-			GasPriceCoef: 0,
-			Gas:          msg.Gas(),
+			Clauses:      []*Clause{&Clause{body: clauseBody{To: to, Value: value, Token: meter.STPT, Data: ethTx.Data}}},
+			GasPriceCoef: ethTx.GasPriceCoef(),
+			Gas:          ethTx.Gas,
 			DependsOn:    nil,
-			Nonce:        msg.Nonce(),
-			Reserved:     []interface{}{RESERVED_PREFIX, msg.From().Bytes(), rawEthTx.Bytes()},
-			Signature:    ethSignature,
+			Nonce:        ethTx.Nonce,
+			Reserved:     []interface{}{RESERVED_PREFIX, from.Bytes(), rawEthTx},
+			Signature:    sig,
 		},
 	}
-	// tx.cache.signer.Store(from)
-	fmt.Println("NewTransactionFromEthTx created tx: ", tx.ID(),
-		"\n  from:", msg.From().Hex(),
-		"\n  to:", to.String(),
-		"\n  value:", msg.Value().String(),
-		"\n  chainID:", fmt.Sprintf("0x%x", ethTx.ChainId()),
-		"\n  r:", fmt.Sprintf("0x%x", rBytes),
-		"\n  s:", fmt.Sprintf("0x%x", sBytes),
-		"\n  v:", fmt.Sprintf("0x%x", V.Bytes()),
-		"\n  nonce:", msg.Nonce())
 	return tx, nil
 }
 
@@ -232,18 +119,12 @@ func ChainIdValidate(chainId *big.Int) (bool, error) {
 }
 
 func (t *Transaction) ChainIdValidate() (bool, error) {
-	var ethTx *types.Transaction
-	var err error
-
 	if t.IsEthTx() {
-		ethTx, err = t.GetEthTx()
-
+		ethTx, err := t.GetEthTx()
 		if err != nil {
 			return false, err
 		}
-
-		chainId := ethTx.ChainId()
-		return ChainIdValidate(chainId)
+		return ChainIdValidate(ethTx.ChainID)
 	}
 
 	return true, nil
@@ -256,17 +137,25 @@ func (t *Transaction) IsEthTx() bool {
 		len(t.body.Signature) >= 65
 }
 
-func (t *Transaction) GetEthTx() (*types.Transaction, error) {
+// GetEthTx decodes the wrapped ethereum tx.
+func (t *Transaction) GetEthTx() (*EthTx, error) {
 	if !t.IsEthTx() {
 		return nil, errors.New("not a tx from ethereum")
 	}
-	var tx *types.Transaction
-	rawTx := t.body.Reserved[2].([]byte)
-	err := rlp.DecodeBytes(rawTx, &tx)
+	return DecodeEthTx(t.body.Reserved[2].([]byte))
+}
+
+// AccessList returns the access list of the wrapped ethereum tx, nil if it's not an eth tx.
+// Eth txs which can't be decoded are rejected by EthTxValidate.
+func (t *Transaction) AccessList() AccessList {
+	if !t.IsEthTx() {
+		return nil
+	}
+	ethTx, err := t.GetEthTx()
 	if err != nil {
-		return nil, err
+		return nil
 	}
-	return tx, nil
+	return ethTx.AccessList
 }
 
 // ChainTag returns chain tag.
@@ -298,7 +187,7 @@ func (t *Transaction) IsExpired(blockNum uint32) bool {
 }
 
 func (t *Transaction) EthTxValidate() (bool, error) {
-	var ethTx *types.Transaction
+	var ethTx *EthTx
 	var err error
 	var reverseTx *Transaction
 
@@ -308,8 +197,8 @@ func (t *Transaction) EthTxValidate() (bool, error) {
 			return false, err
 		}
 
-		if _, err = ChainIdValidate(ethTx.ChainId()); err != nil {
-			return false, err
+		if ethTx.Type != LegacyTxType && !meter.IsTypedEthTxFork(t.BlockRef().Number()) {
+			return false, fmt.Errorf("eth tx type %d not enabled", ethTx.Type)
 		}
 
 		if reverseTx, err = NewTransactionFromEthTx(ethTx, t.ChainTag(), t.BlockRef()); err != nil {
@@ -496,13 +385,6 @@ func (t *Transaction) Signer() (signer meter.Address, err error) {
 	if len(t.body.Signature) == 0 {
 		return meter.Address{}, nil
 	}
-	if t.IsEthTx() {
-		// ethereum translated tx
-		from := "0x" + hex.EncodeToString(t.body.Reserved[1].([]byte))
-		// fmt.Println("Signer for ETH translated TX:", from)
-		addr, err := meter.ParseAddress(from)
-		return addr, err
-	}
 
 	if cached := t.cache.signer.Load(); cached != nil {
 		return cached.(meter.Address), nil
@@ -513,6 +395,22 @@ func (t *Transaction) Signer() (signer meter.Address, err error) {
 		}
 	}()
 
+	if t.IsEthTx() {
+		// ethereum translated tx, recover from the eth tx, which must match the sender in reserved fields
+		ethTx, err := t.GetEthTx()
+		if err != nil {
+			return meter.Address{}, err
+		}
+		from, err := ethTx.Sender()
+		if err != nil {
+			return meter.Address{}, err
+		}
+		if !bytes.Equal(from.Bytes(), t.body.Reserved[1].([]byte)) {
+			return meter.Address{}, errors.New("eth tx sender mismatch")
+		}
+		return meter.Address(from), nil
+	}
+
 	pub, err := crypto.SigToPub(t.SigningHash().Bytes(), t.body.Signature)
 	if err != nil {
 		return meter.Address{}, err
@@ -583,6 +481,10 @@ func (t *Transaction) IntrinsicGas() (uint64, error) {
 	if err != nil {
 		return 0, err
 	}
+	gas, err = accessListGas(gas, t.AccessList())
+	if err != nil {
+		return 0, err
+	}
 	t.cache.intrinsicGas.Store(gas)
 	return gas, nil
 }
@@ -713,6 +615,26 @@ func IntrinsicGas(clauses ...*Clause) (uint64, error) {
 	return total, nil
 }
 
+// accessListGas adds the gas of the access list to gas, see core.IntrinsicGas
+func accessListGas(gas uint64, accessList AccessList) (uint64, error) {
+	var overflow bool
+	for _, tuple := range accessList {
+		gas, overflow = math.SafeAdd(gas, meter.TxAccessListAddressGas)
+		if overflow {
+			return 0, errIntrinsicGasOverflow
+		}
+		keysGas, overflow := math.SafeMul(uint64(len(tuple.StorageKeys)), meter.TxAccessListStorageKeyGas)
+		if overflow {
+			return 0, errIntrinsicGasOverflow
+		}
+		gas, overflow = math.SafeAdd(gas, keysGas)
+		if overflow {
+			return 0, errIntrinsicGasOverflow
+		}
+	}
+	return gas, nil
+}
+
 // see core.IntrinsicGas
 func dataGas(data []byte) (uint64, error) {
 	if len(data) == 0 {
@@ -0,0 +1,126 @@
+// Copyright (c) 2020 The Meter.io developers
+
+// Distributed under the GNU Lesser General Public License v3.0 software license, see the accompanying
+// file LICENSE or <https://www.gnu.org/licenses/lgpl-3.0.html>
+
+package txpool
+
+import (
+	"sort"
+	"time"
+
+	"github.com/meterio/meter-pov/meter"
+	"github.com/meterio/meter-pov/tx"
+	"github.com/pkg/errors"
+)
+
+// TxStatus describes a tx in the pool, checked against the best block.
+type TxStatus struct {
+	Tx         *tx.Transaction
+	Origin     meter.Address
+	TimeAdded  time.Time
+	Executable bool
+	// Reason why the tx is not executable, empty if executable.
+	// Txs not executable with an error reason will be washed out.
+	Reason string
+}
+
+// Stats pool level statistics.
+type Stats struct {
+	Total           int
+	Executables     int
+	Limit           int
+	LimitPerAccount int
+}
+
+// AccountStats statistics of txs from an origin.
+type AccountStats struct {
+	Origin    meter.Address
+	Total     int
+	Limit     int
+	Remaining int
+}
+
+// Inspect checks txs in the pool against the best block, ordered by the time added.
+// Txs of all origins are returned if origin is nil.
+func (p *TxPool) Inspect(origin *meter.Address) ([]*TxStatus, error) {
+	txObjs := p.all.ToTxObjects()
+	if origin != nil {
+		filtered := txObjs[:0]
+		for _, txObj := range txObjs {
+			if txObj.Origin() == *origin {
+				filtered = append(filtered, txObj)
+			}
+		}
+		txObjs = filtered
+	}
+	sort.Slice(txObjs, func(i, j int) bool {
+		return txObjs[i].timeAdded < txObjs[j].timeAdded
+	})
+	return p.inspect(txObjs)
+}
+
+// InspectTx checks the tx in the pool against the best block. Nil is returned if the tx is not in the pool.
+func (p *TxPool) InspectTx(id meter.Bytes32) (*TxStatus, error) {
+	txObj := p.all.GetByID(id)
+	if txObj == nil {
+		return nil, nil
+	}
+	statuses, err := p.inspect([]*txObject{txObj})
+	if err != nil {
+		return nil, err
+	}
+	return statuses[0], nil
+}
+
+func (p *TxPool) inspect(txObjs []*txObject) ([]*TxStatus, error) {
+	headBlock := p.chain.BestBlock().Header()
+	state, err := p.stateCreator.NewState(headBlock.StateRoot())
+	if err != nil {
+		return nil, errors.WithMessage(err, "new state")
+	}
+
+	statuses := make([]*TxStatus, 0, len(txObjs))
+	for _, txObj := range txObjs {
+		reason, err := txObj.checkExecutable(p.chain, state, headBlock)
+		if err != nil {
+			reason = err.Error()
+		}
+		statuses = append(statuses, &TxStatus{
+			Tx:         txObj.Transaction,
+			Origin:     txObj.Origin(),
+			TimeAdded:  time.Unix(0, txObj.timeAdded),
+			Executable: err == nil && reason == "",
+			Reason:     reason,
+		})
+	}
+	if err := state.Err(); err != nil {
+		return nil, errors.WithMessage(err, "state")
+	}
+	return statuses, nil
+}
+
+// Stats returns statistics of the pool.
+func (p *TxPool) Stats() Stats {
+	return Stats{
+		Total:           p.all.Len(),
+		Executables:     len(p.Executables()),
+		Limit:           p.options.Limit,
+		LimitPerAccount: p.options.LimitPerAccount,
+	}
+}
+
+// AccountStats returns statistics of txs from the origin, against the per account limit.
+func (p *TxPool) AccountStats(origin meter.Address) AccountStats {
+	total := p.all.Quota(origin)
+	remaining := p.options.LimitPerAccount - total
+	if remaining < 0 {
+		remaining = 0
+	}
+	return AccountStats{
+		Origin:    origin,
+		Total:     total,
+		Limit:     p.options.LimitPerAccount,
+		Remaining: remaining,
+	}
+}
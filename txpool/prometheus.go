@@ -0,0 +1,28 @@
+// Copyright (c) 2020 The Meter.io developers
+
+// Distributed under the GNU Lesser General Public License v3.0 software license, see the accompanying
+// file LICENSE or <https://www.gnu.org/licenses/lgpl-3.0.html>
+
+package txpool
+
+import "github.com/prometheus/client_golang/prometheus"
+
+var (
+	txPoolSizeGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "txpool_txs",
+		Help: "Number of txs in pool by executable status after the last wash",
+	}, []string{"status"})
+	txPoolWashDuration = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Name:    "txpool_wash_duration_seconds",
+		Help:    "Time taken to wash the tx pool",
+		Buckets: prometheus.ExponentialBuckets(0.001, 2, 14),
+	})
+	txPoolWashedCounter = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "txpool_washed_total",
+		Help: "Counter of txs washed out of pool by reason",
+	}, []string{"reason"})
+	txPoolWashErrorCounter = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "txpool_wash_errors_total",
+		Help: "Counter of failed washes",
+	})
+)
@@ -46,47 +46,57 @@ func (o *txObject) Origin() meter.Address {
 }
 
 func (o *txObject) Executable(chain *chain.Chain, state *state.State, headBlock *block.Header) (bool, error) {
+	reason, err := o.checkExecutable(chain, state, headBlock)
+	if err != nil {
+		return false, err
+	}
+	return reason == "", nil
+}
+
+// checkExecutable returns the reason why the tx is not executable yet, or empty reason if executable.
+// An error is returned if the tx will never be executable.
+func (o *txObject) checkExecutable(chain *chain.Chain, state *state.State, headBlock *block.Header) (string, error) {
 	switch {
 	case o.Gas() > headBlock.GasLimit():
-		return false, errors.New("gas too large")
+		return "", errors.New("gas too large")
 	case o.IsExpired(headBlock.Number()):
-		return false, errors.New("head block expired")
+		return "", errors.New("head block expired")
 	case o.BlockRef().Number() > headBlock.Number()+uint32(3600*24/meter.BlockInterval):
-		return false, errors.New("block ref out of schedule")
+		return "", errors.New("block ref out of schedule")
 	}
 
 	if _, err := chain.GetTransactionMeta(o.ID(), headBlock.ID()); err != nil {
 		if !chain.IsNotFound(err) {
-			return false, err
+			return "", err
 		}
 	} else {
-		return false, errors.New("known tx")
+		return "", errors.New("known tx")
 	}
 
 	if dep := o.DependsOn(); dep != nil {
 		txMeta, err := chain.GetTransactionMeta(*dep, headBlock.ID())
 		if err != nil {
 			if chain.IsNotFound(err) {
-				return false, nil
+				return "dependency not packed", nil
 			}
-			return false, err
+			return "", err
 		}
 		if txMeta.Reverted {
-			return false, errors.New("dep reverted")
+			return "", errors.New("dep reverted")
 		}
 	}
 
 	if o.BlockRef().Number() > headBlock.Number() {
-		return false, nil
+		return "block ref in the future", nil
 	}
 
 	checkpoint := state.NewCheckpoint()
 	defer state.RevertTo(checkpoint)
 
 	if _, _, _, _, err := o.resolved.BuyGas(state, headBlock.Timestamp()+meter.BlockInterval); err != nil {
-		return false, err
+		return "", err
 	}
-	return true, nil
+	return "", nil
 }
 
 func sortTxObjsByOverallGasPriceDesc(txObjs []*txObject) {
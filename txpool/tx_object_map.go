@@ -111,6 +111,13 @@ func (m *txObjectMap) Fill(txObjs []*txObject) {
 	}
 }
 
+// Quota returns the number of txs from the origin.
+func (m *txObjectMap) Quota(origin meter.Address) int {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+	return m.quota[origin]
+}
+
 func (m *txObjectMap) Len() int {
 	m.lock.RLock()
 	defer m.lock.RUnlock()
@@ -21,6 +21,7 @@ import (
 	"github.com/meterio/meter-pov/state"
 	"github.com/meterio/meter-pov/tx"
 	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
 )
 
 const (
@@ -74,6 +75,11 @@ func GetGlobTxPoolInst() *TxPool {
 // New create a new TxPool instance.
 // Shutdown is required to be called at end.
 func New(chain *chain.Chain, stateCreator *state.Creator, options Options) *TxPool {
+	prometheus.Register(txPoolSizeGauge)
+	prometheus.Register(txPoolWashDuration)
+	prometheus.Register(txPoolWashedCounter)
+	prometheus.Register(txPoolWashErrorCounter)
+
 	pool := &TxPool{
 		options:      options,
 		chain:        chain,
@@ -125,6 +131,7 @@ func (p *TxPool) housekeeping() {
 				startTime := mclock.Now()
 				executables, removed, err := p.wash(headBlock)
 				elapsed := mclock.Now() - startTime
+				txPoolWashDuration.Observe(time.Duration(elapsed).Seconds())
 
 				ctx := []interface{}{
 					"len", poolLen,
@@ -133,6 +140,7 @@ func (p *TxPool) housekeeping() {
 				}
 				if err != nil {
 					ctx = append(ctx, "err", err)
+					txPoolWashErrorCounter.Inc()
 				} else {
 					p.executables.Store(executables)
 				}
@@ -195,6 +203,20 @@ func (p *TxPool) add(newTx *tx.Transaction, rejectNonexecutable bool) error {
 			return err
 		}
 
+		if newTx.IsEthTx() {
+			ethTx, err := newTx.GetEthTx()
+			if err != nil {
+				return badTxError{err.Error()}
+			}
+			// dynamic fee txs must not pay more than max fee per gas, see tx.EthTx.GasPriceCoef
+			if ethTx.Type == tx.DynamicFeeTxType {
+				baseGasPrice := builtin.Params.Native(state).Get(meter.KeyBaseGasPrice)
+				if newTx.GasPrice(baseGasPrice).Cmp(ethTx.GasFeeCap) > 0 {
+					return txRejectedError{"max fee per gas less than gas price"}
+				}
+			}
+		}
+
 		executable, err := txObj.Executable(p.chain, state, headBlock)
 		if err != nil {
 			return txRejectedError{err.Error()}
@@ -297,6 +319,7 @@ func (p *TxPool) wash(headBlock *block.Header) (executables tx.Transactions, rem
 				}
 				removed++
 				p.all.Remove(txObj.ID())
+				txPoolWashedCounter.WithLabelValues("limit").Inc()
 			}
 		} else {
 			for _, id := range toRemove {
@@ -321,6 +344,7 @@ func (p *TxPool) wash(headBlock *block.Header) (executables tx.Transactions, rem
 		// out of lifetime
 		if now > txObj.timeAdded+int64(p.options.MaxLifetime) {
 			toRemove = append(toRemove, txObj.ID())
+			txPoolWashedCounter.WithLabelValues("expired").Inc()
 			log.Debug("tx washed out", "id", txObj.ID(), "err", "out of lifetime")
 			continue
 		}
@@ -328,6 +352,7 @@ func (p *TxPool) wash(headBlock *block.Header) (executables tx.Transactions, rem
 		executable, err := txObj.Executable(p.chain, state, headBlock)
 		if err != nil {
 			toRemove = append(toRemove, txObj.ID())
+			txPoolWashedCounter.WithLabelValues("invalid").Inc()
 			log.Debug("tx washed out", "id", txObj.ID(), "err", err)
 			continue
 		}
@@ -360,10 +385,12 @@ func (p *TxPool) wash(headBlock *block.Header) (executables tx.Transactions, rem
 	if len(executableObjs) > limit {
 		for _, txObj := range nonExecutableObjs {
 			toRemove = append(toRemove, txObj.ID())
+			txPoolWashedCounter.WithLabelValues("limit").Inc()
 			log.Debug("non-executable tx washed out due to pool limit", "id", txObj.ID())
 		}
 		for _, txObj := range executableObjs[limit:] {
 			toRemove = append(toRemove, txObj.ID())
+			txPoolWashedCounter.WithLabelValues("limit").Inc()
 			log.Debug("executable tx washed out due to pool limit", "id", txObj.ID())
 		}
 		executableObjs = executableObjs[:limit]
@@ -371,6 +398,7 @@ func (p *TxPool) wash(headBlock *block.Header) (executables tx.Transactions, rem
 		// executableObjs + nonExecutableObjs over pool limit
 		for _, txObj := range nonExecutableObjs[limit-len(executableObjs):] {
 			toRemove = append(toRemove, txObj.ID())
+			txPoolWashedCounter.WithLabelValues("limit").Inc()
 			log.Debug("non-executable tx washed out due to pool limit", "id", txObj.ID())
 		}
 	}
@@ -392,6 +420,8 @@ func (p *TxPool) wash(headBlock *block.Header) (executables tx.Transactions, rem
 			p.txFeed.Send(&TxEvent{tx, &executable})
 		}
 	})
+	txPoolSizeGauge.WithLabelValues("executable").Set(float64(len(executables)))
+	txPoolSizeGauge.WithLabelValues("non-executable").Set(float64(len(all) - len(executables) - len(toRemove)))
 	log.Debug("in wash", "executables size", len(executables), "non-executables size", len(nonExecutableObjs))
 	return executables, 0, nil
 }
@@ -147,3 +147,47 @@ func TestAdd(t *testing.T) {
 		}
 	}
 }
+
+func TestInspect(t *testing.T) {
+	pool := newPool()
+	defer pool.Close()
+	acc0 := genesis.DevAccounts()[0]
+	acc1 := genesis.DevAccounts()[1]
+
+	tx1 := newTx(pool.chain.Tag(), nil, 21000, tx.BlockRef{}, 100, nil, acc0)
+	tx2 := newTx(pool.chain.Tag(), nil, 21000, tx.NewBlockRef(200), 100, nil, acc0)
+	tx3 := newTx(pool.chain.Tag(), nil, 21000, tx.BlockRef{}, 100, &meter.Bytes32{1}, acc1)
+	for _, tx := range []*tx.Transaction{tx1, tx2, tx3} {
+		assert.Nil(t, pool.Add(tx))
+		// keep the order of time added
+		time.Sleep(time.Millisecond)
+	}
+
+	statuses, err := pool.Inspect(nil)
+	assert.Nil(t, err)
+	assert.Equal(t, 3, len(statuses))
+	assert.Equal(t, tx1.ID(), statuses[0].Tx.ID())
+	assert.True(t, statuses[0].Executable)
+	assert.Equal(t, "", statuses[0].Reason)
+	assert.False(t, statuses[1].Executable)
+	assert.Equal(t, "block ref in the future", statuses[1].Reason)
+	assert.False(t, statuses[2].Executable)
+	assert.Equal(t, "dependency not packed", statuses[2].Reason)
+
+	statuses, err = pool.Inspect(&acc1.Address)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(statuses))
+	assert.Equal(t, tx3.ID(), statuses[0].Tx.ID())
+	assert.Equal(t, acc1.Address, statuses[0].Origin)
+
+	status, err := pool.InspectTx(tx2.ID())
+	assert.Nil(t, err)
+	assert.Equal(t, tx2.ID(), status.Tx.ID())
+	status, err = pool.InspectTx(meter.Bytes32{})
+	assert.Nil(t, err)
+	assert.Nil(t, status)
+
+	assert.Equal(t, Stats{Total: 3, Limit: 10, LimitPerAccount: 2}, pool.Stats())
+	assert.Equal(t, AccountStats{Origin: acc0.Address, Total: 2, Limit: 2, Remaining: 0}, pool.AccountStats(acc0.Address))
+	assert.Equal(t, AccountStats{Origin: acc1.Address, Total: 1, Limit: 2, Remaining: 1}, pool.AccountStats(acc1.Address))
+}
@@ -22,6 +22,7 @@ import (
 	"fmt"
 	"io/ioutil"
 	"math/big"
+	"strings"
 	"testing"
 	"time"
 
@@ -317,3 +318,39 @@ func loadJsonFail(name string) ([]precompiledFailureTest, error) {
 	err = json.Unmarshal(data, &testcases)
 	return testcases, err
 }
+
+// Tests the gas repricing of ModExp by EIP-2565.
+func TestPrecompiledModExpEIP2565(t *testing.T) {
+	tests := []struct {
+		input string
+		gas   uint64
+	}{
+		// 3^3 mod 5 with 1 byte operands, priced at the minimum
+		{"0000000000000000000000000000000000000000000000000000000000000001" +
+			"0000000000000000000000000000000000000000000000000000000000000001" +
+			"0000000000000000000000000000000000000000000000000000000000000001" +
+			"030305", 200},
+		// 64 bytes base and modulus, 32 bytes exponent with the highest bit set:
+		// ceil(64/8)^2 * 255 / 3
+		{"0000000000000000000000000000000000000000000000000000000000000040" +
+			"0000000000000000000000000000000000000000000000000000000000000020" +
+			"0000000000000000000000000000000000000000000000000000000000000040" +
+			strings.Repeat("01", 64) + "80" + strings.Repeat("00", 31) + strings.Repeat("02", 64), 5440},
+		// 1024 bytes base and modulus, 64 bytes exponent with 0xff head:
+		// ceil(1024/8)^2 * (8 * (64 - 32) + 255) / 3
+		{"0000000000000000000000000000000000000000000000000000000000000400" +
+			"0000000000000000000000000000000000000000000000000000000000000040" +
+			"0000000000000000000000000000000000000000000000000000000000000400" +
+			strings.Repeat("01", 1024) + strings.Repeat("ff", 64) + strings.Repeat("02", 1024), 2790741},
+	}
+	p := PrecompiledContractsBerlin[common.BytesToAddress([]byte{5})]
+	for i, test := range tests {
+		if gas := p.RequiredGas(common.Hex2Bytes(test.input)); gas != test.gas {
+			t.Errorf("Testcase %d, expected gas %v, got %v", i, test.gas, gas)
+		}
+	}
+	// the same input priced before Berlin
+	if gas := PrecompiledContractsIstanbul[common.BytesToAddress([]byte{5})].RequiredGas(common.Hex2Bytes(tests[1].input)); gas == tests[1].gas {
+		t.Errorf("expected different gas before EIP-2565, got %v", gas)
+	}
+}
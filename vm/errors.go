@@ -25,4 +25,7 @@ var (
 	ErrTraceLimitReached        = errors.New("the number of logs reached the specified limit")
 	ErrInsufficientBalance      = errors.New("insufficient balance for transfer")
 	ErrContractAddressCollision = errors.New("contract address collision")
+	ErrMaxInitCodeSizeExceeded  = errors.New("max initcode size exceeded")
+	ErrInvalidCode              = errors.New("invalid code: must not begin with 0xef")
+	ErrExecutionReverted        = errors.New("evm: execution reverted")
 )
@@ -52,19 +52,27 @@ type (
 // run runs the given contract and takes care of running precompiles with a fallback to the byte code interpreter.
 func run(evm *EVM, contract *Contract, input []byte) ([]byte, error) {
 	if contract.CodeAddr != nil {
-		precompiles := PrecompiledContractsHomestead
-		if evm.ChainConfig().IsIstanbul(evm.BlockNumber) {
-			precompiles = PrecompiledContractsIstanbul
-		} else if evm.ChainConfig().IsByzantium(evm.BlockNumber) {
-			precompiles = PrecompiledContractsByzantium
-		}
-		if p := precompiles[*contract.CodeAddr]; p != nil {
+		if p := evm.precompiles()[*contract.CodeAddr]; p != nil {
 			return RunPrecompiledContract(p, input, contract)
 		}
 	}
 	return evm.interpreter.Run(contract, input)
 }
 
+// precompiles returns the precompiled contracts active at the current block.
+func (evm *EVM) precompiles() map[common.Address]PrecompiledContract {
+	switch {
+	case evm.ChainConfig().IsBerlin(evm.BlockNumber):
+		return PrecompiledContractsBerlin
+	case evm.ChainConfig().IsIstanbul(evm.BlockNumber):
+		return PrecompiledContractsIstanbul
+	case evm.ChainConfig().IsByzantium(evm.BlockNumber):
+		return PrecompiledContractsByzantium
+	default:
+		return PrecompiledContractsHomestead
+	}
+}
+
 // Context provides the EVM with auxiliary information. Once provided
 // it shouldn't be modified.
 type Context struct {
@@ -91,6 +99,7 @@ type Context struct {
 	BlockNumber *big.Int       // Provides information for NUMBER
 	Time        *big.Int       // Provides information for TIME
 	Difficulty  *big.Int       // Provides information for DIFFICULTY
+	BaseFee     *big.Int       // Provides information for BASEFEE
 }
 
 // EVM is the Ethereum Virtual Machine base object and provides
@@ -200,13 +209,7 @@ func (evm *EVM) call(caller ContractRef, addr common.Address, input []byte, gas
 		snapshot = evm.StateDB.Snapshot()
 	)
 	if !evm.StateDB.Exist(addr) {
-		precompiles := PrecompiledContractsHomestead
-		if evm.ChainConfig().IsIstanbul(evm.BlockNumber) {
-			precompiles = PrecompiledContractsIstanbul
-		} else if evm.ChainConfig().IsByzantium(evm.BlockNumber) {
-			precompiles = PrecompiledContractsByzantium
-		}
-		if precompiles[addr] == nil && evm.ChainConfig().IsEIP158(evm.BlockNumber) && value.Sign() == 0 {
+		if evm.precompiles()[addr] == nil && evm.ChainConfig().IsEIP158(evm.BlockNumber) && value.Sign() == 0 {
 			return nil, gas, nil
 		}
 		evm.StateDB.CreateAccount(addr)
@@ -225,7 +228,7 @@ func (evm *EVM) call(caller ContractRef, addr common.Address, input []byte, gas
 	// when we're in homestead this also counts for code storage gas errors.
 	if err != nil {
 		evm.StateDB.RevertToSnapshot(snapshot)
-		if err != errExecutionReverted {
+		if err != ErrExecutionReverted {
 			contract.UseGas(contract.Gas)
 		}
 	}
@@ -266,7 +269,7 @@ func (evm *EVM) CallCode(caller ContractRef, addr common.Address, input []byte,
 	ret, err = run(evm, contract, input)
 	if err != nil {
 		evm.StateDB.RevertToSnapshot(snapshot)
-		if err != errExecutionReverted {
+		if err != ErrExecutionReverted {
 			contract.UseGas(contract.Gas)
 		}
 	}
@@ -299,7 +302,7 @@ func (evm *EVM) DelegateCall(caller ContractRef, addr common.Address, input []by
 	ret, err = run(evm, contract, input)
 	if err != nil {
 		evm.StateDB.RevertToSnapshot(snapshot)
-		if err != errExecutionReverted {
+		if err != ErrExecutionReverted {
 			contract.UseGas(contract.Gas)
 		}
 	}
@@ -342,7 +345,7 @@ func (evm *EVM) StaticCall(caller ContractRef, addr common.Address, input []byte
 	ret, err = run(evm, contract, input)
 	if err != nil {
 		evm.StateDB.RevertToSnapshot(snapshot)
-		if err != errExecutionReverted {
+		if err != ErrExecutionReverted {
 			contract.UseGas(contract.Gas)
 		}
 	}
@@ -375,6 +378,9 @@ func (evm *EVM) create(caller ContractRef, code []byte, gas uint64, value *big.I
 	if !evm.CanTransfer(evm.StateDB, caller.Address(), value, token) {
 		return nil, common.Address{}, gas, ErrInsufficientBalance
 	}
+	if evm.ChainConfig().IsShanghai(evm.BlockNumber) && len(code) > MaxInitCodeSize {
+		return nil, common.Address{}, gas, ErrMaxInitCodeSizeExceeded
+	}
 	nonce := evm.StateDB.GetNonce(caller.Address())
 	evm.StateDB.SetNonce(caller.Address(), nonce+1)
 
@@ -382,6 +388,12 @@ func (evm *EVM) create(caller ContractRef, code []byte, gas uint64, value *big.I
 	// We already have address, just need to increase the counter.
 	evm.contractCreationCount++
 
+	// We add this to the access list _before_ taking a snapshot. Even if the creation fails,
+	// the access-list change should not be rolled back
+	if evm.ChainConfig().IsBerlin(evm.BlockNumber) {
+		evm.StateDB.AddAddressToAccessList(contractAddr)
+	}
+
 	// Ensure there's no existing contract already at the designated address
 	contractHash := evm.StateDB.GetCodeHash(contractAddr)
 	if evm.StateDB.GetNonce(contractAddr) != 0 || (contractHash != (common.Hash{}) && contractHash != EmptyCodeHash) {
@@ -415,6 +427,11 @@ func (evm *EVM) create(caller ContractRef, code []byte, gas uint64, value *big.I
 
 	// check whether the max code size has been exceeded
 	maxCodeSizeExceeded := evm.ChainConfig().IsEIP158(evm.BlockNumber) && len(ret) > params.MaxCodeSize
+
+	// reject code starting with 0xEF, see EIP-3541
+	if err == nil && len(ret) >= 1 && ret[0] == 0xEF && evm.ChainConfig().IsLondon(evm.BlockNumber) {
+		err = ErrInvalidCode
+	}
 	// if the contract creation ran successfully and no errors were returned
 	// calculate the gas required to store the code. If the code could not
 	// be stored due to not enough gas set an error and let it be handled
@@ -433,7 +450,7 @@ func (evm *EVM) create(caller ContractRef, code []byte, gas uint64, value *big.I
 	// when we're in homestead this also counts for code storage gas errors.
 	if maxCodeSizeExceeded || (err != nil && (evm.ChainConfig().IsHomestead(evm.BlockNumber) || err != ErrCodeStoreOutOfGas)) {
 		evm.StateDB.RevertToSnapshot(snapshot)
-		if err != errExecutionReverted {
+		if err != ErrExecutionReverted {
 			contract.UseGas(contract.Gas)
 		}
 	}
@@ -457,6 +474,25 @@ func (evm *EVM) Create2(caller ContractRef, code []byte, gas uint64, endowment *
 	return evm.create(caller, code, gas, endowment, token, contractAddr) // endowment is value.
 }
 
+// PrepareAccessList warms up the addresses touched by the execution at the beginning, see EIP-2929.
+// The sender, the destination and the precompiled contracts are added, and the coinbase
+// is also added since Shanghai, see EIP-3651.
+func (evm *EVM) PrepareAccessList(sender common.Address, dest *common.Address) {
+	if !evm.ChainConfig().IsBerlin(evm.BlockNumber) {
+		return
+	}
+	evm.StateDB.AddAddressToAccessList(sender)
+	if dest != nil {
+		evm.StateDB.AddAddressToAccessList(*dest)
+	}
+	for addr := range evm.precompiles() {
+		evm.StateDB.AddAddressToAccessList(addr)
+	}
+	if evm.ChainConfig().IsShanghai(evm.BlockNumber) {
+		evm.StateDB.AddAddressToAccessList(evm.Coinbase)
+	}
+}
+
 // ChainConfig returns the environment's chain configuration
 func (evm *EVM) ChainConfig() *ChainConfig { return evm.chainConfig }
 
@@ -35,6 +35,26 @@ const (
 	GasContractByte uint64 = 200
 )
 
+// gas costs introduced by evm upgrades, which are not available in go-ethereum params.
+const (
+	ColdAccountAccessCostEIP2929 uint64 = 2600 // COLD_ACCOUNT_ACCESS_COST
+	ColdSloadCostEIP2929         uint64 = 2100 // COLD_SLOAD_COST
+	WarmStorageReadCostEIP2929   uint64 = 100  // WARM_STORAGE_READ_COST
+
+	SstoreSentryGasEIP2200            uint64 = 2300  // Minimum gas required to be present for an SSTORE call, not consumed
+	SstoreSetGasEIP2200               uint64 = 20000 // Once per SSTORE operation from clean zero to non-zero
+	SstoreResetGasEIP2200             uint64 = 5000  // Once per SSTORE operation from clean non-zero to something else
+	SstoreClearsScheduleRefundEIP2200 uint64 = 15000 // Once per SSTORE operation for clearing an originally existing storage slot
+	// SSTORE_RESET_GAS - COLD_SLOAD_COST + ACCESS_LIST_STORAGE_KEY_COST, see EIP-3529
+	SstoreClearsScheduleRefundEIP3529 uint64 = SstoreResetGasEIP2200 - ColdSloadCostEIP2929 + 1900
+
+	RefundQuotient        uint64 = 2 // Maximum refund quotient, the refund is capped to gasUsed / RefundQuotient
+	RefundQuotientEIP3529 uint64 = 5 // Maximum refund quotient after EIP-3529
+
+	InitCodeWordGas uint64 = 2                      // Once per word of the init code when creating a contract, see EIP-3860
+	MaxInitCodeSize        = 2 * params.MaxCodeSize // Maximum init code size allowed for contract creation, see EIP-3860
+)
+
 // calcGas returns the actual gas cost of the call.
 //
 // The cost of gas was changed during the homestead price change HF. To allow for EIP150
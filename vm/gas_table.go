@@ -17,6 +17,8 @@
 package vm
 
 import (
+	"errors"
+
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/math"
 	"github.com/ethereum/go-ethereum/params"
@@ -486,3 +488,219 @@ func gasSwap(gt params.GasTable, evm *EVM, contract *Contract, stack *Stack, mem
 func gasDup(gt params.GasTable, evm *EVM, contract *Contract, stack *Stack, mem *Memory, memorySize uint64) (uint64, error) {
 	return GasFastestStep, nil
 }
+
+// gasSLoadEIP2929 calculates dynamic gas for SLOAD according to EIP-2929.
+// For SLOAD, if the (address, storage_key) pair is not yet in accessed_storage_keys,
+// charge 2100 gas and add the pair to accessed_storage_keys.
+// If the pair is already in accessed_storage_keys, charge 100 gas.
+func gasSLoadEIP2929(gt params.GasTable, evm *EVM, contract *Contract, stack *Stack, mem *Memory, memorySize uint64) (uint64, error) {
+	slot := common.BigToHash(stack.peek())
+	// Check slot presence in the access list
+	if _, slotPresent := evm.StateDB.SlotInAccessList(contract.Address(), slot); !slotPresent {
+		// If the caller cannot afford the cost, this change will be rolled back
+		evm.StateDB.AddSlotToAccessList(contract.Address(), slot)
+		return ColdSloadCostEIP2929, nil
+	}
+	return WarmStorageReadCostEIP2929, nil
+}
+
+// makeGasSStoreFunc creates the SSTORE gas function of EIP-2929, which applies on top of the
+// net gas metering of EIP-2200, with the refund of clearing a slot given, see EIP-3529.
+func makeGasSStoreFunc(clearingRefund uint64) gasFunc {
+	return func(gt params.GasTable, evm *EVM, contract *Contract, stack *Stack, mem *Memory, memorySize uint64) (uint64, error) {
+		// If we fail the minimum gas availability invariant, fail (0)
+		if contract.Gas <= SstoreSentryGasEIP2200 {
+			return 0, errors.New("not enough gas for reentrancy sentry")
+		}
+		// Gas sentry honoured, do the actual gas calculation based on the stored value
+		var (
+			y, x    = stack.Back(1), stack.peek()
+			slot    = common.BigToHash(x)
+			current = evm.StateDB.GetState(contract.Address(), slot)
+			cost    = uint64(0)
+		)
+		// Check slot presence in the access list
+		if _, slotPresent := evm.StateDB.SlotInAccessList(contract.Address(), slot); !slotPresent {
+			cost = ColdSloadCostEIP2929
+			// If the caller cannot afford the cost, this change will be rolled back
+			evm.StateDB.AddSlotToAccessList(contract.Address(), slot)
+		}
+		value := common.BigToHash(y)
+
+		if current == value { // noop (1)
+			// EIP 2200 original clause:
+			//		return params.SloadGasEIP2200, nil
+			return cost + WarmStorageReadCostEIP2929, nil // SLOAD_GAS
+		}
+		original := evm.StateDB.GetCommittedState(contract.Address(), slot)
+		if original == current {
+			if original == (common.Hash{}) { // create slot (2.1.1)
+				return cost + SstoreSetGasEIP2200, nil
+			}
+			if value == (common.Hash{}) { // delete slot (2.1.2b)
+				evm.StateDB.AddRefund(clearingRefund)
+			}
+			// EIP-2200 original clause:
+			//		return params.SstoreResetGasEIP2200, nil // write existing slot (2.1.2)
+			return cost + (SstoreResetGasEIP2200 - ColdSloadCostEIP2929), nil // write existing slot (2.1.2)
+		}
+		if original != (common.Hash{}) {
+			if current == (common.Hash{}) { // recreate slot (2.2.1.1)
+				evm.StateDB.SubRefund(clearingRefund)
+			} else if value == (common.Hash{}) { // delete slot (2.2.1.2)
+				evm.StateDB.AddRefund(clearingRefund)
+			}
+		}
+		if original == value {
+			if original == (common.Hash{}) { // reset to original inexistent slot (2.2.2.1)
+				// EIP 2200 Original clause:
+				//evm.StateDB.AddRefund(params.SstoreSetGasEIP2200 - params.SloadGasEIP2200)
+				evm.StateDB.AddRefund(SstoreSetGasEIP2200 - WarmStorageReadCostEIP2929)
+			} else { // reset to original existing slot (2.2.2.2)
+				// EIP 2200 Original clause:
+				//	evm.StateDB.AddRefund(params.SstoreResetGasEIP2200 - params.SloadGasEIP2200)
+				// - SSTORE_RESET_GAS redefined as (5000 - COLD_SLOAD_COST)
+				// - SLOAD_GAS redefined as WARM_STORAGE_READ_COST
+				// Final: (5000 - COLD_SLOAD_COST) - WARM_STORAGE_READ_COST
+				evm.StateDB.AddRefund((SstoreResetGasEIP2200 - ColdSloadCostEIP2929) - WarmStorageReadCostEIP2929)
+			}
+		}
+		// EIP-2200 original clause:
+		//return params.SloadGasEIP2200, nil // dirty update (2.2)
+		return cost + WarmStorageReadCostEIP2929, nil // dirty update (2.2)
+	}
+}
+
+var (
+	gasSStoreEIP2929 = makeGasSStoreFunc(SstoreClearsScheduleRefundEIP2200)
+	gasSStoreEIP3529 = makeGasSStoreFunc(SstoreClearsScheduleRefundEIP3529)
+)
+
+// makeGasAccountCheckEIP2929 wraps the gas function of an opcode which touches the account
+// at the top of the stack, i.e. BALANCE, EXTCODESIZE, EXTCODEHASH and EXTCODECOPY.
+// The warm access cost is already charged by the gas table, so the cold surcharge is added
+// if the address is not in the access list yet.
+func makeGasAccountCheckEIP2929(oldCalculator gasFunc) gasFunc {
+	return func(gt params.GasTable, evm *EVM, contract *Contract, stack *Stack, mem *Memory, memorySize uint64) (uint64, error) {
+		addr := common.BigToAddress(stack.peek())
+		gas, err := oldCalculator(gt, evm, contract, stack, mem, memorySize)
+		if err != nil {
+			return 0, err
+		}
+		// Check address presence in the access list
+		if !evm.StateDB.AddressInAccessList(addr) {
+			// If the caller cannot afford the cost, this change will be rolled back
+			evm.StateDB.AddAddressToAccessList(addr)
+			var overflow bool
+			if gas, overflow = math.SafeAdd(gas, ColdAccountAccessCostEIP2929-WarmStorageReadCostEIP2929); overflow {
+				return 0, errGasUintOverflow
+			}
+		}
+		return gas, nil
+	}
+}
+
+// makeCallVariantGasCallEIP2929 wraps the gas function of CALL, CALLCODE, DELEGATECALL and STATICCALL.
+func makeCallVariantGasCallEIP2929(oldCalculator gasFunc) gasFunc {
+	return func(gt params.GasTable, evm *EVM, contract *Contract, stack *Stack, mem *Memory, memorySize uint64) (uint64, error) {
+		addr := common.BigToAddress(stack.Back(1))
+		// Check address presence in the access list
+		warmAccess := evm.StateDB.AddressInAccessList(addr)
+		// The WarmStorageReadCostEIP2929 (100) is already charged by the gas table, so
+		// the cost to charge for cold access, if any, is Cold - Warm
+		coldCost := ColdAccountAccessCostEIP2929 - WarmStorageReadCostEIP2929
+		if !warmAccess {
+			evm.StateDB.AddAddressToAccessList(addr)
+			// Charge the remaining difference here already, to correctly calculate available
+			// gas for call
+			if !contract.UseGas(coldCost) {
+				return 0, ErrOutOfGas
+			}
+		}
+		// Now call the old calculator, which takes into account
+		// - create new account
+		// - transfer value
+		// - memory expansion
+		// - 63/64ths rule
+		gas, err := oldCalculator(gt, evm, contract, stack, mem, memorySize)
+		if warmAccess || err != nil {
+			return gas, err
+		}
+		// In case of a cold access, we temporarily add the cold charge back, and also
+		// add it to the returned gas. By adding it to the return, it will be charged
+		// by the interpreter, and that will make it also become correctly reported to tracers.
+		contract.Gas += coldCost
+
+		var overflow bool
+		if gas, overflow = math.SafeAdd(gas, coldCost); overflow {
+			return 0, errGasUintOverflow
+		}
+		return gas, nil
+	}
+}
+
+var (
+	gasBalanceEIP2929      = makeGasAccountCheckEIP2929(gasBalance)
+	gasExtCodeSizeEIP2929  = makeGasAccountCheckEIP2929(gasExtCodeSize)
+	gasExtCodeHashEIP2929  = makeGasAccountCheckEIP2929(gasExtCodeHash)
+	gasExtCodeCopyEIP2929  = makeGasAccountCheckEIP2929(gasExtCodeCopy)
+	gasCallEIP2929         = makeCallVariantGasCallEIP2929(gasCall)
+	gasCallCodeEIP2929     = makeCallVariantGasCallEIP2929(gasCallCode)
+	gasDelegateCallEIP2929 = makeCallVariantGasCallEIP2929(gasDelegateCall)
+	gasStaticCallEIP2929   = makeCallVariantGasCallEIP2929(gasStaticCall)
+)
+
+// makeSelfdestructGasFn creates the SELFDESTRUCT gas function of EIP-2929,
+// and the refund is removed by EIP-3529.
+func makeSelfdestructGasFn(refundsEnabled bool) gasFunc {
+	return func(gt params.GasTable, evm *EVM, contract *Contract, stack *Stack, mem *Memory, memorySize uint64) (uint64, error) {
+		var (
+			gas     = gt.Suicide
+			address = common.BigToAddress(stack.peek())
+		)
+		if !evm.StateDB.AddressInAccessList(address) {
+			// If the caller cannot afford the cost, this change will be rolled back
+			evm.StateDB.AddAddressToAccessList(address)
+			gas += ColdAccountAccessCostEIP2929
+		}
+		// if empty and transfers value
+		if evm.StateDB.Empty(address) && evm.StateDB.GetBalance(contract.Address()).Sign() != 0 {
+			gas += gt.CreateBySuicide
+		}
+		if refundsEnabled && !evm.StateDB.HasSuicided(contract.Address()) {
+			evm.StateDB.AddRefund(params.SuicideRefundGas)
+		}
+		return gas, nil
+	}
+}
+
+var (
+	gasSelfdestructEIP2929 = makeSelfdestructGasFn(true)
+	gasSelfdestructEIP3529 = makeSelfdestructGasFn(false)
+)
+
+// makeGasCreateEIP3860 charges the init code of CREATE and CREATE2 per word, and
+// limits its size, see EIP-3860.
+func makeGasCreateEIP3860(oldCalculator gasFunc) gasFunc {
+	return func(gt params.GasTable, evm *EVM, contract *Contract, stack *Stack, mem *Memory, memorySize uint64) (uint64, error) {
+		gas, err := oldCalculator(gt, evm, contract, stack, mem, memorySize)
+		if err != nil {
+			return 0, err
+		}
+		size, overflow := bigUint64(stack.Back(2))
+		if overflow || size > MaxInitCodeSize {
+			return 0, errGasUintOverflow
+		}
+		// Since size <= MaxInitCodeSize, this multiplication cannot overflow
+		moreGas := InitCodeWordGas * toWordSize(size)
+		if gas, overflow = math.SafeAdd(gas, moreGas); overflow {
+			return 0, errGasUintOverflow
+		}
+		return gas, nil
+	}
+}
+
+var (
+	gasCreateEIP3860  = makeGasCreateEIP3860(gasCreate)
+	gasCreate2EIP3860 = makeGasCreateEIP3860(gasCreate2)
+)
@@ -504,3 +504,69 @@ func TestCreate2Addreses(t *testing.T) {
 
 	}
 }
+
+func TestOpPush0(t *testing.T) {
+	var (
+		env   = NewEVM(Context{}, nil, &ChainConfig{ChainConfig: *params.TestChainConfig}, Config{})
+		stack = newstack()
+		pc    = uint64(0)
+	)
+	opPush0(&pc, env, nil, nil, stack)
+	if actual := stack.pop(); actual.Sign() != 0 {
+		t.Errorf("expected 0, got %v", actual)
+	}
+}
+
+func TestOpBaseFee(t *testing.T) {
+	tests := []struct {
+		baseFee  *big.Int
+		expected *big.Int
+	}{
+		{nil, big.NewInt(0)},
+		{big.NewInt(5e11), big.NewInt(5e11)},
+	}
+	for i, test := range tests {
+		var (
+			env   = NewEVM(Context{BaseFee: test.baseFee}, nil, &ChainConfig{ChainConfig: *params.TestChainConfig}, Config{})
+			stack = newstack()
+			pc    = uint64(0)
+		)
+		opBaseFee(&pc, env, nil, nil, stack)
+		if actual := stack.pop(); actual.Cmp(test.expected) != 0 {
+			t.Errorf("Testcase %d, expected %v, got %v", i, test.expected, actual)
+		}
+	}
+}
+
+func TestInstructionSetForks(t *testing.T) {
+	config := &ChainConfig{
+		ChainConfig:   *params.TestChainConfig,
+		IstanbulBlock: big.NewInt(0),
+		BerlinBlock:   big.NewInt(10),
+		LondonBlock:   big.NewInt(20),
+		ShanghaiBlock: big.NewInt(30),
+	}
+	tests := []struct {
+		number         int64
+		baseFee, push0 bool
+		sloadGas       uint64
+	}{
+		{0, false, false, params.GasTableEIP158.SLoad},
+		{10, false, false, WarmStorageReadCostEIP2929},
+		{20, true, false, WarmStorageReadCostEIP2929},
+		{30, true, true, WarmStorageReadCostEIP2929},
+	}
+	for i, test := range tests {
+		env := NewEVM(Context{BlockNumber: big.NewInt(test.number)}, nil, config, Config{})
+		jt := env.interpreter.cfg.JumpTable
+		if jt[BASEFEE].valid != test.baseFee {
+			t.Errorf("Testcase %d, BASEFEE valid expected %v", i, test.baseFee)
+		}
+		if jt[PUSH0].valid != test.push0 {
+			t.Errorf("Testcase %d, PUSH0 valid expected %v", i, test.push0)
+		}
+		if env.interpreter.gasTable.SLoad != test.sloadGas {
+			t.Errorf("Testcase %d, SLOAD gas expected %v, got %v", i, test.sloadGas, env.interpreter.gasTable.SLoad)
+		}
+	}
+}
@@ -62,6 +62,12 @@ func NewInterpreter(evm *EVM, cfg Config) *Interpreter {
 	// we'll set the default jump table.
 	if !cfg.JumpTable[STOP].valid {
 		switch {
+		case evm.ChainConfig().IsShanghai(evm.BlockNumber):
+			cfg.JumpTable = shanghaiInstructionSet
+		case evm.ChainConfig().IsLondon(evm.BlockNumber):
+			cfg.JumpTable = londonInstructionSet
+		case evm.ChainConfig().IsBerlin(evm.BlockNumber):
+			cfg.JumpTable = berlinInstructionSet
 		case evm.ChainConfig().IsIstanbul(evm.BlockNumber):
 			cfg.JumpTable = istanbulInstructionSet
 		case evm.ChainConfig().IsConstantinople(evm.BlockNumber):
@@ -75,10 +81,22 @@ func NewInterpreter(evm *EVM, cfg Config) *Interpreter {
 		}
 	}
 
+	gasTable := evm.ChainConfig().GasTable(evm.BlockNumber)
+	if evm.ChainConfig().IsBerlin(evm.BlockNumber) {
+		// the warm access cost is charged here, and the cold access surcharge
+		// by the gas functions of EIP-2929.
+		gasTable.Balance = WarmStorageReadCostEIP2929
+		gasTable.ExtcodeSize = WarmStorageReadCostEIP2929
+		gasTable.ExtcodeCopy = WarmStorageReadCostEIP2929
+		gasTable.ExtcodeHash = WarmStorageReadCostEIP2929
+		gasTable.SLoad = WarmStorageReadCostEIP2929
+		gasTable.Calls = WarmStorageReadCostEIP2929
+	}
+
 	return &Interpreter{
 		evm:      evm,
 		cfg:      cfg,
-		gasTable: evm.ChainConfig().GasTable(evm.BlockNumber),
+		gasTable: gasTable,
 		intPool:  newIntPool(),
 	}
 }
@@ -104,7 +122,7 @@ func (in *Interpreter) enforceRestrictions(op OpCode, operation operation, stack
 //
 // It's important to note that any errors returned by the interpreter should be
 // considered a revert-and-consume-all-gas operation except for
-// errExecutionReverted which means revert-and-keep-gas-left.
+// ErrExecutionReverted which means revert-and-keep-gas-left.
 func (in *Interpreter) Run(contract *Contract, input []byte) (ret []byte, err error) {
 	// Increment the call depth which is restricted to 1024
 	in.evm.depth++
@@ -228,7 +246,7 @@ func (in *Interpreter) Run(contract *Contract, input []byte) (ret []byte, err er
 		case err != nil:
 			return nil, err
 		case operation.reverts:
-			return res, errExecutionReverted
+			return res, ErrExecutionReverted
 		case operation.halts:
 			return res, nil
 		case !operation.jumps:
@@ -56,8 +56,66 @@ var (
 	byzantiumInstructionSet      = NewByzantiumInstructionSet()
 	constantinopleInstructionSet = NewConstantinopleInstructionSet()
 	istanbulInstructionSet       = NewIstanbulInstructionSet()
+	berlinInstructionSet         = NewBerlinInstructionSet()
+	londonInstructionSet         = NewLondonInstructionSet()
+	shanghaiInstructionSet       = NewShanghaiInstructionSet()
 )
 
+// NewShanghaiInstructionSet returns the frontier, homestead, byzantium,
+// constantinople, istanbul, berlin, london and shanghai instructions.
+func NewShanghaiInstructionSet() [256]operation {
+	instructionSet := NewLondonInstructionSet()
+	// PUSH0 opcode, see EIP-3855
+	instructionSet[PUSH0] = operation{
+		execute:       opPush0,
+		gasCost:       constGasFunc(GasQuickStep),
+		validateStack: makeStackFunc(0, 1),
+		valid:         true,
+	}
+
+	// limit and meter init code, see EIP-3860
+	instructionSet[CREATE].gasCost = gasCreateEIP3860
+	instructionSet[CREATE2].gasCost = gasCreate2EIP3860
+	return instructionSet
+}
+
+// NewLondonInstructionSet returns the frontier, homestead, byzantium,
+// constantinople, istanbul, berlin and london instructions.
+func NewLondonInstructionSet() [256]operation {
+	instructionSet := NewBerlinInstructionSet()
+	// BASEFEE opcode, see EIP-3198
+	instructionSet[BASEFEE] = operation{
+		execute:       opBaseFee,
+		gasCost:       constGasFunc(GasQuickStep),
+		validateStack: makeStackFunc(0, 1),
+		valid:         true,
+	}
+
+	// reduced refunds, see EIP-3529
+	instructionSet[SSTORE].gasCost = gasSStoreEIP3529
+	instructionSet[SELFDESTRUCT].gasCost = gasSelfdestructEIP3529
+	return instructionSet
+}
+
+// NewBerlinInstructionSet returns the frontier, homestead, byzantium,
+// constantinople, istanbul and berlin instructions.
+func NewBerlinInstructionSet() [256]operation {
+	instructionSet := NewIstanbulInstructionSet()
+	// gas cost increases for state access opcodes, see EIP-2929
+	instructionSet[SLOAD].gasCost = gasSLoadEIP2929
+	instructionSet[SSTORE].gasCost = gasSStoreEIP2929
+	instructionSet[BALANCE].gasCost = gasBalanceEIP2929
+	instructionSet[EXTCODESIZE].gasCost = gasExtCodeSizeEIP2929
+	instructionSet[EXTCODECOPY].gasCost = gasExtCodeCopyEIP2929
+	instructionSet[EXTCODEHASH].gasCost = gasExtCodeHashEIP2929
+	instructionSet[CALL].gasCost = gasCallEIP2929
+	instructionSet[CALLCODE].gasCost = gasCallCodeEIP2929
+	instructionSet[DELEGATECALL].gasCost = gasDelegateCallEIP2929
+	instructionSet[STATICCALL].gasCost = gasStaticCallEIP2929
+	instructionSet[SELFDESTRUCT].gasCost = gasSelfdestructEIP2929
+	return instructionSet
+}
+
 // IstanbulInstruction
 func NewIstanbulInstructionSet() [256]operation {
 	instructionSet := NewConstantinopleInstructionSet()
@@ -6,6 +6,7 @@
 package xenv
 
 import (
+	"encoding/binary"
 	"encoding/hex"
 	"fmt"
 	"math/big"
@@ -30,6 +31,7 @@ type BlockContext struct {
 	Time        uint64
 	GasLimit    uint64
 	TotalScore  uint64
+	Epoch       uint64 // epoch of the parent block
 }
 
 // TransactionContext transaction context.
@@ -41,6 +43,7 @@ type TransactionContext struct {
 	BlockRef   tx.BlockRef
 	Expiration uint32
 	Nonce      uint64
+	AccessList tx.AccessList // of eth txs, warmed before each clause
 }
 
 func (ctx *TransactionContext) String() string {
@@ -92,6 +95,11 @@ func (env *Environment) UseGas(gas uint64) {
 	}
 }
 
+// Gas returns the gas left for the native method.
+func (env *Environment) Gas() uint64 {
+	return env.contract.Gas
+}
+
 func (env *Environment) ParseArgs(val interface{}) {
 	if err := env.abi.DecodeInput(env.contract.Input, val); err != nil {
 		// as vm error
@@ -99,15 +107,27 @@ func (env *Environment) ParseArgs(val interface{}) {
 	}
 }
 
+// ParseArgsOrFail parses the args like ParseArgs, but malformed input fails the call instead,
+// for natives callable by any contract.
+func (env *Environment) ParseArgsOrFail(val interface{}) {
+	if err := env.abi.DecodeInput(env.contract.Input, val); err != nil {
+		panic(&vmError{errors.WithMessage(err, "decode native input")})
+	}
+}
+
 func (env *Environment) Log(abi *abi.Event, address meter.Address, topics []meter.Bytes32, args ...interface{}) {
 	data, err := abi.Encode(args...)
 	if err != nil {
 		panic(errors.WithMessage(err, "encode native event"))
 	}
+	env.RawLog(address, append([]meter.Bytes32{abi.ID()}, topics...), data)
+}
+
+// RawLog adds the log with encoded topics and data.
+func (env *Environment) RawLog(address meter.Address, topics []meter.Bytes32, data []byte) {
 	env.UseGas(ethparams.LogGas + ethparams.LogTopicGas*uint64(len(topics)) + ethparams.LogDataGas*uint64(len(data)))
 
-	ethTopics := make([]common.Hash, 0, len(topics)+1)
-	ethTopics = append(ethTopics, common.Hash(abi.ID()))
+	ethTopics := make([]common.Hash, 0, len(topics))
 	for _, t := range topics {
 		ethTopics = append(ethTopics, common.Hash(t))
 	}
@@ -118,11 +138,38 @@ func (env *Environment) Log(abi *abi.Event, address meter.Address, topics []mete
 	})
 }
 
+// Transfer records the transfer, if transfers are recorded by the state db.
+func (env *Environment) Transfer(transfer *tx.Transfer) {
+	if db, ok := env.evm.StateDB.(interface{ AddTransfer(*tx.Transfer) }); ok {
+		db.AddTransfer(transfer)
+	}
+}
+
+// vmError is returned as the error of the call, which consumes all the gas.
+type vmError struct {
+	err error
+}
+
+// revertError carries the reason of Revert.
+type revertError struct {
+	reason string
+}
+
+// Revert aborts the native method, state changes are reverted and the gas left is kept.
+// The reason is returned to the caller as Error(string), like revert in solidity.
+func (env *Environment) Revert(reason string) {
+	panic(&revertError{reason})
+}
+
 func (env *Environment) Call(proc func(env *Environment) []interface{}) (output []byte, err error) {
 	defer func() {
 		if e := recover(); e != nil {
 			if e == vm.ErrOutOfGas {
 				err = vm.ErrOutOfGas
+			} else if ve, ok := e.(*vmError); ok {
+				err = ve.err
+			} else if r, ok := e.(*revertError); ok {
+				output, err = encodeRevert(r.reason), vm.ErrExecutionReverted
 			} else {
 				panic(e)
 			}
@@ -134,3 +181,16 @@ func (env *Environment) Call(proc func(env *Environment) []interface{}) (output
 	}
 	return data, nil
 }
+
+// encodeRevert encodes the revert reason as Error(string).
+func encodeRevert(reason string) []byte {
+	data := make([]byte, 4+32+32, 4+32+32+(len(reason)+31)/32*32)
+	copy(data, revertSelector)
+	data[4+31] = 32 // offset of the string
+	binary.BigEndian.PutUint64(data[4+32+24:], uint64(len(reason)))
+	data = append(data, reason...)
+	return data[:cap(data)]
+}
+
+// revertSelector is the selector of Error(string).
+var revertSelector = []byte{0x08, 0xc3, 0x79, 0xa0}